@@ -108,6 +108,48 @@ function get_bundle() {
   fi
 }
 
+function run_step() {
+  step="$1"
+  step_started_at="$(date +%s)"
+  step_exit_code=0
+  /bin/bash -eEo pipefail -c "export TERM=xterm-256color; unset CDPATH; cd $BOOTSTRAP_DIR; source /var/lib/bashible/bashbooster.sh; source $step" 2>&1 | tee "$BOOTSTRAP_DIR/step.log" || step_exit_code="$?"
+
+  # save the result of the step to report it to the bashible-apiserver
+  jq -nc \
+    --arg name "$(basename "$step")" \
+    --arg checksum "$(sha256sum "$step" | cut -d " " -f 1)" \
+    --argjson exitCode "$step_exit_code" \
+    --arg duration "$(( $(date +%s) - step_started_at ))s" \
+    --arg output "$(tail -n 20 "$BOOTSTRAP_DIR/step.log")" \
+    --arg finishedAt "$(date -u +%Y-%m-%dT%H:%M:%SZ)" \
+    '{"name": $name, "checksum": $checksum, "exitCode": $exitCode, "duration": $duration, "output": $output, "finishedAt": $finishedAt}' >> "$STEPS_STATUS_FILE" || true
+
+  return "$step_exit_code"
+}
+
+function report_status() {
+  # status can be reported only by a node with kubelet credentials
+  if ! type kubectl >/dev/null 2>&1 || ! test -f /etc/kubernetes/kubelet.conf ; then
+    return 0
+  fi
+
+  # only the last attempt of every step is reported
+  jq -s \
+    --arg node "$(hostname -s)" \
+    --arg nodeGroup "$NODE_GROUP" \
+    --arg checksum "$CONFIGURATION_CHECKSUM" \
+    '{
+      "apiVersion": "bashible.deckhouse.io/v1alpha1",
+      "kind": "BashibleStatus",
+      "metadata": {"name": $node},
+      "nodeGroup": $nodeGroup,
+      "configurationChecksum": $checksum,
+      "steps": (reduce .[] as $s ({}; .[$s.name] = $s) | [.[]])
+    }' "$STEPS_STATUS_FILE" > "$BOOTSTRAP_DIR/bashible-status.json" &&
+  kubectl_exec create --raw /apis/bashible.deckhouse.io/v1alpha1/bashiblestatuses -f "$BOOTSTRAP_DIR/bashible-status.json" >/dev/null ||
+  >&2 echo "Failed to report bashible status, skipping."
+}
+
 function main() {
   # IMPORTANT !!! Do not remove this line, because in Centos/Redhat when dhctl bootstraps the cluster /usr/local/bin not in PATH.
   export PATH="/usr/local/bin:$PATH"
@@ -115,6 +157,7 @@ function main() {
   export BUNDLE_STEPS_DIR="$BOOTSTRAP_DIR/bundle_steps"
   export BUNDLE="{{ .bundle }}"
   export CONFIGURATION_CHECKSUM_FILE="/var/lib/bashible/configuration_checksum"
+  export STEPS_STATUS_FILE="$BOOTSTRAP_DIR/steps_status"
   export CONFIGURATION_CHECKSUM="{{ .configurationChecksum | default "" }}"
   export FIRST_BASHIBLE_RUN="no"
   export NODE_GROUP="{{ .nodeGroup.name }}"
@@ -234,13 +277,20 @@ function main() {
 {{ end }}
 
   # Execute bashible steps
+  rm -f "$STEPS_STATUS_FILE"
   for step in $BUNDLE_STEPS_DIR/*; do
     echo ===
     echo === Step: $step
     echo ===
     attempt=0
-    until /bin/bash -eEo pipefail -c "export TERM=xterm-256color; unset CDPATH; cd $BOOTSTRAP_DIR; source /var/lib/bashible/bashbooster.sh; source $step"
+    reported_at=0
+    until run_step "$step"
     do
+      # report the first failure at once, the step can be retried for a long time, so further failures are reported every 5 minutes
+      if [ "$(( $(date +%s) - reported_at ))" -ge 300 ]; then
+        report_status
+        reported_at="$(date +%s)"
+      fi
       attempt=$(( attempt + 1 ))
       if [ -n "${MAX_RETRIES-}" ] && [ "$attempt" -gt "${MAX_RETRIES}" ]; then
         >&2 echo "ERROR: Failed to execute step $step. Retry limit is over."
@@ -253,6 +303,7 @@ function main() {
       echo ===
     done
   done
+  report_status
 
 {{ if eq .runType "Normal" }}
   annotate_node node.deckhouse.io/configuration-checksum=${CONFIGURATION_CHECKSUM}
@@ -42,6 +42,7 @@ import (
 	_ "github.com/deckhouse/deckhouse/modules/040-control-plane-manager/requirements"
 	_ "github.com/deckhouse/deckhouse/modules/040-node-manager/hooks"
 	_ "github.com/deckhouse/deckhouse/modules/040-node-manager/hooks/pkg/schema"
+	_ "github.com/deckhouse/deckhouse/modules/040-terraform-manager/hooks"
 	_ "github.com/deckhouse/deckhouse/modules/041-linstor/hooks"
 	_ "github.com/deckhouse/deckhouse/modules/042-kube-dns/hooks"
 	_ "github.com/deckhouse/deckhouse/modules/045-snapshot-controller/hooks"
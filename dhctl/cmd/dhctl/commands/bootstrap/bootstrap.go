@@ -17,6 +17,7 @@ package bootstrap
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"gopkg.in/alecthomas/kingpin.v2"
@@ -30,12 +31,14 @@ import (
 	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/operations"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/operations/bootstrap"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/preflight"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/state"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/state/cache"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/system/ssh"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/template"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/terminal"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/terraform"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/util/retry"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/util/tomb"
 )
 
@@ -139,6 +142,92 @@ func setBastionHostFromCloudProvider(host string, sshClient *ssh.Client) {
 	}
 }
 
+func runBaseInfrastructure(metaConfig *config.MetaConfig, stateCache state.Cache) (*bootstrap.BaseInfraOutputs, error) {
+	var outputs *bootstrap.BaseInfraOutputs
+	err := log.Process("bootstrap", "Cloud infrastructure", func() error {
+		baseRunner := terraform.NewRunnerFromConfig(metaConfig, "base-infrastructure", stateCache).
+			WithVariables(metaConfig.MarshalConfig()).
+			WithAutoApprove(true)
+		tomb.RegisterOnShutdown("base-infrastructure", baseRunner.Stop)
+
+		baseOutputs, err := terraform.ApplyPipeline(baseRunner, "Kubernetes cluster", terraform.GetBaseInfraResult)
+		if err != nil {
+			return err
+		}
+
+		masterNodeName := fmt.Sprintf("%s-master-0", metaConfig.ClusterPrefix)
+		masterRunner := terraform.NewRunnerFromConfig(metaConfig, "master-node", stateCache).
+			WithVariables(metaConfig.NodeGroupConfig("master", 0, "")).
+			WithName(masterNodeName).
+			WithAutoApprove(true)
+		tomb.RegisterOnShutdown(masterNodeName, masterRunner.Stop)
+
+		masterOutputs, err := terraform.ApplyPipeline(masterRunner, masterNodeName, terraform.GetMasterNodeResult)
+		if err != nil {
+			return err
+		}
+
+		outputs = &bootstrap.BaseInfraOutputs{
+			BaseTerraformState: baseOutputs.TerraformState,
+			CloudDiscovery:     baseOutputs.CloudDiscovery,
+			BastionHost:        baseOutputs.BastionHost,
+
+			MasterNodeName:       masterNodeName,
+			MasterTerraformState: masterOutputs.TerraformState,
+			MasterIPForSSH:       masterOutputs.MasterIPForSSH,
+			NodeInternalIP:       masterOutputs.NodeInternalIP,
+			KubeDataDevicePath:   masterOutputs.KubeDataDevicePath,
+		}
+		return nil
+	})
+	return outputs, err
+}
+
+func applyBaseInfraOutputs(outputs *bootstrap.BaseInfraOutputs, sshClient *ssh.Client, masterAddressesForSSH map[string]string) {
+	if outputs.BastionHost != "" {
+		setBastionHostFromCloudProvider(outputs.BastionHost, sshClient)
+		operations.SaveBastionHostToCache(outputs.BastionHost)
+	}
+
+	app.SSHHosts = []string{outputs.MasterIPForSSH}
+	sshClient.Settings.SetAvailableHosts(app.SSHHosts)
+
+	masterAddressesForSSH[outputs.MasterNodeName] = outputs.MasterIPForSSH
+	operations.SaveMasterHostsToCache(masterAddressesForSSH)
+}
+
+func checkMasterIsReachable(sshClient *ssh.Client) error {
+	return retry.NewLoop("Check master node is reachable", 5, 5*time.Second).Run(func() error {
+		output, err := sshClient.Check().ExpectAvailable()
+		if err != nil {
+			return fmt.Errorf("master node is not reachable: %v %s", err, string(output))
+		}
+		return nil
+	})
+}
+
+func checkAdditionalNodesExist(kubeCl *client.KubernetesClient, metaConfig *config.MetaConfig) error {
+	nodeGroups := map[string]int{converge.MasterNodeGroupName: metaConfig.MasterNodeGroupSpec.Replicas}
+	for _, ng := range metaConfig.GetTerraNodeGroups() {
+		nodeGroups[ng.Name] = ng.Replicas
+	}
+
+	for nodeGroupName, replicas := range nodeGroups {
+		for i := 0; i < replicas; i++ {
+			nodeName := fmt.Sprintf("%s-%s-%v", metaConfig.ClusterPrefix, nodeGroupName, i)
+			exists, err := converge.IsNodeExistsInCluster(kubeCl, nodeName)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return fmt.Errorf("node %s not found", nodeName)
+			}
+		}
+	}
+
+	return nil
+}
+
 func DefineBootstrapCommand(kpApp *kingpin.Application) *kingpin.CmdClause {
 	cmd := kpApp.Command("bootstrap", "Bootstrap cluster.")
 	app.DefineSSHFlags(cmd)
@@ -150,6 +239,8 @@ func DefineBootstrapCommand(kpApp *kingpin.Application) *kingpin.CmdClause {
 	app.DefineDeckhouseFlags(cmd)
 	app.DefineDontUsePublicImagesFlags(cmd)
 	app.DefinePostBootstrapScriptFlags(cmd)
+	app.DefineResumeFlags(cmd)
+	app.DefinePreflightFlags(cmd, preflight.CheckNames())
 
 	runFunc := func() error {
 		masterAddressesForSSH := make(map[string]string)
@@ -195,6 +286,14 @@ func DefineBootstrapCommand(kpApp *kingpin.Application) *kingpin.CmdClause {
 
 		showWarningAboutUsageDontUsePublicImagesFlagIfNeed()
 
+		// the master node is already changed by the previous bootstrap, e.g. ports are busy
+		if !app.ResumeBootstrap {
+			err = preflight.RunChecks(&preflight.Input{MetaConfig: metaConfig, SSHClient: sshClient})
+			if err != nil {
+				return err
+			}
+		}
+
 		bootstrapState := bootstrap.NewBootstrapState(stateCache)
 
 		clusterUUID, err := generateClusterUUID(stateCache)
@@ -212,68 +311,66 @@ func DefineBootstrapCommand(kpApp *kingpin.Application) *kingpin.CmdClause {
 		deckhouseInstallConfig.KubeadmBootstrap = true
 		deckhouseInstallConfig.MasterNodeSelector = true
 
+		journal, err := bootstrap.NewJournal(stateCache, app.ResumeBootstrap)
+		if err != nil {
+			return err
+		}
+
 		var nodeIP string
 		var devicePath string
 		var resourcesTemplateData map[string]interface{}
 
 		if metaConfig.ClusterType == config.CloudClusterType {
-			err = log.Process("bootstrap", "Cloud infrastructure", func() error {
-				baseRunner := terraform.NewRunnerFromConfig(metaConfig, "base-infrastructure", stateCache).
-					WithVariables(metaConfig.MarshalConfig()).
-					WithAutoApprove(true)
-				tomb.RegisterOnShutdown("base-infrastructure", baseRunner.Stop)
+			var baseInfra *bootstrap.BaseInfraOutputs
 
-				baseOutputs, err := terraform.ApplyPipeline(baseRunner, "Kubernetes cluster", terraform.GetBaseInfraResult)
+			validateBaseInfra := func() error {
+				outputs, err := bootstrapState.BaseInfraOutputs()
 				if err != nil {
 					return err
 				}
 
-				var cloudDiscoveryData map[string]interface{}
-				err = json.Unmarshal(baseOutputs.CloudDiscovery, &cloudDiscoveryData)
-				if err != nil {
+				applyBaseInfraOutputs(outputs, sshClient, masterAddressesForSSH)
+				if err := checkMasterIsReachable(sshClient); err != nil {
 					return err
 				}
 
-				resourcesTemplateData = map[string]interface{}{
-					"cloudDiscovery": cloudDiscoveryData,
-				}
-
-				masterNodeName := fmt.Sprintf("%s-master-0", metaConfig.ClusterPrefix)
-				masterRunner := terraform.NewRunnerFromConfig(metaConfig, "master-node", stateCache).
-					WithVariables(metaConfig.NodeGroupConfig("master", 0, "")).
-					WithName(masterNodeName).
-					WithAutoApprove(true)
-				tomb.RegisterOnShutdown(masterNodeName, masterRunner.Stop)
+				baseInfra = outputs
+				return nil
+			}
 
-				masterOutputs, err := terraform.ApplyPipeline(masterRunner, masterNodeName, terraform.GetMasterNodeResult)
+			err = journal.RunPhase(bootstrap.BaseInfraPhase, validateBaseInfra, func() error {
+				outputs, err := runBaseInfrastructure(metaConfig, stateCache)
 				if err != nil {
 					return err
 				}
 
-				deckhouseInstallConfig.CloudDiscovery = baseOutputs.CloudDiscovery
-				deckhouseInstallConfig.TerraformState = baseOutputs.TerraformState
-
-				if baseOutputs.BastionHost != "" {
-					setBastionHostFromCloudProvider(baseOutputs.BastionHost, sshClient)
-					operations.SaveBastionHostToCache(baseOutputs.BastionHost)
-				}
-
-				app.SSHHosts = []string{masterOutputs.MasterIPForSSH}
-				sshClient.Settings.SetAvailableHosts(app.SSHHosts)
-
-				nodeIP = masterOutputs.NodeInternalIP
-				devicePath = masterOutputs.KubeDataDevicePath
+				applyBaseInfraOutputs(outputs, sshClient, masterAddressesForSSH)
 
-				deckhouseInstallConfig.NodesTerraformState = make(map[string][]byte)
-				deckhouseInstallConfig.NodesTerraformState[masterNodeName] = masterOutputs.TerraformState
-
-				masterAddressesForSSH[masterNodeName] = masterOutputs.MasterIPForSSH
-				operations.SaveMasterHostsToCache(masterAddressesForSSH)
-				return nil
+				baseInfra = outputs
+				return bootstrapState.SaveBaseInfraOutputs(outputs)
 			})
 			if err != nil {
 				return err
 			}
+
+			var cloudDiscoveryData map[string]interface{}
+			err = json.Unmarshal(baseInfra.CloudDiscovery, &cloudDiscoveryData)
+			if err != nil {
+				return err
+			}
+
+			resourcesTemplateData = map[string]interface{}{
+				"cloudDiscovery": cloudDiscoveryData,
+			}
+
+			deckhouseInstallConfig.CloudDiscovery = baseInfra.CloudDiscovery
+			deckhouseInstallConfig.TerraformState = baseInfra.BaseTerraformState
+
+			nodeIP = baseInfra.NodeInternalIP
+			devicePath = baseInfra.KubeDataDevicePath
+
+			deckhouseInstallConfig.NodesTerraformState = make(map[string][]byte)
+			deckhouseInstallConfig.NodesTerraformState[baseInfra.MasterNodeName] = baseInfra.MasterTerraformState
 		} else {
 			var static struct {
 				NodeIP string `json:"nodeIP"`
@@ -298,20 +395,53 @@ func DefineBootstrapCommand(kpApp *kingpin.Application) *kingpin.CmdClause {
 		if err := operations.WaitForSSHConnectionOnMaster(sshClient); err != nil {
 			return err
 		}
-		if err := operations.RunBashiblePipeline(sshClient, metaConfig, nodeIP, devicePath); err != nil {
+
+		validateBashible := func() error {
+			if !operations.CheckBashibleBundle(sshClient) {
+				return fmt.Errorf("bashible is not installed on the master node")
+			}
+			return nil
+		}
+
+		err = journal.RunPhase(bootstrap.ExecuteBashiblePhase, validateBashible, func() error {
+			return operations.RunBashiblePipeline(sshClient, metaConfig, nodeIP, devicePath)
+		})
+		if err != nil {
 			return err
 		}
+
 		kubeCl, err := operations.ConnectToKubernetesAPI(sshClient)
 		if err != nil {
 			return err
 		}
-		if err := operations.InstallDeckhouse(kubeCl, deckhouseInstallConfig); err != nil {
+
+		validateDeckhouse := func() error {
+			exists, err := deckhouse.IsDeckhouseDeploymentExists(kubeCl)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return fmt.Errorf("deckhouse deployment not found")
+			}
+			return nil
+		}
+
+		err = journal.RunPhase(bootstrap.InstallDeckhousePhase, validateDeckhouse, func() error {
+			return operations.InstallDeckhouse(kubeCl, deckhouseInstallConfig)
+		})
+		if err != nil {
 			return err
 		}
 
 		if metaConfig.ClusterType == config.CloudClusterType {
-			err := converge.NewInLockLocalRunner(kubeCl, "local-bootstraper").Run(func() error {
-				return bootstrapAdditionalNodesForCloudCluster(kubeCl, metaConfig, masterAddressesForSSH)
+			validateNodes := func() error {
+				return checkAdditionalNodesExist(kubeCl, metaConfig)
+			}
+
+			err = journal.RunPhase(bootstrap.InstallAdditionalMastersAndStaticNodes, validateNodes, func() error {
+				return converge.NewInLockLocalRunner(kubeCl, "local-bootstraper").Run(func() error {
+					return bootstrapAdditionalNodesForCloudCluster(kubeCl, metaConfig, masterAddressesForSSH)
+				})
 			})
 			if err != nil {
 				return err
@@ -319,8 +449,11 @@ func DefineBootstrapCommand(kpApp *kingpin.Application) *kingpin.CmdClause {
 		}
 
 		if resourcesToCreate != nil {
-			err = log.Process("bootstrap", "Create Resources", func() error {
-				return resources.CreateResourcesLoop(kubeCl, resourcesToCreate)
+			// resources creation is idempotent, so completed phase does not need validation
+			err = journal.RunPhase(bootstrap.CreateResourcesPhase, nil, func() error {
+				return log.Process("bootstrap", "Create Resources", func() error {
+					return resources.CreateResourcesLoop(kubeCl, resourcesToCreate)
+				})
 			})
 			if err != nil {
 				return err
@@ -328,10 +461,13 @@ func DefineBootstrapCommand(kpApp *kingpin.Application) *kingpin.CmdClause {
 		}
 
 		if app.PostBootstrapScriptPath != "" {
-			postScriptExecutor := bootstrap.NewPostBootstrapScriptExecutor(sshClient, app.PostBootstrapScriptPath, bootstrapState).
-				WithTimeout(app.PostBootstrapScriptTimeout)
+			err = journal.RunPhase(bootstrap.ExecPostBootstrapPhase, nil, func() error {
+				postScriptExecutor := bootstrap.NewPostBootstrapScriptExecutor(sshClient, app.PostBootstrapScriptPath, bootstrapState).
+					WithTimeout(app.PostBootstrapScriptTimeout)
 
-			if err := postScriptExecutor.Execute(); err != nil {
+				return postScriptExecutor.Execute()
+			})
+			if err != nil {
 				return err
 			}
 		}
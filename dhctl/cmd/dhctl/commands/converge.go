@@ -15,10 +15,13 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"time"
 
 	"gopkg.in/alecthomas/kingpin.v2"
+	"sigs.k8s.io/yaml"
 
 	"github.com/deckhouse/deckhouse/dhctl/pkg/app"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/kubernetes/actions/converge"
@@ -34,6 +37,9 @@ func DefineConvergeCommand(kpApp *kingpin.Application) *kingpin.CmdClause {
 	app.DefineSSHFlags(cmd)
 	app.DefineBecomeFlags(cmd)
 	app.DefineKubeFlags(cmd)
+	app.DefineConvergeDryRunFlag(cmd)
+	app.DefineConvergeParallelismFlags(cmd)
+	app.DefineOutputFlag(cmd)
 
 	runFunc := func(sshClient *ssh.Client) error {
 		kubeCl, err := operations.ConnectToKubernetesAPI(sshClient)
@@ -41,6 +47,10 @@ func DefineConvergeCommand(kpApp *kingpin.Application) *kingpin.CmdClause {
 			return err
 		}
 
+		if app.ConvergeDryRun {
+			return printConvergePlan(kubeCl)
+		}
+
 		cacheIdentity := ""
 		if app.KubeConfigInCluster {
 			cacheIdentity = "in-cluster"
@@ -64,6 +74,7 @@ func DefineConvergeCommand(kpApp *kingpin.Application) *kingpin.CmdClause {
 		runner.WithChangeSettings(&terraform.ChangeActionSettings{
 			AutoDismissDestructive: false,
 		})
+		runner.WithMaxParallelNodes(app.ConvergeMaxParallelNodes, app.ConvergeNodeGroupMaxParallelNodes)
 
 		err = runner.RunConverge()
 		if err != nil {
@@ -84,6 +95,38 @@ func DefineConvergeCommand(kpApp *kingpin.Application) *kingpin.CmdClause {
 	return cmd
 }
 
+func printConvergePlan(kubeCl *client.KubernetesClient) error {
+	metaConfig, err := converge.GetMetaConfig(kubeCl)
+	if err != nil {
+		return err
+	}
+
+	plan, err := converge.BuildPlan(kubeCl, metaConfig)
+	if err != nil {
+		return fmt.Errorf("converge plan problem: %v", err)
+	}
+
+	var data []byte
+	switch app.OutputFormat {
+	case "yaml":
+		data, err = yaml.Marshal(plan)
+	case "json":
+		data, err = json.MarshalIndent(plan, "", "  ")
+	default:
+		return fmt.Errorf("Unknown output format %s", app.OutputFormat)
+	}
+	if err != nil {
+		return err
+	}
+
+	if app.ConvergePlanFile != "" {
+		return os.WriteFile(app.ConvergePlanFile, data, 0o600)
+	}
+
+	fmt.Println(string(data))
+	return nil
+}
+
 func DefineAutoConvergeCommand(kpApp *kingpin.Application) *kingpin.CmdClause {
 	cmd := kpApp.Command("converge-periodical", "Start service for periodical run converge.")
 	app.DefineAutoConvergeFlags(cmd)
@@ -64,7 +64,7 @@ func DefineReleaseConvergeLockCommand(parent *kingpin.CmdClause) *kingpin.CmdCla
 				return fmt.Errorf(autoConvergerErrorFmt, info)
 			}
 
-			c := input.NewConfirmation()
+			c := input.NewConfirmation().WithAction(input.ActionReleaseLock)
 			approve := c.WithMessage(fmt.Sprintf("Do you want to release lock:\n\n%s", info)).Ask()
 			if !approve {
 				return fmt.Errorf("Don't confirm release lock")
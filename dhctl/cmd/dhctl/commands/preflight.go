@@ -0,0 +1,51 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/app"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/config"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/preflight"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/system/ssh"
+)
+
+func DefinePreflightCommand(kpApp *kingpin.Application) *kingpin.CmdClause {
+	cmd := kpApp.Command("preflight", "Run preflight checks for the cluster bootstrap.")
+	app.DefineConfigFlags(cmd)
+	app.DefineSSHFlags(cmd)
+	app.DefineBecomeFlags(cmd)
+	app.DefinePreflightFlags(cmd, preflight.CheckNames())
+
+	cmd.Action(func(c *kingpin.ParseContext) error {
+		metaConfig, err := config.LoadConfigFromFile(app.ConfigPath)
+		if err != nil {
+			return err
+		}
+
+		// ssh checks are skipped if hosts are not passed
+		sshClient, err := ssh.NewInitClientFromFlags(true)
+		if err != nil {
+			return err
+		}
+
+		return preflight.RunChecks(&preflight.Input{
+			MetaConfig: metaConfig,
+			SSHClient:  sshClient,
+		})
+	})
+	return cmd
+}
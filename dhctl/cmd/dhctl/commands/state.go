@@ -0,0 +1,121 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/app"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/kubernetes/actions/converge"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/operations"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/system/ssh"
+)
+
+func DefineStateExportCommand(parent *kingpin.CmdClause) *kingpin.CmdClause {
+	cmd := parent.Command("export", "Export terraform states of the cluster and nodes into the archive.")
+	app.DefineSSHFlags(cmd)
+	app.DefineBecomeFlags(cmd)
+	app.DefineKubeFlags(cmd)
+	app.DefineStateExportFlags(cmd)
+
+	cmd.Action(func(c *kingpin.ParseContext) error {
+		sshClient, err := ssh.NewInitClientFromFlags(true)
+		if err != nil {
+			return err
+		}
+
+		kubeCl, err := operations.ConnectToKubernetesAPI(sshClient)
+		if err != nil {
+			return err
+		}
+
+		metaConfig, err := converge.GetMetaConfig(kubeCl)
+		if err != nil {
+			return err
+		}
+
+		archive, err := converge.ExportState(kubeCl, metaConfig)
+		if err != nil {
+			return err
+		}
+
+		file, err := os.OpenFile(app.StateArchivePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("create state archive: %v", err)
+		}
+		defer file.Close()
+
+		if err := converge.WriteStateArchive(file, archive); err != nil {
+			return fmt.Errorf("write state archive: %v", err)
+		}
+
+		log.InfoF("%d Secrets are exported into %s\n", len(archive.Secrets), app.StateArchivePath)
+		return nil
+	})
+	return cmd
+}
+
+func DefineStateImportCommand(parent *kingpin.CmdClause) *kingpin.CmdClause {
+	cmd := parent.Command("import", "Restore terraform states of the cluster and nodes from the archive.")
+	app.DefineSSHFlags(cmd)
+	app.DefineBecomeFlags(cmd)
+	app.DefineKubeFlags(cmd)
+	app.DefineStateImportFlags(cmd)
+
+	cmd.Action(func(c *kingpin.ParseContext) error {
+		file, err := os.Open(app.StateArchivePath)
+		if err != nil {
+			return fmt.Errorf("open state archive: %v", err)
+		}
+		defer file.Close()
+
+		archive, err := converge.ReadStateArchive(file)
+		if err != nil {
+			return err
+		}
+
+		sshClient, err := ssh.NewInitClientFromFlags(true)
+		if err != nil {
+			return err
+		}
+
+		kubeCl, err := operations.ConnectToKubernetesAPI(sshClient)
+		if err != nil {
+			return err
+		}
+
+		metaConfig, err := converge.StateImportMetaConfig(kubeCl, archive, app.ConfigPath)
+		if err != nil {
+			return err
+		}
+
+		// prevent concurrent converge while states are restored
+		inLockRunner := converge.NewInLockLocalRunner(kubeCl, "local-state-importer")
+		err = inLockRunner.Run(func() error {
+			return converge.ImportState(kubeCl, archive, metaConfig, app.StateImportForce)
+		})
+		if err != nil {
+			return err
+		}
+
+		log.InfoF("%d Secrets are restored from %s\n", len(archive.Secrets), app.StateArchivePath)
+		return nil
+	})
+	return cmd
+}
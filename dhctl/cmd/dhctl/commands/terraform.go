@@ -37,7 +37,8 @@ func DefineTerraformConvergeExporterCommand(parent *kingpin.CmdClause) *kingpin.
 	app.DefineBecomeFlags(cmd)
 
 	cmd.Action(func(c *kingpin.ParseContext) error {
-		exporter := operations.NewConvergeExporter(app.ListenAddress, app.MetricsPath, app.CheckInterval)
+		exporter := operations.NewConvergeExporter(app.ListenAddress, app.MetricsPath, app.CheckInterval).
+			WithStatusObject(app.StatusObject)
 		exporter.Start()
 		return nil
 	})
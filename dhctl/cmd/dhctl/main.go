@@ -15,9 +15,12 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"runtime/trace"
+	"sync"
+	"time"
 
 	"golang.org/x/crypto/ssh/terminal"
 	"gopkg.in/alecthomas/kingpin.v2"
@@ -26,14 +29,24 @@ import (
 	"github.com/deckhouse/deckhouse/dhctl/cmd/dhctl/commands/bootstrap"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/app"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/operations"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/system/process"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/util/cache"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/util/input"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/util/tomb"
 )
 
+var errInterrupted = errors.New("interrupted by signal")
+
 func main() {
 	_ = os.Mkdir(app.TmpDirName, 0o755)
 
+	result := newResultEmitter()
+	// Callbacks are called in reverse order, the result is emitted after all other callbacks.
+	// It is emitted here only if the command is interrupted by a signal, otherwise it is already emitted.
+	tomb.RegisterOnShutdown("Emit result", func() {
+		result.emit(errInterrupted, tomb.ExitCode())
+	})
 	tomb.RegisterOnShutdown("Trace", EnableTrace())
 	tomb.RegisterOnShutdown("Restore terminal if needed", restoreTerminal())
 	tomb.RegisterOnShutdown("Stop default SSH session", process.DefaultSession.Stop)
@@ -51,6 +64,7 @@ func main() {
 		return nil
 	})
 
+	commands.DefinePreflightCommand(kpApp)
 	bootstrap.DefineBootstrapCommand(kpApp)
 	bootstrapPhaseCmd := kpApp.Command("bootstrap-phase", "Commands to run a single phase of the bootstrap process.")
 	{
@@ -72,6 +86,12 @@ func main() {
 
 	commands.DefineDestroyCommand(kpApp)
 
+	stateCmd := kpApp.Command("state", "Backup and restore terraform states stored in the cluster.")
+	{
+		commands.DefineStateExportCommand(stateCmd)
+		commands.DefineStateImportCommand(stateCmd)
+	}
+
 	terraformCmd := kpApp.Command("terraform", "Terraform commands.")
 	{
 		commands.DefineTerraformConvergeExporterCommand(terraformCmd)
@@ -123,7 +143,20 @@ func main() {
 
 	kpApp.Action(func(c *kingpin.ParseContext) error {
 		log.InitLogger(app.LoggerType)
-		return nil
+
+		if c.SelectedCommand != nil {
+			result.setCommand(c.SelectedCommand.FullCommand())
+		}
+
+		if app.ConfirmationPolicyPath != "" {
+			policy, err := input.LoadPolicyFile(app.ConfirmationPolicyPath)
+			if err != nil {
+				return err
+			}
+			input.SetPolicy(policy)
+		}
+
+		return log.InitEventsOutput(app.EventsOutput)
 	})
 
 	kpApp.Version("v0.0.0").Author("Flant")
@@ -136,6 +169,10 @@ func main() {
 			log.ErrorLn(err)
 			errorCode = 1
 		}
+		if command != "" {
+			result.setCommand(command)
+		}
+		result.emit(err, errorCode)
 		tomb.Shutdown(errorCode)
 	}()
 
@@ -144,6 +181,49 @@ func main() {
 	os.Exit(exitCode)
 }
 
+// resultEmitter emits the result event and closes the events output only once,
+// either when the command is finished or when dhctl is shut down by a signal.
+type resultEmitter struct {
+	mu        sync.Mutex
+	once      sync.Once
+	command   string
+	startedAt time.Time
+}
+
+func newResultEmitter() *resultEmitter {
+	return &resultEmitter{startedAt: time.Now()}
+}
+
+func (r *resultEmitter) setCommand(command string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.command = command
+}
+
+func (r *resultEmitter) emit(err error, exitCode int) {
+	r.once.Do(func() {
+		r.mu.Lock()
+		command := r.command
+		r.mu.Unlock()
+
+		result := log.Event{
+			Type:            log.EventResult,
+			Command:         command,
+			Status:          log.EventStatusSuccess,
+			ExitCode:        &exitCode,
+			DurationSeconds: time.Since(r.startedAt).Seconds(),
+		}
+		if err != nil {
+			result.Status = log.EventStatusFailed
+			result.Error = err.Error()
+			result.ErrorClass = operations.ClassifyError(err)
+		}
+
+		log.EmitEvent(result)
+		log.CloseEventsOutput()
+	})
+}
+
 func EnableTrace() func() {
 	fName := os.Getenv("DHCTL_TRACE")
 	if fName == "" || fName == "0" || fName == "no" {
@@ -32,6 +32,9 @@ var (
 	SanityCheck = false
 	LoggerType  = "pretty"
 	IsDebug     = false
+
+	EventsOutput           = ""
+	ConfirmationPolicyPath = ""
 )
 
 func init() {
@@ -45,6 +48,12 @@ func GlobalFlags(cmd *kingpin.Application) {
 		Envar(configEnvName("LOGGER_TYPE")).
 		Default("pretty").
 		EnumVar(&LoggerType, "pretty", "simple", "json")
+	cmd.Flag("events-output", "Write newline-delimited JSON events into the file or into the file descriptor if the number is passed.").
+		Envar(configEnvName("EVENTS_OUTPUT")).
+		StringVar(&EventsOutput)
+	cmd.Flag("confirmation-policy", "Answer all confirmations by the rules from the policy file. Confirmations without a matching rule are denied.").
+		Envar(configEnvName("CONFIRMATION_POLICY")).
+		StringVar(&ConfirmationPolicyPath)
 	cmd.Flag("tmp-dir", "Set temporary directory for debug purposes.").
 		Envar(configEnvName("TMP_DIR")).
 		Default(TmpDirName).
@@ -36,6 +36,8 @@ var (
 
 	KubeadmBootstrap   = false
 	MasterNodeSelector = false
+
+	ResumeBootstrap = false
 )
 
 func DefineBashibleBundleFlags(cmd *kingpin.CmdClause) {
@@ -49,6 +51,13 @@ func DefineBashibleBundleFlags(cmd *kingpin.CmdClause) {
 		StringVar(&DevicePath)
 }
 
+func DefineResumeFlags(cmd *kingpin.CmdClause) {
+	cmd.Flag("resume", "Continue the previous bootstrap from the first incomplete phase. Completed phases are skipped if their outputs are still valid.").
+		Envar(configEnvName("RESUME")).
+		Default("false").
+		BoolVar(&ResumeBootstrap)
+}
+
 func DefineDeckhouseFlags(cmd *kingpin.CmdClause) {
 	cmd.Flag("deckhouse-timeout", "Timeout to install deckhouse. Experimental. This feature may be deleted in the future.").
 		Envar(configEnvName("DECKHOUSE_TIMEOUT")).
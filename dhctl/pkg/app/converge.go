@@ -15,6 +15,8 @@
 package app
 
 import (
+	"fmt"
+	"strconv"
 	"time"
 
 	"gopkg.in/alecthomas/kingpin.v2"
@@ -24,7 +26,14 @@ var (
 	MetricsPath   = "/metrics"
 	ListenAddress = ":9101"
 	CheckInterval = time.Minute
+	StatusObject  = false
 	OutputFormat  = "yaml"
+
+	ConvergeDryRun   = false
+	ConvergePlanFile = ""
+
+	ConvergeMaxParallelNodes          = 1
+	ConvergeNodeGroupMaxParallelNodes = make(map[string]int)
 )
 
 func DefineConvergeExporterFlags(cmd *kingpin.CmdClause) {
@@ -37,6 +46,9 @@ func DefineConvergeExporterFlags(cmd *kingpin.CmdClause) {
 	cmd.Flag("check-interval", "Period to check terraform state converge").
 		Envar(configEnvName("CHECK_INTERVAL")).
 		DurationVar(&CheckInterval)
+	cmd.Flag("status-object", "Write drift details into the TerraformStateStatus object and create events on status changes").
+		Envar(configEnvName("STATUS_OBJECT")).
+		BoolVar(&StatusObject)
 }
 
 func DefineOutputFlag(cmd *kingpin.CmdClause) {
@@ -45,3 +57,51 @@ func DefineOutputFlag(cmd *kingpin.CmdClause) {
 		Short('o').
 		EnumVar(&OutputFormat, "yaml", "json")
 }
+
+func DefineConvergeDryRunFlag(cmd *kingpin.CmdClause) {
+	cmd.Flag("dry-run", "Do not apply changes, only output the plan of changes for the cluster and every NodeGroup.").
+		Envar(configEnvName("DRY_RUN")).
+		BoolVar(&ConvergeDryRun)
+	cmd.Flag("plan-file", "Write the plan of changes into the file instead of stdout (works only with --dry-run).").
+		Envar(configEnvName("PLAN_FILE")).
+		StringVar(&ConvergePlanFile)
+
+	cmd.PreAction(func(c *kingpin.ParseContext) error {
+		if ConvergePlanFile != "" && !ConvergeDryRun {
+			return fmt.Errorf("--plan-file works only with --dry-run")
+		}
+		return nil
+	})
+}
+
+func DefineConvergeParallelismFlags(cmd *kingpin.CmdClause) {
+	perNodeGroup := make(map[string]string)
+
+	cmd.Flag("max-parallel-nodes", "How many nodes of a NodeGroup can be converged at the same time. Master nodes are always converged one by one.").
+		Envar(configEnvName("MAX_PARALLEL_NODES")).
+		Default(strconv.Itoa(ConvergeMaxParallelNodes)).
+		IntVar(&ConvergeMaxParallelNodes)
+	cmd.Flag("node-group-max-parallel-nodes", "Override --max-parallel-nodes for the NodeGroup, for example: worker=5. Can be passed multiple times.").
+		Envar(configEnvName("NODE_GROUP_MAX_PARALLEL_NODES")).
+		StringMapVar(&perNodeGroup)
+
+	cmd.PreAction(func(c *kingpin.ParseContext) (err error) {
+		if ConvergeMaxParallelNodes < 1 {
+			return fmt.Errorf("--max-parallel-nodes should be greater than 0, got %d", ConvergeMaxParallelNodes)
+		}
+		ConvergeNodeGroupMaxParallelNodes, err = ParseNodeGroupMaxParallelNodes(perNodeGroup)
+		return err
+	})
+}
+
+func ParseNodeGroupMaxParallelNodes(values map[string]string) (map[string]int, error) {
+	res := make(map[string]int, len(values))
+	for nodeGroup, value := range values {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("--node-group-max-parallel-nodes for NodeGroup %s should be a positive number, got '%s'", nodeGroup, value)
+		}
+		res[nodeGroup] = n
+	}
+	return res, nil
+}
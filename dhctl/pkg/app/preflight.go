@@ -0,0 +1,64 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	PreflightSkipAll    = false
+	PreflightReportPath = ""
+
+	preflightSkipFlags = make(map[string]*bool)
+)
+
+// DefinePreflightFlags defines --preflight-skip-<check> flag for every check.
+func DefinePreflightFlags(cmd *kingpin.CmdClause, checkNames []string) {
+	cmd.Flag("preflight-skip-all", "Do not run preflight checks.").
+		Envar(configEnvName("PREFLIGHT_SKIP_ALL")).
+		Default("false").
+		BoolVar(&PreflightSkipAll)
+	cmd.Flag("preflight-report", "Write the preflight checks report in JSON format into the file.").
+		Envar(configEnvName("PREFLIGHT_REPORT")).
+		StringVar(&PreflightReportPath)
+
+	for _, name := range checkNames {
+		skip, ok := preflightSkipFlags[name]
+		if !ok {
+			skip = new(bool)
+			preflightSkipFlags[name] = skip
+		}
+
+		envName := strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+		cmd.Flag(fmt.Sprintf("preflight-skip-%s", name), fmt.Sprintf("Skip '%s' preflight check.", name)).
+			Envar(configEnvName("PREFLIGHT_SKIP_" + envName)).
+			Default("false").
+			BoolVar(skip)
+	}
+}
+
+func PreflightSkipChecks() []string {
+	names := make([]string, 0)
+	for name, skip := range preflightSkipFlags {
+		if *skip {
+			names = append(names, name)
+		}
+	}
+	return names
+}
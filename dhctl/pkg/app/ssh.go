@@ -25,6 +25,13 @@ import (
 
 const DefaultSSHAgentPrivateKeys = "~/.ssh/id_rsa"
 
+const (
+	// SSHTransportCLI runs ssh, scp, ssh-add and ssh-agent binaries
+	SSHTransportCLI = "cli"
+	// SSHTransportGo uses ssh client library and does not depend on binaries
+	SSHTransportGo = "gossh"
+)
+
 var (
 	SSHAgentPrivateKeys = make([]string, 0)
 	SSHPrivateKeys      = make([]string, 0)
@@ -35,6 +42,7 @@ var (
 	SSHHosts            = make([]string, 0)
 	SSHPort             = ""
 	SSHExtraArgs        = ""
+	SSHTransport        = SSHTransportCLI
 
 	AskBecomePass = false
 	BecomePass    = ""
@@ -67,6 +75,10 @@ func DefineSSHFlags(cmd *kingpin.CmdClause) {
 	cmd.Flag("ssh-extra-args", "extra args for ssh commands (-vvv)").
 		Envar(configEnvName("SSH_EXTRA_ARGS")).
 		StringVar(&SSHExtraArgs)
+	cmd.Flag("ssh-transport", "SSH implementation: 'cli' runs ssh, scp and ssh-agent binaries, 'gossh' uses built-in ssh client. ssh-extra-args are ignored by 'gossh'.").
+		Envar(configEnvName("SSH_TRANSPORT")).
+		Default(SSHTransport).
+		EnumVar(&SSHTransport, SSHTransportCLI, SSHTransportGo)
 
 	cmd.PreAction(func(c *kingpin.ParseContext) (err error) {
 		if len(SSHAgentPrivateKeys) == 0 {
@@ -0,0 +1,47 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	StateArchivePath = ""
+	StateImportForce = false
+)
+
+func DefineStateExportFlags(cmd *kingpin.CmdClause) {
+	cmd.Flag("output", "Path to the archive file to write terraform states into.").
+		Short('o').
+		Required().
+		Envar(configEnvName("STATE_OUTPUT")).
+		StringVar(&StateArchivePath)
+}
+
+func DefineStateImportFlags(cmd *kingpin.CmdClause) {
+	cmd.Flag("input", "Path to the archive file created by 'dhctl state export'.").
+		Short('i').
+		Required().
+		Envar(configEnvName("STATE_INPUT")).
+		StringVar(&StateArchivePath)
+	cmd.Flag("force", "Overwrite terraform states in the cluster even if they are newer than states in the archive.").
+		Envar(configEnvName("STATE_FORCE")).
+		Default("false").
+		BoolVar(&StateImportForce)
+	cmd.Flag("config", "Config file path. It is used if the cluster configuration is lost in the cluster.").
+		Envar(configEnvName("CONFIG")).
+		StringVar(&ConfigPath)
+}
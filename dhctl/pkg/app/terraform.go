@@ -28,6 +28,12 @@ const (
 	UseStateCacheNo  = "no"
 )
 
+const (
+	CacheBackendLocal     = "local"
+	CacheBackendEncrypted = "encrypted-local"
+	CacheBackendS3        = "s3"
+)
+
 var (
 	CacheDir   = filepath.Join(os.TempDir(), "dhctl")
 	UseTfCache = "ask"
@@ -40,6 +46,16 @@ var (
 	CacheKubeNamespace       = ""
 	CacheKubeName            = ""
 	CacheKubeLabels          = make(map[string]string)
+
+	CacheBackend                  = CacheBackendLocal
+	CacheEncryptionPassphraseFile = ""
+
+	CacheS3Endpoint        = ""
+	CacheS3Region          = ""
+	CacheS3Bucket          = ""
+	CacheS3Prefix          = ""
+	CacheS3AccessKeyID     = ""
+	CacheS3SecretAccessKey = ""
 )
 
 func DefineCacheFlags(cmd *kingpin.CmdClause) {
@@ -74,6 +90,39 @@ func DefineCacheFlags(cmd *kingpin.CmdClause) {
 	cmd.Flag("kube-cachestore-name", "Name for cache secret").
 		Envar(configEnvName("CACHE_STORE_KUBE_NAME")).
 		StringVar(&CacheKubeName)
+
+	cmd.Flag("cache-backend", fmt.Sprintf(`Storage for the cache. May be:
+	%s - plain files in the cache directory (Default)
+	%s - files in the cache directory encrypted with the passphrase
+	%s - objects in the S3-compatible bucket
+	`, CacheBackendLocal, CacheBackendEncrypted, CacheBackendS3)).
+		Envar(configEnvName("CACHE_BACKEND")).
+		Default(CacheBackendLocal).
+		EnumVar(&CacheBackend, CacheBackendLocal, CacheBackendEncrypted, CacheBackendS3)
+
+	cmd.Flag("cache-encryption-passphrase-file", "Path to the file with the passphrase for the encrypted-local cache.").
+		Envar(configEnvName("CACHE_ENCRYPTION_PASSPHRASE_FILE")).
+		StringVar(&CacheEncryptionPassphraseFile)
+
+	cmd.Flag("cache-s3-endpoint", "Endpoint of the S3-compatible storage with scheme, e.g. https://storage.example.com").
+		Envar(configEnvName("CACHE_S3_ENDPOINT")).
+		StringVar(&CacheS3Endpoint)
+	cmd.Flag("cache-s3-region", "Region of the S3-compatible storage.").
+		Envar(configEnvName("CACHE_S3_REGION")).
+		Default("us-east-1").
+		StringVar(&CacheS3Region)
+	cmd.Flag("cache-s3-bucket", "Bucket to store the cache.").
+		Envar(configEnvName("CACHE_S3_BUCKET")).
+		StringVar(&CacheS3Bucket)
+	cmd.Flag("cache-s3-prefix", "Prefix for the cache objects in the bucket. Cache identity is used by default.").
+		Envar(configEnvName("CACHE_S3_PREFIX")).
+		StringVar(&CacheS3Prefix)
+	cmd.Flag("cache-s3-access-key-id", "Access key id for the S3-compatible storage.").
+		Envar(configEnvName("CACHE_S3_ACCESS_KEY_ID")).
+		StringVar(&CacheS3AccessKeyID)
+	cmd.Flag("cache-s3-secret-access-key", "Secret access key for the S3-compatible storage.").
+		Envar(configEnvName("CACHE_S3_SECRET_ACCESS_KEY")).
+		StringVar(&CacheS3SecretAccessKey)
 }
 
 func DefineDropCacheFlags(cmd *kingpin.CmdClause) {
@@ -0,0 +1,525 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converge
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	k8errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/app"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/config"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/kubernetes/actions"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/kubernetes/actions/manifests"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/kubernetes/client"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/util/retry"
+)
+
+const (
+	StateArchiveVersion = 1
+
+	stateArchiveManifestFile = "manifest.json"
+
+	providerClusterConfigurationSecret = "d8-provider-cluster-configuration"
+	mastersDevicePathSecret            = "d8-masters-kubernetes-data-device-path"
+
+	clusterStateKey = "cluster-tf-state.json"
+	nodeStateKey    = "node-tf-state.json"
+)
+
+type StateArchiveManifest struct {
+	Version      int       `json:"version"`
+	CreatedAt    time.Time `json:"createdAt"`
+	DhctlVersion string    `json:"dhctlVersion"`
+
+	ClusterUUID string `json:"clusterUUID"`
+	ClusterType string `json:"clusterType"`
+	Provider    string `json:"provider,omitempty"`
+	Prefix      string `json:"prefix,omitempty"`
+	Layout      string `json:"layout,omitempty"`
+
+	// Checksums contains sha256 of every file in the archive except the manifest.
+	Checksums map[string]string `json:"checksums"`
+}
+
+// StateArchive contains Secrets with terraform states and cluster configuration.
+type StateArchive struct {
+	Manifest StateArchiveManifest
+	Secrets  []*apiv1.Secret
+}
+
+func (a *StateArchive) secretPath(secret *apiv1.Secret) string {
+	return path.Join("secrets", secret.Namespace, secret.Name+".json")
+}
+
+// ExportState collects terraform states of the cluster and nodes, cluster UUID and provider cluster configuration.
+func ExportState(kubeCl *client.KubernetesClient, metaConfig *config.MetaConfig) (*StateArchive, error) {
+	clusterUUID, err := GetClusterUUID(kubeCl)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &StateArchive{
+		Manifest: StateArchiveManifest{
+			Version:      StateArchiveVersion,
+			CreatedAt:    time.Now().UTC(),
+			DhctlVersion: app.AppVersion,
+			ClusterUUID:  clusterUUID,
+			ClusterType:  metaConfig.ClusterType,
+			Provider:     metaConfig.ProviderName,
+			Prefix:       metaConfig.ClusterPrefix,
+			Layout:       metaConfig.Layout,
+		},
+	}
+
+	err = retry.NewLoop("Get Terraform state Secrets from Kubernetes cluster", 5, 5*time.Second).Run(func() error {
+		archive.Secrets = make([]*apiv1.Secret, 0)
+
+		single := []struct{ namespace, name string }{
+			{"d8-system", manifests.TerraformClusterStateName},
+			{"d8-system", mastersDevicePathSecret},
+			{"kube-system", providerClusterConfigurationSecret},
+		}
+		for _, s := range single {
+			secret, err := kubeCl.CoreV1().Secrets(s.namespace).Get(context.TODO(), s.name, metav1.GetOptions{})
+			if err != nil {
+				if k8errors.IsNotFound(err) {
+					log.InfoF("Secret %s/%s not found, skip\n", s.namespace, s.name)
+					continue
+				}
+				return err
+			}
+			archive.Secrets = append(archive.Secrets, cleanSecret(secret))
+		}
+
+		nodeStates, err := kubeCl.CoreV1().Secrets("d8-system").List(context.TODO(), metav1.ListOptions{LabelSelector: "node.deckhouse.io/terraform-state"})
+		if err != nil {
+			return err
+		}
+		for i := range nodeStates.Items {
+			archive.Secrets = append(archive.Secrets, cleanSecret(&nodeStates.Items[i]))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(archive.Secrets, func(i, j int) bool {
+		return archive.secretPath(archive.Secrets[i]) < archive.secretPath(archive.Secrets[j])
+	})
+
+	for _, secret := range archive.Secrets {
+		log.InfoF("Export Secret %s/%s\n", secret.Namespace, secret.Name)
+	}
+
+	return archive, nil
+}
+
+// cleanSecret keeps only fields required to restore the Secret in another cluster.
+func cleanSecret(secret *apiv1.Secret) *apiv1.Secret {
+	return &apiv1.Secret{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secret.Name,
+			Namespace: secret.Namespace,
+			Labels:    secret.Labels,
+		},
+		Type: secret.Type,
+		Data: secret.Data,
+	}
+}
+
+// WriteStateArchive writes the manifest with checksums and Secrets as tar.gz.
+func WriteStateArchive(w io.Writer, archive *StateArchive) error {
+	files := make(map[string][]byte)
+	archive.Manifest.Checksums = make(map[string]string)
+
+	for _, secret := range archive.Secrets {
+		data, err := json.MarshalIndent(secret, "", "  ")
+		if err != nil {
+			return err
+		}
+		name := archive.secretPath(secret)
+		files[name] = data
+		archive.Manifest.Checksums[name] = checksum(data)
+	}
+
+	manifestData, err := json.MarshalIndent(archive.Manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if err := writeTarFile(tw, stateArchiveManifestFile, manifestData); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := writeTarFile(tw, name, files[name]); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func writeTarFile(tw *tar.Writer, name string, data []byte) error {
+	err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o600,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
+
+// ReadStateArchive reads the archive and verifies its version and checksums.
+func ReadStateArchive(r io.Reader) (*StateArchive, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("state archive is not a gzip file: %v", err)
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read state archive: %v", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("read %s from state archive: %v", header.Name, err)
+		}
+		files[header.Name] = data
+	}
+
+	manifestData, ok := files[stateArchiveManifestFile]
+	if !ok {
+		return nil, fmt.Errorf("%s is not found in the state archive", stateArchiveManifestFile)
+	}
+	delete(files, stateArchiveManifestFile)
+
+	archive := &StateArchive{}
+	if err := json.Unmarshal(manifestData, &archive.Manifest); err != nil {
+		return nil, fmt.Errorf("parse %s: %v", stateArchiveManifestFile, err)
+	}
+
+	if archive.Manifest.Version != StateArchiveVersion {
+		return nil, fmt.Errorf("state archive version %d is not supported, expected %d", archive.Manifest.Version, StateArchiveVersion)
+	}
+
+	for name := range archive.Manifest.Checksums {
+		if _, ok := files[name]; !ok {
+			return nil, fmt.Errorf("%s is listed in the manifest, but not found in the state archive", name)
+		}
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		data := files[name]
+		expected, ok := archive.Manifest.Checksums[name]
+		if !ok {
+			return nil, fmt.Errorf("%s is not listed in the manifest", name)
+		}
+		if actual := checksum(data); actual != expected {
+			return nil, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", name, expected, actual)
+		}
+
+		var secret apiv1.Secret
+		if err := json.Unmarshal(data, &secret); err != nil {
+			return nil, fmt.Errorf("parse %s: %v", name, err)
+		}
+		if secret.Name == "" || secret.Namespace == "" {
+			return nil, fmt.Errorf("%s: Secret name or namespace is empty", name)
+		}
+		archive.Secrets = append(archive.Secrets, &secret)
+	}
+
+	return archive, nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+type terraformStateVersion struct {
+	Serial  int64  `json:"serial"`
+	Lineage string `json:"lineage"`
+}
+
+func parseTerraformStateVersion(state []byte) (*terraformStateVersion, error) {
+	if len(state) == 0 {
+		return nil, nil
+	}
+
+	var version terraformStateVersion
+	if err := json.Unmarshal(state, &version); err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+func terraformStateOf(secret *apiv1.Secret) []byte {
+	if state, ok := secret.Data[clusterStateKey]; ok {
+		return state
+	}
+	return secret.Data[nodeStateKey]
+}
+
+// ValidateStateArchive checks that the archive belongs to the cluster described by metaConfig
+// and that terraform states in the cluster are not newer than the states in the archive.
+// Newer states and different lineages are returned as conflicts.
+func ValidateStateArchive(kubeCl *client.KubernetesClient, archive *StateArchive, metaConfig *config.MetaConfig) ([]string, error) {
+	m := archive.Manifest
+	mismatch := func(field, inArchive, inCluster string) error {
+		return fmt.Errorf("%s in the state archive (%q) does not match the cluster configuration (%q)", field, inArchive, inCluster)
+	}
+
+	switch {
+	case m.ClusterType != metaConfig.ClusterType:
+		return nil, mismatch("cluster type", m.ClusterType, metaConfig.ClusterType)
+	case m.Provider != metaConfig.ProviderName:
+		return nil, mismatch("provider", m.Provider, metaConfig.ProviderName)
+	case m.Prefix != metaConfig.ClusterPrefix:
+		return nil, mismatch("prefix", m.Prefix, metaConfig.ClusterPrefix)
+	case m.Layout != metaConfig.Layout:
+		return nil, mismatch("layout", m.Layout, metaConfig.Layout)
+	}
+
+	uuidConfigMap, err := kubeCl.CoreV1().ConfigMaps(manifests.ClusterUUIDCmNamespace).Get(context.TODO(), manifests.ClusterUUIDCm, metav1.GetOptions{})
+	switch {
+	case k8errors.IsNotFound(err):
+		// cluster UUID will be restored from the archive
+	case err != nil:
+		return nil, err
+	default:
+		if uuidInCluster := uuidConfigMap.Data[manifests.ClusterUUIDCmKey]; uuidInCluster != m.ClusterUUID {
+			return nil, mismatch("cluster UUID", m.ClusterUUID, uuidInCluster)
+		}
+	}
+
+	conflicts := make([]string, 0)
+	for _, secret := range archive.Secrets {
+		state := terraformStateOf(secret)
+		if state == nil {
+			continue
+		}
+
+		fromArchive, err := parseTerraformStateVersion(state)
+		if err != nil {
+			return nil, fmt.Errorf("parse terraform state in Secret %s/%s: %v", secret.Namespace, secret.Name, err)
+		}
+
+		current, err := kubeCl.CoreV1().Secrets(secret.Namespace).Get(context.TODO(), secret.Name, metav1.GetOptions{})
+		if err != nil {
+			if k8errors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		inCluster, err := parseTerraformStateVersion(terraformStateOf(current))
+		if err != nil {
+			return nil, fmt.Errorf("parse terraform state in Secret %s/%s from cluster: %v", secret.Namespace, secret.Name, err)
+		}
+
+		if conflict := stateConflict(fromArchive, inCluster); conflict != "" {
+			conflicts = append(conflicts, fmt.Sprintf("%s/%s: %s", secret.Namespace, secret.Name, conflict))
+		}
+	}
+
+	return conflicts, nil
+}
+
+func stateConflict(fromArchive, inCluster *terraformStateVersion) string {
+	if inCluster == nil || fromArchive == nil {
+		return ""
+	}
+
+	if inCluster.Lineage != "" && fromArchive.Lineage != inCluster.Lineage {
+		return fmt.Sprintf("lineage %q in the cluster differs from %q in the archive", inCluster.Lineage, fromArchive.Lineage)
+	}
+
+	if inCluster.Serial > fromArchive.Serial {
+		return fmt.Sprintf("serial %d in the cluster is newer than %d in the archive", inCluster.Serial, fromArchive.Serial)
+	}
+
+	return ""
+}
+
+// StateImportMetaConfig returns the cluster configuration to check the archive against.
+// It is parsed from configPath if it is set. Otherwise, the ClusterConfiguration is read from the cluster,
+// and the provider cluster configuration is read from the cluster or from the archive if it is lost.
+// The cluster UUID may be absent in the cluster, it is checked and restored by ImportState.
+func StateImportMetaConfig(kubeCl *client.KubernetesClient, archive *StateArchive, configPath string) (*config.MetaConfig, error) {
+	var metaConfig *config.MetaConfig
+	var err error
+
+	if configPath != "" {
+		metaConfig, err = config.ParseConfig(configPath)
+	} else {
+		var configData string
+		configData, err = stateImportConfigData(kubeCl, archive)
+		if err != nil {
+			return nil, err
+		}
+		metaConfig, err = config.ParseConfigFromData(configData)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	metaConfig.UUID = archive.Manifest.ClusterUUID
+	return metaConfig, nil
+}
+
+func stateImportConfigData(kubeCl *client.KubernetesClient, archive *StateArchive) (string, error) {
+	clusterConfig, err := kubeCl.CoreV1().Secrets("kube-system").Get(context.TODO(), "d8-cluster-configuration", metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("get cluster configuration, pass it with --config if it is lost: %v", err)
+	}
+
+	docs := []string{string(clusterConfig.Data["cluster-configuration.yaml"])}
+
+	providerConfig, err := kubeCl.CoreV1().Secrets("kube-system").Get(context.TODO(), providerClusterConfigurationSecret, metav1.GetOptions{})
+	switch {
+	case k8errors.IsNotFound(err):
+		for _, secret := range archive.Secrets {
+			if secret.Namespace == "kube-system" && secret.Name == providerClusterConfigurationSecret {
+				log.InfoF("Secret kube-system/%s not found, use the provider cluster configuration from the archive\n", providerClusterConfigurationSecret)
+				providerConfig = secret
+				break
+			}
+		}
+	case err != nil:
+		return "", err
+	}
+
+	if providerConfig != nil {
+		docs = append(docs, string(providerConfig.Data["cloud-provider-cluster-configuration.yaml"]))
+	}
+
+	return strings.Join(docs, "\n---\n"), nil
+}
+
+// ImportState restores Secrets from the archive. Provider cluster configuration and cluster UUID
+// are restored only if they are absent in the cluster.
+func ImportState(kubeCl *client.KubernetesClient, archive *StateArchive, metaConfig *config.MetaConfig, force bool) error {
+	conflicts, err := ValidateStateArchive(kubeCl, archive, metaConfig)
+	if err != nil {
+		return err
+	}
+
+	if len(conflicts) > 0 {
+		msg := fmt.Sprintf("terraform states in the cluster are newer or belong to other infrastructure:\n\t%s", strings.Join(conflicts, "\n\t"))
+		if !force {
+			return fmt.Errorf("%s\nUse --force to overwrite them", msg)
+		}
+		log.WarnF("%s\nThey will be overwritten because of --force\n", msg)
+	}
+
+	for _, secret := range archive.Secrets {
+		secret := secret
+		if secret.Namespace == "kube-system" && secret.Name == providerClusterConfigurationSecret {
+			if err := createSecretIfNotExists(kubeCl, secret); err != nil {
+				return err
+			}
+			continue
+		}
+
+		task := actions.ManifestTask{
+			Name:     fmt.Sprintf(`Secret "%s/%s"`, secret.Namespace, secret.Name),
+			Manifest: func() interface{} { return secret },
+			CreateFunc: func(manifest interface{}) error {
+				_, err := kubeCl.CoreV1().Secrets(secret.Namespace).Create(context.TODO(), manifest.(*apiv1.Secret), metav1.CreateOptions{})
+				return err
+			},
+			UpdateFunc: func(manifest interface{}) error {
+				_, err := kubeCl.CoreV1().Secrets(secret.Namespace).Update(context.TODO(), manifest.(*apiv1.Secret), metav1.UpdateOptions{})
+				return err
+			},
+		}
+
+		err := retry.NewLoop(fmt.Sprintf("Restore Secret %s/%s", secret.Namespace, secret.Name), 45, 10*time.Second).Run(task.CreateOrUpdate)
+		if err != nil {
+			return err
+		}
+	}
+
+	return retry.NewLoop("Restore cluster UUID", 45, 10*time.Second).Run(func() error {
+		_, err := kubeCl.CoreV1().ConfigMaps(manifests.ClusterUUIDCmNamespace).Create(context.TODO(), manifests.ClusterUUIDConfigMap(archive.Manifest.ClusterUUID), metav1.CreateOptions{})
+		if k8errors.IsAlreadyExists(err) {
+			return nil
+		}
+		return err
+	})
+}
+
+func createSecretIfNotExists(kubeCl *client.KubernetesClient, secret *apiv1.Secret) error {
+	return retry.NewLoop(fmt.Sprintf("Restore Secret %s/%s", secret.Namespace, secret.Name), 45, 10*time.Second).Run(func() error {
+		_, err := kubeCl.CoreV1().Secrets(secret.Namespace).Create(context.TODO(), secret, metav1.CreateOptions{})
+		if k8errors.IsAlreadyExists(err) {
+			log.InfoF("Secret %s/%s already exists, skip\n", secret.Namespace, secret.Name)
+			return nil
+		}
+		return err
+	})
+}
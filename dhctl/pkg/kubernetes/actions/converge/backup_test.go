@@ -0,0 +1,281 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converge
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/config"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/kubernetes/actions/manifests"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/kubernetes/client"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+)
+
+const testClusterUUID = "a1b2c3"
+
+func testMetaConfig() *config.MetaConfig {
+	return &config.MetaConfig{
+		ClusterType:   config.CloudClusterType,
+		ProviderName:  "openstack",
+		ClusterPrefix: "test",
+		Layout:        "standard",
+	}
+}
+
+func tfState(serial int, lineage string) []byte {
+	return []byte(fmt.Sprintf(`{"version": 4, "serial": %d, "lineage": %q}`, serial, lineage))
+}
+
+func createSecret(t *testing.T, kubeCl *client.KubernetesClient, secret *apiv1.Secret) {
+	_, err := kubeCl.CoreV1().Secrets(secret.Namespace).Create(context.TODO(), secret, metav1.CreateOptions{})
+	require.NoError(t, err)
+}
+
+func createUUID(t *testing.T, kubeCl *client.KubernetesClient, uuid string) {
+	_, err := kubeCl.CoreV1().ConfigMaps(manifests.ClusterUUIDCmNamespace).Create(context.TODO(), manifests.ClusterUUIDConfigMap(uuid), metav1.CreateOptions{})
+	require.NoError(t, err)
+}
+
+func newClusterWithState(t *testing.T) *client.KubernetesClient {
+	kubeCl := client.NewFakeKubernetesClient()
+	createUUID(t, kubeCl, testClusterUUID)
+	createSecret(t, kubeCl, manifests.SecretWithTerraformState(tfState(10, "cluster")))
+	createSecret(t, kubeCl, manifests.SecretWithNodeTerraformState("test-master-0", MasterNodeGroupName, tfState(3, "master-0"), nil))
+	createSecret(t, kubeCl, manifests.SecretWithNodeTerraformState("test-worker-0", "worker", tfState(5, "worker-0"), []byte(`{"replicas": 1}`)))
+	createSecret(t, kubeCl, manifests.SecretMasterDevicePath("test-master-0", []byte("/dev/vdb")))
+	createSecret(t, kubeCl, manifests.SecretWithProviderClusterConfig([]byte("kind: OpenStackClusterConfiguration"), []byte("{}")))
+	return kubeCl
+}
+
+func exportArchive(t *testing.T, kubeCl *client.KubernetesClient) []byte {
+	archive, err := ExportState(kubeCl, testMetaConfig())
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteStateArchive(&buf, archive))
+	return buf.Bytes()
+}
+
+func TestStateArchiveRoundTrip(t *testing.T) {
+	log.InitLogger("simple")
+
+	data := exportArchive(t, newClusterWithState(t))
+
+	archive, err := ReadStateArchive(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	require.Equal(t, StateArchiveVersion, archive.Manifest.Version)
+	require.Equal(t, testClusterUUID, archive.Manifest.ClusterUUID)
+	require.Equal(t, "openstack", archive.Manifest.Provider)
+	require.Len(t, archive.Manifest.Checksums, 5)
+
+	names := make([]string, 0)
+	for _, secret := range archive.Secrets {
+		names = append(names, secret.Namespace+"/"+secret.Name)
+	}
+	require.Equal(t, []string{
+		"d8-system/d8-cluster-terraform-state",
+		"d8-system/d8-masters-kubernetes-data-device-path",
+		"d8-system/d8-node-terraform-state-test-master-0",
+		"d8-system/d8-node-terraform-state-test-worker-0",
+		"kube-system/d8-provider-cluster-configuration",
+	}, names)
+
+	// restore into the cluster with lost states
+	kubeCl := client.NewFakeKubernetesClient()
+	require.NoError(t, ImportState(kubeCl, archive, testMetaConfig(), false))
+
+	nodesState, err := GetNodesStateFromCluster(kubeCl)
+	require.NoError(t, err)
+	require.Equal(t, tfState(5, "worker-0"), nodesState["worker"].State["test-worker-0"])
+	require.Equal(t, []byte(`{"replicas": 1}`), nodesState["worker"].Settings)
+	require.Equal(t, tfState(3, "master-0"), nodesState[MasterNodeGroupName].State["test-master-0"])
+
+	clusterState, err := GetClusterStateFromCluster(kubeCl)
+	require.NoError(t, err)
+	require.Equal(t, tfState(10, "cluster"), clusterState)
+
+	uuid, err := GetClusterUUID(kubeCl)
+	require.NoError(t, err)
+	require.Equal(t, testClusterUUID, uuid)
+}
+
+// rewriteArchive applies fn to every file of the archive.
+func rewriteArchive(t *testing.T, data []byte, fn func(name string, content []byte) []byte) []byte {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		content, err := ioutil.ReadAll(tr)
+		require.NoError(t, err)
+
+		content = fn(header.Name, content)
+		require.NoError(t, writeTarFile(tw, header.Name, content))
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gzw.Close())
+	return buf.Bytes()
+}
+
+func TestReadStateArchiveValidation(t *testing.T) {
+	log.InitLogger("simple")
+
+	data := exportArchive(t, newClusterWithState(t))
+
+	t.Run("Corrupted file", func(t *testing.T) {
+		corrupted := rewriteArchive(t, data, func(name string, content []byte) []byte {
+			if name == "secrets/d8-system/d8-cluster-terraform-state.json" {
+				return bytes.Replace(content, []byte("data"), []byte("DATA"), 1)
+			}
+			return content
+		})
+
+		_, err := ReadStateArchive(bytes.NewReader(corrupted))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "checksum mismatch")
+	})
+
+	t.Run("Unsupported version", func(t *testing.T) {
+		newer := rewriteArchive(t, data, func(name string, content []byte) []byte {
+			if name == stateArchiveManifestFile {
+				return bytes.Replace(content, []byte(`"version": 1`), []byte(`"version": 2`), 1)
+			}
+			return content
+		})
+
+		_, err := ReadStateArchive(bytes.NewReader(newer))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "version 2 is not supported")
+	})
+
+	t.Run("Not an archive", func(t *testing.T) {
+		_, err := ReadStateArchive(bytes.NewReader([]byte("plain text")))
+		require.Error(t, err)
+	})
+}
+
+func TestImportStateConflicts(t *testing.T) {
+	log.InitLogger("simple")
+
+	archive, err := ReadStateArchive(bytes.NewReader(exportArchive(t, newClusterWithState(t))))
+	require.NoError(t, err)
+
+	t.Run("Another cluster configuration", func(t *testing.T) {
+		metaConfig := testMetaConfig()
+		metaConfig.ClusterPrefix = "other"
+
+		err := ImportState(client.NewFakeKubernetesClient(), archive, metaConfig, true)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "prefix")
+	})
+
+	t.Run("Another cluster UUID", func(t *testing.T) {
+		kubeCl := client.NewFakeKubernetesClient()
+		createUUID(t, kubeCl, "other-uuid")
+
+		err := ImportState(kubeCl, archive, testMetaConfig(), true)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "cluster UUID")
+	})
+
+	t.Run("Newer serial in the cluster", func(t *testing.T) {
+		kubeCl := client.NewFakeKubernetesClient()
+		createUUID(t, kubeCl, testClusterUUID)
+		createSecret(t, kubeCl, manifests.SecretWithNodeTerraformState("test-worker-0", "worker", tfState(6, "worker-0"), nil))
+
+		err := ImportState(kubeCl, archive, testMetaConfig(), false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "serial 6 in the cluster is newer than 5 in the archive")
+
+		// nothing is restored
+		_, err = kubeCl.CoreV1().Secrets("d8-system").Get(context.TODO(), manifests.TerraformClusterStateName, metav1.GetOptions{})
+		require.Error(t, err)
+
+		require.NoError(t, ImportState(kubeCl, archive, testMetaConfig(), true))
+
+		nodesState, err := GetNodesStateFromCluster(kubeCl)
+		require.NoError(t, err)
+		require.Equal(t, tfState(5, "worker-0"), nodesState["worker"].State["test-worker-0"])
+	})
+
+	t.Run("Another lineage in the cluster", func(t *testing.T) {
+		kubeCl := client.NewFakeKubernetesClient()
+		createSecret(t, kubeCl, manifests.SecretWithTerraformState(tfState(1, "recreated")))
+
+		conflicts, err := ValidateStateArchive(kubeCl, archive, testMetaConfig())
+		require.NoError(t, err)
+		require.Len(t, conflicts, 1)
+		require.Contains(t, conflicts[0], `lineage "recreated" in the cluster differs`)
+	})
+
+	t.Run("Older serial in the cluster", func(t *testing.T) {
+		kubeCl := client.NewFakeKubernetesClient()
+		createSecret(t, kubeCl, manifests.SecretWithTerraformState(tfState(9, "cluster")))
+
+		conflicts, err := ValidateStateArchive(kubeCl, archive, testMetaConfig())
+		require.NoError(t, err)
+		require.Empty(t, conflicts)
+	})
+}
+
+func TestImportStateIntoClusterWithoutConfiguration(t *testing.T) {
+	log.InitLogger("simple")
+
+	archive, err := ReadStateArchive(bytes.NewReader(exportArchive(t, newClusterWithState(t))))
+	require.NoError(t, err)
+
+	kubeCl := client.NewFakeKubernetesClient()
+
+	_, err = stateImportConfigData(kubeCl, archive)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "--config")
+
+	createSecret(t, kubeCl, manifests.SecretWithClusterConfig([]byte("kind: ClusterConfiguration")))
+
+	configData, err := stateImportConfigData(kubeCl, archive)
+	require.NoError(t, err)
+	require.Equal(t, "kind: ClusterConfiguration\n---\nkind: OpenStackClusterConfiguration", configData)
+
+	require.NoError(t, ImportState(kubeCl, archive, testMetaConfig(), false))
+
+	providerConfig, err := kubeCl.CoreV1().Secrets("kube-system").Get(context.TODO(), providerClusterConfigurationSecret, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []byte("kind: OpenStackClusterConfiguration"), providerConfig.Data["cloud-provider-cluster-configuration.yaml"])
+
+	clusterUUID, err := GetClusterUUID(kubeCl)
+	require.NoError(t, err)
+	require.Equal(t, testClusterUUID, clusterUUID)
+}
@@ -37,18 +37,25 @@ const (
 )
 
 type ClusterCheckResult struct {
-	Status string `json:"status,omitempty"`
+	Status  string `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+	// Changes contain attribute values, they are not marshalled because values can be sensitive
+	Changes []terraform.ResourceChange `json:"-"`
 }
 
 type NodeCheckResult struct {
-	Group  string `json:"group,omitempty"`
-	Name   string `json:"name,omitempty"`
-	Status string `json:"status,omitempty"`
+	Group   string `json:"group,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Status  string `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+	// Changes contain attribute values, they are not marshalled because values can be sensitive
+	Changes []terraform.ResourceChange `json:"-"`
 }
 
 type NodeGroupCheckResult struct {
-	Name   string `json:"name,omitempty"`
-	Status string `json:"status,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Status  string `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
 }
 
 type Statistics struct {
@@ -57,14 +64,23 @@ type Statistics struct {
 	Cluster       ClusterCheckResult     `json:"cluster,omitempty"`
 }
 
-func checkClusterState(kubeCl *client.KubernetesClient, metaConfig *config.MetaConfig) (int, error) {
+// planChanges returns resource changes of the last plan of the runner if the plan has changes.
+func planChanges(r *terraform.Runner, changed int) ([]terraform.ResourceChange, error) {
+	if changed == terraform.PlanHasNoChanges {
+		return nil, nil
+	}
+
+	return r.GetPlanResourceChanges()
+}
+
+func checkClusterState(kubeCl *client.KubernetesClient, metaConfig *config.MetaConfig) (int, []terraform.ResourceChange, error) {
 	clusterState, err := GetClusterStateFromCluster(kubeCl)
 	if err != nil {
-		return terraform.PlanHasNoChanges, fmt.Errorf("terraform cluster state in Kubernetes cluster not found: %w", err)
+		return terraform.PlanHasNoChanges, nil, fmt.Errorf("terraform cluster state in Kubernetes cluster not found: %w", err)
 	}
 
 	if clusterState == nil {
-		return terraform.PlanHasNoChanges, fmt.Errorf("kubernetes cluster has no state")
+		return terraform.PlanHasNoChanges, nil, fmt.Errorf("kubernetes cluster has no state")
 	}
 
 	baseRunner := terraform.NewImmutableRunnerFromConfig(metaConfig, "base-infrastructure").
@@ -73,13 +89,19 @@ func checkClusterState(kubeCl *client.KubernetesClient, metaConfig *config.MetaC
 		WithAutoApprove(true)
 	tomb.RegisterOnShutdown("base-infrastructure", baseRunner.Stop)
 
-	return terraform.CheckBaseInfrastructurePipeline(baseRunner, "Kubernetes cluster")
+	changed, err := terraform.CheckBaseInfrastructurePipeline(baseRunner, "Kubernetes cluster")
+	if err != nil {
+		return changed, nil, err
+	}
+
+	changes, err := planChanges(baseRunner, changed)
+	return changed, changes, err
 }
 
-func checkNodeState(metaConfig *config.MetaConfig, nodeGroup *NodeGroupGroupOptions, nodeName string) (int, error) {
+func checkNodeState(metaConfig *config.MetaConfig, nodeGroup *NodeGroupGroupOptions, nodeName string) (int, []terraform.ResourceChange, error) {
 	index, ok := getIndexFromNodeName(nodeName)
 	if !ok {
-		return terraform.PlanHasNoChanges, fmt.Errorf("can't extract index from terraform state secret, skip %s", nodeName)
+		return terraform.PlanHasNoChanges, nil, fmt.Errorf("can't extract index from terraform state secret, skip %s", nodeName)
 	}
 
 	nodeRunner := terraform.NewImmutableRunnerFromConfig(metaConfig, nodeGroup.Step).
@@ -88,7 +110,13 @@ func checkNodeState(metaConfig *config.MetaConfig, nodeGroup *NodeGroupGroupOpti
 		WithName(nodeName)
 	tomb.RegisterOnShutdown(nodeName, nodeRunner.Stop)
 
-	return terraform.CheckPipeline(nodeRunner, nodeName)
+	changed, err := terraform.CheckPipeline(nodeRunner, nodeName)
+	if err != nil {
+		return changed, nil, err
+	}
+
+	changes, err := planChanges(nodeRunner, changed)
+	return changed, changes, err
 }
 
 func CheckState(kubeCl *client.KubernetesClient, metaConfig *config.MetaConfig) (*Statistics, error) {
@@ -100,15 +128,21 @@ func CheckState(kubeCl *client.KubernetesClient, metaConfig *config.MetaConfig)
 
 	var allErrs *multierror.Error
 
-	clusterChanged, err := checkClusterState(kubeCl, metaConfig)
+	clusterChanged, clusterChanges, err := checkClusterState(kubeCl, metaConfig)
+	statistics.Cluster.Changes = clusterChanges
 	switch {
 	case err != nil:
 		statistics.Cluster.Status = ErrorStatus
+		statistics.Cluster.Message = err.Error()
 		allErrs = multierror.Append(allErrs, err)
 	case clusterChanged == terraform.PlanHasChanges:
 		statistics.Cluster.Status = ChangedStatus
 	case clusterChanged == terraform.PlanHasDestructiveChanges:
 		statistics.Cluster.Status = DestructiveStatus
+		if len(clusterChanges) == 0 {
+			// CheckBaseInfrastructurePipeline marks the plan as destructive if zones are changed
+			statistics.Cluster.Message = "zones in cloud discovery data are changed"
+		}
 	}
 
 	nodesState, err := GetNodesStateFromCluster(kubeCl)
@@ -126,16 +160,18 @@ func CheckState(kubeCl *client.KubernetesClient, metaConfig *config.MetaConfig)
 
 	var nodeGroupsWithStateInCluster []string
 	for _, group := range metaConfig.GetTerraNodeGroups() {
-		templateStatus := OKStatus
+		templateResult := NodeGroupCheckResult{Name: group.Name, Status: OKStatus}
 
 		if template, ok := nodeTemplates[group.Name]; ok {
 			if !reflect.DeepEqual(template, group.NodeTemplate) {
-				templateStatus = ChangedStatus
+				templateResult.Status = ChangedStatus
+				templateResult.Message = "nodeTemplate in the NodeGroup differs from the cluster configuration"
 			}
 		} else {
-			templateStatus = AbsentStatus
+			templateResult.Status = AbsentStatus
+			templateResult.Message = "NodeGroup is not found in the cluster"
 		}
-		statistics.NodeTemplates = append(statistics.NodeTemplates, NodeGroupCheckResult{Name: group.Name, Status: templateStatus})
+		statistics.NodeTemplates = append(statistics.NodeTemplates, templateResult)
 
 		// Skip if node group terraform state exists, we will update node group state below
 		if _, ok := nodesState[group.Name]; ok {
@@ -190,9 +226,10 @@ func CheckState(kubeCl *client.KubernetesClient, metaConfig *config.MetaConfig)
 				nodeName := sortedNodeNames[lastIndex]
 
 				statistics.Node = append(statistics.Node, NodeCheckResult{
-					Group:  nodeGroupName,
-					Name:   nodeName,
-					Status: AbandonedStatus,
+					Group:   nodeGroupName,
+					Name:    nodeName,
+					Status:  AbandonedStatus,
+					Message: fmt.Sprintf("node is not needed anymore, NodeGroup has %d replicas", replicas),
 				})
 
 				sortedNodeNames = sortedNodeNames[:lastIndex]
@@ -215,10 +252,12 @@ func CheckState(kubeCl *client.KubernetesClient, metaConfig *config.MetaConfig)
 				Name:   name,
 				Status: OKStatus,
 			}
-			changed, err := checkNodeState(metaConfig, &nodeGroup, name)
+			changed, changes, err := checkNodeState(metaConfig, &nodeGroup, name)
+			checkResult.Changes = changes
 			switch {
 			case err != nil:
 				checkResult.Status = ErrorStatus
+				checkResult.Message = err.Error()
 				allErrs = multierror.Append(allErrs, fmt.Errorf("node %s: %v", name, err))
 			case changed == terraform.PlanHasChanges:
 				checkResult.Status = ChangedStatus
@@ -267,20 +306,24 @@ func sortNodesByIndex(nodesState map[string][]byte) ([]string, error) {
 
 func getStatusForMissedNode(kubeCl *client.KubernetesClient, nodeName, nodeGroupName string, allErrs **multierror.Error) NodeCheckResult {
 	status := AbsentStatus
+	message := "terraform state for the node is not found"
 
 	exists, err := IsNodeExistsInCluster(kubeCl, nodeName)
 	if err != nil {
 		*allErrs = multierror.Append(*allErrs, err)
 		status = ErrorStatus
+		message = err.Error()
 	}
 
 	if exists {
 		status = ErrorStatus
+		message = "node exists in the cluster, but terraform state for the node is not found"
 	}
 
 	return NodeCheckResult{
-		Group:  nodeGroupName,
-		Name:   nodeName,
-		Status: status,
+		Group:   nodeGroupName,
+		Name:    nodeName,
+		Status:  status,
+		Message: message,
 	}
 }
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"sync"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/hashicorp/go-multierror"
@@ -64,6 +65,9 @@ type Runner struct {
 	excludedNodes map[string]bool
 	skipPhases    map[Phase]bool
 
+	maxParallelNodes          int
+	nodeGroupMaxParallelNodes map[string]int
+
 	stateCache dstate.Cache
 }
 
@@ -76,6 +80,9 @@ func NewRunner(kubeCl *client.KubernetesClient, lockRunner *InLockRunner) *Runne
 		excludedNodes: make(map[string]bool),
 		skipPhases:    make(map[Phase]bool),
 		stateCache:    cache.Global(),
+
+		maxParallelNodes:          1,
+		nodeGroupMaxParallelNodes: make(map[string]int),
 	}
 }
 
@@ -110,6 +117,14 @@ func (r *Runner) WithSkipPhases(phases []Phase) *Runner {
 	return r
 }
 
+// WithMaxParallelNodes sets how many nodes of a NodeGroup can be converged at the same time.
+// perNodeGroup overrides the value for the particular NodeGroups. Master nodes are always converged one by one.
+func (r *Runner) WithMaxParallelNodes(maxParallelNodes int, perNodeGroup map[string]int) *Runner {
+	r.maxParallelNodes = maxParallelNodes
+	r.nodeGroupMaxParallelNodes = perNodeGroup
+	return r
+}
+
 func (r *Runner) isSkip(phase Phase) bool {
 	_, ok := r.skipPhases[phase]
 	return ok
@@ -160,7 +175,10 @@ func (r *Runner) converge() error {
 
 	// dhctl has nodes to create, and there are no nodes in the cluster.
 	if len(nodesState) == 0 && desiredQuantity > 0 {
-		confirmation := input.NewConfirmation().WithYesByDefault().WithMessage(noNodesConfirmationMessage)
+		confirmation := input.NewConfirmation().
+			WithAction(input.ActionCreateAllNodes).
+			WithYesByDefault().
+			WithMessage(noNodesConfirmationMessage)
 		if !r.changeSettings.AutoApprove && !confirmation.Ask() {
 			log.InfoLn("Aborted")
 			return nil
@@ -185,6 +203,7 @@ func (r *Runner) converge() error {
 		controller := NewConvergeController(r.kubeCl, metaConfig, nodeGroupName, ngState, r.stateCache)
 		controller.WithChangeSettings(r.changeSettings)
 		controller.WithExcludedNodes(r.excludedNodes)
+		controller.WithMaxParallelNodes(r.maxParallelNodes, r.nodeGroupMaxParallelNodes)
 
 		if err := controller.Run(); err != nil {
 			return err
@@ -256,11 +275,17 @@ type NodeGroupController struct {
 
 	excludedNodes map[string]bool
 
+	maxParallelNodes          int
+	nodeGroupMaxParallelNodes map[string]int
+
 	stateCache dstate.Cache
 
 	nodeToHost map[string]string
 	name       string
 	state      NodeGroupTerraformState
+
+	// nodeUpdater updates a single node, it is replaced in tests.
+	nodeUpdater func(nodeGroup *NodeGroupGroupOptions, nodeName string, logger log.Logger) error
 }
 
 type NodeGroupGroupOptions struct {
@@ -276,16 +301,22 @@ func (n *NodeGroupGroupOptions) CurReplicas() int {
 }
 
 func NewConvergeController(kubeCl *client.KubernetesClient, metaConfig *config.MetaConfig, name string, state NodeGroupTerraformState, stateCache dstate.Cache) *NodeGroupController {
-	return &NodeGroupController{
+	c := &NodeGroupController{
 		client:         kubeCl,
 		config:         metaConfig,
 		changeSettings: &terraform.ChangeActionSettings{},
 		excludedNodes:  make(map[string]bool),
 		stateCache:     stateCache,
 
+		maxParallelNodes:          1,
+		nodeGroupMaxParallelNodes: make(map[string]int),
+
 		name:  name,
 		state: state,
 	}
+	c.nodeUpdater = c.updateNode
+
+	return c
 }
 
 func (c *NodeGroupController) WithChangeSettings(changeSettings *terraform.ChangeActionSettings) *NodeGroupController {
@@ -298,6 +329,31 @@ func (c *NodeGroupController) WithExcludedNodes(nodesMap map[string]bool) *NodeG
 	return c
 }
 
+func (c *NodeGroupController) WithMaxParallelNodes(maxParallelNodes int, perNodeGroup map[string]int) *NodeGroupController {
+	c.maxParallelNodes = maxParallelNodes
+	c.nodeGroupMaxParallelNodes = perNodeGroup
+	return c
+}
+
+// parallelism returns how many nodes of the NodeGroup can be converged at the same time.
+func (c *NodeGroupController) parallelism() int {
+	// control plane quorum should be kept, masters are converged strictly one by one
+	if c.name == MasterNodeGroupName {
+		return 1
+	}
+
+	parallelism := c.maxParallelNodes
+	if n, ok := c.nodeGroupMaxParallelNodes[c.name]; ok {
+		parallelism = n
+	}
+
+	if parallelism < 1 {
+		return 1
+	}
+
+	return parallelism
+}
+
 func (c *NodeGroupController) populateNodeToHost() error {
 	if c.name != MasterNodeGroupName {
 		c.nodeToHost = make(map[string]string)
@@ -315,7 +371,7 @@ func (c *NodeGroupController) populateNodeToHost() error {
 	}
 
 	nodeToHost, err := ssh.CheckSSHHosts(userPassedHosts, nodesNames, func(msg string) bool {
-		return input.NewConfirmation().WithMessage(msg).Ask()
+		return input.NewConfirmation().WithAction(input.ActionSSHHosts).WithNodeGroup(c.name).WithMessage(msg).Ask()
 	})
 
 	if err != nil {
@@ -343,7 +399,12 @@ func (c *NodeGroupController) getNodeGroupReadinessChecker(nodeGroup *NodeGroupG
 	nodesToCheck := maputil.ExcludeKeys(c.nodeToHost, convergedNode)
 
 	confirm := func(msg string) bool {
-		return input.NewConfirmation().WithMessage(msg).Ask()
+		return input.NewConfirmation().
+			WithAction(input.ActionControlPlaneCheck).
+			WithNodeGroup(c.name).
+			WithNode(convergedNode).
+			WithMessage(msg).
+			Ask()
 	}
 
 	if c.changeSettings.AutoApprove {
@@ -414,30 +475,52 @@ func (c *NodeGroupController) addNewNodesToGroup(nodeGroup *NodeGroupGroupOption
 	index := 0
 
 	var nodesToWait []string
+	nodesIndexes := make(map[string]int)
 
 	for nodeGroup.DesiredReplicas > count {
 		candidateName := fmt.Sprintf("%s-%s-%v", c.config.ClusterPrefix, nodeGroup.Name, index)
 
 		if _, ok := nodeGroup.State[candidateName]; !ok {
-			var err error
-			var output *terraform.PipelineOutputs
-			if nodeGroup.Name == MasterNodeGroupName {
-				output, err = BootstrapAdditionalMasterNode(c.client, c.config, index, nodeGroup.CloudConfig, true)
-			} else {
-				err = BootstrapAdditionalNode(c.client, c.config, index, nodeGroup.Step, nodeGroup.Name, nodeGroup.CloudConfig, true)
-			}
-			if err != nil {
-				return err
-			}
 			count++
-			if output != nil {
-				nodeGroup.State[candidateName] = output.TerraformState
-			}
+			nodesIndexes[candidateName] = index
 			nodesToWait = append(nodesToWait, candidateName)
 		}
 		index++
 	}
 
+	var stateMutex sync.Mutex
+	bootstrapNode := func(nodeName string, logger log.Logger) error {
+		var err error
+		var output *terraform.PipelineOutputs
+		if nodeGroup.Name == MasterNodeGroupName {
+			output, err = BootstrapAdditionalMasterNode(c.client, c.config, nodesIndexes[nodeName], nodeGroup.CloudConfig, true)
+		} else {
+			err = bootstrapAdditionalNode(c.client, c.config, nodesIndexes[nodeName], nodeGroup.Step, nodeGroup.Name, nodeGroup.CloudConfig, true, logger)
+		}
+		if err != nil {
+			return err
+		}
+		if output != nil {
+			stateMutex.Lock()
+			nodeGroup.State[nodeName] = output.TerraformState
+			stateMutex.Unlock()
+		}
+		return nil
+	}
+
+	if parallelism := c.parallelism(); parallelism > 1 {
+		err := runInWaves(fmt.Sprintf("Add Nodes to NodeGroup %s", nodeGroup.Name), nodesToWait, parallelism, bootstrapNode)
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, nodeName := range nodesToWait {
+			if err := bootstrapNode(nodeName, log.GetDefaultLogger()); err != nil {
+				return err
+			}
+		}
+	}
+
 	if nodeGroup.Name == MasterNodeGroupName {
 		return WaitForNodesListBecomeReady(c.client, nodesToWait, controlplane.NewManagerReadinessChecker(c.client))
 	}
@@ -445,16 +528,16 @@ func (c *NodeGroupController) addNewNodesToGroup(nodeGroup *NodeGroupGroupOption
 	return WaitForNodesListBecomeReady(c.client, nodesToWait, nil)
 }
 
-func (c *NodeGroupController) updateNode(nodeGroup *NodeGroupGroupOptions, nodeName string) error {
+func (c *NodeGroupController) updateNode(nodeGroup *NodeGroupGroupOptions, nodeName string, logger log.Logger) error {
 	if _, ok := c.excludedNodes[nodeName]; ok {
-		log.InfoF("Skip update excluded node %v\n", nodeName)
+		logger.LogInfoF("Skip update excluded node %v\n", nodeName)
 		return nil
 	}
 
 	state := nodeGroup.State[nodeName]
 	index, ok := getIndexFromNodeName(nodeName)
 	if !ok {
-		log.ErrorF("can't extract index from terraform state secret, skip %s\n", nodeName)
+		logger.LogErrorF("can't extract index from terraform state secret, skip %s\n", nodeName)
 		return nil
 	}
 
@@ -465,9 +548,11 @@ func (c *NodeGroupController) updateNode(nodeGroup *NodeGroupGroupOptions, nodeN
 		WithSkipChangesOnDeny(true).
 		WithState(state).
 		WithName(nodeName).
+		WithConfirm(nodeConfirmation(nodeGroup.Name, nodeName)).
 		WithAutoDismissDestructiveChanges(c.changeSettings.AutoDismissDestructive).
 		WithAutoApprove(c.changeSettings.AutoApprove).
-		WithHook(checker)
+		WithHook(checker).
+		WithLogger(logger)
 
 	tomb.RegisterOnShutdown(nodeName, nodeRunner.Stop)
 
@@ -488,7 +573,7 @@ func (c *NodeGroupController) updateNode(nodeGroup *NodeGroupGroupOptions, nodeN
 
 	outputs, err := terraform.ApplyPipeline(nodeRunner, nodeName, extractOutputFunc)
 	if err != nil {
-		log.ErrorF("Terraform exited with an error:\n%s\n", err.Error())
+		logger.LogErrorF("Terraform exited with an error:\n%s\n", err.Error())
 		return err
 	}
 
@@ -547,6 +632,7 @@ func (c *NodeGroupController) deleteRedundantNodes(nodeGroup *NodeGroupGroupOpti
 			WithVariables(cfg.NodeGroupConfig(nodeGroup.Name, int(index), nodeGroup.CloudConfig)).
 			WithState(state).
 			WithName(name).
+			WithConfirm(nodeConfirmation(nodeGroup.Name, name)).
 			WithAllowedCachedState(true).
 			WithSkipChangesOnDeny(true).
 			WithAutoDismissDestructiveChanges(c.changeSettings.AutoDismissDestructive)
@@ -598,7 +684,7 @@ func (c *NodeGroupController) tryDeleteNodes(deleteNodesNames map[string][]byte,
 
 		noQuorum := nodeGroup.DesiredReplicas < needToQuorum
 		msg := fmt.Sprintf("Desired master replicas count (%d) can break cluster. Need minimum replicas (%d). Do you want to continue?", nodeGroup.DesiredReplicas, needToQuorum)
-		confirm := input.NewConfirmation().WithMessage(msg)
+		confirm := input.NewConfirmation().WithAction(input.ActionMasterQuorumLoss).WithNodeGroup(c.name).WithMessage(msg)
 		if noQuorum && !confirm.Ask() {
 			return fmt.Errorf("Skip delete master nodes")
 		}
@@ -630,7 +716,8 @@ func (c *NodeGroupController) tryUpdateNodeTemplate(nodeGroup *NodeGroupGroupOpt
 
 		msg := fmt.Sprintf("Node template diff:\n\n%s\n", diff)
 
-		if !c.changeSettings.AutoApprove && !input.NewConfirmation().WithMessage(msg).Ask() {
+		confirm := input.NewConfirmation().WithAction(input.ActionNodeTemplateChange).WithNodeGroup(c.name).WithMessage(msg)
+		if !c.changeSettings.AutoApprove && !confirm.Ask() {
 			log.InfoLn("Updating node group template was skipped")
 			return nil
 		}
@@ -694,11 +781,24 @@ func (c *NodeGroupController) updateNodes(nodeGroup *NodeGroupGroupOptions) erro
 		return err
 	}
 
+	nodesNames := make([]string, 0, len(nodeGroup.State))
 	for nodeName := range nodeGroup.State {
+		nodesNames = append(nodesNames, nodeName)
+	}
+	sort.Strings(nodesNames)
+
+	if parallelism := c.parallelism(); parallelism > 1 {
+		processName := fmt.Sprintf("Update Nodes in NodeGroup %s (replicas: %v, parallel: %v)", c.name, replicas, parallelism)
+		return runInWaves(processName, nodesNames, parallelism, func(nodeName string, logger log.Logger) error {
+			return c.nodeUpdater(nodeGroup, nodeName, logger)
+		})
+	}
+
+	for _, nodeName := range nodesNames {
 		processTitle := fmt.Sprintf("Update Node %s in NodeGroup %s (replicas: %v)", nodeName, c.name, replicas)
 
 		err := log.Process("converge", processTitle, func() error {
-			return c.updateNode(nodeGroup, nodeName)
+			return c.nodeUpdater(nodeGroup, nodeName, log.GetDefaultLogger())
 		})
 
 		if err != nil {
@@ -19,16 +19,29 @@ import (
 
 	"github.com/deckhouse/deckhouse/dhctl/pkg/config"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/kubernetes/client"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/state/cache"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/terraform"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/util/input"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/util/tomb"
 )
 
+// nodeConfirmation returns confirmations of the node terraform runner to match them in the confirmation policy.
+func nodeConfirmation(nodeGroupName, nodeName string) func() *input.Confirmation {
+	return func() *input.Confirmation {
+		return input.NewConfirmation().WithNodeGroup(nodeGroupName).WithNode(nodeName)
+	}
+}
+
 func NodeName(cfg *config.MetaConfig, nodeGroupName string, index int) string {
 	return fmt.Sprintf("%s-%s-%v", cfg.ClusterPrefix, nodeGroupName, index)
 }
 
 func BootstrapAdditionalNode(kubeCl *client.KubernetesClient, cfg *config.MetaConfig, index int, step, nodeGroupName, cloudConfig string, isConverge bool) error {
+	return bootstrapAdditionalNode(kubeCl, cfg, index, step, nodeGroupName, cloudConfig, isConverge, log.GetDefaultLogger())
+}
+
+func bootstrapAdditionalNode(kubeCl *client.KubernetesClient, cfg *config.MetaConfig, index int, step, nodeGroupName, cloudConfig string, isConverge bool, logger log.Logger) error {
 	nodeName := NodeName(cfg, nodeGroupName, index)
 
 	if isConverge {
@@ -47,8 +60,10 @@ func BootstrapAdditionalNode(kubeCl *client.KubernetesClient, cfg *config.MetaCo
 	runner := terraform.NewRunnerFromConfig(cfg, step, cache.Global()).
 		WithVariables(nodeConfig).
 		WithName(nodeName).
+		WithConfirm(nodeConfirmation(nodeGroupName, nodeName)).
 		WithAutoApprove(true).
-		WithAdditionalStateSaverDestination(NewNodeStateSaver(kubeCl, nodeName, nodeGroupName, nodeGroupSettings))
+		WithAdditionalStateSaverDestination(NewNodeStateSaver(kubeCl, nodeName, nodeGroupName, nodeGroupSettings)).
+		WithLogger(logger)
 	tomb.RegisterOnShutdown(nodeName, runner.Stop)
 
 	outputs, err := terraform.ApplyPipeline(runner, nodeName, terraform.OnlyState)
@@ -86,6 +101,7 @@ func BootstrapAdditionalMasterNode(kubeCl *client.KubernetesClient, cfg *config.
 	runner := terraform.NewRunnerFromConfig(cfg, "master-node", cache.Global()).
 		WithVariables(nodeConfig).
 		WithName(nodeName).
+		WithConfirm(nodeConfirmation(MasterNodeGroupName, nodeName)).
 		WithAutoApprove(true).
 		WithAdditionalStateSaverDestination(NewNodeStateSaver(kubeCl, nodeName, MasterNodeGroupName, nil))
 	tomb.RegisterOnShutdown(nodeName, runner.Stop)
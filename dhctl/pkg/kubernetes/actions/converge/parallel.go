@@ -0,0 +1,102 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converge
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/util/tomb"
+)
+
+// nodesWaves splits nodes into waves of the parallelism size.
+func nodesWaves(nodes []string, parallelism int) [][]string {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	waves := make([][]string, 0, (len(nodes)+parallelism-1)/parallelism)
+	for start := 0; start < len(nodes); start += parallelism {
+		end := start + parallelism
+		if end > len(nodes) {
+			end = len(nodes)
+		}
+		waves = append(waves, nodes[start:end])
+	}
+
+	return waves
+}
+
+// runInWaves calls action concurrently for the nodes of one wave.
+// Every action gets the logger which buffers logs of its node.
+// Next waves are not started if any node of the wave fails, errors of all nodes of the failed wave are returned.
+func runInWaves(processName string, nodes []string, parallelism int, action func(nodeName string, logger log.Logger) error) error {
+	waves := nodesWaves(nodes, parallelism)
+	for i, wave := range waves {
+		if tomb.IsInterrupted() {
+			return ErrConvergeInterrupted
+		}
+
+		title := fmt.Sprintf("%s: wave %d/%d %v", processName, i+1, len(waves), wave)
+		err := log.Process("converge", title, func() error {
+			return runWave(wave, action)
+		})
+		if err != nil {
+			if i+1 < len(waves) {
+				log.WarnF("Stop converge after the failed wave, %d wave(s) are skipped\n", len(waves)-i-1)
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runWave runs action for nodes concurrently. Logs of every node are buffered and printed
+// one after another in the order of nodes when all nodes are processed.
+// Confirmations are not buffered, they are asked with the default logger.
+func runWave(nodes []string, action func(nodeName string, logger log.Logger) error) error {
+	var wg sync.WaitGroup
+
+	buffers := make([]bytes.Buffer, len(nodes))
+	errs := make([]error, len(nodes))
+
+	for i, nodeName := range nodes {
+		wg.Add(1)
+		go func(i int, nodeName string) {
+			defer wg.Done()
+			errs[i] = action(nodeName, log.NewBufferLogger(&buffers[i]))
+		}(i, nodeName)
+	}
+	wg.Wait()
+
+	var allErrs *multierror.Error
+	for i, nodeName := range nodes {
+		_ = log.Process("converge", fmt.Sprintf("Node %s", nodeName), func() error {
+			log.InfoF("%s", buffers[i].String())
+			return errs[i]
+		})
+
+		if errs[i] != nil {
+			allErrs = multierror.Append(allErrs, fmt.Errorf("%s: %v", nodeName, errs[i]))
+		}
+	}
+
+	return allErrs.ErrorOrNil()
+}
@@ -0,0 +1,143 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converge
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+)
+
+func TestNodesWaves(t *testing.T) {
+	nodes := []string{"n-0", "n-1", "n-2", "n-3", "n-4"}
+
+	require.Equal(t, [][]string{{"n-0", "n-1"}, {"n-2", "n-3"}, {"n-4"}}, nodesWaves(nodes, 2))
+	require.Equal(t, [][]string{nodes}, nodesWaves(nodes, 10))
+	require.Len(t, nodesWaves(nodes, 0), 5)
+	require.Empty(t, nodesWaves(nil, 3))
+}
+
+func TestRunInWaves(t *testing.T) {
+	log.InitLogger("simple")
+
+	t.Run("Nodes of the wave run concurrently", func(t *testing.T) {
+		var running, maxRunning int32
+		var mu sync.Mutex
+		processed := make([]string, 0)
+
+		err := runInWaves("test", []string{"a", "b", "c", "d", "e"}, 2, func(nodeName string, _ log.Logger) error {
+			cur := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+
+			mu.Lock()
+			if cur > maxRunning {
+				maxRunning = cur
+			}
+			processed = append(processed, nodeName)
+			mu.Unlock()
+			return nil
+		})
+
+		require.NoError(t, err)
+		require.LessOrEqual(t, maxRunning, int32(2))
+		require.ElementsMatch(t, []string{"a", "b", "c", "d", "e"}, processed)
+	})
+
+	t.Run("Next waves are skipped after failure", func(t *testing.T) {
+		var mu sync.Mutex
+		processed := make([]string, 0)
+
+		err := runInWaves("test", []string{"a", "b", "c", "d", "e"}, 2, func(nodeName string, _ log.Logger) error {
+			mu.Lock()
+			processed = append(processed, nodeName)
+			mu.Unlock()
+			if nodeName == "c" || nodeName == "d" {
+				return fmt.Errorf("failed")
+			}
+			return nil
+		})
+
+		require.EqualError(t, err, "2 errors occurred:\n\t* c: failed\n\t* d: failed\n\n")
+		require.ElementsMatch(t, []string{"a", "b", "c", "d"}, processed)
+	})
+
+	t.Run("Logs of nodes are buffered", func(t *testing.T) {
+		err := runInWaves("test", []string{"a", "b"}, 2, func(nodeName string, logger log.Logger) error {
+			require.NotEqual(t, log.GetDefaultLogger(), logger)
+			logger.LogInfoF("update %s\n", nodeName)
+			return nil
+		})
+
+		require.NoError(t, err)
+	})
+}
+
+func TestUpdateNodesInWaves(t *testing.T) {
+	log.InitLogger("simple")
+
+	nodeGroup := &NodeGroupGroupOptions{
+		Name:            "worker",
+		DesiredReplicas: 5,
+		State: map[string][]byte{
+			"worker-0": nil, "worker-1": nil, "worker-2": nil, "worker-3": nil, "worker-4": nil,
+		},
+	}
+
+	var mu sync.Mutex
+	updated := make([]string, 0)
+
+	c := NewConvergeController(nil, nil, "worker", NodeGroupTerraformState{}, nil).
+		WithMaxParallelNodes(2, nil)
+	c.nodeUpdater = func(_ *NodeGroupGroupOptions, nodeName string, _ log.Logger) error {
+		mu.Lock()
+		updated = append(updated, nodeName)
+		mu.Unlock()
+		if nodeName == "worker-2" {
+			return fmt.Errorf("failed")
+		}
+		return nil
+	}
+
+	err := c.updateNodes(nodeGroup)
+
+	require.EqualError(t, err, "1 error occurred:\n\t* worker-2: failed\n\n")
+	require.ElementsMatch(t, []string{"worker-0", "worker-1", "worker-2", "worker-3"}, updated)
+}
+
+func TestNodeGroupControllerParallelism(t *testing.T) {
+	perNodeGroup := map[string]int{"master": 5, "frontend": 3}
+
+	cases := []struct {
+		name     string
+		global   int
+		expected int
+	}{
+		{name: "master", global: 10, expected: 1},
+		{name: "frontend", global: 10, expected: 3},
+		{name: "worker", global: 10, expected: 10},
+		{name: "worker", global: 0, expected: 1},
+	}
+
+	for _, tc := range cases {
+		c := NewConvergeController(nil, nil, tc.name, NodeGroupTerraformState{}, nil).
+			WithMaxParallelNodes(tc.global, perNodeGroup)
+		require.Equal(t, tc.expected, c.parallelism(), tc.name)
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2021 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converge
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/config"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/kubernetes/client"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/terraform"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/util/tomb"
+)
+
+const PlanVersion = "v1"
+
+const (
+	NodeActionCreate = "create"
+	NodeActionUpdate = "update"
+	NodeActionDelete = "delete"
+	NodeActionNone   = "none"
+)
+
+type ClusterPlan struct {
+	Changes []terraform.ResourceChange `json:"changes"`
+}
+
+type NodePlan struct {
+	Name    string                     `json:"name"`
+	Action  string                     `json:"action"`
+	Changes []terraform.ResourceChange `json:"changes"`
+}
+
+type NodeGroupPlan struct {
+	Name  string     `json:"name"`
+	Nodes []NodePlan `json:"nodes"`
+}
+
+// Plan is a machine-readable description of changes which converge is going to make.
+type Plan struct {
+	Version    string          `json:"version"`
+	Cluster    ClusterPlan     `json:"cluster"`
+	NodeGroups []NodeGroupPlan `json:"node_groups"`
+}
+
+func planClusterState(kubeCl *client.KubernetesClient, metaConfig *config.MetaConfig) ([]terraform.ResourceChange, error) {
+	clusterState, err := GetClusterStateFromCluster(kubeCl)
+	if err != nil {
+		return nil, fmt.Errorf("terraform cluster state in Kubernetes cluster not found: %w", err)
+	}
+
+	if clusterState == nil {
+		return nil, fmt.Errorf("kubernetes cluster has no state")
+	}
+
+	baseRunner := terraform.NewImmutableRunnerFromConfig(metaConfig, "base-infrastructure").
+		WithVariables(metaConfig.MarshalConfig()).
+		WithState(clusterState)
+	tomb.RegisterOnShutdown("base-infrastructure", baseRunner.Stop)
+
+	return terraform.PlanPipeline(baseRunner, "Kubernetes cluster")
+}
+
+func planNodeState(metaConfig *config.MetaConfig, nodeGroup *NodeGroupGroupOptions, nodeName string, destroy bool) ([]terraform.ResourceChange, error) {
+	index, ok := getIndexFromNodeName(nodeName)
+	if !ok {
+		return nil, fmt.Errorf("can't extract index from terraform state secret, skip %s", nodeName)
+	}
+
+	nodeRunner := terraform.NewImmutableRunnerFromConfig(metaConfig, nodeGroup.Step).
+		WithVariables(metaConfig.NodeGroupConfig(nodeGroup.Name, int(index), nodeGroup.CloudConfig)).
+		WithState(nodeGroup.State[nodeName]).
+		WithName(nodeName).
+		WithPlanDestroy(destroy)
+	tomb.RegisterOnShutdown(nodeName, nodeRunner.Stop)
+
+	return terraform.PlanPipeline(nodeRunner, nodeName)
+}
+
+func nodeActionByChanges(changes []terraform.ResourceChange) string {
+	if len(changes) == 0 {
+		return NodeActionNone
+	}
+
+	return NodeActionUpdate
+}
+
+// BuildPlan runs terraform plan for the base infrastructure and for every node in every terraform NodeGroup.
+// It never applies anything.
+func BuildPlan(kubeCl *client.KubernetesClient, metaConfig *config.MetaConfig) (*Plan, error) {
+	plan := Plan{
+		Version:    PlanVersion,
+		NodeGroups: make([]NodeGroupPlan, 0),
+	}
+
+	var allErrs *multierror.Error
+
+	clusterChanges, err := planClusterState(kubeCl, metaConfig)
+	if err != nil {
+		allErrs = multierror.Append(allErrs, err)
+	}
+	plan.Cluster.Changes = clusterChanges
+
+	nodesState, err := GetNodesStateFromCluster(kubeCl)
+	if err != nil {
+		return &plan, multierror.Append(allErrs, fmt.Errorf("terraform nodes state in Kubernetes cluster not found: %w", err))
+	}
+
+	var nodeGroupsWithStateInCluster []string
+	for _, group := range metaConfig.GetTerraNodeGroups() {
+		if _, ok := nodesState[group.Name]; ok {
+			nodeGroupsWithStateInCluster = append(nodeGroupsWithStateInCluster, group.Name)
+			continue
+		}
+
+		// The whole node group is going to be created
+		nodeGroup := NodeGroupGroupOptions{
+			Name:            group.Name,
+			Step:            getStepByNodeGroupName(group.Name),
+			DesiredReplicas: group.Replicas,
+			State:           make(map[string][]byte),
+		}
+
+		groupPlan := NodeGroupPlan{Name: group.Name, Nodes: make([]NodePlan, 0)}
+		for _, nodeName := range expectedNodeNames(metaConfig, group.Name, group.Replicas) {
+			changes, err := planNodeState(metaConfig, &nodeGroup, nodeName, false)
+			if err != nil {
+				allErrs = multierror.Append(allErrs, fmt.Errorf("node %s: %v", nodeName, err))
+			}
+			groupPlan.Nodes = append(groupPlan.Nodes, NodePlan{Name: nodeName, Action: NodeActionCreate, Changes: changes})
+		}
+
+		plan.NodeGroups = append(plan.NodeGroups, groupPlan)
+	}
+
+	for _, nodeGroupName := range sortNodeGroupsStateKeys(nodesState, nodeGroupsWithStateInCluster) {
+		nodeGroupState := nodesState[nodeGroupName]
+		replicas := getReplicasByNodeGroupName(metaConfig, nodeGroupName)
+
+		nodeGroup := NodeGroupGroupOptions{
+			Name:            nodeGroupName,
+			Step:            getStepByNodeGroupName(nodeGroupName),
+			DesiredReplicas: replicas,
+			State:           nodeGroupState.State,
+		}
+
+		groupPlan := NodeGroupPlan{Name: nodeGroupName, Nodes: make([]NodePlan, 0)}
+
+		sortedNodeNames, err := sortNodesByIndex(nodeGroupState.State)
+		if err != nil {
+			allErrs = multierror.Append(allErrs, err)
+			plan.NodeGroups = append(plan.NodeGroups, groupPlan)
+			continue
+		}
+
+		// The same logic as in the converge: nodes with bigger indexes are deleted first,
+		// and missed nodes are created with the first free indexes.
+		toDelete := make(map[string]struct{})
+		for i := len(sortedNodeNames) - 1; i >= 0 && len(sortedNodeNames)-len(toDelete) > replicas; i-- {
+			toDelete[sortedNodeNames[i]] = struct{}{}
+		}
+
+		for _, nodeName := range sortedNodeNames {
+			_, destroy := toDelete[nodeName]
+
+			changes, err := planNodeState(metaConfig, &nodeGroup, nodeName, destroy)
+			if err != nil {
+				allErrs = multierror.Append(allErrs, fmt.Errorf("node %s: %v", nodeName, err))
+			}
+
+			action := nodeActionByChanges(changes)
+			if destroy {
+				action = NodeActionDelete
+			}
+
+			groupPlan.Nodes = append(groupPlan.Nodes, NodePlan{Name: nodeName, Action: action, Changes: changes})
+		}
+
+		missed := replicas - len(sortedNodeNames)
+		for index := 0; missed > 0; index++ {
+			nodeName := fmt.Sprintf("%s-%s-%v", metaConfig.ClusterPrefix, nodeGroupName, index)
+			if _, ok := nodeGroupState.State[nodeName]; ok {
+				continue
+			}
+
+			changes, err := planNodeState(metaConfig, &nodeGroup, nodeName, false)
+			if err != nil {
+				allErrs = multierror.Append(allErrs, fmt.Errorf("node %s: %v", nodeName, err))
+			}
+
+			groupPlan.Nodes = append(groupPlan.Nodes, NodePlan{Name: nodeName, Action: NodeActionCreate, Changes: changes})
+			missed--
+		}
+
+		plan.NodeGroups = append(plan.NodeGroups, groupPlan)
+	}
+
+	return &plan, allErrs.ErrorOrNil()
+}
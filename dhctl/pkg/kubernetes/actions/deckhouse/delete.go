@@ -175,6 +175,19 @@ func DeleteMachineDeployments(kubeCl *client.KubernetesClient) error {
 	})
 }
 
+func IsDeckhouseDeploymentExists(kubeCl *client.KubernetesClient) (bool, error) {
+	_, err := kubeCl.AppsV1().Deployments(deckhouseDeploymentNamespace).Get(context.TODO(), deckhouseDeploymentName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
 func WaitForDeckhouseDeploymentDeletion(kubeCl *client.KubernetesClient) error {
 	return retry.NewLoop("Wait for Deckhouse Deployment deletion", 30, 5*time.Second).Run(func() error {
 		_, err := kubeCl.AppsV1().Deployments(deckhouseDeploymentNamespace).Get(context.TODO(), deckhouseDeploymentName, metav1.GetOptions{})
@@ -340,6 +353,7 @@ func DeleteMachinesIfResourcesExist(kubeCl *client.KubernetesClient) error {
 	if err != nil {
 		log.WarnF("Can't get resources in group=machine.sapcloud.io, version=v1alpha1: %v\n", err)
 		if input.NewConfirmation().
+			WithAction(input.ActionSkipMachinesDeletion).
 			WithMessage("Machines weren't deleted from the cluster. Do you want to continue?").
 			WithYesByDefault().
 			Ask() {
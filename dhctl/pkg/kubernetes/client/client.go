@@ -25,7 +25,6 @@ import (
 	"github.com/deckhouse/deckhouse/dhctl/pkg/app"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/system/ssh"
-	"github.com/deckhouse/deckhouse/dhctl/pkg/system/ssh/frontend"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/util/retry"
 )
 
@@ -35,7 +34,7 @@ type KubeClient = klient.Client
 type KubernetesClient struct {
 	KubeClient
 	SSHClient *ssh.Client
-	KubeProxy *frontend.KubeProxy
+	KubeProxy ssh.KubeProxy
 }
 
 type KubernetesInitParams struct {
@@ -0,0 +1,102 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/app"
+)
+
+// BufferLogger writes plain text logs into the writer.
+// Logs of concurrent operations are interleaved in the output. To print them one after another,
+// every operation gets its own BufferLogger, and buffers are printed after all operations are finished.
+type BufferLogger struct {
+	out io.Writer
+}
+
+func NewBufferLogger(out io.Writer) *BufferLogger {
+	return &BufferLogger{out: out}
+}
+
+func (d *BufferLogger) ProcessLogger() ProcessLogger {
+	return newWrappedProcessLogger(d)
+}
+
+func (d *BufferLogger) LogProcess(_, t string, run func() error) error {
+	fmt.Fprintln(d.out, t)
+	err := run()
+	if err != nil {
+		fmt.Fprintf(d.out, "%s FAILED\n", t)
+		return err
+	}
+	fmt.Fprintln(d.out, t)
+	return nil
+}
+
+func (d *BufferLogger) LogInfoF(format string, a ...interface{}) {
+	fmt.Fprintf(d.out, format, a...)
+}
+
+func (d *BufferLogger) LogInfoLn(a ...interface{}) {
+	fmt.Fprintln(d.out, a...)
+}
+
+func (d *BufferLogger) LogErrorF(format string, a ...interface{}) {
+	fmt.Fprintf(d.out, format, a...)
+}
+
+func (d *BufferLogger) LogErrorLn(a ...interface{}) {
+	fmt.Fprintln(d.out, a...)
+}
+
+func (d *BufferLogger) LogDebugF(format string, a ...interface{}) {
+	if app.IsDebug {
+		fmt.Fprintf(d.out, format, a...)
+	}
+}
+
+func (d *BufferLogger) LogDebugLn(a ...interface{}) {
+	if app.IsDebug {
+		fmt.Fprintln(d.out, a...)
+	}
+}
+
+func (d *BufferLogger) LogSuccess(l string) {
+	fmt.Fprintln(d.out, l)
+}
+
+func (d *BufferLogger) LogFail(l string) {
+	fmt.Fprintln(d.out, l)
+}
+
+func (d *BufferLogger) LogWarnLn(a ...interface{}) {
+	fmt.Fprintln(d.out, a...)
+}
+
+func (d *BufferLogger) LogWarnF(format string, a ...interface{}) {
+	fmt.Fprintf(d.out, format, a...)
+}
+
+func (d *BufferLogger) LogJSON(content []byte) {
+	fmt.Fprintln(d.out, string(content))
+}
+
+func (d *BufferLogger) Write(content []byte) (int, error) {
+	return d.out.Write(content)
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBufferLogger(t *testing.T) {
+	var wg sync.WaitGroup
+	buffers := make([]bytes.Buffer, 3)
+
+	for i := range buffers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			logger := NewBufferLogger(&buffers[i])
+			_ = ProcessWithLogger(logger, "test", fmt.Sprintf("node-%d", i), func() error {
+				for j := 0; j < 3; j++ {
+					logger.LogInfoF("node-%d line %d\n", i, j)
+				}
+				return nil
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range buffers {
+		expected := fmt.Sprintf("node-%[1]d\nnode-%[1]d line 0\nnode-%[1]d line 1\nnode-%[1]d line 2\nnode-%[1]d\n", i)
+		require.Equal(t, expected, buffers[i].String())
+	}
+}
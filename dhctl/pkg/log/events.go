@@ -0,0 +1,209 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// EventsSchemaVersion is increased on every incompatible change of the Event fields.
+const EventsSchemaVersion = 1
+
+type EventType string
+
+const (
+	EventPhaseStarted          = EventType("phase_started")
+	EventPhaseFinished         = EventType("phase_finished")
+	EventTerraformResource     = EventType("terraform_resource")
+	EventRetryAttemptFailed    = EventType("retry_attempt_failed")
+	EventConfirmationRequested = EventType("confirmation_requested")
+	EventConfirmationAnswered  = EventType("confirmation_answered")
+	EventResult                = EventType("result")
+)
+
+const (
+	EventStatusSuccess = "success"
+	EventStatusFailed  = "failed"
+)
+
+// Event is a single line of the events output. Fields not related to the event type are omitted.
+type Event struct {
+	SchemaVersion int       `json:"schemaVersion"`
+	Seq           uint64    `json:"seq"`
+	Time          string    `json:"time"`
+	Type          EventType `json:"type"`
+
+	// phase_started, phase_finished
+	PhaseID    uint64 `json:"phaseId,omitempty"`
+	PhaseGroup string `json:"phaseGroup,omitempty"`
+	Phase      string `json:"phase,omitempty"`
+
+	// terraform_resource
+	Resource string `json:"resource,omitempty"`
+	Action   string `json:"action,omitempty"`
+	State    string `json:"state,omitempty"`
+
+	// retry_attempt_failed
+	Loop        string `json:"loop,omitempty"`
+	Attempt     int    `json:"attempt,omitempty"`
+	MaxAttempts int    `json:"maxAttempts,omitempty"`
+
+	// confirmation_requested, confirmation_answered
+	Message       string `json:"message,omitempty"`
+	DefaultAnswer *bool  `json:"defaultAnswer,omitempty"`
+	Answer        *bool  `json:"answer,omitempty"`
+	Interactive   *bool  `json:"interactive,omitempty"`
+
+	// result
+	Command  string `json:"command,omitempty"`
+	ExitCode *int   `json:"exitCode,omitempty"`
+
+	// phase_finished, result
+	Status          string  `json:"status,omitempty"`
+	DurationSeconds float64 `json:"durationSeconds,omitempty"`
+
+	// phase_finished, retry_attempt_failed, result
+	Error      string `json:"error,omitempty"`
+	ErrorClass string `json:"errorClass,omitempty"`
+}
+
+type eventsWriter struct {
+	mu      sync.Mutex
+	out     io.Writer
+	closer  io.Closer
+	seq     uint64
+	phaseID uint64
+}
+
+var events = &eventsWriter{}
+
+// InitEventsOutput opens the events output. The target is either a file path or a number of an open file descriptor.
+// Empty target disables events.
+func InitEventsOutput(target string) error {
+	if target == "" {
+		return nil
+	}
+
+	if fd, err := strconv.Atoi(target); err == nil {
+		f := os.NewFile(uintptr(fd), fmt.Sprintf("fd%d", fd))
+		if f == nil {
+			return fmt.Errorf("invalid file descriptor %d for events output", fd)
+		}
+		// The descriptor is owned by the caller, so it is not closed.
+		SetEventsOutput(f, nil)
+		return nil
+	}
+
+	f, err := os.Create(target)
+	if err != nil {
+		return fmt.Errorf("open events output: %v", err)
+	}
+
+	SetEventsOutput(f, f)
+	return nil
+}
+
+// SetEventsOutput sends events to the writer. Closer is called by CloseEventsOutput and can be nil.
+func SetEventsOutput(out io.Writer, closer io.Closer) {
+	events.mu.Lock()
+	defer events.mu.Unlock()
+
+	events.out = out
+	events.closer = closer
+	events.seq = 0
+}
+
+func CloseEventsOutput() {
+	events.mu.Lock()
+	defer events.mu.Unlock()
+
+	if events.closer != nil {
+		_ = events.closer.Close()
+	}
+	events.out = nil
+	events.closer = nil
+}
+
+func EventsEnabled() bool {
+	events.mu.Lock()
+	defer events.mu.Unlock()
+
+	return events.out != nil
+}
+
+// EmitEvent writes the event as a single JSON line. Schema version, sequence number and time are filled here.
+func EmitEvent(e Event) {
+	events.mu.Lock()
+	defer events.mu.Unlock()
+
+	if events.out == nil {
+		return
+	}
+
+	events.seq++
+	e.SchemaVersion = EventsSchemaVersion
+	e.Seq = events.seq
+	e.Time = time.Now().UTC().Format(time.RFC3339Nano)
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+
+	// Events output must not break the operation, so write errors are ignored.
+	_, _ = events.out.Write(append(data, '\n'))
+}
+
+func nextPhaseID() uint64 {
+	events.mu.Lock()
+	defer events.mu.Unlock()
+
+	events.phaseID++
+	return events.phaseID
+}
+
+func processWithEvents(p, t string, run func() error) error {
+	if !EventsEnabled() {
+		return run()
+	}
+
+	id := nextPhaseID()
+	EmitEvent(Event{Type: EventPhaseStarted, PhaseID: id, PhaseGroup: p, Phase: t})
+
+	start := time.Now()
+	err := run()
+
+	finished := Event{
+		Type:            EventPhaseFinished,
+		PhaseID:         id,
+		PhaseGroup:      p,
+		Phase:           t,
+		Status:          EventStatusSuccess,
+		DurationSeconds: time.Since(start).Seconds(),
+	}
+	if err != nil {
+		finished.Status = EventStatusFailed
+		finished.Error = err.Error()
+	}
+	EmitEvent(finished)
+
+	return err
+}
@@ -0,0 +1,106 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func readEvents(t *testing.T, buf *bytes.Buffer) []Event {
+	result := make([]Event, 0)
+
+	s := bufio.NewScanner(buf)
+	for s.Scan() {
+		var e Event
+		require.NoError(t, json.Unmarshal(s.Bytes(), &e))
+		result = append(result, e)
+	}
+
+	return result
+}
+
+func TestEventsOutput(t *testing.T) {
+	t.Run("Disabled output", func(t *testing.T) {
+		require.False(t, EventsEnabled())
+		EmitEvent(Event{Type: EventResult})
+
+		called := false
+		require.NoError(t, Process("common", "Phase", func() error {
+			called = true
+			return nil
+		}))
+		require.True(t, called)
+	})
+
+	t.Run("Phases", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		SetEventsOutput(buf, nil)
+		defer CloseEventsOutput()
+
+		err := Process("bootstrap", "Outer", func() error {
+			return Process("common", "Inner", func() error {
+				return errors.New("failed")
+			})
+		})
+		require.Error(t, err)
+
+		result := readEvents(t, buf)
+		require.Len(t, result, 4)
+
+		for i, e := range result {
+			require.Equal(t, EventsSchemaVersion, e.SchemaVersion)
+			require.Equal(t, uint64(i+1), e.Seq)
+			require.NotEmpty(t, e.Time)
+		}
+
+		require.Equal(t, EventPhaseStarted, result[0].Type)
+		require.Equal(t, "Outer", result[0].Phase)
+		require.Equal(t, "bootstrap", result[0].PhaseGroup)
+
+		require.Equal(t, EventPhaseStarted, result[1].Type)
+		require.Equal(t, "Inner", result[1].Phase)
+
+		require.Equal(t, EventPhaseFinished, result[2].Type)
+		require.Equal(t, result[1].PhaseID, result[2].PhaseID)
+		require.Equal(t, EventStatusFailed, result[2].Status)
+		require.Equal(t, "failed", result[2].Error)
+
+		require.Equal(t, EventPhaseFinished, result[3].Type)
+		require.Equal(t, result[0].PhaseID, result[3].PhaseID)
+		require.Equal(t, EventStatusFailed, result[3].Status)
+	})
+
+	t.Run("Result", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		SetEventsOutput(buf, nil)
+
+		code := 0
+		EmitEvent(Event{Type: EventResult, Command: "bootstrap", Status: EventStatusSuccess, ExitCode: &code})
+		CloseEventsOutput()
+
+		EmitEvent(Event{Type: EventResult})
+
+		require.JSONEq(t,
+			`{"schemaVersion":1,"seq":1,"time":"`+readEvents(t, bytes.NewBuffer(buf.Bytes()))[0].Time+`","type":"result","command":"bootstrap","exitCode":0,"status":"success"}`,
+			buf.String(),
+		)
+	})
+}
@@ -100,10 +100,12 @@ var (
 	_ Logger    = &PrettyLogger{}
 	_ Logger    = &SimpleLogger{}
 	_ Logger    = &DummyLogger{}
+	_ Logger    = &BufferLogger{}
 	_ Logger    = &SilentLogger{}
 	_ io.Writer = &PrettyLogger{}
 	_ io.Writer = &SimpleLogger{}
 	_ io.Writer = &DummyLogger{}
+	_ io.Writer = &BufferLogger{}
 	_ io.Writer = &SilentLogger{}
 )
 
@@ -373,7 +375,14 @@ func (d *DummyLogger) Write(content []byte) (int, error) {
 }
 
 func Process(p, t string, run func() error) error {
-	return defaultLogger.LogProcess(p, t, run)
+	return ProcessWithLogger(defaultLogger, p, t, run)
+}
+
+// ProcessWithLogger is a Process which writes logs with the logger instead of the default one.
+func ProcessWithLogger(logger Logger, p, t string, run func() error) error {
+	return logger.LogProcess(p, t, func() error {
+		return processWithEvents(p, t, run)
+	})
 }
 
 func InfoF(format string, a ...interface{}) {
@@ -291,21 +291,17 @@ func ConnectToKubernetesAPI(sshClient *ssh.Client) (*client.KubernetesClient, er
 	return kubeCl, nil
 }
 
-const rebootExitCode = 255
-
 func RebootMaster(sshClient *ssh.Client) error {
 	return log.Process("bootstrap", "Reboot Master️", func() error {
 		rebootCmd := sshClient.Command("sudo", "reboot").Sudo().
 			WithSSHArgs("-o", "ServerAliveInterval=15", "-o", "ServerAliveCountMax=2")
 		if err := rebootCmd.Run(); err != nil {
-			if ee, ok := err.(*exec.ExitError); ok {
-				if ee.ExitCode() == rebootExitCode {
-					return nil
-				}
+			if ssh.IsConnectionLost(err) {
+				return nil
 			}
 			return fmt.Errorf("shutdown error: stdout: %s stderr: %s %v",
-				rebootCmd.StdoutBuffer.String(),
-				rebootCmd.StderrBuffer.String(),
+				string(rebootCmd.StdoutBytes()),
+				string(rebootCmd.StderrBytes()),
 				err,
 			)
 		}
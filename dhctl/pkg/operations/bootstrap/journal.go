@@ -0,0 +1,146 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bootstrap
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/state"
+)
+
+const PhaseJournalCacheKey = "bootstrap-phase-journal"
+
+type Phase string
+
+const (
+	BaseInfraPhase                         = Phase("base-infra")
+	ExecuteBashiblePhase                   = Phase("exec-bashible")
+	InstallDeckhousePhase                  = Phase("install-deckhouse")
+	InstallAdditionalMastersAndStaticNodes = Phase("install-additional-masters-and-static-nodes")
+	CreateResourcesPhase                   = Phase("create-resources")
+	ExecPostBootstrapPhase                 = Phase("exec-post-bootstrap")
+)
+
+// PhaseRecord describes the completed phase.
+type PhaseRecord struct {
+	Phase       Phase
+	CompletedAt time.Time
+}
+
+// Journal records completed bootstrap phases into the state cache.
+// With resume enabled, completed phases are skipped if their outputs are still valid.
+// Once a phase is executed, all next phases are executed too.
+type Journal struct {
+	cache   state.Cache
+	resume  bool
+	records []PhaseRecord
+}
+
+// NewJournal loads the journal from the cache. Without resume, the journal is started from scratch.
+func NewJournal(stateCache state.Cache, resume bool) (*Journal, error) {
+	j := &Journal{
+		cache:   stateCache,
+		resume:  resume,
+		records: make([]PhaseRecord, 0),
+	}
+
+	inCache, err := stateCache.InCache(PhaseJournalCacheKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if !inCache {
+		return j, nil
+	}
+
+	if !resume {
+		log.WarnLn("Bootstrap phase journal found in the cache. It will be overwritten. Use --resume flag to continue the previous bootstrap.")
+		return j, j.save()
+	}
+
+	if err := stateCache.LoadStruct(PhaseJournalCacheKey, &j.records); err != nil {
+		return nil, fmt.Errorf("can't load bootstrap phase journal: %w", err)
+	}
+
+	return j, nil
+}
+
+func (j *Journal) save() error {
+	return j.cache.SaveStruct(PhaseJournalCacheKey, j.records)
+}
+
+func (j *Journal) IsCompleted(phase Phase) bool {
+	for _, r := range j.records {
+		if r.Phase == phase {
+			return true
+		}
+	}
+	return false
+}
+
+func (j *Journal) Records() []PhaseRecord {
+	return j.records
+}
+
+func (j *Journal) complete(phase Phase) error {
+	j.records = append(j.records, PhaseRecord{Phase: phase, CompletedAt: time.Now().UTC()})
+	return j.save()
+}
+
+// RunPhase runs the phase action and records the phase as completed.
+// In resume mode the completed phase is skipped if validate returns no error.
+// validate should also restore phase outputs needed by next phases.
+func (j *Journal) RunPhase(phase Phase, validate func() error, action func() error) error {
+	if j.resume && j.IsCompleted(phase) {
+		var err error
+		if validate != nil {
+			err = validate()
+		}
+
+		if err == nil {
+			log.InfoF("Phase %s is already completed, skip it.\n", phase)
+			return nil
+		}
+
+		log.WarnF("Phase %s was completed, but its outputs are not valid: %v\nRun the phase again.\n", phase, err)
+	}
+
+	// all next phases should be run again, because they can depend on this phase
+	if j.resume {
+		j.resume = false
+		if err := j.truncate(phase); err != nil {
+			return err
+		}
+	}
+
+	if err := action(); err != nil {
+		return err
+	}
+
+	return j.complete(phase)
+}
+
+// truncate removes records starting from the phase.
+func (j *Journal) truncate(phase Phase) error {
+	for i, r := range j.records {
+		if r.Phase == phase {
+			j.records = j.records[:i]
+			return j.save()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bootstrap
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/state"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/util/cache"
+)
+
+func newTestStateCache(t *testing.T) state.Cache {
+	dir, err := ioutil.TempDir(os.TempDir(), "dhctl-test-journal-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	stateCache, err := cache.NewStateCache(dir)
+	require.NoError(t, err)
+
+	return stateCache
+}
+
+func TestJournal(t *testing.T) {
+	log.InitLogger("simple")
+
+	ok := func() error { return nil }
+	failed := func() error { return fmt.Errorf("failed") }
+
+	t.Run("Without resume all phases are executed", func(t *testing.T) {
+		stateCache := newTestStateCache(t)
+
+		journal, err := NewJournal(stateCache, false)
+		require.NoError(t, err)
+		require.NoError(t, journal.RunPhase(BaseInfraPhase, ok, ok))
+
+		journal, err = NewJournal(stateCache, false)
+		require.NoError(t, err)
+		require.False(t, journal.IsCompleted(BaseInfraPhase))
+
+		executed := false
+		require.NoError(t, journal.RunPhase(BaseInfraPhase, ok, func() error {
+			executed = true
+			return nil
+		}))
+		require.True(t, executed)
+	})
+
+	t.Run("Resume skips completed phases and continues from the first incomplete", func(t *testing.T) {
+		stateCache := newTestStateCache(t)
+
+		journal, err := NewJournal(stateCache, false)
+		require.NoError(t, err)
+		require.NoError(t, journal.RunPhase(BaseInfraPhase, ok, ok))
+		require.NoError(t, journal.RunPhase(ExecuteBashiblePhase, ok, ok))
+		require.Error(t, journal.RunPhase(InstallDeckhousePhase, ok, failed))
+
+		journal, err = NewJournal(stateCache, true)
+		require.NoError(t, err)
+		require.True(t, journal.IsCompleted(BaseInfraPhase))
+		require.True(t, journal.IsCompleted(ExecuteBashiblePhase))
+		require.False(t, journal.IsCompleted(InstallDeckhousePhase))
+
+		var executed []Phase
+		run := func(phase Phase) func() error {
+			return func() error {
+				executed = append(executed, phase)
+				return nil
+			}
+		}
+
+		require.NoError(t, journal.RunPhase(BaseInfraPhase, ok, run(BaseInfraPhase)))
+		require.NoError(t, journal.RunPhase(ExecuteBashiblePhase, ok, run(ExecuteBashiblePhase)))
+		require.NoError(t, journal.RunPhase(InstallDeckhousePhase, ok, run(InstallDeckhousePhase)))
+
+		require.Equal(t, []Phase{InstallDeckhousePhase}, executed)
+	})
+
+	t.Run("Invalid outputs of the completed phase rerun it and all next phases", func(t *testing.T) {
+		stateCache := newTestStateCache(t)
+
+		journal, err := NewJournal(stateCache, false)
+		require.NoError(t, err)
+		require.NoError(t, journal.RunPhase(BaseInfraPhase, ok, ok))
+		require.NoError(t, journal.RunPhase(ExecuteBashiblePhase, ok, ok))
+		require.NoError(t, journal.RunPhase(InstallDeckhousePhase, ok, ok))
+
+		journal, err = NewJournal(stateCache, true)
+		require.NoError(t, err)
+
+		var executed []Phase
+		run := func(phase Phase) func() error {
+			return func() error {
+				executed = append(executed, phase)
+				return nil
+			}
+		}
+
+		require.NoError(t, journal.RunPhase(BaseInfraPhase, ok, run(BaseInfraPhase)))
+		require.NoError(t, journal.RunPhase(ExecuteBashiblePhase, failed, run(ExecuteBashiblePhase)))
+		require.NoError(t, journal.RunPhase(InstallDeckhousePhase, ok, run(InstallDeckhousePhase)))
+
+		require.Equal(t, []Phase{ExecuteBashiblePhase, InstallDeckhousePhase}, executed)
+
+		phases := make([]Phase, 0)
+		for _, r := range journal.Records() {
+			phases = append(phases, r.Phase)
+		}
+		require.Equal(t, []Phase{BaseInfraPhase, ExecuteBashiblePhase, InstallDeckhousePhase}, phases)
+	})
+}
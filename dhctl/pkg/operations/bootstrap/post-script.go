@@ -22,7 +22,6 @@ import (
 
 	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/system/ssh"
-	"github.com/deckhouse/deckhouse/dhctl/pkg/system/ssh/frontend"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/util/fs"
 )
 
@@ -72,7 +71,7 @@ func (e *PostBootstrapScriptExecutor) run() (string, error) {
 	}
 
 	createOUtFileCmd := fmt.Sprintf("touch %s && chmod 644 %s", outputFile, outputFile)
-	err := frontend.NewCommand(e.sshClient.Settings, createOUtFileCmd).
+	err := e.sshClient.Command(createOUtFileCmd).
 		Sudo().
 		WithStderrHandler(nil).
 		WithStdoutHandler(nil).
@@ -84,7 +83,7 @@ func (e *PostBootstrapScriptExecutor) run() (string, error) {
 
 	defer func() {
 		// remove out file on server because it can contain non-safe information
-		err = frontend.NewCommand(e.sshClient.Settings, fmt.Sprintf("rm %s", outputFile)).
+		err = e.sshClient.Command(fmt.Sprintf("rm %s", outputFile)).
 			Sudo().
 			WithStderrHandler(nil).
 			WithStdoutHandler(nil).
@@ -16,9 +16,16 @@
 
 package bootstrap
 
-import "github.com/deckhouse/deckhouse/dhctl/pkg/state"
+import (
+	"fmt"
 
-const PostBootstrapResultCacheKey = "post-bootstrap-result"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/state"
+)
+
+const (
+	PostBootstrapResultCacheKey = "post-bootstrap-result"
+	BaseInfraOutputsCacheKey    = "bootstrap-base-infra-outputs"
+)
 
 type State struct {
 	cache state.Cache
@@ -38,6 +45,36 @@ func (s *State) PostBootstrapScriptResult() ([]byte, error) {
 	return s.cache.Load(PostBootstrapResultCacheKey)
 }
 
+// BaseInfraOutputs are outputs of the base-infra phase needed by next phases.
+type BaseInfraOutputs struct {
+	BaseTerraformState []byte
+	CloudDiscovery     []byte
+	BastionHost        string
+
+	MasterNodeName       string
+	MasterTerraformState []byte
+	MasterIPForSSH       string
+	NodeInternalIP       string
+	KubeDataDevicePath   string
+}
+
+func (s *State) SaveBaseInfraOutputs(outputs *BaseInfraOutputs) error {
+	return s.cache.SaveStruct(BaseInfraOutputsCacheKey, outputs)
+}
+
+func (s *State) BaseInfraOutputs() (*BaseInfraOutputs, error) {
+	var outputs BaseInfraOutputs
+	if err := s.cache.LoadStruct(BaseInfraOutputsCacheKey, &outputs); err != nil {
+		return nil, err
+	}
+
+	if outputs.MasterIPForSSH == "" || len(outputs.CloudDiscovery) == 0 {
+		return nil, fmt.Errorf("base infrastructure outputs are incomplete")
+	}
+
+	return &outputs, nil
+}
+
 func (s *State) Clean() {
 	s.cache.Clean()
 }
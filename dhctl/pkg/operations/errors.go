@@ -0,0 +1,58 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"errors"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/kubernetes/actions/converge"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/preflight"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/terraform"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/util/retry"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/util/tomb"
+)
+
+// Error classes of the result event.
+const (
+	ErrorClassInterrupted = "interrupted"
+	ErrorClassAborted     = "aborted"
+	ErrorClassPreflight   = "preflight"
+	ErrorClassTimeout     = "timeout"
+	ErrorClassUnknown     = "unknown"
+)
+
+// ClassifyError returns the class of the error returned by a dhctl command.
+func ClassifyError(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var timeoutErr *retry.TimeoutError
+
+	switch {
+	case tomb.IsInterrupted(),
+		errors.Is(err, converge.ErrConvergeInterrupted),
+		errors.Is(err, terraform.ErrRunnerStopped):
+		return ErrorClassInterrupted
+	case errors.Is(err, terraform.ErrTerraformApplyAborted):
+		return ErrorClassAborted
+	case errors.Is(err, preflight.ErrChecksFailed):
+		return ErrorClassPreflight
+	case errors.As(err, &timeoutErr):
+		return ErrorClassTimeout
+	}
+
+	return ErrorClassUnknown
+}
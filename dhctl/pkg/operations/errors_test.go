@@ -0,0 +1,47 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/kubernetes/actions/converge"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/preflight"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/terraform"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/util/retry"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{err: nil, expected: ""},
+		{err: converge.ErrConvergeInterrupted, expected: ErrorClassInterrupted},
+		{err: fmt.Errorf("apply: %w", terraform.ErrRunnerStopped), expected: ErrorClassInterrupted},
+		{err: terraform.ErrTerraformApplyAborted, expected: ErrorClassAborted},
+		{err: fmt.Errorf("%w: ssh", preflight.ErrChecksFailed), expected: ErrorClassPreflight},
+		{err: &retry.TimeoutError{Name: "Wait for SSH", Err: errors.New("refused")}, expected: ErrorClassTimeout},
+		{err: errors.New("something went wrong"), expected: ErrorClassUnknown},
+	}
+
+	for _, tc := range tests {
+		require.Equal(t, tc.expected, ClassifyError(tc.err), "error: %v", tc.err)
+	}
+}
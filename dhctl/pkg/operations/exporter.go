@@ -55,6 +55,9 @@ type ConvergeExporter struct {
 	ListenAddress string
 	CheckInterval time.Duration
 
+	// statusObject enables writing drift details into the TerraformStateStatus object.
+	statusObject bool
+
 	existedEntities *previouslyExistedEntities
 
 	GaugeMetrics   map[string]*prometheus.GaugeVec
@@ -107,6 +110,11 @@ func NewConvergeExporter(address, path string, interval time.Duration) *Converge
 	}
 }
 
+func (c *ConvergeExporter) WithStatusObject(flag bool) *ConvergeExporter {
+	c.statusObject = flag
+	return c
+}
+
 func (c *ConvergeExporter) registerMetrics() {
 	clusterStateVec := prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: "candi",
@@ -169,6 +177,7 @@ func (c *ConvergeExporter) Start() {
 	log.InfoLn("Address: ", app.ListenAddress)
 	log.InfoLn("Metrics path: ", app.MetricsPath)
 	log.InfoLn("Checks interval: ", app.CheckInterval)
+	log.InfoLn("Status object: ", c.statusObject)
 	c.registerMetrics()
 
 	stopCh := make(chan struct{})
@@ -194,7 +203,7 @@ func (c *ConvergeExporter) Start() {
 }
 
 func (c *ConvergeExporter) convergeLoop(stopCh chan struct{}) {
-	c.recordStatistic(c.getStatistic())
+	c.processStatistic(c.getStatistic())
 
 	ticker := time.NewTicker(c.CheckInterval)
 	defer ticker.Stop()
@@ -203,7 +212,7 @@ func (c *ConvergeExporter) convergeLoop(stopCh chan struct{}) {
 		select {
 		case <-ticker.C:
 			cache.ClearTemporaryDirs()
-			c.recordStatistic(c.getStatistic())
+			c.processStatistic(c.getStatistic())
 		case <-stopCh:
 			log.ErrorLn("Stop exporter...")
 			return
@@ -238,6 +247,19 @@ func (c *ConvergeExporter) getStatistic() *converge.Statistics {
 	return statistic
 }
 
+func (c *ConvergeExporter) processStatistic(statistic *converge.Statistics) {
+	c.recordStatistic(statistic)
+
+	if !c.statusObject || statistic == nil {
+		return
+	}
+
+	if err := updateStatusObject(c.kubeCl, statistic, time.Now()); err != nil {
+		log.ErrorLn(err)
+		c.CounterMetrics["errors"].WithLabelValues().Inc()
+	}
+}
+
 func (c *ConvergeExporter) recordStatistic(statistic *converge.Statistics) {
 	if statistic == nil {
 		return
@@ -0,0 +1,393 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/kubernetes/actions/converge"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/kubernetes/client"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/terraform"
+)
+
+const (
+	statusObjectAPIVersion = "deckhouse.io/v1alpha1"
+	statusObjectKind       = "TerraformStateStatus"
+	statusObjectName       = "cluster"
+
+	// Events for cluster-scoped objects are created in the default namespace.
+	statusEventsNamespace = "default"
+	statusEventsComponent = "terraform-state-exporter"
+
+	maxEventMessageLength = 1024
+)
+
+const (
+	EventReasonDriftDetected = "DriftDetected"
+	EventReasonDriftResolved = "DriftResolved"
+	EventReasonCheckFailed   = "CheckFailed"
+)
+
+var statusObjectGVR = schema.GroupVersionResource{
+	Group:    "deckhouse.io",
+	Version:  "v1alpha1",
+	Resource: "terraformstatestatuses",
+}
+
+type DriftedResource struct {
+	Address    string   `json:"address"`
+	Action     string   `json:"action"`
+	Attributes []string `json:"attributes,omitempty"`
+}
+
+type ClusterDriftStatus struct {
+	Status    string            `json:"status"`
+	Message   string            `json:"message,omitempty"`
+	Resources []DriftedResource `json:"resources,omitempty"`
+}
+
+type NodeDriftStatus struct {
+	Name      string            `json:"name"`
+	Status    string            `json:"status"`
+	Message   string            `json:"message,omitempty"`
+	Resources []DriftedResource `json:"resources,omitempty"`
+}
+
+type NodeGroupDriftStatus struct {
+	Name            string            `json:"name"`
+	TemplateStatus  string            `json:"templateStatus,omitempty"`
+	TemplateMessage string            `json:"templateMessage,omitempty"`
+	Nodes           []NodeDriftStatus `json:"nodes,omitempty"`
+}
+
+// DriftStatus is the status of the TerraformStateStatus object.
+type DriftStatus struct {
+	LastCheckTime string                 `json:"lastCheckTime"`
+	Cluster       ClusterDriftStatus     `json:"cluster"`
+	NodeGroups    []NodeGroupDriftStatus `json:"nodeGroups,omitempty"`
+}
+
+func driftedResources(changes []terraform.ResourceChange) []DriftedResource {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	resources := make([]DriftedResource, 0, len(changes))
+	for i := range changes {
+		resources = append(resources, DriftedResource{
+			Address:    changes[i].Address,
+			Action:     changes[i].Action,
+			Attributes: changes[i].ChangedAttributes(),
+		})
+	}
+
+	return resources
+}
+
+func newDriftStatus(statistic *converge.Statistics, now time.Time) *DriftStatus {
+	status := &DriftStatus{
+		LastCheckTime: now.UTC().Format(time.RFC3339),
+		Cluster: ClusterDriftStatus{
+			Status:    statistic.Cluster.Status,
+			Message:   statistic.Cluster.Message,
+			Resources: driftedResources(statistic.Cluster.Changes),
+		},
+	}
+
+	groups := make(map[string]*NodeGroupDriftStatus)
+	getGroup := func(name string) *NodeGroupDriftStatus {
+		group, ok := groups[name]
+		if !ok {
+			group = &NodeGroupDriftStatus{Name: name}
+			groups[name] = group
+		}
+		return group
+	}
+
+	for _, template := range statistic.NodeTemplates {
+		group := getGroup(template.Name)
+		group.TemplateStatus = template.Status
+		group.TemplateMessage = template.Message
+	}
+
+	for _, node := range statistic.Node {
+		group := getGroup(node.Group)
+		group.Nodes = append(group.Nodes, NodeDriftStatus{
+			Name:      node.Name,
+			Status:    node.Status,
+			Message:   node.Message,
+			Resources: driftedResources(node.Changes),
+		})
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		group := groups[name]
+		sort.Slice(group.Nodes, func(i, j int) bool { return group.Nodes[i].Name < group.Nodes[j].Name })
+		status.NodeGroups = append(status.NodeGroups, *group)
+	}
+
+	return status
+}
+
+type driftEntity struct {
+	Title     string
+	Status    string
+	Message   string
+	Resources []DriftedResource
+}
+
+// entities flattens the status into the map of checked entities to compare statuses between checks.
+func (s *DriftStatus) entities() map[string]driftEntity {
+	result := make(map[string]driftEntity)
+	if s == nil {
+		return result
+	}
+
+	if s.Cluster.Status != "" {
+		result["cluster"] = driftEntity{
+			Title:     "Base infrastructure",
+			Status:    s.Cluster.Status,
+			Message:   s.Cluster.Message,
+			Resources: s.Cluster.Resources,
+		}
+	}
+
+	for _, group := range s.NodeGroups {
+		if group.TemplateStatus != "" {
+			result["nodegroup/"+group.Name] = driftEntity{
+				Title:   fmt.Sprintf("NodeGroup %s nodeTemplate", group.Name),
+				Status:  group.TemplateStatus,
+				Message: group.TemplateMessage,
+			}
+		}
+
+		for _, node := range group.Nodes {
+			result["node/"+group.Name+"/"+node.Name] = driftEntity{
+				Title:     fmt.Sprintf("Node %s/%s", group.Name, node.Name),
+				Status:    node.Status,
+				Message:   node.Message,
+				Resources: node.Resources,
+			}
+		}
+	}
+
+	return result
+}
+
+type driftTransition struct {
+	Reason  string
+	Type    string
+	Message string
+}
+
+func transitionMessage(entity driftEntity, from string) string {
+	var b strings.Builder
+
+	if from == "" {
+		fmt.Fprintf(&b, "%s status is %s", entity.Title, entity.Status)
+	} else {
+		fmt.Fprintf(&b, "%s status changed from %s to %s", entity.Title, from, entity.Status)
+	}
+
+	if entity.Message != "" {
+		fmt.Fprintf(&b, ": %s", entity.Message)
+	}
+
+	for i, resource := range entity.Resources {
+		if i == 0 {
+			b.WriteString("; resources:")
+		}
+		fmt.Fprintf(&b, " %s %s", resource.Action, resource.Address)
+		if len(resource.Attributes) > 0 {
+			fmt.Fprintf(&b, " (%s)", strings.Join(resource.Attributes, ", "))
+		}
+		if i < len(entity.Resources)-1 {
+			b.WriteString(",")
+		}
+	}
+
+	message := b.String()
+	if len(message) > maxEventMessageLength {
+		message = message[:maxEventMessageLength-3] + "..."
+	}
+
+	return message
+}
+
+// driftTransitions returns transitions of entity statuses between two checks.
+// Entities which appear with the ok status and entities which disappear are not reported.
+func driftTransitions(previous, current *DriftStatus) []driftTransition {
+	previousEntities := previous.entities()
+	currentEntities := current.entities()
+
+	keys := make([]string, 0, len(currentEntities))
+	for key := range currentEntities {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	transitions := make([]driftTransition, 0)
+	for _, key := range keys {
+		entity := currentEntities[key]
+		from := previousEntities[key].Status
+
+		if from == entity.Status || (from == "" && entity.Status == converge.OKStatus) {
+			continue
+		}
+
+		transition := driftTransition{
+			Reason:  EventReasonDriftDetected,
+			Type:    apiv1.EventTypeWarning,
+			Message: transitionMessage(entity, from),
+		}
+
+		switch entity.Status {
+		case converge.OKStatus:
+			transition.Reason = EventReasonDriftResolved
+			transition.Type = apiv1.EventTypeNormal
+		case converge.ErrorStatus:
+			transition.Reason = EventReasonCheckFailed
+		}
+
+		transitions = append(transitions, transition)
+	}
+
+	return transitions
+}
+
+func getOrCreateStatusObject(kubeCl *client.KubernetesClient) (*unstructured.Unstructured, error) {
+	obj, err := kubeCl.Dynamic().Resource(statusObjectGVR).Get(context.TODO(), statusObjectName, metav1.GetOptions{})
+	if err == nil {
+		return obj, nil
+	}
+
+	if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	obj = &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": statusObjectAPIVersion,
+		"kind":       statusObjectKind,
+		"metadata": map[string]interface{}{
+			"name": statusObjectName,
+		},
+	}}
+
+	return kubeCl.Dynamic().Resource(statusObjectGVR).Create(context.TODO(), obj, metav1.CreateOptions{})
+}
+
+func statusFromObject(obj *unstructured.Unstructured) (*DriftStatus, error) {
+	content, ok := obj.Object["status"]
+	if !ok {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var status DriftStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+func createTransitionEvent(kubeCl *client.KubernetesClient, obj *unstructured.Unstructured, transition driftTransition, now time.Time) error {
+	event := &apiv1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			// the same naming as in the client-go event recorder
+			Name:      fmt.Sprintf("%v.%x", obj.GetName(), now.UnixNano()),
+			Namespace: statusEventsNamespace,
+		},
+		InvolvedObject: apiv1.ObjectReference{
+			APIVersion:      statusObjectAPIVersion,
+			Kind:            statusObjectKind,
+			Name:            obj.GetName(),
+			UID:             obj.GetUID(),
+			ResourceVersion: obj.GetResourceVersion(),
+		},
+		Reason:         transition.Reason,
+		Message:        transition.Message,
+		Type:           transition.Type,
+		Source:         apiv1.EventSource{Component: statusEventsComponent},
+		FirstTimestamp: metav1.NewTime(now),
+		LastTimestamp:  metav1.NewTime(now),
+		Count:          1,
+	}
+
+	_, err := kubeCl.CoreV1().Events(statusEventsNamespace).Create(context.TODO(), event, metav1.CreateOptions{})
+	return err
+}
+
+// updateStatusObject writes the drift details into the TerraformStateStatus object
+// and creates an event for every status transition since the previous check.
+func updateStatusObject(kubeCl *client.KubernetesClient, statistic *converge.Statistics, now time.Time) error {
+	obj, err := getOrCreateStatusObject(kubeCl)
+	if err != nil {
+		return fmt.Errorf("get %s %s: %v", statusObjectKind, statusObjectName, err)
+	}
+
+	previous, err := statusFromObject(obj)
+	if err != nil {
+		return fmt.Errorf("parse %s %s status: %v", statusObjectKind, statusObjectName, err)
+	}
+
+	current := newDriftStatus(statistic, now)
+
+	data, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+
+	var content map[string]interface{}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return err
+	}
+
+	obj.Object["status"] = content
+	obj, err = kubeCl.Dynamic().Resource(statusObjectGVR).UpdateStatus(context.TODO(), obj, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("update %s %s status: %v", statusObjectKind, statusObjectName, err)
+	}
+
+	for i, transition := range driftTransitions(previous, current) {
+		// shift timestamps to get unique event names
+		if err := createTransitionEvent(kubeCl, obj, transition, now.Add(time.Duration(i))); err != nil {
+			return fmt.Errorf("create event: %v", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/kubernetes/actions/converge"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/kubernetes/client"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/terraform"
+)
+
+func testDriftStatistic(nodeStatus string) *converge.Statistics {
+	node := converge.NodeCheckResult{Group: "worker", Name: "test-worker-0", Status: nodeStatus}
+	if nodeStatus == converge.ChangedStatus {
+		node.Changes = []terraform.ResourceChange{{
+			Address: "module.node.yandex_compute_instance.node",
+			Action:  terraform.ResourceActionUpdate,
+			Before:  map[string]interface{}{"cores": float64(2), "name": "test-worker-0"},
+			After:   map[string]interface{}{"cores": float64(4), "name": "test-worker-0"},
+		}}
+	}
+
+	return &converge.Statistics{
+		Cluster: converge.ClusterCheckResult{Status: converge.OKStatus},
+		Node: []converge.NodeCheckResult{
+			{Group: "worker", Name: "test-worker-1", Status: converge.OKStatus},
+			node,
+			{Group: "master", Name: "test-master-0", Status: converge.OKStatus},
+		},
+		NodeTemplates: []converge.NodeGroupCheckResult{
+			{Name: "master", Status: converge.OKStatus},
+			{Name: "worker", Status: converge.ChangedStatus, Message: "nodeTemplate differs"},
+		},
+	}
+}
+
+func TestNewDriftStatus(t *testing.T) {
+	now := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	status := newDriftStatus(testDriftStatistic(converge.ChangedStatus), now)
+
+	require.Equal(t, "2022-03-01T10:00:00Z", status.LastCheckTime)
+	require.Equal(t, converge.OKStatus, status.Cluster.Status)
+	require.Len(t, status.NodeGroups, 2)
+
+	require.Equal(t, "master", status.NodeGroups[0].Name)
+	require.Len(t, status.NodeGroups[0].Nodes, 1)
+
+	worker := status.NodeGroups[1]
+	require.Equal(t, "worker", worker.Name)
+	require.Equal(t, converge.ChangedStatus, worker.TemplateStatus)
+	require.Equal(t, "nodeTemplate differs", worker.TemplateMessage)
+	require.Equal(t, "test-worker-0", worker.Nodes[0].Name)
+	require.Equal(t, "test-worker-1", worker.Nodes[1].Name)
+	require.Equal(t, []DriftedResource{{
+		Address:    "module.node.yandex_compute_instance.node",
+		Action:     terraform.ResourceActionUpdate,
+		Attributes: []string{"cores"},
+	}}, worker.Nodes[0].Resources)
+}
+
+func TestDriftTransitions(t *testing.T) {
+	now := time.Now()
+	changed := newDriftStatus(testDriftStatistic(converge.ChangedStatus), now)
+	ok := newDriftStatus(testDriftStatistic(converge.OKStatus), now)
+	failed := newDriftStatus(testDriftStatistic(converge.ErrorStatus), now)
+
+	t.Run("First check reports only not ok statuses", func(t *testing.T) {
+		transitions := driftTransitions(nil, changed)
+		require.Len(t, transitions, 2)
+
+		require.Equal(t, EventReasonDriftDetected, transitions[0].Reason)
+		require.Equal(t, apiv1.EventTypeWarning, transitions[0].Type)
+		require.Equal(t,
+			"Node worker/test-worker-0 status is changed; resources: update module.node.yandex_compute_instance.node (cores)",
+			transitions[0].Message,
+		)
+		require.Equal(t, "NodeGroup worker nodeTemplate status is changed: nodeTemplate differs", transitions[1].Message)
+	})
+
+	t.Run("Same statuses do not produce transitions", func(t *testing.T) {
+		require.Len(t, driftTransitions(changed, changed), 0)
+	})
+
+	t.Run("Resolved drift", func(t *testing.T) {
+		transitions := driftTransitions(changed, ok)
+		require.Len(t, transitions, 1)
+		require.Equal(t, EventReasonDriftResolved, transitions[0].Reason)
+		require.Equal(t, apiv1.EventTypeNormal, transitions[0].Type)
+		require.Equal(t, "Node worker/test-worker-0 status changed from changed to ok", transitions[0].Message)
+	})
+
+	t.Run("Failed check", func(t *testing.T) {
+		transitions := driftTransitions(ok, failed)
+		require.Len(t, transitions, 1)
+		require.Equal(t, EventReasonCheckFailed, transitions[0].Reason)
+		require.Equal(t, apiv1.EventTypeWarning, transitions[0].Type)
+	})
+}
+
+func TestUpdateStatusObject(t *testing.T) {
+	kubeCl := client.NewFakeKubernetesClient()
+
+	listEvents := func() []apiv1.Event {
+		events, err := kubeCl.CoreV1().Events(statusEventsNamespace).List(context.TODO(), metav1.ListOptions{})
+		require.NoError(t, err)
+		return events.Items
+	}
+
+	err := updateStatusObject(kubeCl, testDriftStatistic(converge.ChangedStatus), time.Now())
+	require.NoError(t, err)
+
+	obj, err := kubeCl.Dynamic().Resource(statusObjectGVR).Get(context.TODO(), statusObjectName, metav1.GetOptions{})
+	require.NoError(t, err)
+
+	status, err := statusFromObject(obj)
+	require.NoError(t, err)
+	require.Equal(t, converge.ChangedStatus, status.NodeGroups[1].Nodes[0].Status)
+	require.Equal(t, []string{"cores"}, status.NodeGroups[1].Nodes[0].Resources[0].Attributes)
+
+	events := listEvents()
+	require.Len(t, events, 2)
+	require.Equal(t, statusObjectKind, events[0].InvolvedObject.Kind)
+	require.Equal(t, statusObjectName, events[0].InvolvedObject.Name)
+
+	err = updateStatusObject(kubeCl, testDriftStatistic(converge.ChangedStatus), time.Now())
+	require.NoError(t, err)
+	require.Len(t, listEvents(), 2)
+
+	err = updateStatusObject(kubeCl, testDriftStatistic(converge.OKStatus), time.Now())
+	require.NoError(t, err)
+
+	events = listEvents()
+	require.Len(t, events, 3)
+
+	reasons := make([]string, 0, len(events))
+	for _, event := range events {
+		reasons = append(reasons, event.Reason)
+	}
+	require.ElementsMatch(t, []string{EventReasonDriftDetected, EventReasonDriftDetected, EventReasonDriftResolved}, reasons)
+}
@@ -0,0 +1,59 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"fmt"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/config"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/terraform"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/util/tomb"
+)
+
+func cloudCredentialsCheck() Check {
+	return Check{
+		Name:        CheckCloudAccess,
+		Description: "Cloud provider credentials are valid",
+		Applicable: func(in *Input) (bool, string) {
+			if in.MetaConfig == nil || in.MetaConfig.ClusterType != config.CloudClusterType {
+				return false, "cluster is not a cloud cluster"
+			}
+			return true, ""
+		},
+		Run: checkCloudCredentials,
+	}
+}
+
+// checkCloudCredentials runs terraform plan for the base infrastructure with an empty state.
+// Nothing is created, but the provider authenticates to refresh data sources.
+func checkCloudCredentials(in *Input) error {
+	runner := terraform.NewImmutableRunnerFromConfig(in.MetaConfig, "base-infrastructure").
+		WithName("preflight-base-infrastructure").
+		WithVariables(in.MetaConfig.MarshalConfig()).
+		WithState(nil).
+		WithAutoApprove(true)
+	tomb.RegisterOnShutdown("preflight-base-infrastructure", runner.Stop)
+	defer runner.Stop()
+
+	if err := runner.Init(); err != nil {
+		return fmt.Errorf("terraform init: %v", err)
+	}
+
+	if err := runner.Plan(); err != nil {
+		return fmt.Errorf("terraform plan with %s provider credentials: %v", in.MetaConfig.ProviderName, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,311 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/template"
+)
+
+const (
+	CheckSSH         = "ssh"
+	CheckSudo        = "sudo"
+	CheckOS          = "os"
+	CheckDisk        = "disk"
+	CheckPorts       = "ports"
+	CheckTimeSkew    = "time-skew"
+	CheckRegistry    = "registry"
+	CheckCloudAccess = "cloud-credentials"
+
+	// containerd images and etcd data are stored in /var/lib
+	diskPathToCheck = "/var/lib"
+	minFreeDisk     = 20 * 1024 * 1024 * 1024
+
+	maxTimeSkew = 30 * time.Second
+
+	candiBundlesDir = "/deckhouse/candi/bashible/bundles"
+)
+
+var (
+	minKernelVersion = [2]int{3, 10}
+
+	// ports of control plane components on the master node
+	requiredPorts = []int{2379, 2380, 6443, 10250, 10257, 10259}
+)
+
+func nodeChecks() []Check {
+	return []Check{
+		{
+			Name:        CheckSSH,
+			Description: "SSH connection to the master host",
+			Applicable:  sshApplicable,
+			Run: func(in *Input) error {
+				output, err := in.SSHClient.Check().ExpectAvailable()
+				if err != nil {
+					return fmt.Errorf("host is not reachable via ssh: %v %s", err, string(output))
+				}
+				return nil
+			},
+		},
+		{
+			Name:        CheckSudo,
+			Description: "Sudo on the master host",
+			DependsOn:   []string{CheckSSH},
+			Applicable:  sshApplicable,
+			Run: func(in *Input) error {
+				output, err := in.SSHClient.Command("id -u").Sudo().CombinedOutput()
+				if err != nil {
+					return fmt.Errorf("sudo failed: %v %s", err, string(output))
+				}
+				if uid := strings.TrimSpace(lastLine(string(output))); uid != "0" {
+					return fmt.Errorf("command is executed with uid %q instead of root", uid)
+				}
+				return nil
+			},
+		},
+		{
+			Name:        CheckOS,
+			Description: "OS distribution and kernel of the master host",
+			DependsOn:   []string{CheckSSH},
+			Applicable:  sshApplicable,
+			Run:         checkOS,
+		},
+		{
+			Name:        CheckDisk,
+			Description: fmt.Sprintf("Free disk space in %s on the master host", diskPathToCheck),
+			DependsOn:   []string{CheckSSH},
+			Applicable:  sshApplicable,
+			Run: func(in *Input) error {
+				output, err := in.SSHClient.Command(fmt.Sprintf("df -P -B1 %s", diskPathToCheck)).CombinedOutput()
+				if err != nil {
+					return fmt.Errorf("df: %v %s", err, string(output))
+				}
+				free, err := parseDfAvailable(string(output))
+				if err != nil {
+					return err
+				}
+				if free < minFreeDisk {
+					return fmt.Errorf("%s has %s free, at least %s is required", diskPathToCheck, formatBytes(free), formatBytes(minFreeDisk))
+				}
+				log.InfoF("Free space: %s\n", formatBytes(free))
+				return nil
+			},
+		},
+		{
+			Name:        CheckPorts,
+			Description: "Control plane ports are free on the master host",
+			DependsOn:   []string{CheckSudo},
+			Applicable:  sshApplicable,
+			Run: func(in *Input) error {
+				output, err := in.SSHClient.Command("ss -ltn").Sudo().CombinedOutput()
+				if err != nil {
+					return fmt.Errorf("ss: %v %s", err, string(output))
+				}
+				busy := busyPorts(parseListenPorts(string(output)), requiredPorts)
+				if len(busy) > 0 {
+					return fmt.Errorf("ports are already in use: %v", busy)
+				}
+				return nil
+			},
+		},
+		{
+			Name:        CheckTimeSkew,
+			Description: "Time on the master host is synchronized",
+			DependsOn:   []string{CheckSSH},
+			Applicable:  sshApplicable,
+			Run: func(in *Input) error {
+				before := time.Now()
+				output, err := in.SSHClient.Command("date +%s").CombinedOutput()
+				if err != nil {
+					return fmt.Errorf("date: %v %s", err, string(output))
+				}
+				after := time.Now()
+
+				skew, err := timeSkew(string(output), before, after)
+				if err != nil {
+					return err
+				}
+				if skew > maxTimeSkew {
+					return fmt.Errorf("time difference between local and master host is %s, should be less than %s", skew, maxTimeSkew)
+				}
+				return nil
+			},
+		},
+	}
+}
+
+func sshApplicable(in *Input) (bool, string) {
+	if !in.hasSSHHosts() {
+		return false, "ssh host is not passed"
+	}
+	return true, ""
+}
+
+func checkOS(in *Input) error {
+	file, err := template.RenderAndSaveDetectBundle(make(map[string]interface{}))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file)
+
+	stdout, err := in.SSHClient.UploadScript(file).Execute()
+	if err != nil {
+		return fmt.Errorf("OS is not supported: %v", err)
+	}
+
+	bundle := strings.TrimSpace(string(stdout))
+	if bundle == "" {
+		return fmt.Errorf("OS is not supported: bundle is not detected")
+	}
+	if _, err := os.Stat(filepath.Join(candiBundlesDir, bundle)); err != nil {
+		return fmt.Errorf("bundle %s is detected, but it is not found in candi: %v", bundle, err)
+	}
+	log.InfoF("Detected bundle: %s\n", bundle)
+
+	output, err := in.SSHClient.Command("uname -r").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("uname: %v %s", err, string(output))
+	}
+
+	kernel := strings.TrimSpace(string(output))
+	version, err := parseKernelVersion(kernel)
+	if err != nil {
+		return err
+	}
+	if version[0] < minKernelVersion[0] || (version[0] == minKernelVersion[0] && version[1] < minKernelVersion[1]) {
+		return fmt.Errorf("kernel %s is not supported, minimal version is %d.%d", kernel, minKernelVersion[0], minKernelVersion[1])
+	}
+	log.InfoF("Kernel: %s\n", kernel)
+
+	return nil
+}
+
+// lastLine returns the last non-empty line, sudo may output a banner before the command output.
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return lines[len(lines)-1]
+}
+
+// parseDfAvailable returns available bytes from the 'df -P -B1 <path>' output.
+func parseDfAvailable(output string) (int64, error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) < 2 {
+		return 0, fmt.Errorf("unexpected df output: %q", output)
+	}
+
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 4 {
+		return 0, fmt.Errorf("unexpected df output: %q", output)
+	}
+
+	available, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected df output: %q: %v", output, err)
+	}
+	return available, nil
+}
+
+// parseListenPorts returns ports from the 'ss -ltn' output, the header line is skipped because it has no port.
+func parseListenPorts(output string) map[int]bool {
+	ports := make(map[int]bool)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+
+		local := fields[3]
+		idx := strings.LastIndex(local, ":")
+		if idx < 0 {
+			continue
+		}
+
+		port, err := strconv.Atoi(local[idx+1:])
+		if err != nil {
+			continue
+		}
+		ports[port] = true
+	}
+	return ports
+}
+
+func busyPorts(listen map[int]bool, required []int) []int {
+	busy := make([]int, 0)
+	for _, port := range required {
+		if listen[port] {
+			busy = append(busy, port)
+		}
+	}
+	sort.Ints(busy)
+	return busy
+}
+
+// parseKernelVersion returns major and minor version from the 'uname -r' output, e.g. 5.4.0-100-generic.
+func parseKernelVersion(kernel string) ([2]int, error) {
+	var version [2]int
+
+	parts := strings.SplitN(kernel, ".", 3)
+	if len(parts) < 2 {
+		return version, fmt.Errorf("unexpected kernel version %q", kernel)
+	}
+
+	for i := 0; i < 2; i++ {
+		digits := parts[i]
+		if end := strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }); end >= 0 {
+			digits = digits[:end]
+		}
+
+		n, err := strconv.Atoi(digits)
+		if err != nil {
+			return version, fmt.Errorf("unexpected kernel version %q", kernel)
+		}
+		version[i] = n
+	}
+
+	return version, nil
+}
+
+// timeSkew compares the remote unix time with the middle of the request.
+func timeSkew(output string, before, after time.Time) (time.Duration, error) {
+	remoteUnix, err := strconv.ParseInt(strings.TrimSpace(lastLine(output)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected date output: %q", output)
+	}
+
+	local := before.Add(after.Sub(before) / 2)
+	skew := local.Sub(time.Unix(remoteUnix, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+
+	// remote time has seconds precision
+	if skew <= time.Second {
+		return 0, nil
+	}
+	return skew.Round(time.Second), nil
+}
+
+func formatBytes(b int64) string {
+	const gib = 1024 * 1024 * 1024
+	return fmt.Sprintf("%.1fGiB", float64(b)/gib)
+}
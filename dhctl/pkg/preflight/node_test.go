@@ -0,0 +1,79 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDfAvailable(t *testing.T) {
+	output := `Filesystem        1-blocks       Used    Available Capacity Mounted on
+/dev/sda1      52776349696 9046806528 43712765952      18% /
+`
+	free, err := parseDfAvailable(output)
+	require.NoError(t, err)
+	require.Equal(t, int64(43712765952), free)
+
+	_, err = parseDfAvailable("df: /var/lib: No such file or directory")
+	require.Error(t, err)
+}
+
+func TestParseListenPorts(t *testing.T) {
+	output := `State    Recv-Q   Send-Q     Local Address:Port     Peer Address:Port  Process
+LISTEN   0        128              0.0.0.0:22            0.0.0.0:*
+LISTEN   0        4096           127.0.0.1:2379          0.0.0.0:*
+LISTEN   0        4096                   *:10250               *:*
+LISTEN   0        128                 [::]:22               [::]:*
+`
+	ports := parseListenPorts(output)
+	require.Equal(t, map[int]bool{22: true, 2379: true, 10250: true}, ports)
+	require.Equal(t, []int{2379, 10250}, busyPorts(ports, requiredPorts))
+}
+
+func TestParseKernelVersion(t *testing.T) {
+	for kernel, expected := range map[string][2]int{
+		"5.4.0-100-generic":          {5, 4},
+		"3.10.0-1160.el7.x86_64":     {3, 10},
+		"4.19.0-18-cloud-amd64":      {4, 19},
+		"5.15.0-1019-aws\n":          {5, 15},
+		"6.1rc1-something-not-digit": {6, 1},
+	} {
+		version, err := parseKernelVersion(kernel)
+		require.NoError(t, err, kernel)
+		require.Equal(t, expected, version, kernel)
+	}
+
+	_, err := parseKernelVersion("unknown")
+	require.Error(t, err)
+}
+
+func TestTimeSkew(t *testing.T) {
+	before := time.Unix(1000, 0)
+	after := before.Add(2 * time.Second)
+
+	skew, err := timeSkew("1001\n", before, after)
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(0), skew)
+
+	skew, err = timeSkew("SUDO-SUCCESS\n941\n", before, after)
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, skew)
+
+	_, err = timeSkew("Thu Jan  1 00:16:41 UTC 1970", before, after)
+	require.Error(t, err)
+}
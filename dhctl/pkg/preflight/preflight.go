@@ -0,0 +1,243 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/app"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/config"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/system/ssh"
+)
+
+var ErrChecksFailed = errors.New("preflight checks failed")
+
+type Status string
+
+const (
+	StatusPassed  = Status("passed")
+	StatusFailed  = Status("failed")
+	StatusSkipped = Status("skipped")
+)
+
+// Input is passed to every check.
+type Input struct {
+	MetaConfig *config.MetaConfig
+	// SSHClient is nil if ssh hosts are not known yet, e.g. master of the cloud cluster is not created.
+	SSHClient *ssh.Client
+}
+
+func (i *Input) hasSSHHosts() bool {
+	return i.SSHClient != nil && len(i.SSHClient.Settings.AvailableHosts()) > 0
+}
+
+type Check struct {
+	Name        string
+	Description string
+	// DependsOn contains names of checks that should pass before this check.
+	DependsOn []string
+	// Applicable returns reason if the check makes no sense for the input.
+	Applicable func(in *Input) (bool, string)
+	Run        func(in *Input) error
+}
+
+type Result struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Status      Status `json:"status"`
+	Message     string `json:"message,omitempty"`
+	Duration    string `json:"duration"`
+}
+
+type Report struct {
+	Success bool     `json:"success"`
+	Checks  []Result `json:"checks"`
+}
+
+func (r *Report) Failed() []Result {
+	failed := make([]Result, 0)
+	for _, res := range r.Checks {
+		if res.Status == StatusFailed {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+func (r *Report) Error() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(failed))
+	for _, res := range failed {
+		names = append(names, res.Name)
+	}
+	return fmt.Errorf("%w: %s", ErrChecksFailed, strings.Join(names, ", "))
+}
+
+func (r *Report) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0o644)
+}
+
+var registry = make([]Check, 0)
+
+func init() {
+	Register(nodeChecks()...)
+	Register(registryCheck(), cloudCredentialsCheck())
+}
+
+// Register adds checks to the registry. Checks are run in the order of registration.
+func Register(checks ...Check) {
+	registry = append(registry, checks...)
+}
+
+func Checks() []Check {
+	return append([]Check(nil), registry...)
+}
+
+func CheckNames() []string {
+	names := make([]string, 0, len(registry))
+	for _, check := range registry {
+		names = append(names, check.Name)
+	}
+	return names
+}
+
+type Checker struct {
+	checks []Check
+	skip   map[string]bool
+}
+
+func NewChecker(checks []Check) *Checker {
+	return &Checker{
+		checks: checks,
+		skip:   make(map[string]bool),
+	}
+}
+
+func (c *Checker) WithSkipChecks(names []string) *Checker {
+	for _, name := range names {
+		c.skip[name] = true
+	}
+	return c
+}
+
+func (c *Checker) Run(in *Input) *Report {
+	report := &Report{Success: true, Checks: make([]Result, 0, len(c.checks))}
+	statuses := make(map[string]Status)
+
+	for _, check := range c.checks {
+		res := c.runCheck(check, in, statuses)
+		statuses[check.Name] = res.Status
+
+		if res.Status == StatusFailed {
+			report.Success = false
+		}
+		report.Checks = append(report.Checks, res)
+	}
+
+	return report
+}
+
+func (c *Checker) runCheck(check Check, in *Input, statuses map[string]Status) Result {
+	res := Result{Name: check.Name, Description: check.Description, Status: StatusSkipped, Duration: "0s"}
+
+	if c.skip[check.Name] {
+		res.Message = "skipped by flag"
+		log.InfoF("Skip %s: %s\n", check.Name, res.Message)
+		return res
+	}
+
+	if check.Applicable != nil {
+		if ok, reason := check.Applicable(in); !ok {
+			res.Message = reason
+			log.InfoF("Skip %s: %s\n", check.Name, res.Message)
+			return res
+		}
+	}
+
+	for _, dep := range check.DependsOn {
+		if statuses[dep] != StatusPassed {
+			res.Message = fmt.Sprintf("check %s is not passed", dep)
+			log.InfoF("Skip %s: %s\n", check.Name, res.Message)
+			return res
+		}
+	}
+
+	start := time.Now()
+	err := log.Process("common", check.Description, func() error {
+		return check.Run(in)
+	})
+	res.Duration = time.Since(start).Round(time.Millisecond).String()
+
+	if err != nil {
+		res.Status = StatusFailed
+		res.Message = err.Error()
+		return res
+	}
+
+	res.Status = StatusPassed
+	return res
+}
+
+// PrintReport outputs the summary table of the checks.
+func PrintReport(report *Report) {
+	_ = log.Process("common", "Preflight checks summary", func() error {
+		for _, res := range report.Checks {
+			line := fmt.Sprintf("%-8s %s", strings.ToUpper(string(res.Status)), res.Name)
+			if res.Message != "" {
+				line += ": " + res.Message
+			}
+			log.InfoLn(line)
+		}
+		return nil
+	})
+}
+
+// RunChecks runs registered checks according to the preflight flags and returns an error if any check fails.
+func RunChecks(in *Input) error {
+	if app.PreflightSkipAll {
+		log.WarnLn("Preflight checks are skipped by --preflight-skip-all flag")
+		return nil
+	}
+
+	var report *Report
+	_ = log.Process("common", "Preflight checks", func() error {
+		report = NewChecker(Checks()).WithSkipChecks(app.PreflightSkipChecks()).Run(in)
+		return nil
+	})
+
+	PrintReport(report)
+
+	if app.PreflightReportPath != "" {
+		if err := report.WriteFile(app.PreflightReportPath); err != nil {
+			return fmt.Errorf("write preflight report: %v", err)
+		}
+	}
+
+	return report.Error()
+}
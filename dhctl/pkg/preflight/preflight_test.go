@@ -0,0 +1,107 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+)
+
+func TestMain(m *testing.M) {
+	log.InitLogger("simple")
+	os.Exit(m.Run())
+}
+
+func TestChecker(t *testing.T) {
+	called := make([]string, 0)
+	check := func(name string, err error, deps ...string) Check {
+		return Check{
+			Name:        name,
+			Description: name,
+			DependsOn:   deps,
+			Run: func(_ *Input) error {
+				called = append(called, name)
+				return err
+			},
+		}
+	}
+
+	notApplicable := check("not-applicable", nil)
+	notApplicable.Applicable = func(_ *Input) (bool, string) { return false, "no hosts" }
+
+	report := NewChecker([]Check{
+		check("a", nil),
+		check("b", fmt.Errorf("broken")),
+		check("depends-on-b", nil, "b"),
+		check("depends-on-a", nil, "a"),
+		check("skipped", nil),
+		notApplicable,
+	}).WithSkipChecks([]string{"skipped"}).Run(&Input{})
+
+	require.False(t, report.Success)
+	require.Equal(t, []string{"a", "b", "depends-on-a"}, called)
+
+	statuses := make(map[string]Status)
+	messages := make(map[string]string)
+	for _, res := range report.Checks {
+		statuses[res.Name] = res.Status
+		messages[res.Name] = res.Message
+	}
+
+	require.Equal(t, map[string]Status{
+		"a":              StatusPassed,
+		"b":              StatusFailed,
+		"depends-on-b":   StatusSkipped,
+		"depends-on-a":   StatusPassed,
+		"skipped":        StatusSkipped,
+		"not-applicable": StatusSkipped,
+	}, statuses)
+	require.Equal(t, "broken", messages["b"])
+	require.Equal(t, "check b is not passed", messages["depends-on-b"])
+	require.Equal(t, "skipped by flag", messages["skipped"])
+	require.Equal(t, "no hosts", messages["not-applicable"])
+
+	require.EqualError(t, report.Error(), "preflight checks failed: b")
+
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+	require.NoError(t, report.WriteFile(reportPath))
+
+	data, err := ioutil.ReadFile(reportPath)
+	require.NoError(t, err)
+
+	var fromFile Report
+	require.NoError(t, json.Unmarshal(data, &fromFile))
+	require.Equal(t, *report, fromFile)
+}
+
+func TestRegisteredChecks(t *testing.T) {
+	names := CheckNames()
+	require.Equal(t, []string{CheckSSH, CheckSudo, CheckOS, CheckDisk, CheckPorts, CheckTimeSkew, CheckRegistry, CheckCloudAccess}, names)
+
+	// ssh checks are not applicable without hosts
+	report := NewChecker(Checks()).WithSkipChecks([]string{CheckRegistry, CheckCloudAccess}).Run(&Input{})
+	require.True(t, report.Success)
+	for _, res := range report.Checks {
+		require.Equal(t, StatusSkipped, res.Status, res.Name)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/config"
+)
+
+const registryRequestTimeout = 15 * time.Second
+
+var authParamRe = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+func registryCheck() Check {
+	return Check{
+		Name:        CheckRegistry,
+		Description: "Registry is reachable and credentials are valid",
+		Applicable: func(in *Input) (bool, string) {
+			if in.MetaConfig == nil || in.MetaConfig.Registry.Address == "" {
+				return false, "registry is not configured"
+			}
+			return true, ""
+		},
+		Run: func(in *Input) error {
+			registryData, err := in.MetaConfig.ParseRegistryData()
+			if err != nil {
+				return err
+			}
+
+			auth, _ := registryData["auth"].(string)
+			return checkRegistry(in.MetaConfig.Registry, auth)
+		},
+	}
+}
+
+// checkRegistry requests the registry API version check endpoint.
+// If the registry requires a token, the token is requested with the credentials from the docker config.
+func checkRegistry(registry config.RegistryData, auth string) error {
+	client, err := registryHTTPClient(registry.CA)
+	if err != nil {
+		return err
+	}
+
+	scheme := registry.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+	endpoint := fmt.Sprintf("%s://%s/v2/", scheme, registry.Address)
+
+	resp, err := client.Get(endpoint)
+	if err != nil {
+		return fmt.Errorf("registry %s is not reachable: %v", registry.Address, err)
+	}
+	_ = resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized:
+	default:
+		return fmt.Errorf("unexpected response from %s: %s", endpoint, resp.Status)
+	}
+
+	challenge := resp.Header.Get("WWW-Authenticate")
+	switch {
+	case strings.HasPrefix(strings.ToLower(challenge), "basic"):
+		return checkRegistryBasicAuth(client, endpoint, auth)
+	case strings.HasPrefix(strings.ToLower(challenge), "bearer"):
+		return checkRegistryToken(client, challenge, strings.TrimPrefix(registry.Path, "/"), auth)
+	default:
+		return fmt.Errorf("unsupported authentication challenge from %s: %q", endpoint, challenge)
+	}
+}
+
+func checkRegistryBasicAuth(client *http.Client, endpoint, auth string) error {
+	if auth == "" {
+		return fmt.Errorf("registry requires authentication, but credentials are not found in registryDockerCfg")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Basic "+auth)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("registry credentials are not valid: %s", resp.Status)
+	}
+	return nil
+}
+
+func checkRegistryToken(client *http.Client, challenge, repository, auth string) error {
+	params := make(map[string]string)
+	for _, m := range authParamRe.FindAllStringSubmatch(challenge, -1) {
+		params[m[1]] = m[2]
+	}
+
+	realm := params["realm"]
+	if realm == "" {
+		return fmt.Errorf("realm is not found in the authentication challenge %q", challenge)
+	}
+
+	query := url.Values{}
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	if repository != "" {
+		query.Set("scope", fmt.Sprintf("repository:%s:pull", repository))
+	}
+
+	req, err := http.NewRequest(http.MethodGet, realm+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	if auth != "" {
+		req.Header.Set("Authorization", "Basic "+auth)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("token service %s is not reachable: %v", realm, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("registry credentials are not valid: token service responded with %s", resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return fmt.Errorf("unexpected response from token service: %v", err)
+	}
+	if token.Token == "" && token.AccessToken == "" {
+		return fmt.Errorf("token service returned an empty token")
+	}
+
+	return nil
+}
+
+func registryHTTPClient(ca string) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if ca != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(ca)) {
+			return nil, fmt.Errorf("registryCA contains no valid certificates")
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	return &http.Client{Transport: transport, Timeout: registryRequestTimeout}, nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/config"
+)
+
+func TestCheckRegistry(t *testing.T) {
+	validAuth := base64.StdEncoding.EncodeToString([]byte("user:password"))
+
+	var scopes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="http://%s/token",service="test-registry"`, r.Host))
+			w.WriteHeader(http.StatusUnauthorized)
+		case "/token":
+			scopes = append(scopes, r.URL.Query().Get("scope"))
+			if r.Header.Get("Authorization") != "Basic "+validAuth || r.URL.Query().Get("service") != "test-registry" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"token": "secret"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	registry := config.RegistryData{
+		Address: strings.TrimPrefix(server.URL, "http://"),
+		Path:    "/deckhouse/ce",
+		Scheme:  "http",
+	}
+
+	require.NoError(t, checkRegistry(registry, validAuth))
+	require.Equal(t, []string{"repository:deckhouse/ce:pull"}, scopes)
+
+	err := checkRegistry(registry, base64.StdEncoding.EncodeToString([]byte("user:wrong")))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "credentials are not valid")
+}
+
+func TestCheckRegistryBasicAuth(t *testing.T) {
+	validAuth := base64.StdEncoding.EncodeToString([]byte("user:password"))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Basic "+validAuth {
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	registry := config.RegistryData{Address: strings.TrimPrefix(server.URL, "http://"), Scheme: "http"}
+
+	require.NoError(t, checkRegistry(registry, validAuth))
+	require.Error(t, checkRegistry(registry, ""))
+}
+
+func TestCheckRegistryUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	address := strings.TrimPrefix(server.URL, "http://")
+	server.Close()
+
+	err := checkRegistry(config.RegistryData{Address: address, Scheme: "http"}, "")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "is not reachable")
+}
@@ -15,7 +15,9 @@
 package cache
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"sync"
@@ -25,7 +27,9 @@ import (
 	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/state"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/util/cache"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/util/s3"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/util/stringsutil"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/util/tomb"
 )
 
 var once sync.Once
@@ -34,6 +38,11 @@ var (
 	_ state.Cache = &cache.StateCache{}
 	_ state.Cache = &cache.DummyCache{}
 	_ state.Cache = &client.StateCache{}
+	_ state.Cache = &cache.EncryptedStateCache{}
+	_ state.Cache = &cache.S3StateCache{}
+
+	_ state.Locker = &cache.EncryptedStateCache{}
+	_ state.Locker = &cache.S3StateCache{}
 )
 
 var globalCache state.Cache = &cache.DummyCache{}
@@ -46,6 +55,12 @@ func choiceCache(identity string) (state.Cache, error) {
 	}
 
 	if app.CacheKubeNamespace == "" {
+		switch app.CacheBackend {
+		case app.CacheBackendEncrypted:
+			return newEncryptedCache(identity, tmpDir)
+		case app.CacheBackendS3:
+			return newS3Cache(identity)
+		}
 		return cache.NewStateCache(tmpDir)
 	}
 
@@ -84,10 +99,96 @@ func choiceCache(identity string) (state.Cache, error) {
 	return k8sCache, nil
 }
 
+// plainFilesDir returns the directory for plain terraform states of the remote and encrypted caches.
+// The directory is removed on dhctl shutdown.
+func plainFilesDir(identity string) string {
+	dir := filepath.Join(app.TmpDirName, "cache", stringsutil.Sha256Encode(identity))
+	tomb.RegisterOnShutdown("Clear plain cache files", func() {
+		// do not clean tmp dir, because user may need temporary files to debug terraform
+		if app.IsDebug {
+			return
+		}
+		_ = os.RemoveAll(dir)
+	})
+	return dir
+}
+
+func newEncryptedCache(identity, dir string) (state.Cache, error) {
+	if app.CacheEncryptionPassphraseFile == "" {
+		return nil, fmt.Errorf("--cache-encryption-passphrase-file is required for the %s cache", app.CacheBackendEncrypted)
+	}
+
+	passphrase, err := ioutil.ReadFile(app.CacheEncryptionPassphraseFile)
+	if err != nil {
+		return nil, fmt.Errorf("can't read passphrase for the cache: %w", err)
+	}
+
+	tmpDir := plainFilesDir(identity)
+	return cache.NewEncryptedStateCache(dir, tmpDir, bytes.TrimSpace(passphrase))
+}
+
+func newS3Cache(identity string) (state.Cache, error) {
+	client, err := s3.NewClient(s3.Config{
+		Endpoint:        app.CacheS3Endpoint,
+		Region:          app.CacheS3Region,
+		Bucket:          app.CacheS3Bucket,
+		AccessKeyID:     app.CacheS3AccessKeyID,
+		SecretAccessKey: app.CacheS3SecretAccessKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't create s3 client for the cache: %w", err)
+	}
+
+	prefix := app.CacheS3Prefix
+	if prefix == "" {
+		prefix = stringsutil.Sha256Encode(identity)
+	}
+
+	tmpDir := plainFilesDir(identity)
+	return cache.NewS3StateCache(client, prefix, tmpDir)
+}
+
+// lockCache locks the cache if it can be shared between several operators.
+// The lock is released on dhctl shutdown.
+func lockCache(stateCache state.Cache) error {
+	locker, ok := stateCache.(state.Locker)
+	if !ok {
+		return nil
+	}
+
+	owner := os.Getenv("USER")
+	if owner == "" {
+		owner = app.AppName
+	}
+
+	if err := locker.Lock(state.NewLockInfo(owner)); err != nil {
+		return err
+	}
+
+	tomb.RegisterOnShutdown("Unlock state cache", func() {
+		if err := locker.Unlock(); err != nil {
+			log.ErrorF("Cannot unlock state cache: %v\n", err)
+		}
+	})
+
+	return nil
+}
+
 func initCache(identity string) error {
 	var err error
 	once.Do(func() {
-		globalCache, err = choiceCache(identity)
+		var c state.Cache
+		c, err = choiceCache(identity)
+		if err != nil {
+			return
+		}
+
+		err = lockCache(c)
+		if err != nil {
+			return
+		}
+
+		globalCache = c
 	})
 	return err
 }
@@ -0,0 +1,77 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+const LockKey = ".dhctl-lock"
+
+// Locker is implemented by caches which can be shared between several operators.
+// The lock prevents two dhctl instances from working with the same cache simultaneously.
+type Locker interface {
+	Lock(info LockInfo) error
+	Unlock() error
+}
+
+type LockInfo struct {
+	Owner    string    `json:"owner"`
+	Host     string    `json:"host"`
+	PID      int       `json:"pid"`
+	Acquired time.Time `json:"acquired"`
+}
+
+func NewLockInfo(owner string) LockInfo {
+	host, _ := os.Hostname()
+	return LockInfo{
+		Owner:    owner,
+		Host:     host,
+		PID:      os.Getpid(),
+		Acquired: time.Now().UTC(),
+	}
+}
+
+func (l LockInfo) Marshal() []byte {
+	// marshal of the struct with primitive fields never returns error
+	data, _ := json.Marshal(l)
+	return data
+}
+
+func (l LockInfo) Equal(other LockInfo) bool {
+	return l.Owner == other.Owner && l.Host == other.Host && l.PID == other.PID && l.Acquired.Equal(other.Acquired)
+}
+
+func (l LockInfo) String() string {
+	return fmt.Sprintf("owner=%s host=%s pid=%d acquired=%s", l.Owner, l.Host, l.PID, l.Acquired.Format(time.RFC3339))
+}
+
+func ParseLockInfo(data []byte) (LockInfo, error) {
+	var info LockInfo
+	err := json.Unmarshal(data, &info)
+	return info, err
+}
+
+type ErrLocked struct {
+	Holder LockInfo
+}
+
+func (e *ErrLocked) Error() string {
+	return fmt.Sprintf("cache is locked by another dhctl process (%s). "+
+		"If you are sure that nobody uses the cache, remove the %q key from the cache storage", e.Holder, LockKey)
+}
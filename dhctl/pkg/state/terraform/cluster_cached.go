@@ -45,6 +45,7 @@ func (s *KubeTerraStateLoader) PopulateMetaConfig() (*config.MetaConfig, error)
 	var err error
 
 	confirmation := input.NewConfirmation().
+		WithAction(input.ActionUseCachedState).
 		WithMessage("Do you want to continue with Cluster configuration from local cache?").
 		WithYesByDefault()
 
@@ -102,6 +103,7 @@ func (s *KubeTerraStateLoader) getNodesState() (map[string]converge.NodeGroupTer
 	var nodesState map[string]converge.NodeGroupTerraformState
 
 	confirmation := input.NewConfirmation().
+		WithAction(input.ActionUseCachedState).
 		WithMessage("Do you want to continue with Nodes state from local cache?").
 		WithYesByDefault()
 
@@ -137,6 +139,7 @@ func (s *KubeTerraStateLoader) getClusterState() ([]byte, error) {
 	var clusterState []byte
 
 	confirmation := input.NewConfirmation().
+		WithAction(input.ActionUseCachedState).
 		WithMessage("Do you want to continue with Cluster state from local cache?").
 		WithYesByDefault()
 
@@ -18,15 +18,24 @@ import (
 	"fmt"
 	"sync"
 
+	"golang.org/x/crypto/ssh"
+
 	"github.com/deckhouse/deckhouse/dhctl/pkg/app"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/system/ssh/frontend"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/system/ssh/gossh"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/system/ssh/session"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/terminal"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/util/tomb"
 )
 
+const knownHostsFile = ".ssh_known_hosts"
+
 var (
 	agentInstanceSingleton sync.Once
 	agentInstance          *frontend.Agent
+
+	signersSingleton sync.Once
+	signers          []ssh.Signer
 )
 
 func initAgentInstance() (*frontend.Agent, error) {
@@ -53,9 +62,27 @@ func initAgentInstance() (*frontend.Agent, error) {
 	return agentInstance, err
 }
 
+// initSigners loads private keys once to ask passphrases only once.
+func initSigners() ([]ssh.Signer, error) {
+	var err error
+
+	signersSingleton.Do(func() {
+		signers, err = gossh.LoadSigners(app.SSHPrivateKeys, terminal.AskSSHKeyPassphrase)
+	})
+
+	if err == nil && signers == nil {
+		return nil, fmt.Errorf("private keys were not loaded")
+	}
+
+	return signers, err
+}
+
 type Client struct {
 	Settings *session.Session
 	Agent    *frontend.Agent
+
+	// native is set when the gossh transport is used
+	native *gossh.Client
 }
 
 func (s *Client) Start() (*Client, error) {
@@ -63,6 +90,10 @@ func (s *Client) Start() (*Client, error) {
 		return nil, fmt.Errorf("possible bug in ssh client: session should be created before start")
 	}
 
+	if app.SSHTransport == app.SSHTransportGo {
+		return s.startNative()
+	}
+
 	a, err := initAgentInstance()
 	if err != nil {
 		return nil, err
@@ -73,40 +104,75 @@ func (s *Client) Start() (*Client, error) {
 	return s, nil
 }
 
+func (s *Client) startNative() (*Client, error) {
+	keys, err := initSigners()
+	if err != nil {
+		return nil, err
+	}
+
+	hostKeyCallback, err := gossh.AcceptNewHostKeyCallback(knownHostsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	s.native = gossh.NewClient(s.Settings, keys).WithHostKeyCallback(hostKeyCallback)
+	tomb.RegisterOnShutdown("Close ssh connection", s.native.Stop)
+
+	return s, nil
+}
+
 // Easy access to frontends
 
 // Tunnel is used to open local (L) and remote (R) tunnels
-func (s *Client) Tunnel(ttype, address string) *frontend.Tunnel {
+func (s *Client) Tunnel(ttype, address string) Tunnel {
+	if s.native != nil {
+		return s.native.Tunnel(ttype, address)
+	}
 	return frontend.NewTunnel(s.Settings, ttype, address)
 }
 
 // Command is used to run commands on remote server
-func (s *Client) Command(name string, arg ...string) *frontend.Command {
-	return frontend.NewCommand(s.Settings, name, arg...)
+func (s *Client) Command(name string, arg ...string) Command {
+	if s.native != nil {
+		return &nativeCommand{cmd: s.native.Command(name, arg...)}
+	}
+	return &cliCommand{cmd: frontend.NewCommand(s.Settings, name, arg...)}
 }
 
 // KubeProxy is used to start kubectl proxy and create a tunnel from local port to proxy port
-func (s *Client) KubeProxy() *frontend.KubeProxy {
+func (s *Client) KubeProxy() KubeProxy {
+	if s.native != nil {
+		return s.native.KubeProxy()
+	}
 	return frontend.NewKubeProxy(s.Settings)
 }
 
 // File is used to upload and download files and directories
-func (s *Client) File() *frontend.File {
+func (s *Client) File() File {
+	if s.native != nil {
+		return s.native.File()
+	}
 	return frontend.NewFile(s.Settings)
 }
 
 // UploadScript is used to upload script and execute it on remote server
-func (s *Client) UploadScript(scriptPath string, args ...string) *frontend.UploadScript {
-	return frontend.NewUploadScript(s.Settings, scriptPath, args...)
+func (s *Client) UploadScript(scriptPath string, args ...string) Script {
+	if s.native != nil {
+		return &nativeScript{script: s.native.UploadScript(scriptPath, args...)}
+	}
+	return &cliScript{script: frontend.NewUploadScript(s.Settings, scriptPath, args...)}
 }
 
-// UploadScript is used to upload script and execute it on remote server
-func (s *Client) Check() *frontend.Check {
-	return frontend.NewCheck(s.Settings)
+// Check is used to wait for ssh availability of the host
+func (s *Client) Check() Check {
+	if s.native != nil {
+		return &frontendCheck{check: s.native.Check()}
+	}
+	return &frontendCheck{check: frontend.NewCheck(s.Settings)}
 }
 
 // Stop stop client
 func (s *Client) Stop() {
 	// do nothing
-	// stop agent on shutdown because agent is singleton
+	// stop agent and connections on shutdown because agent is singleton
 }
@@ -112,21 +112,26 @@ func (u *UploadScript) Execute() (stdout []byte, err error) {
 }
 
 func (u *UploadScript) pathWithEnv(path string) string {
-	if len(u.envs) == 0 {
+	return PathWithEnv(path, u.envs)
+}
+
+// PathWithEnv prepends the script path with escaped environment variables.
+func PathWithEnv(path string, envs map[string]string) string {
+	if len(envs) == 0 {
 		return path
 	}
 
-	arrayToJoin := make([]string, 0, len(u.envs)*2)
+	arrayToJoin := make([]string, 0, len(envs)*2)
 
-	for k, v := range u.envs {
+	for k, v := range envs {
 		vEscaped := shellescape.Quote(v)
 		kvStr := fmt.Sprintf("%s=%s", k, vEscaped)
 		arrayToJoin = append(arrayToJoin, kvStr)
 	}
 
-	envs := strings.Join(arrayToJoin, " ")
+	envsStr := strings.Join(arrayToJoin, " ")
 
-	return fmt.Sprintf("%s %s", envs, path)
+	return fmt.Sprintf("%s %s", envsStr, path)
 }
 
 func (u *UploadScript) ExecuteBundle(parentDir, bundleDir string) (stdout []byte, err error) {
@@ -159,7 +164,10 @@ func (u *UploadScript) ExecuteBundle(parentDir, bundleDir string) (stdout []byte
 
 	processLogger := log.GetProcessLogger()
 
-	handler := bundleOutputHandler(bundleCmd, processLogger, &lastStep, &failsCounter)
+	handler := BundleOutputHandler(func() {
+		// Force kill bashible
+		_ = bundleCmd.cmd.Process.Kill()
+	}, processLogger, &lastStep, &failsCounter)
 	err = bundleCmd.WithStdoutHandler(handler).CaptureStdout(nil).Run()
 	if err != nil {
 		if lastStep != "" {
@@ -174,7 +182,8 @@ func (u *UploadScript) ExecuteBundle(parentDir, bundleDir string) (stdout []byte
 
 var stepHeaderRegexp = regexp.MustCompile("^=== Step: /var/lib/bashible/bundle_steps/(.*)$")
 
-func bundleOutputHandler(cmd *Command, processLogger log.ProcessLogger, lastStep *string, failsCounter *int) func(string) {
+// BundleOutputHandler logs bashible steps as processes. kill is called when the step is failed too many times.
+func BundleOutputHandler(kill func(), processLogger log.ProcessLogger, lastStep *string, failsCounter *int) func(string) {
 	return func(l string) {
 		if l == "===" {
 			return
@@ -186,9 +195,8 @@ func bundleOutputHandler(cmd *Command, processLogger log.ProcessLogger, lastStep
 			if *lastStep == stepName {
 				*failsCounter++
 				if *failsCounter > 10 {
-					if cmd != nil {
-						// Force kill bashible
-						_ = cmd.cmd.Process.Kill()
+					if kill != nil {
+						kill()
 					}
 					return
 				}
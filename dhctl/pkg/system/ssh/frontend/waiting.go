@@ -27,10 +27,19 @@ import (
 type Check struct {
 	Session *session.Session
 	delay   time.Duration
+
+	runCommand func(command string) ([]byte, error)
 }
 
 func NewCheck(sess *session.Session) *Check {
-	return &Check{Session: sess}
+	return NewCheckWithCommand(sess, func(command string) ([]byte, error) {
+		return NewCommand(sess, command).Cmd().CombinedOutput()
+	})
+}
+
+// NewCheckWithCommand returns the check which runs commands on the host with runCommand.
+func NewCheckWithCommand(sess *session.Session, runCommand func(command string) ([]byte, error)) *Check {
+	return &Check{Session: sess, runCommand: runCommand}
 }
 
 func (c *Check) WithDelaySeconds(seconds int) *Check {
@@ -58,8 +67,7 @@ func (c *Check) AwaitAvailability() error {
 }
 
 func (c *Check) ExpectAvailable() ([]byte, error) {
-	cmd := NewCommand(c.Session, "echo SUCCESS").Cmd()
-	output, err := cmd.CombinedOutput()
+	output, err := c.runCommand("echo SUCCESS")
 	if err != nil {
 		return output, err
 	}
@@ -0,0 +1,228 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gossh is the ssh transport built on golang.org/x/crypto/ssh.
+// It does not require ssh, scp and ssh-agent binaries on the local host.
+package gossh
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/system/ssh/frontend"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/system/ssh/session"
+)
+
+const (
+	defaultPort      = "22"
+	connectTimeout   = 5 * time.Second
+	keepaliveTimeout = 5 * time.Second
+)
+
+// Client keeps the connection to the current host of the session.
+// The connection is reestablished if the session host is changed or the connection is broken.
+// Replaced connections are not closed until Stop, because commands and tunnels may still use them.
+type Client struct {
+	Settings *session.Session
+
+	signers         []ssh.Signer
+	hostKeyCallback ssh.HostKeyCallback
+
+	mu      sync.Mutex
+	host    string
+	conn    *ssh.Client
+	bastion *ssh.Client
+	retired []*ssh.Client
+}
+
+func NewClient(sess *session.Session, signers []ssh.Signer) *Client {
+	return &Client{
+		Settings:        sess,
+		signers:         signers,
+		hostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	}
+}
+
+func (c *Client) WithHostKeyCallback(callback ssh.HostKeyCallback) *Client {
+	c.hostKeyCallback = callback
+	return c
+}
+
+// Connection returns the connection to the current host of the session.
+func (c *Client) Connection() (*ssh.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	host := c.Settings.Host()
+	if host == "" {
+		return nil, fmt.Errorf("ssh: empty host for connection")
+	}
+
+	if c.conn != nil {
+		if c.host == host && c.isAlive() {
+			return c.conn, nil
+		}
+		log.DebugF("ssh: reconnect to %s\n", host)
+		c.retire()
+	}
+
+	if err := c.connect(host); err != nil {
+		c.close()
+		return nil, err
+	}
+
+	return c.conn, nil
+}
+
+// isAlive sends the keepalive request, the connection is considered broken if there is no reply in time.
+func (c *Client) isAlive() bool {
+	conn := c.conn
+	replied := make(chan error, 1)
+	go func() {
+		_, _, err := conn.SendRequest("keepalive@openssh.com", true, nil)
+		replied <- err
+	}()
+
+	select {
+	case err := <-replied:
+		return err == nil
+	case <-time.After(keepaliveTimeout):
+		return false
+	}
+}
+
+func (c *Client) connect(host string) error {
+	targetAddr := net.JoinHostPort(host, portOrDefault(c.Settings.Port))
+	targetConfig := c.clientConfig(c.Settings.User)
+
+	if c.Settings.BastionHost == "" {
+		log.DebugF("ssh: connect to %s\n", targetAddr)
+		conn, err := ssh.Dial("tcp", targetAddr, targetConfig)
+		if err != nil {
+			return fmt.Errorf("ssh: connect to %s: %w", targetAddr, err)
+		}
+		c.host = host
+		c.conn = conn
+		return nil
+	}
+
+	bastionAddr := net.JoinHostPort(c.Settings.BastionHost, portOrDefault(c.Settings.BastionPort))
+	log.DebugF("ssh: connect to bastion %s\n", bastionAddr)
+	bastion, err := ssh.Dial("tcp", bastionAddr, c.clientConfig(c.Settings.BastionUser))
+	if err != nil {
+		return fmt.Errorf("ssh: connect to bastion %s: %w", bastionAddr, err)
+	}
+	c.bastion = bastion
+
+	log.DebugF("ssh: connect to %s via bastion %s\n", targetAddr, bastionAddr)
+	netConn, err := bastion.Dial("tcp", targetAddr)
+	if err != nil {
+		return fmt.Errorf("ssh: connect to %s via bastion: %w", targetAddr, err)
+	}
+
+	clientConn, chans, reqs, err := ssh.NewClientConn(netConn, targetAddr, targetConfig)
+	if err != nil {
+		_ = netConn.Close()
+		return fmt.Errorf("ssh: handshake with %s via bastion: %w", targetAddr, err)
+	}
+
+	c.host = host
+	c.conn = ssh.NewClient(clientConn, chans, reqs)
+	return nil
+}
+
+func (c *Client) clientConfig(user string) *ssh.ClientConfig {
+	return &ssh.ClientConfig{
+		User:            user,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(c.signers...)},
+		HostKeyCallback: c.hostKeyCallback,
+		Timeout:         connectTimeout,
+	}
+}
+
+// retire keeps the current connections to close them on Stop.
+func (c *Client) retire() {
+	if c.conn != nil {
+		c.retired = append(c.retired, c.conn)
+		c.conn = nil
+	}
+	if c.bastion != nil {
+		c.retired = append(c.retired, c.bastion)
+		c.bastion = nil
+	}
+	c.host = ""
+}
+
+func (c *Client) close() {
+	if c.conn != nil {
+		_ = c.conn.Close()
+		c.conn = nil
+	}
+	if c.bastion != nil {
+		_ = c.bastion.Close()
+		c.bastion = nil
+	}
+	c.host = ""
+}
+
+// Stop closes all connections to hosts and to the bastion.
+func (c *Client) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.close()
+	for _, conn := range c.retired {
+		_ = conn.Close()
+	}
+	c.retired = nil
+}
+
+// Easy access to frontends
+
+func (c *Client) Command(name string, arg ...string) *Command {
+	return NewCommand(c, name, arg...)
+}
+
+func (c *Client) File() *File {
+	return NewFile(c)
+}
+
+func (c *Client) Tunnel(ttype, address string) *Tunnel {
+	return NewTunnel(c, ttype, address)
+}
+
+func (c *Client) KubeProxy() *KubeProxy {
+	return NewKubeProxy(c)
+}
+
+func (c *Client) UploadScript(scriptPath string, args ...string) *UploadScript {
+	return NewUploadScript(c, scriptPath, args...)
+}
+
+func (c *Client) Check() *frontend.Check {
+	return frontend.NewCheckWithCommand(c.Settings, func(command string) ([]byte, error) {
+		return NewCommand(c, command).CombinedOutput()
+	})
+}
+
+func portOrDefault(port string) string {
+	if port == "" {
+		return defaultPort
+	}
+	return port
+}
@@ -0,0 +1,323 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gossh
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/system/ssh/session"
+)
+
+func TestMain(m *testing.M) {
+	log.InitLogger("simple")
+	os.Exit(m.Run())
+}
+
+func tmpDir(t *testing.T) string {
+	dir, err := ioutil.TempDir(os.TempDir(), "dhctl-test-gossh-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return dir
+}
+
+func newTestClient(t *testing.T) (*Client, *testServer, string) {
+	signer := newTestSigner(t)
+	workDir := tmpDir(t)
+	server := newTestServer(t, signer.PublicKey(), workDir)
+
+	sess := session.NewSession(session.Input{
+		User:           "user",
+		Port:           server.Port(),
+		AvailableHosts: []string{server.Host()},
+	})
+
+	client := NewClient(sess, []ssh.Signer{signer})
+	t.Cleanup(client.Stop)
+
+	return client, server, workDir
+}
+
+func TestCommand(t *testing.T) {
+	client, server, _ := newTestClient(t)
+
+	t.Run("Output and stdout handler", func(t *testing.T) {
+		lines := make([]string, 0)
+		cmd := client.Command("echo", "first", "&&", "echo", "second").
+			WithStdoutHandler(func(l string) { lines = append(lines, l) })
+
+		require.NoError(t, cmd.Run())
+		require.Equal(t, []string{"first", "second"}, lines)
+		require.Equal(t, "first\nsecond\n", string(cmd.StdoutBytes()))
+		require.Contains(t, server.Commands(), "echo first && echo second")
+	})
+
+	t.Run("Stderr and exit status", func(t *testing.T) {
+		stdout, stderr, err := client.Command("echo out; echo err >&2; exit 3").Output()
+		require.Error(t, err)
+
+		var exitErr *ssh.ExitError
+		require.True(t, errors.As(err, &exitErr))
+		require.Equal(t, 3, exitErr.ExitStatus())
+		require.Equal(t, "out\n", string(stdout))
+		require.Equal(t, "err\n", string(stderr))
+	})
+
+	t.Run("Timeout kills the command", func(t *testing.T) {
+		start := time.Now()
+		err := client.Command("sleep", "10").WithTimeout(200 * time.Millisecond).Run()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "timed out")
+		require.Less(t, int64(time.Since(start)), int64(5*time.Second))
+	})
+
+	t.Run("Check", func(t *testing.T) {
+		output, err := client.Check().ExpectAvailable()
+		require.NoError(t, err)
+		require.Empty(t, output)
+	})
+}
+
+func TestWaitSudo(t *testing.T) {
+	t.Run("Password is sent on prompt, output starts after success marker", func(t *testing.T) {
+		started := false
+		cmd := NewCommand(nil, "id").OnCommandStart(func() { started = true })
+
+		var stdin bytes.Buffer
+		r := cmd.waitSudo(strings.NewReader("SudoPassword\r\nSUDO-SUCCESS\r\nuid=0\r\n"), &stdin)
+		rest, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+
+		require.Equal(t, "\n", stdin.String())
+		require.True(t, started)
+		require.Equal(t, "uid=0\r\n", string(rest))
+	})
+
+	t.Run("Second prompt means bad password", func(t *testing.T) {
+		cmd := NewCommand(nil, "id")
+
+		var stdin bytes.Buffer
+		r := cmd.waitSudo(strings.NewReader("SudoPassword\r\nSudoPassword"), &stdin)
+		rest, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+
+		require.Empty(t, rest)
+		require.Error(t, cmd.sudoErr)
+	})
+}
+
+func TestFile(t *testing.T) {
+	client, _, workDir := newTestClient(t)
+	localDir := tmpDir(t)
+
+	t.Run("Upload file into existing directory and download it", func(t *testing.T) {
+		src := filepath.Join(localDir, "script.sh")
+		require.NoError(t, ioutil.WriteFile(src, []byte("#!/bin/sh\necho script\n"), 0o755))
+
+		require.NoError(t, client.File().Upload(src, workDir))
+
+		info, err := os.Stat(filepath.Join(workDir, "script.sh"))
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o755), info.Mode().Perm()&0o755)
+
+		data, err := client.File().DownloadBytes(filepath.Join(workDir, "script.sh"))
+		require.NoError(t, err)
+		require.Equal(t, "#!/bin/sh\necho script\n", string(data))
+	})
+
+	t.Run("Upload bytes to the file path", func(t *testing.T) {
+		dst := filepath.Join(workDir, "config.yaml")
+		require.NoError(t, client.File().UploadBytes([]byte("a: b"), dst))
+
+		data, err := ioutil.ReadFile(dst)
+		require.NoError(t, err)
+		require.Equal(t, "a: b", string(data))
+	})
+
+	t.Run("Upload and download directories", func(t *testing.T) {
+		src := filepath.Join(localDir, "bundle")
+		require.NoError(t, os.MkdirAll(filepath.Join(src, "steps"), 0o755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(src, "steps", "01.sh"), []byte("step"), 0o644))
+
+		remote := filepath.Join(workDir, "remote-bundle")
+		require.NoError(t, client.File().Upload(src, remote))
+
+		data, err := ioutil.ReadFile(filepath.Join(remote, "steps", "01.sh"))
+		require.NoError(t, err)
+		require.Equal(t, "step", string(data))
+
+		downloaded := filepath.Join(localDir, "downloaded")
+		require.NoError(t, client.File().Download(remote, downloaded))
+
+		data, err = ioutil.ReadFile(filepath.Join(downloaded, "steps", "01.sh"))
+		require.NoError(t, err)
+		require.Equal(t, "step", string(data))
+	})
+
+	t.Run("Download absent file", func(t *testing.T) {
+		_, err := client.File().DownloadBytes(filepath.Join(workDir, "absent"))
+		require.Error(t, err)
+	})
+}
+
+func TestUploadScript(t *testing.T) {
+	client, _, _ := newTestClient(t)
+
+	src := filepath.Join(tmpDir(t), "detect.sh")
+	require.NoError(t, ioutil.WriteFile(src, []byte("#!/bin/sh\necho \"$1 $NAME\"\n"), 0o755))
+
+	lines := make([]string, 0)
+	stdout, err := client.UploadScript(src, "hello").
+		WithEnvs(map[string]string{"NAME": "world with spaces"}).
+		WithStdoutHandler(func(l string) { lines = append(lines, l) }).
+		Execute()
+
+	require.NoError(t, err)
+	require.Equal(t, "hello world with spaces\n", string(stdout))
+	require.Equal(t, []string{"hello world with spaces"}, lines)
+}
+
+func TestTunnel(t *testing.T) {
+	client, server, _ := newTestClient(t)
+
+	// echo server behind ssh host
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer echo.Close()
+	go func() {
+		for {
+			conn, err := echo.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 5)
+				n, _ := conn.Read(buf)
+				_, _ = conn.Write(append([]byte("echo:"), buf[:n]...))
+			}()
+		}
+	}()
+
+	echoPort := echo.Addr().(*net.TCPAddr).Port
+	tun := client.Tunnel("L", "127.0.0.1:0:127.0.0.1:"+strconv.Itoa(echoPort))
+	require.NoError(t, tun.Up())
+	defer tun.Stop()
+
+	conn, err := net.Dial("tcp", tun.LocalAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("hello"))
+	require.NoError(t, err)
+
+	resp, err := ioutil.ReadAll(conn)
+	require.NoError(t, err)
+	require.Equal(t, "echo:hello", string(resp))
+	require.Contains(t, server.Dials(), "127.0.0.1:"+strconv.Itoa(echoPort))
+
+	errCh := make(chan error, 1)
+	go tun.HealthMonitor(errCh)
+	tun.Stop()
+	select {
+	case err := <-errCh:
+		t.Fatalf("unexpected tunnel error after stop: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	signer := newTestSigner(t)
+	server := newTestServer(t, signer.PublicKey(), tmpDir(t))
+
+	sess := session.NewSession(session.Input{
+		User:           "user",
+		Port:           server.Port(),
+		AvailableHosts: []string{"127.0.0.1", "localhost"},
+	})
+
+	client := NewClient(sess, []ssh.Signer{signer})
+	defer client.Stop()
+
+	first, err := client.Connection()
+	require.NoError(t, err)
+
+	sess.ChoiceNewHost()
+	second, err := client.Connection()
+	require.NoError(t, err)
+	require.NotSame(t, first, second)
+
+	// the connection to the previous host may be used by running commands and tunnels
+	_, _, err = first.SendRequest("keepalive@openssh.com", true, nil)
+	require.NoError(t, err)
+
+	client.Stop()
+	_, _, err = first.SendRequest("keepalive@openssh.com", true, nil)
+	require.Error(t, err)
+}
+
+func TestBastion(t *testing.T) {
+	signer := newTestSigner(t)
+	target := newTestServer(t, signer.PublicKey(), tmpDir(t))
+	bastion := newTestServer(t, signer.PublicKey(), tmpDir(t))
+
+	sess := session.NewSession(session.Input{
+		User:           "user",
+		Port:           target.Port(),
+		BastionHost:    bastion.Host(),
+		BastionPort:    bastion.Port(),
+		BastionUser:    "jumper",
+		AvailableHosts: []string{target.Host()},
+	})
+
+	client := NewClient(sess, []ssh.Signer{signer})
+	defer client.Stop()
+
+	output, err := client.Command("echo", "via-bastion").CombinedOutput()
+	require.NoError(t, err)
+	require.Equal(t, "via-bastion\n", string(output))
+
+	require.Equal(t, []string{"127.0.0.1:" + target.Port()}, bastion.Dials())
+	require.Empty(t, bastion.Commands())
+	require.Equal(t, []string{"echo via-bastion"}, target.Commands())
+}
+
+func TestAcceptNewHostKeyCallback(t *testing.T) {
+	knownHosts := filepath.Join(tmpDir(t), "known_hosts")
+	callback, err := AcceptNewHostKeyCallback(knownHosts)
+	require.NoError(t, err)
+
+	addr := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 22}
+	key := newTestSigner(t).PublicKey()
+
+	// unknown host is added
+	require.NoError(t, callback("127.0.0.1:22", addr, key))
+	// known host with the same key is accepted
+	require.NoError(t, callback("127.0.0.1:22", addr, key))
+	// changed key is rejected
+	require.Error(t, callback("127.0.0.1:22", addr, newTestSigner(t).PublicKey()))
+}
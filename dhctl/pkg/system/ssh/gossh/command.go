@@ -0,0 +1,393 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gossh
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/app"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/system/process"
+)
+
+const (
+	sudoPrompt  = "SudoPassword"
+	sudoSuccess = "SUDO-SUCCESS"
+)
+
+type Command struct {
+	client *Client
+
+	Name string
+	Args []string
+
+	sudo    bool
+	timeout time.Duration
+
+	stdoutHandler  func(string)
+	stderrHandler  func(string)
+	waitHandler    func(error)
+	onCommandStart func()
+
+	stdout   syncBuffer
+	stderr   syncBuffer
+	combined syncBuffer
+
+	session *ssh.Session
+	doneCh  chan struct{}
+
+	lock     sync.Mutex
+	started  bool
+	stopped  bool
+	timedOut bool
+	sudoErr  error
+	waitErr  error
+}
+
+func NewCommand(client *Client, name string, arg ...string) *Command {
+	return &Command{
+		client: client,
+		Name:   name,
+		Args:   arg,
+	}
+}
+
+// Sudo runs the command with sudo. The password from --ask-become-pass is sent on the sudo prompt.
+func (c *Command) Sudo() *Command {
+	c.sudo = true
+	return c
+}
+
+// Cmd runs the command as is. It exists for compatibility with the frontend.Command.
+func (c *Command) Cmd() *Command {
+	c.sudo = false
+	return c
+}
+
+// WithSSHArgs is ignored: the options of the ssh binary are not applicable to this transport.
+func (c *Command) WithSSHArgs(args ...string) *Command {
+	if len(args) > 0 {
+		log.DebugF("ssh: ignore ssh args %v for the command '%s'\n", args, c.Name)
+	}
+	return c
+}
+
+func (c *Command) WithTimeout(timeout time.Duration) *Command {
+	c.timeout = timeout
+	return c
+}
+
+func (c *Command) WithStdoutHandler(handler func(string)) *Command {
+	c.stdoutHandler = handler
+	return c
+}
+
+func (c *Command) WithStderrHandler(handler func(string)) *Command {
+	c.stderrHandler = handler
+	return c
+}
+
+func (c *Command) WithWaitHandler(handler func(error)) *Command {
+	c.waitHandler = handler
+	return c
+}
+
+func (c *Command) OnCommandStart(fn func()) *Command {
+	c.onCommandStart = fn
+	return c
+}
+
+func (c *Command) cmdLine() string {
+	cmdLine := c.Name
+	if len(c.Args) > 0 {
+		cmdLine += " " + strings.Join(c.Args, " ")
+	}
+
+	if c.sudo {
+		return fmt.Sprintf(`sudo -p %s -H -S -i bash -c 'echo %s && %s'`, sudoPrompt, sudoSuccess, cmdLine)
+	}
+
+	return cmdLine
+}
+
+func (c *Command) Start() error {
+	conn, err := c.client.Connection()
+	if err != nil {
+		return err
+	}
+
+	sess, err := conn.NewSession()
+	if err != nil {
+		return fmt.Errorf("open ssh session: %w", err)
+	}
+
+	c.lock.Lock()
+	c.session = sess
+	c.doneCh = make(chan struct{})
+	c.lock.Unlock()
+
+	stdout, err := sess.StdoutPipe()
+	if err != nil {
+		_ = sess.Close()
+		return err
+	}
+	stderr, err := sess.StderrPipe()
+	if err != nil {
+		_ = sess.Close()
+		return err
+	}
+
+	var stdin io.Writer
+	if c.sudo {
+		stdin, err = sess.StdinPipe()
+		if err != nil {
+			_ = sess.Close()
+			return err
+		}
+		// allocate tty to kill remote process when the connection is closed
+		err = sess.RequestPty("xterm", 40, 80, ssh.TerminalModes{ssh.ECHO: 0})
+		if err != nil {
+			_ = sess.Close()
+			return fmt.Errorf("request pty: %w", err)
+		}
+	}
+
+	var readers sync.WaitGroup
+	readers.Add(2)
+	go func() {
+		defer readers.Done()
+		if c.sudo {
+			stdout = c.waitSudo(stdout, stdin)
+		}
+		c.consume(stdout, &c.stdout, c.stdoutHandler)
+	}()
+	go func() {
+		defer readers.Done()
+		c.consume(stderr, &c.stderr, c.stderrHandler)
+	}()
+
+	log.DebugF("ssh: start '%s' on %s\n", c.Name, c.client.Settings.Host())
+	if err := sess.Start(c.cmdLine()); err != nil {
+		_ = sess.Close()
+		return fmt.Errorf("start command '%s': %w", c.Name, err)
+	}
+
+	c.lock.Lock()
+	c.started = true
+	c.lock.Unlock()
+
+	if !c.sudo && c.onCommandStart != nil {
+		c.onCommandStart()
+	}
+
+	go func() {
+		err := sess.Wait()
+		readers.Wait()
+		c.finish(err)
+	}()
+
+	if c.timeout > 0 {
+		go func() {
+			select {
+			case <-time.After(c.timeout):
+				c.lock.Lock()
+				c.timedOut = true
+				c.lock.Unlock()
+				c.kill()
+			case <-c.doneCh:
+			}
+		}()
+	}
+
+	process.DefaultSession.RegisterStoppable(c)
+
+	return nil
+}
+
+func (c *Command) finish(err error) {
+	c.lock.Lock()
+	switch {
+	case c.sudoErr != nil:
+		err = c.sudoErr
+	case c.timedOut:
+		err = fmt.Errorf("command '%s' timed out after %s", c.Name, c.timeout)
+	case c.stopped:
+		err = nil
+	}
+	c.waitErr = err
+	stopped := c.stopped
+	c.lock.Unlock()
+
+	_ = c.session.Close()
+	close(c.doneCh)
+
+	// ignore the result if Stop() was called
+	if c.waitHandler != nil && !stopped {
+		c.waitHandler(err)
+	}
+}
+
+// waitSudo sends the password on the sudo prompt and returns stdout after the success marker.
+func (c *Command) waitSudo(r io.Reader, stdin io.Writer) io.Reader {
+	pending := make([]byte, 0)
+	buf := make([]byte, 4096)
+	passSent := false
+
+	for {
+		n, err := r.Read(buf)
+		pending = append(pending, buf[:n]...)
+
+		if idx := bytes.Index(pending, []byte(sudoPrompt)); idx >= 0 {
+			if passSent {
+				c.lock.Lock()
+				c.sudoErr = fmt.Errorf("bad sudo password")
+				c.lock.Unlock()
+				c.kill()
+				return bytes.NewReader(nil)
+			}
+			log.DebugLn("Send become pass to cmd")
+			_, _ = stdin.Write([]byte(app.BecomePass + "\n"))
+			passSent = true
+			pending = pending[idx+len(sudoPrompt):]
+		}
+
+		if idx := bytes.Index(pending, []byte(sudoSuccess)); idx >= 0 {
+			log.DebugLn("Got SUCCESS")
+			rest := bytes.TrimLeft(pending[idx+len(sudoSuccess):], "\r\n")
+			if c.onCommandStart != nil {
+				c.onCommandStart()
+			}
+			return io.MultiReader(bytes.NewReader(rest), r)
+		}
+
+		if err != nil {
+			return bytes.NewReader(pending)
+		}
+	}
+}
+
+func (c *Command) consume(r io.Reader, buf *syncBuffer, handler func(string)) {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			buf.WriteString(line)
+			c.combined.WriteString(line)
+
+			trimmed := strings.TrimRight(line, "\r\n")
+			if handler != nil {
+				handler(trimmed)
+			}
+			if trimmed != "" {
+				log.DebugF("%s: %s\n", c.Name, trimmed)
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (c *Command) kill() {
+	c.lock.Lock()
+	sess := c.session
+	c.lock.Unlock()
+
+	if sess == nil {
+		return
+	}
+	_ = sess.Signal(ssh.SIGKILL)
+	_ = sess.Close()
+}
+
+// Stop kills the command and waits for its completion.
+func (c *Command) Stop() {
+	c.lock.Lock()
+	if !c.started || c.stopped {
+		c.lock.Unlock()
+		return
+	}
+	c.stopped = true
+	c.lock.Unlock()
+
+	log.DebugF("Stop '%s'\n", c.Name)
+	c.kill()
+	<-c.doneCh
+}
+
+// Run executes the command and blocks until it is finished or stopped.
+func (c *Command) Run() error {
+	if err := c.Start(); err != nil {
+		return err
+	}
+
+	<-c.doneCh
+	return c.WaitError()
+}
+
+func (c *Command) WaitError() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.waitErr
+}
+
+func (c *Command) Output() ([]byte, []byte, error) {
+	err := c.Run()
+	if err != nil {
+		return c.StdoutBytes(), c.StderrBytes(), fmt.Errorf("execute command '%s': %w", c.Name, err)
+	}
+	return c.StdoutBytes(), c.StderrBytes(), nil
+}
+
+func (c *Command) CombinedOutput() ([]byte, error) {
+	err := c.Run()
+	if err != nil {
+		return c.combined.Bytes(), fmt.Errorf("execute command '%s': %w", c.Name, err)
+	}
+	return c.combined.Bytes(), nil
+}
+
+func (c *Command) StdoutBytes() []byte {
+	return c.stdout.Bytes()
+}
+
+func (c *Command) StderrBytes() []byte {
+	return c.stderr.Bytes()
+}
+
+type syncBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *syncBuffer) WriteString(s string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.buf.WriteString(s)
+}
+
+func (b *syncBuffer) Bytes() []byte {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return append([]byte(nil), b.buf.Bytes()...)
+}
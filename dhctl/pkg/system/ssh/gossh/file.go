@@ -0,0 +1,257 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gossh
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/alessio/shellescape"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+)
+
+// File copies files and directories with tar streams over ssh sessions.
+// It has the same semantics as scp: if the destination is an existing directory,
+// the source is copied into it, otherwise the source is copied as the destination.
+type File struct {
+	client *Client
+}
+
+func NewFile(client *Client) *File {
+	return &File{client: client}
+}
+
+func (f *File) Upload(srcPath, remotePath string) error {
+	info, err := os.Stat(srcPath)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsDir() && !info.Mode().IsRegular() {
+		return fmt.Errorf("path '%s' is not a directory or file", srcPath)
+	}
+
+	name := filepath.Base(srcPath)
+	pr, pw := io.Pipe()
+	go func() {
+		_ = pw.CloseWithError(writeTar(pw, srcPath, name))
+	}()
+
+	err = f.run(untarCmdLine(remotePath, name), pr, nil)
+	if err != nil {
+		return fmt.Errorf("upload file '%s': %w", srcPath, err)
+	}
+
+	return nil
+}
+
+// UploadBytes uploads data to remote dstPath
+func (f *File) UploadBytes(data []byte, remotePath string) error {
+	const name = "data"
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(data)), Typeflag: tar.TypeReg})
+	if err == nil {
+		_, err = tw.Write(data)
+	}
+	if err == nil {
+		err = tw.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("prepare data for upload: %w", err)
+	}
+
+	err = f.run(untarCmdLine(remotePath, name), &buf, nil)
+	if err != nil {
+		return fmt.Errorf("upload file '%s': %w", remotePath, err)
+	}
+
+	return nil
+}
+
+func (f *File) Download(remotePath, dstPath string) error {
+	remotePath = strings.TrimSuffix(remotePath, "/")
+	name := path.Base(remotePath)
+	cmdLine := fmt.Sprintf("tar -c -f - -C %s %s",
+		shellescape.Quote(path.Dir(remotePath)), shellescape.Quote(name))
+
+	pr, pw := io.Pipe()
+	extractErrCh := make(chan error, 1)
+	go func() {
+		err := readTar(pr, dstPath, name)
+		// drain the stream to let the remote command finish
+		_, _ = io.Copy(ioutil.Discard, pr)
+		extractErrCh <- err
+	}()
+
+	err := f.run(cmdLine, nil, pw)
+	_ = pw.Close()
+	extractErr := <-extractErrCh
+	if err == nil {
+		err = extractErr
+	}
+	if err != nil {
+		return fmt.Errorf("download file '%s': %w", remotePath, err)
+	}
+
+	return nil
+}
+
+// DownloadBytes downloads remote file and returns its content as an array of bytes.
+func (f *File) DownloadBytes(remotePath string) ([]byte, error) {
+	var buf bytes.Buffer
+	err := f.run("cat "+shellescape.Quote(remotePath), nil, &buf)
+	if err != nil {
+		return nil, fmt.Errorf("download file '%s': %w", remotePath, err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (f *File) run(cmdLine string, stdin io.Reader, stdout io.Writer) error {
+	conn, err := f.client.Connection()
+	if err != nil {
+		return err
+	}
+
+	sess, err := conn.NewSession()
+	if err != nil {
+		return fmt.Errorf("open ssh session: %w", err)
+	}
+	defer sess.Close()
+
+	var stderr bytes.Buffer
+	sess.Stdin = stdin
+	sess.Stdout = stdout
+	sess.Stderr = &stderr
+
+	log.DebugF("ssh: run '%s'\n", cmdLine)
+	if err := sess.Run(cmdLine); err != nil {
+		return fmt.Errorf("%w\nstderr: %s", err, stderr.String())
+	}
+
+	return nil
+}
+
+// untarCmdLine extracts the stream to the dst directory if it exists, otherwise renames the name entry to dst.
+func untarCmdLine(dst, name string) string {
+	dst = shellescape.Quote(dst)
+	return fmt.Sprintf(`if [ -d %[1]s ]; then tar -x -f - -C %[1]s; `+
+		`else tmp=$(mktemp -d) && tar -x -f - -C "$tmp" && mv "$tmp"/%[2]s %[1]s && rmdir "$tmp"; fi`,
+		dst, shellescape.Quote(name))
+}
+
+func writeTar(w io.Writer, srcPath, name string) error {
+	tw := tar.NewWriter(w)
+
+	err := filepath.Walk(srcPath, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(srcPath, p)
+		if err != nil {
+			return err
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(filepath.Join(name, rel))
+		if info.IsDir() {
+			header.Name += "/"
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		file, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.Copy(tw, file)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return tw.Close()
+}
+
+func readTar(r io.Reader, dstPath, name string) error {
+	dstIsDir := false
+	if info, err := os.Stat(dstPath); err == nil && info.IsDir() {
+		dstIsDir = true
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		entry := path.Clean(header.Name)
+		if entry != name && !strings.HasPrefix(entry, name+"/") {
+			return fmt.Errorf("unexpected entry '%s' in the archive", header.Name)
+		}
+
+		target := filepath.Join(dstPath, filepath.FromSlash(strings.TrimPrefix(entry, name)))
+		if dstIsDir {
+			target = filepath.Join(dstPath, filepath.FromSlash(entry))
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, os.FileMode(header.Mode)|0o700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode))
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(file, tr)
+			_ = file.Close()
+			if err != nil {
+				return err
+			}
+		default:
+			log.DebugF("ssh: skip '%s' with unsupported type\n", header.Name)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gossh
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+)
+
+// LoadSigners parses private keys. askPassphrase is called for keys protected with a passphrase.
+// Without keys, signers are requested from the running ssh-agent (SSH_AUTH_SOCK).
+func LoadSigners(keyPaths []string, askPassphrase func(path string) ([]byte, error)) ([]ssh.Signer, error) {
+	if len(keyPaths) == 0 {
+		return agentSigners()
+	}
+
+	signers := make([]ssh.Signer, 0, len(keyPaths))
+	for _, keyPath := range keyPaths {
+		log.DebugF("ssh: load key %s\n", keyPath)
+		data, err := ioutil.ReadFile(keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("read private key %s: %w", keyPath, err)
+		}
+
+		signer, err := ssh.ParsePrivateKey(data)
+		var passphraseErr *ssh.PassphraseMissingError
+		if errors.As(err, &passphraseErr) && askPassphrase != nil {
+			passphrase, askErr := askPassphrase(keyPath)
+			if askErr != nil {
+				return nil, askErr
+			}
+			signer, err = ssh.ParsePrivateKeyWithPassphrase(data, passphrase)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse private key %s: %w", keyPath, err)
+		}
+
+		signers = append(signers, signer)
+	}
+
+	return signers, nil
+}
+
+func agentSigners() ([]ssh.Signer, error) {
+	sock := os.Getenv("SSH_AUTH_SOCK")
+	if sock == "" {
+		return nil, fmt.Errorf("no private keys passed and SSH_AUTH_SOCK is not set")
+	}
+
+	conn, err := net.Dial("unix", sock)
+	if err != nil {
+		return nil, fmt.Errorf("connect to ssh-agent: %w", err)
+	}
+
+	signers, err := agent.NewClient(conn).Signers()
+	if err != nil {
+		return nil, fmt.Errorf("get keys from ssh-agent: %w", err)
+	}
+
+	return signers, nil
+}
+
+// AcceptNewHostKeyCallback works like StrictHostKeyChecking=accept-new option of the ssh binary:
+// keys of unknown hosts are added to the known hosts file, changed keys are rejected.
+func AcceptNewHostKeyCallback(knownHostsPath string) (ssh.HostKeyCallback, error) {
+	if err := os.MkdirAll(filepath.Dir(knownHostsPath), 0o700); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(knownHostsPath, os.O_CREATE|os.O_RDONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open known hosts file: %w", err)
+	}
+	_ = f.Close()
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		callback, err := knownhosts.New(knownHostsPath)
+		if err != nil {
+			return fmt.Errorf("read known hosts file: %w", err)
+		}
+
+		err = callback(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if !errors.As(err, &keyErr) || len(keyErr.Want) > 0 {
+			return err
+		}
+
+		log.DebugF("ssh: add %s to known hosts\n", hostname)
+		f, err := os.OpenFile(knownHostsPath, os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("open known hosts file: %w", err)
+		}
+		defer f.Close()
+
+		_, err = f.WriteString(knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key) + "\n")
+		return err
+	}, nil
+}
@@ -0,0 +1,227 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gossh
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/system/ssh/frontend"
+)
+
+var kubeProxyPortRe = regexp.MustCompile(`Starting to serve on .*?:(\d+)`)
+
+type KubeProxy struct {
+	client *Client
+
+	proxy  *Command
+	tunnel *Tunnel
+
+	stop      bool
+	port      string
+	localPort int
+}
+
+func NewKubeProxy(client *Client) *KubeProxy {
+	return &KubeProxy{
+		client:    client,
+		port:      "0",
+		localPort: frontend.DefaultLocalAPIPort,
+	}
+}
+
+func (k *KubeProxy) Start(useLocalPort int) (port string, err error) {
+	success := false
+	defer func() {
+		k.stop = false
+		if !success {
+			k.Stop()
+		}
+	}()
+
+	proxyErrorCh := make(chan error, 1)
+	proxy, port, err := k.runKubeProxy(proxyErrorCh)
+	if err != nil {
+		return "", err
+	}
+
+	k.proxy = proxy
+	k.port = port
+
+	tunnelErrorCh := make(chan error)
+	tun, localPort, err := k.upTunnel(port, useLocalPort, tunnelErrorCh)
+	if err != nil {
+		return "", fmt.Errorf("tunnel up error: max retries reached, last error: %v", err)
+	}
+
+	k.tunnel = tun
+	k.localPort = localPort
+
+	go k.healthMonitor(proxyErrorCh, tunnelErrorCh)
+
+	success = true
+
+	return fmt.Sprintf("%d", k.localPort), nil
+}
+
+func (k *KubeProxy) Stop() {
+	if k == nil || k.proxy == nil || k.stop {
+		return
+	}
+
+	log.DebugF("Stop proxy command\n")
+	k.proxy.Stop()
+	log.DebugF("Proxy command stopped\n")
+
+	if k.tunnel != nil {
+		log.DebugF("Stop tunnel\n")
+		k.tunnel.Stop()
+		log.DebugF("Tunnel stopped\n")
+	}
+	k.stop = true
+}
+
+func (k *KubeProxy) Restart() error {
+	k.Stop()
+	_, err := k.Start(k.localPort)
+	if err == nil {
+		k.stop = false
+	}
+
+	return err
+}
+
+func (k *KubeProxy) tryToRestartFully() {
+	log.DebugF("Try restart kubeproxy fully\n")
+	for {
+		err := k.Restart()
+		if err == nil {
+			return
+		}
+
+		// need warn for human
+		log.WarnF("Proxy was not started %v\n", err)
+		k.client.Settings.ChoiceNewHost()
+		log.DebugF("New host choice %v\n", k.client.Settings.Host())
+	}
+}
+
+func (k *KubeProxy) healthMonitor(proxyErrorCh, tunnelErrorCh chan error) {
+	defer log.DebugF("Kubeproxy health monitor stopped\n")
+	log.DebugF("Kubeproxy health monitor started\n")
+
+	for {
+		select {
+		case err := <-proxyErrorCh:
+			if k.stop {
+				return
+			}
+			log.DebugF("Proxy failed %v\n", err)
+			// tunnel depends on proxy, restart both of them
+			k.tryToRestartFully()
+			return
+
+		case err := <-tunnelErrorCh:
+			log.DebugF("Tunnel failed %v\n Try to up tunnel\n", err)
+			k.tunnel.Stop()
+			k.tunnel, _, err = k.upTunnel(k.port, k.localPort, tunnelErrorCh)
+			if err != nil {
+				k.tryToRestartFully()
+				return
+			}
+
+			log.DebugF("Tunnel re up successfully\n")
+		}
+	}
+}
+
+func (k *KubeProxy) upTunnel(kubeProxyPort string, useLocalPort int, tunnelErrorCh chan error) (*Tunnel, int, error) {
+	rewriteLocalPort := false
+	localPort := useLocalPort
+
+	if useLocalPort < 1 {
+		localPort = frontend.DefaultLocalAPIPort
+		rewriteLocalPort = true
+	}
+
+	const maxRetries = 5
+	var lastError error
+	for retries := 0; retries < maxRetries; retries++ {
+		if k.proxy.WaitError() != nil {
+			return nil, 0, fmt.Errorf("proxy was failed while restart tunnel")
+		}
+
+		tunnelAddress := fmt.Sprintf("%d:localhost:%s", localPort, kubeProxyPort)
+		log.DebugF("Try up tunnel on %v\n", tunnelAddress)
+		tun := NewTunnel(k.client, "L", tunnelAddress)
+		err := tun.Up()
+		if err == nil {
+			go tun.HealthMonitor(tunnelErrorCh)
+			log.DebugF("Tunnel up\n")
+			return tun, localPort, nil
+		}
+
+		tun.Stop()
+		lastError = fmt.Errorf("tunnel '%s': %v", tunnelAddress, err)
+		if rewriteLocalPort {
+			localPort++
+		}
+	}
+
+	log.DebugF("Tunnel was not up: %v\n", lastError)
+	return nil, 0, lastError
+}
+
+func (k *KubeProxy) runKubeProxy(waitCh chan error) (*Command, string, error) {
+	port := ""
+	portReady := make(chan string, 1)
+
+	proxy := NewCommand(k.client, fmt.Sprintf("kubectl proxy --port=%s --kubeconfig /etc/kubernetes/admin.conf", k.port)).
+		Sudo().
+		WithStdoutHandler(func(line string) {
+			m := kubeProxyPortRe.FindStringSubmatch(line)
+			if len(m) == 2 && m[1] != "" {
+				log.InfoF("Got proxy port = %s on host %s\n", m[1], k.client.Settings.Host())
+				portReady <- m[1]
+			}
+		}).
+		WithWaitHandler(func(err error) {
+			waitCh <- err
+		})
+
+	if err := proxy.Start(); err != nil {
+		return nil, "", fmt.Errorf("start kubectl proxy: %v", err)
+	}
+
+	returnWaitErr := func(err error) error {
+		return fmt.Errorf("Proxy exited suddenly:\n%s%sStatus: %v", string(proxy.StdoutBytes()), string(proxy.StderrBytes()), err)
+	}
+
+	t := time.NewTimer(20 * time.Second)
+	defer t.Stop()
+	select {
+	case err := <-waitCh:
+		return nil, "", returnWaitErr(err)
+	case <-t.C:
+		proxy.Stop()
+		return nil, "", fmt.Errorf("timeout waiting for api proxy port")
+	case port = <-portReady:
+	}
+
+	log.DebugLn("Proxy process started")
+	return proxy, port, nil
+}
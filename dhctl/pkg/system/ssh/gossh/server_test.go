@@ -0,0 +1,237 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gossh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os/exec"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// testServer is an in-process ssh server. It executes commands with local sh in the work dir
+// and supports direct-tcpip channels to test tunnels and jumps via bastion.
+type testServer struct {
+	listener net.Listener
+	config   *ssh.ServerConfig
+	workDir  string
+
+	lock     sync.Mutex
+	commands []string
+	dials    []string
+}
+
+func newTestSigner(t *testing.T) ssh.Signer {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+
+	return signer
+}
+
+func newTestServer(t *testing.T, clientKey ssh.PublicKey, workDir string) *testServer {
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) == string(clientKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unknown key for %s", conn.User())
+		},
+	}
+	config.AddHostKey(newTestSigner(t))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &testServer{listener: listener, config: config, workDir: workDir}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go s.serve()
+
+	return s
+}
+
+func (s *testServer) Host() string {
+	return "127.0.0.1"
+}
+
+func (s *testServer) Port() string {
+	return strconv.Itoa(s.listener.Addr().(*net.TCPAddr).Port)
+}
+
+func (s *testServer) Commands() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+func (s *testServer) Dials() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string(nil), s.dials...)
+}
+
+func (s *testServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			_, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+			if err != nil {
+				return
+			}
+			go ssh.DiscardRequests(reqs)
+
+			for newChannel := range chans {
+				switch newChannel.ChannelType() {
+				case "session":
+					go s.handleSession(newChannel)
+				case "direct-tcpip":
+					go s.handleDirectTCPIP(newChannel)
+				default:
+					_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+				}
+			}
+		}()
+	}
+}
+
+func (s *testServer) handleSession(newChannel ssh.NewChannel) {
+	channel, reqs, err := newChannel.Accept()
+	if err != nil {
+		return
+	}
+	defer channel.Close()
+
+	var cmd *exec.Cmd
+	done := make(chan struct{})
+
+	for req := range reqs {
+		switch req.Type {
+		case "pty-req", "signal":
+			_ = req.Reply(true, nil)
+		case "exec":
+			if cmd != nil {
+				_ = req.Reply(false, nil)
+				continue
+			}
+
+			var payload struct{ Command string }
+			if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+				_ = req.Reply(false, nil)
+				continue
+			}
+
+			s.lock.Lock()
+			s.commands = append(s.commands, payload.Command)
+			s.lock.Unlock()
+
+			cmd = exec.Command("sh", "-c", payload.Command)
+			cmd.Dir = s.workDir
+			cmd.Stdout = channel
+			cmd.Stderr = channel.Stderr()
+			stdin, _ := cmd.StdinPipe()
+			if err := cmd.Start(); err != nil {
+				_ = req.Reply(false, nil)
+				continue
+			}
+			_ = req.Reply(true, nil)
+
+			go func() {
+				_, _ = io.Copy(stdin, channel)
+				_ = stdin.Close()
+			}()
+
+			go func() {
+				defer close(done)
+				status := uint32(0)
+				if err := cmd.Wait(); err != nil {
+					status = 1
+					if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() > 0 {
+						status = uint32(exitErr.ExitCode())
+					}
+				}
+				statusPayload := make([]byte, 4)
+				binary.BigEndian.PutUint32(statusPayload, status)
+				_, _ = channel.SendRequest("exit-status", false, statusPayload)
+				_ = channel.Close()
+			}()
+		default:
+			_ = req.Reply(false, nil)
+		}
+	}
+
+	// client closed the session, kill the command
+	if cmd != nil {
+		if cmd.Process != nil {
+			_ = cmd.Process.Kill()
+		}
+		<-done
+	}
+}
+
+func (s *testServer) handleDirectTCPIP(newChannel ssh.NewChannel) {
+	var payload struct {
+		Host       string
+		Port       uint32
+		OriginHost string
+		OriginPort uint32
+	}
+	if err := ssh.Unmarshal(newChannel.ExtraData(), &payload); err != nil {
+		_ = newChannel.Reject(ssh.ConnectionFailed, "bad payload")
+		return
+	}
+
+	addr := net.JoinHostPort(payload.Host, strconv.Itoa(int(payload.Port)))
+	s.lock.Lock()
+	s.dials = append(s.dials, addr)
+	s.lock.Unlock()
+
+	target, err := net.Dial("tcp", addr)
+	if err != nil {
+		_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+
+	channel, reqs, err := newChannel.Accept()
+	if err != nil {
+		_ = target.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	go func() {
+		_, _ = io.Copy(channel, target)
+		_ = channel.Close()
+	}()
+	go func() {
+		_, _ = io.Copy(target, channel)
+		_ = target.Close()
+	}()
+}
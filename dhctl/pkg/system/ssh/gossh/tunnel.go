@@ -0,0 +1,182 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gossh
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+)
+
+// Tunnel forwards connections like -L and -R options of the ssh binary.
+// Address format is [bind_address:]port:host:hostport.
+type Tunnel struct {
+	client *Client
+
+	Type    string // Remote or Local
+	Address string
+
+	listener net.Listener
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	errorCh  chan error
+}
+
+func NewTunnel(client *Client, ttype, address string) *Tunnel {
+	return &Tunnel{
+		client:  client,
+		Type:    ttype,
+		Address: address,
+		stopCh:  make(chan struct{}),
+		errorCh: make(chan error, 1),
+	}
+}
+
+func (t *Tunnel) Up() error {
+	listenAddr, targetAddr, err := parseTunnelAddress(t.Address)
+	if err != nil {
+		return fmt.Errorf("cannot open tunnel '%s': %w", t.String(), err)
+	}
+
+	conn, err := t.client.Connection()
+	if err != nil {
+		return fmt.Errorf("cannot open tunnel '%s': %w", t.String(), err)
+	}
+
+	var dial func() (net.Conn, error)
+	switch t.Type {
+	case "L":
+		t.listener, err = net.Listen("tcp", listenAddr)
+		dial = func() (net.Conn, error) { return conn.Dial("tcp", targetAddr) }
+	case "R":
+		t.listener, err = conn.Listen("tcp", listenAddr)
+		dial = func() (net.Conn, error) { return net.Dial("tcp", targetAddr) }
+	default:
+		return fmt.Errorf("unknown tunnel type '%s'", t.Type)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot open tunnel '%s': %w", t.String(), err)
+	}
+
+	go t.accept(dial)
+
+	return nil
+}
+
+func (t *Tunnel) accept(dial func() (net.Conn, error)) {
+	for {
+		local, err := t.listener.Accept()
+		if err != nil {
+			select {
+			case <-t.stopCh:
+			default:
+				t.errorCh <- fmt.Errorf("tunnel '%s': %w", t.String(), err)
+			}
+			return
+		}
+
+		go func() {
+			defer local.Close()
+
+			remote, err := dial()
+			if err != nil {
+				log.DebugF("Tunnel '%s': dial: %v\n", t.String(), err)
+				return
+			}
+			defer remote.Close()
+
+			copyConns(local, remote)
+		}()
+	}
+}
+
+func (t *Tunnel) HealthMonitor(errorOutCh chan<- error) {
+	defer log.DebugF("Tunnel health monitor stopped\n")
+	log.DebugF("Tunnel health monitor started\n")
+
+	for {
+		select {
+		case err := <-t.errorCh:
+			errorOutCh <- err
+		case <-t.stopCh:
+			return
+		}
+	}
+}
+
+func (t *Tunnel) Stop() {
+	if t == nil {
+		return
+	}
+
+	t.stopOnce.Do(func() {
+		close(t.stopCh)
+		if t.listener != nil {
+			_ = t.listener.Close()
+		}
+	})
+}
+
+func (t *Tunnel) String() string {
+	return fmt.Sprintf("%s:%s", t.Type, t.Address)
+}
+
+// LocalAddr returns the listening address. It is useful when port 0 is passed.
+func (t *Tunnel) LocalAddr() net.Addr {
+	if t.listener == nil {
+		return nil
+	}
+	return t.listener.Addr()
+}
+
+func parseTunnelAddress(address string) (listenAddr, targetAddr string, err error) {
+	parts := strings.Split(address, ":")
+	switch len(parts) {
+	case 3:
+		return net.JoinHostPort("localhost", parts[0]), net.JoinHostPort(parts[1], parts[2]), nil
+	case 4:
+		return net.JoinHostPort(parts[0], parts[1]), net.JoinHostPort(parts[2], parts[3]), nil
+	}
+	return "", "", fmt.Errorf("invalid address '%s', expected [bind_address:]port:host:hostport", address)
+}
+
+func copyConns(a, b net.Conn) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(a, b)
+		closeWrite(a)
+	}()
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(b, a)
+		closeWrite(b)
+	}()
+	wg.Wait()
+}
+
+func closeWrite(conn net.Conn) {
+	if c, ok := conn.(interface{ CloseWrite() error }); ok {
+		_ = c.CloseWrite()
+		return
+	}
+	_ = conn.Close()
+}
@@ -0,0 +1,148 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gossh
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/app"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/system/ssh/frontend"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/util/tomb"
+)
+
+type UploadScript struct {
+	client *Client
+
+	ScriptPath string
+	Args       []string
+	envs       map[string]string
+
+	sudo bool
+
+	stdoutHandler func(string)
+
+	timeout time.Duration
+}
+
+func NewUploadScript(client *Client, scriptPath string, args ...string) *UploadScript {
+	return &UploadScript{
+		client:     client,
+		ScriptPath: scriptPath,
+		Args:       args,
+	}
+}
+
+func (u *UploadScript) Sudo() *UploadScript {
+	u.sudo = true
+	return u
+}
+
+func (u *UploadScript) WithStdoutHandler(handler func(string)) *UploadScript {
+	u.stdoutHandler = handler
+	return u
+}
+
+func (u *UploadScript) WithTimeout(timeout time.Duration) *UploadScript {
+	u.timeout = timeout
+	return u
+}
+
+func (u *UploadScript) WithEnvs(envs map[string]string) *UploadScript {
+	u.envs = envs
+	return u
+}
+
+func (u *UploadScript) Execute() (stdout []byte, err error) {
+	scriptName := filepath.Base(u.ScriptPath)
+
+	remotePath := "."
+	if u.sudo {
+		remotePath = "/tmp/" + scriptName
+	}
+	err = NewFile(u.client).Upload(u.ScriptPath, remotePath)
+	if err != nil {
+		return nil, fmt.Errorf("upload: %v", err)
+	}
+
+	var cmd *Command
+	if u.sudo {
+		cmd = NewCommand(u.client, frontend.PathWithEnv("/tmp/"+scriptName, u.envs), u.Args...).Sudo()
+	} else {
+		cmd = NewCommand(u.client, frontend.PathWithEnv("./"+scriptName, u.envs), u.Args...)
+	}
+
+	if u.stdoutHandler != nil {
+		cmd.WithStdoutHandler(u.stdoutHandler)
+	}
+
+	if u.timeout > 0 {
+		cmd.WithTimeout(u.timeout)
+	}
+
+	err = cmd.Run()
+	if err != nil {
+		err = fmt.Errorf("execute on remote: %w", err)
+	}
+	return cmd.StdoutBytes(), err
+}
+
+func (u *UploadScript) ExecuteBundle(parentDir, bundleDir string) (stdout []byte, err error) {
+	bundleName := fmt.Sprintf("bundle-%s.tar", time.Now().Format("20060102-150405"))
+	bundleLocalFilepath := filepath.Join(app.TmpDirName, bundleName)
+
+	// tar cpf bundle.tar -C /tmp/dhctl.1231qd23/var/lib bashible
+	tarCmd := exec.Command("tar", "cpf", bundleLocalFilepath, "-C", parentDir, bundleDir)
+	err = tarCmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("tar bundle: %v", err)
+	}
+
+	tomb.RegisterOnShutdown("Delete bashible bundle folder", func() { _ = os.Remove(bundleLocalFilepath) })
+
+	// upload to /tmp
+	err = NewFile(u.client).Upload(bundleLocalFilepath, "/tmp")
+	if err != nil {
+		return nil, fmt.Errorf("upload: %v", err)
+	}
+
+	// sudo:
+	// tar xpof /tmp/bundle.tar -C /var/lib && /var/lib/bashible/bashible.sh args...
+	tarCmdline := fmt.Sprintf("tar xpof /tmp/%s -C /var/lib && /var/lib/%s/%s %s", bundleName, bundleDir, u.ScriptPath, strings.Join(u.Args, " "))
+	bundleCmd := NewCommand(u.client, tarCmdline).Sudo()
+
+	// Buffers to implement output handler logic
+	lastStep := ""
+	failsCounter := 0
+
+	processLogger := log.GetProcessLogger()
+
+	handler := frontend.BundleOutputHandler(bundleCmd.kill, processLogger, &lastStep, &failsCounter)
+	err = bundleCmd.WithStdoutHandler(handler).Run()
+	if err != nil {
+		if lastStep != "" {
+			processLogger.LogProcessFail()
+		}
+		err = fmt.Errorf("execute bundle: %w", err)
+	} else {
+		processLogger.LogProcessEnd()
+	}
+	return bundleCmd.StdoutBytes(), err
+}
@@ -0,0 +1,295 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ssh
+
+import (
+	"errors"
+	"io"
+	"os/exec"
+	"time"
+
+	gossh "golang.org/x/crypto/ssh"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/system/ssh/frontend"
+	nativessh "github.com/deckhouse/deckhouse/dhctl/pkg/system/ssh/gossh"
+)
+
+// Interfaces are implemented by both transports:
+// frontend package runs ssh binaries and gossh package uses the ssh client library.
+
+type Command interface {
+	Sudo() Command
+	WithSSHArgs(args ...string) Command
+	WithTimeout(timeout time.Duration) Command
+	WithStdoutHandler(handler func(string)) Command
+	WithStderrHandler(handler func(string)) Command
+	Run() error
+	Output() ([]byte, []byte, error)
+	CombinedOutput() ([]byte, error)
+	StdoutBytes() []byte
+	StderrBytes() []byte
+}
+
+type File interface {
+	Upload(srcPath, remotePath string) error
+	UploadBytes(data []byte, remotePath string) error
+	Download(remotePath, dstPath string) error
+	DownloadBytes(remotePath string) ([]byte, error)
+}
+
+type Tunnel interface {
+	Up() error
+	HealthMonitor(errorOutCh chan<- error)
+	Stop()
+	String() string
+}
+
+type KubeProxy interface {
+	Start(useLocalPort int) (port string, err error)
+	Stop()
+}
+
+type Script interface {
+	Sudo() Script
+	WithStdoutHandler(handler func(string)) Script
+	WithTimeout(timeout time.Duration) Script
+	WithEnvs(envs map[string]string) Script
+	Execute() ([]byte, error)
+	ExecuteBundle(parentDir, bundleDir string) ([]byte, error)
+}
+
+type Check interface {
+	WithDelaySeconds(seconds int) Check
+	AwaitAvailability() error
+	ExpectAvailable() ([]byte, error)
+	String() string
+}
+
+var (
+	_ File      = &frontend.File{}
+	_ File      = &nativessh.File{}
+	_ Tunnel    = &frontend.Tunnel{}
+	_ Tunnel    = &nativessh.Tunnel{}
+	_ KubeProxy = &frontend.KubeProxy{}
+	_ KubeProxy = &nativessh.KubeProxy{}
+)
+
+// IsConnectionLost returns true if the command is failed because the connection is closed, e.g. on reboot.
+func IsConnectionLost(err error) bool {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		// ssh binary returns 255 if the connection is closed
+		return exitErr.ExitCode() == 255
+	}
+
+	var exitMissingErr *gossh.ExitMissingError
+	return errors.As(err, &exitMissingErr) || errors.Is(err, io.EOF)
+}
+
+// cli transport
+
+type cliCommand struct {
+	cmd *frontend.Command
+}
+
+// executor prepares command without sudo if neither Sudo() nor Cmd() was called.
+func (c *cliCommand) executor() *frontend.Command {
+	if c.cmd.Executor == nil {
+		c.cmd.Cmd()
+	}
+	return c.cmd
+}
+
+func (c *cliCommand) Sudo() Command {
+	c.cmd.Sudo()
+	return c
+}
+
+func (c *cliCommand) WithSSHArgs(args ...string) Command {
+	c.cmd.WithSSHArgs(args...)
+	return c
+}
+
+func (c *cliCommand) WithTimeout(timeout time.Duration) Command {
+	c.executor().WithTimeout(timeout)
+	return c
+}
+
+func (c *cliCommand) WithStdoutHandler(handler func(string)) Command {
+	c.executor().WithStdoutHandler(handler)
+	return c
+}
+
+func (c *cliCommand) WithStderrHandler(handler func(string)) Command {
+	c.executor().WithStderrHandler(handler)
+	return c
+}
+
+func (c *cliCommand) Run() error {
+	return c.executor().Run()
+}
+
+func (c *cliCommand) Output() ([]byte, []byte, error) {
+	return c.cmd.Output()
+}
+
+func (c *cliCommand) CombinedOutput() ([]byte, error) {
+	return c.cmd.CombinedOutput()
+}
+
+func (c *cliCommand) StdoutBytes() []byte {
+	return c.executor().StdoutBytes()
+}
+
+func (c *cliCommand) StderrBytes() []byte {
+	return c.executor().StderrBytes()
+}
+
+type cliScript struct {
+	script *frontend.UploadScript
+}
+
+func (s *cliScript) Sudo() Script {
+	s.script.Sudo()
+	return s
+}
+
+func (s *cliScript) WithStdoutHandler(handler func(string)) Script {
+	s.script.WithStdoutHandler(handler)
+	return s
+}
+
+func (s *cliScript) WithTimeout(timeout time.Duration) Script {
+	s.script.WithTimeout(timeout)
+	return s
+}
+
+func (s *cliScript) WithEnvs(envs map[string]string) Script {
+	s.script.WithEnvs(envs)
+	return s
+}
+
+func (s *cliScript) Execute() ([]byte, error) {
+	return s.script.Execute()
+}
+
+func (s *cliScript) ExecuteBundle(parentDir, bundleDir string) ([]byte, error) {
+	return s.script.ExecuteBundle(parentDir, bundleDir)
+}
+
+// frontendCheck is used by both transports, they differ only in the way the command is run
+type frontendCheck struct {
+	check *frontend.Check
+}
+
+func (c *frontendCheck) WithDelaySeconds(seconds int) Check {
+	c.check.WithDelaySeconds(seconds)
+	return c
+}
+
+func (c *frontendCheck) AwaitAvailability() error {
+	return c.check.AwaitAvailability()
+}
+
+func (c *frontendCheck) ExpectAvailable() ([]byte, error) {
+	return c.check.ExpectAvailable()
+}
+
+func (c *frontendCheck) String() string {
+	return c.check.String()
+}
+
+// gossh transport
+
+type nativeCommand struct {
+	cmd *nativessh.Command
+}
+
+func (c *nativeCommand) Sudo() Command {
+	c.cmd.Sudo()
+	return c
+}
+
+func (c *nativeCommand) WithSSHArgs(args ...string) Command {
+	c.cmd.WithSSHArgs(args...)
+	return c
+}
+
+func (c *nativeCommand) WithTimeout(timeout time.Duration) Command {
+	c.cmd.WithTimeout(timeout)
+	return c
+}
+
+func (c *nativeCommand) WithStdoutHandler(handler func(string)) Command {
+	c.cmd.WithStdoutHandler(handler)
+	return c
+}
+
+func (c *nativeCommand) WithStderrHandler(handler func(string)) Command {
+	c.cmd.WithStderrHandler(handler)
+	return c
+}
+
+func (c *nativeCommand) Run() error {
+	return c.cmd.Run()
+}
+
+func (c *nativeCommand) Output() ([]byte, []byte, error) {
+	return c.cmd.Output()
+}
+
+func (c *nativeCommand) CombinedOutput() ([]byte, error) {
+	return c.cmd.CombinedOutput()
+}
+
+func (c *nativeCommand) StdoutBytes() []byte {
+	return c.cmd.StdoutBytes()
+}
+
+func (c *nativeCommand) StderrBytes() []byte {
+	return c.cmd.StderrBytes()
+}
+
+type nativeScript struct {
+	script *nativessh.UploadScript
+}
+
+func (s *nativeScript) Sudo() Script {
+	s.script.Sudo()
+	return s
+}
+
+func (s *nativeScript) WithStdoutHandler(handler func(string)) Script {
+	s.script.WithStdoutHandler(handler)
+	return s
+}
+
+func (s *nativeScript) WithTimeout(timeout time.Duration) Script {
+	s.script.WithTimeout(timeout)
+	return s
+}
+
+func (s *nativeScript) WithEnvs(envs map[string]string) Script {
+	s.script.WithEnvs(envs)
+	return s
+}
+
+func (s *nativeScript) Execute() ([]byte, error) {
+	return s.script.Execute()
+}
+
+func (s *nativeScript) ExecuteBundle(parentDir, bundleDir string) ([]byte, error) {
+	return s.script.ExecuteBundle(parentDir, bundleDir)
+}
@@ -48,3 +48,21 @@ func AskBecomePassword() (err error) {
 	app.BecomePass = string(data)
 	return nil
 }
+
+func AskSSHKeyPassphrase(keyPath string) ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(fd) {
+		return nil, fmt.Errorf("stdin is not a terminal, error reading passphrase for key %s", keyPath)
+	}
+
+	log.InfoF("Enter passphrase for %s: ", keyPath)
+
+	data, err := terminal.ReadPassword(fd)
+	log.InfoLn()
+
+	if err != nil {
+		return nil, fmt.Errorf("read passphrase: %v", err)
+	}
+
+	return data, nil
+}
@@ -21,6 +21,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
+	"sync/atomic"
 	"syscall"
 
 	"github.com/deckhouse/deckhouse/dhctl/pkg/app"
@@ -29,15 +31,22 @@ import (
 
 type Executor interface {
 	Output(...string) ([]byte, error)
-	Exec(...string) (int, error)
+	Exec(log.Logger, ...string) (int, error)
 	Stop()
 }
 
-func terraformCmd(args ...string) *exec.Cmd {
+// dataDirsCounter makes data dirs of executors unique, concurrent terraform runs can not share the data dir.
+var dataDirsCounter uint64
+
+func newDataDir() string {
+	return filepath.Join(app.TmpDirName, "tf_dhctl", strconv.FormatUint(atomic.AddUint64(&dataDirsCounter, 1), 10))
+}
+
+func terraformCmd(dataDir string, args ...string) *exec.Cmd {
 	cmd := exec.Command("terraform", args...)
 	cmd.Env = append(
 		cmd.Env,
-		"TF_IN_AUTOMATION=yes", "TF_DATA_DIR="+filepath.Join(app.TmpDirName, "tf_dhctl"),
+		"TF_IN_AUTOMATION=yes", "TF_DATA_DIR="+dataDir,
 	)
 	if app.IsDebug {
 		// Debug mode is deprecated, however trace produces more useless information
@@ -55,15 +64,21 @@ func terraformCmd(args ...string) *exec.Cmd {
 
 // CMDExecutor straightforward cmd executor which provides convenient output and handles quit signal.
 type CMDExecutor struct {
-	cmd *exec.Cmd
+	cmd     *exec.Cmd
+	dataDir string
+}
+
+func NewCMDExecutor() *CMDExecutor {
+	return &CMDExecutor{dataDir: newDataDir()}
 }
 
 func (c *CMDExecutor) Output(args ...string) ([]byte, error) {
-	return terraformCmd(args...).Output()
+	return terraformCmd(c.dataDir, args...).Output()
 }
 
-func (c *CMDExecutor) Exec(args ...string) (int, error) {
-	c.cmd = terraformCmd(args...)
+// Exec runs terraform and writes its output with the logger.
+func (c *CMDExecutor) Exec(logger log.Logger, args ...string) (int, error) {
+	c.cmd = terraformCmd(c.dataDir, args...)
 
 	// Start terraform as a leader of the new process group to prevent
 	// os.Interrupt (SIGINT) signal from the shell when Ctrl-C is pressed.
@@ -81,10 +96,10 @@ func (c *CMDExecutor) Exec(args ...string) (int, error) {
 		return 1, fmt.Errorf("stderr pipe: %v", err)
 	}
 
-	log.DebugLn(c.cmd.String())
+	logger.LogDebugLn(c.cmd.String())
 	err = c.cmd.Start()
 	if err != nil {
-		log.ErrorLn(err)
+		logger.LogErrorLn(err)
 		return c.cmd.ProcessState.ExitCode(), err
 	}
 
@@ -94,7 +109,7 @@ func (c *CMDExecutor) Exec(args ...string) (int, error) {
 		e := bufio.NewScanner(stderr)
 		for e.Scan() {
 			if app.IsDebug {
-				log.DebugLn(e.Text())
+				logger.LogDebugLn(e.Text())
 			} else {
 				errBuf.WriteString(e.Text() + "\n")
 			}
@@ -105,14 +120,15 @@ func (c *CMDExecutor) Exec(args ...string) (int, error) {
 
 	s := bufio.NewScanner(stdout)
 	for s.Scan() {
-		log.InfoLn(s.Text())
+		logger.LogInfoLn(s.Text())
+		emitResourceProgress(s.Text())
 	}
 
 	err = <-waitCh
 
 	exitCode := c.cmd.ProcessState.ExitCode() // 2 = exit code, if terraform plan has diff
 	if err != nil && exitCode != terraformHasChangesExitCode {
-		log.ErrorLn(err)
+		logger.LogErrorLn(err)
 		err = fmt.Errorf(errBuf.String())
 		if app.IsDebug {
 			err = fmt.Errorf("terraform has failed in DEBUG mode, search in the output above for an error")
@@ -151,7 +167,7 @@ func (f *fakeExecutor) Output(parts ...string) ([]byte, error) {
 	result := f.data[parts[0]]
 	return result.resp, result.err
 }
-func (f *fakeExecutor) Exec(parts ...string) (int, error) {
+func (f *fakeExecutor) Exec(_ log.Logger, parts ...string) (int, error) {
 	result := f.data[parts[0]]
 	return result.code, result.err
 }
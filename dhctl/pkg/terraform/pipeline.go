@@ -73,7 +73,7 @@ func ApplyPipeline(r *Runner, name string, extractFn func(r *Runner) (*PipelineO
 		return err
 	}
 
-	err := log.Process("terraform", fmt.Sprintf("Pipeline %s for %s", r.step, name), pipelineFunc)
+	err := log.ProcessWithLogger(r.getLogger(), "terraform", fmt.Sprintf("Pipeline %s for %s", r.step, name), pipelineFunc)
 	return extractedData, err
 }
 
@@ -93,7 +93,7 @@ func CheckPipeline(r *Runner, name string) (int, error) {
 		isChange = r.changesInPlan
 		return nil
 	}
-	err := log.Process("terraform", fmt.Sprintf("Check state %s for %s", r.step, name), pipelineFunc)
+	err := log.ProcessWithLogger(r.getLogger(), "terraform", fmt.Sprintf("Check state %s for %s", r.step, name), pipelineFunc)
 	return isChange, err
 }
 
@@ -163,7 +163,7 @@ func CheckBaseInfrastructurePipeline(r *Runner, name string) (int, error) {
 
 		return nil
 	}
-	err := log.Process("terraform", fmt.Sprintf("Check state %s for %s", r.step, name), pipelineFunc)
+	err := log.ProcessWithLogger(r.getLogger(), "terraform", fmt.Sprintf("Check state %s for %s", r.step, name), pipelineFunc)
 	return isChange, err
 }
 
@@ -175,7 +175,7 @@ func DestroyPipeline(r *Runner, name string) error {
 		}
 
 		if r.ResourcesQuantityInState() == 0 {
-			log.InfoLn("Nothing to destroy! Skipping ...")
+			r.getLogger().LogInfoLn("Nothing to destroy! Skipping ...")
 			return nil
 		}
 
@@ -185,7 +185,7 @@ func DestroyPipeline(r *Runner, name string) error {
 		}
 		return nil
 	}
-	return log.Process("terraform", fmt.Sprintf("Destroy %s for %s", r.step, name), pipelineFunc)
+	return log.ProcessWithLogger(r.getLogger(), "terraform", fmt.Sprintf("Destroy %s for %s", r.step, name), pipelineFunc)
 }
 
 func GetBaseInfraResult(r *Runner) (*PipelineOutputs, error) {
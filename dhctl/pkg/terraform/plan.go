@@ -0,0 +1,241 @@
+// Copyright 2021 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terraform
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"reflect"
+	"sort"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+)
+
+const (
+	ResourceActionCreate  = "create"
+	ResourceActionUpdate  = "update"
+	ResourceActionReplace = "replace"
+	ResourceActionDelete  = "delete"
+)
+
+// SensitiveValue replaces values of sensitive attributes in resource changes.
+const SensitiveValue = "(sensitive value)"
+
+// ResourceChange is a single resource change from the terraform plan.
+type ResourceChange struct {
+	Address string                 `json:"address"`
+	Type    string                 `json:"type"`
+	Name    string                 `json:"name"`
+	Action  string                 `json:"action"`
+	Before  map[string]interface{} `json:"before,omitempty"`
+	After   map[string]interface{} `json:"after,omitempty"`
+
+	// changedAttributes are calculated before masking sensitive values, masked values are always equal
+	changedAttributes []string
+}
+
+// ChangedAttributes returns sorted names of top-level attributes which differ between before and after.
+// Attribute values are not returned, because they can contain sensitive data.
+func (c *ResourceChange) ChangedAttributes() []string {
+	if c.changedAttributes != nil {
+		return c.changedAttributes
+	}
+
+	keys := make(map[string]struct{})
+	for k := range c.Before {
+		keys[k] = struct{}{}
+	}
+	for k := range c.After {
+		keys[k] = struct{}{}
+	}
+
+	attributes := make([]string, 0)
+	for k := range keys {
+		before, inBefore := c.Before[k]
+		after, inAfter := c.After[k]
+		if inBefore != inAfter || !reflect.DeepEqual(before, after) {
+			attributes = append(attributes, k)
+		}
+	}
+
+	sort.Strings(attributes)
+	return attributes
+}
+
+type planResourceChanges struct {
+	ResourcesChanges []struct {
+		Address string `json:"address"`
+		Type    string `json:"type"`
+		Name    string `json:"name"`
+		Change  struct {
+			Actions         []string               `json:"actions"`
+			Before          map[string]interface{} `json:"before"`
+			After           map[string]interface{} `json:"after"`
+			BeforeSensitive interface{}            `json:"before_sensitive"`
+			AfterSensitive  interface{}            `json:"after_sensitive"`
+		} `json:"change"`
+	} `json:"resource_changes"`
+}
+
+// resourceAction converts terraform plan actions list to the single action.
+// Empty string is returned for no-op and read actions.
+func resourceAction(actions []string) string {
+	switch {
+	case equalArray(actions, []string{"create"}):
+		return ResourceActionCreate
+	case equalArray(actions, []string{"update"}):
+		return ResourceActionUpdate
+	case equalArray(actions, []string{"delete"}):
+		return ResourceActionDelete
+	case equalArray(actions, []string{"delete", "create"}), equalArray(actions, []string{"create", "delete"}):
+		return ResourceActionReplace
+	}
+
+	return ""
+}
+
+func parsePlanResourceChanges(data []byte) ([]ResourceChange, error) {
+	var changes planResourceChanges
+
+	err := json.Unmarshal(data, &changes)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ResourceChange, 0)
+	for _, resource := range changes.ResourcesChanges {
+		action := resourceAction(resource.Change.Actions)
+		if action == "" {
+			continue
+		}
+
+		change := ResourceChange{
+			Address: resource.Address,
+			Type:    resource.Type,
+			Name:    resource.Name,
+			Action:  action,
+			Before:  resource.Change.Before,
+			After:   resource.Change.After,
+		}
+		change.changedAttributes = change.ChangedAttributes()
+		change.Before = maskSensitiveAttributes(change.Before, resource.Change.BeforeSensitive)
+		change.After = maskSensitiveAttributes(change.After, resource.Change.AfterSensitive)
+
+		result = append(result, change)
+	}
+
+	return result, nil
+}
+
+// maskSensitiveAttributes replaces attribute values marked as sensitive in the plan.
+// Terraform marks them with the structure of the same shape as values, where true means a sensitive value.
+func maskSensitiveAttributes(attributes map[string]interface{}, sensitive interface{}) map[string]interface{} {
+	if attributes == nil {
+		return nil
+	}
+
+	masked := make(map[string]interface{}, len(attributes))
+	for k, v := range attributes {
+		if all, ok := sensitive.(bool); ok && all {
+			masked[k] = SensitiveValue
+			continue
+		}
+
+		var attrSensitive interface{}
+		if m, ok := sensitive.(map[string]interface{}); ok {
+			attrSensitive = m[k]
+		}
+		masked[k] = maskSensitive(v, attrSensitive)
+	}
+
+	return masked
+}
+
+func maskSensitive(value interface{}, sensitive interface{}) interface{} {
+	switch s := sensitive.(type) {
+	case bool:
+		if s && value != nil {
+			return SensitiveValue
+		}
+	case map[string]interface{}:
+		if v, ok := value.(map[string]interface{}); ok {
+			return maskSensitiveAttributes(v, s)
+		}
+	case []interface{}:
+		if v, ok := value.([]interface{}); ok {
+			masked := make([]interface{}, len(v))
+			for i := range v {
+				var itemSensitive interface{}
+				if i < len(s) {
+					itemSensitive = s[i]
+				}
+				masked[i] = maskSensitive(v[i], itemSensitive)
+			}
+			return masked
+		}
+	}
+
+	return value
+}
+
+// GetPlanResourceChanges returns the list of resource changes from the last plan of the runner.
+func (r *Runner) GetPlanResourceChanges() ([]ResourceChange, error) {
+	if r.stopped {
+		return nil, ErrRunnerStopped
+	}
+
+	if r.planPath == "" {
+		return nil, fmt.Errorf("no plan found, try to run terraform plan first")
+	}
+
+	result, err := r.terraformExecutor.Output("show", "-json", r.planPath)
+	if err != nil {
+		var ee *exec.ExitError
+		if ok := errors.As(err, &ee); ok {
+			err = fmt.Errorf("%s\n%v", string(ee.Stderr), err)
+		}
+		return nil, fmt.Errorf("can't get terraform plan for %q\n%v", r.planPath, err)
+	}
+
+	return parsePlanResourceChanges(result)
+}
+
+// PlanPipeline runs terraform plan and returns resource changes. It never applies the plan.
+func PlanPipeline(r *Runner, name string) ([]ResourceChange, error) {
+	var changes []ResourceChange
+	pipelineFunc := func() error {
+		err := r.Init()
+		if err != nil {
+			return err
+		}
+
+		err = r.Plan()
+		if err != nil {
+			return err
+		}
+
+		if r.changesInPlan == PlanHasNoChanges {
+			changes = make([]ResourceChange, 0)
+			return nil
+		}
+
+		changes, err = r.GetPlanResourceChanges()
+		return err
+	}
+	err := log.ProcessWithLogger(r.getLogger(), "terraform", fmt.Sprintf("Plan %s for %s", r.step, name), pipelineFunc)
+	return changes, err
+}
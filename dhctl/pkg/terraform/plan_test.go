@@ -0,0 +1,141 @@
+// Copyright 2021 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terraform
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceAction(t *testing.T) {
+	tests := []struct {
+		actions  []string
+		expected string
+	}{
+		{actions: []string{"no-op"}, expected: ""},
+		{actions: []string{"read"}, expected: ""},
+		{actions: []string{"create"}, expected: ResourceActionCreate},
+		{actions: []string{"update"}, expected: ResourceActionUpdate},
+		{actions: []string{"delete"}, expected: ResourceActionDelete},
+		{actions: []string{"delete", "create"}, expected: ResourceActionReplace},
+		{actions: []string{"create", "delete"}, expected: ResourceActionReplace},
+	}
+
+	for _, tc := range tests {
+		require.Equal(t, tc.expected, resourceAction(tc.actions), "actions: %v", tc.actions)
+	}
+}
+
+func TestResourceChangeChangedAttributes(t *testing.T) {
+	change := ResourceChange{
+		Before: map[string]interface{}{
+			"name":     "node-0",
+			"cores":    float64(4),
+			"labels":   map[string]interface{}{"a": "b"},
+			"obsolete": "value",
+		},
+		After: map[string]interface{}{
+			"name":   "node-0",
+			"cores":  float64(8),
+			"labels": map[string]interface{}{"a": "c"},
+			"added":  true,
+		},
+	}
+
+	require.Equal(t, []string{"added", "cores", "labels", "obsolete"}, change.ChangedAttributes())
+
+	created := ResourceChange{Action: ResourceActionCreate, After: map[string]interface{}{"name": "node-0"}}
+	require.Equal(t, []string{"name"}, created.ChangedAttributes())
+
+	require.Len(t, (&ResourceChange{}).ChangedAttributes(), 0)
+}
+
+func TestGetPlanResourceChanges(t *testing.T) {
+	t.Run("Without plan returns error", func(t *testing.T) {
+		_, err := newTestRunner().GetPlanResourceChanges()
+		require.Error(t, err)
+	})
+
+	t.Run("Empty plan", func(t *testing.T) {
+		data, err := os.ReadFile("./mocks/checkplan/empty.json")
+		require.NoError(t, err)
+
+		runner := newTestRunner().withTerraformExecutor(&fakeExecutor{data: map[string]fakeResponse{
+			"show": {code: 0, resp: data},
+		}})
+		runner.planPath = "plan"
+
+		changes, err := runner.GetPlanResourceChanges()
+		require.NoError(t, err)
+		require.Len(t, changes, 0)
+	})
+
+	t.Run("No-op resources are skipped, replace is detected", func(t *testing.T) {
+		data, err := os.ReadFile("./mocks/checkplan/destructively_changed.json")
+		require.NoError(t, err)
+
+		runner := newTestRunner().withTerraformExecutor(&fakeExecutor{data: map[string]fakeResponse{
+			"show": {code: 0, resp: data},
+		}})
+		runner.planPath = "plan"
+
+		changes, err := runner.GetPlanResourceChanges()
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+
+		require.Equal(t, "yandex_compute_instance.master", changes[0].Address)
+		require.Equal(t, "yandex_compute_instance", changes[0].Type)
+		require.Equal(t, "master", changes[0].Name)
+		require.Equal(t, ResourceActionReplace, changes[0].Action)
+		require.NotEmpty(t, changes[0].Before)
+	})
+}
+
+func TestParsePlanResourceChangesMasksSensitiveValues(t *testing.T) {
+	plan := `{"resource_changes": [{
+  "address": "yandex_compute_instance.master",
+  "type": "yandex_compute_instance",
+  "name": "master",
+  "change": {
+    "actions": ["update"],
+    "before": {"name": "master", "metadata": {"user-data": "old", "ssh-keys": "key"}, "disks": [{"id": "a"}, {"id": "b"}], "password": "old"},
+    "after": {"name": "master", "metadata": {"user-data": "new", "ssh-keys": "key"}, "disks": [{"id": "a"}, {"id": "c"}], "password": "new"},
+    "before_sensitive": {"metadata": {"user-data": true}, "disks": [{}, {"id": true}], "password": true},
+    "after_sensitive": {"metadata": true, "password": true}
+  }
+}]}`
+
+	changes, err := parsePlanResourceChanges([]byte(plan))
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+
+	require.Equal(t, map[string]interface{}{
+		"name":     "master",
+		"metadata": map[string]interface{}{"user-data": SensitiveValue, "ssh-keys": "key"},
+		"disks":    []interface{}{map[string]interface{}{"id": "a"}, map[string]interface{}{"id": SensitiveValue}},
+		"password": SensitiveValue,
+	}, changes[0].Before)
+	require.Equal(t, map[string]interface{}{
+		"name":     "master",
+		"metadata": SensitiveValue,
+		"disks":    []interface{}{map[string]interface{}{"id": "a"}, map[string]interface{}{"id": "c"}},
+		"password": SensitiveValue,
+	}, changes[0].After)
+
+	// Changes of sensitive values are detected before masking
+	require.Equal(t, []string{"disks", "metadata", "password"}, changes[0].ChangedAttributes())
+}
@@ -0,0 +1,70 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terraform
+
+import (
+	"regexp"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+)
+
+const (
+	ResourceStateStarted    = "started"
+	ResourceStateInProgress = "in_progress"
+	ResourceStateComplete   = "complete"
+	ResourceStateErrored    = "errored"
+)
+
+var resourceProgressRe = regexp.MustCompile(`^(\S+): (Creating|Modifying|Destroying|Still creating|Still modifying|Still destroying|Creation complete|Modifications complete|Destruction complete|Creation errored|Modifications errored|Destruction errored)\b`)
+
+var resourceProgress = map[string][2]string{
+	"Creating":               {ResourceActionCreate, ResourceStateStarted},
+	"Modifying":              {ResourceActionUpdate, ResourceStateStarted},
+	"Destroying":             {ResourceActionDelete, ResourceStateStarted},
+	"Still creating":         {ResourceActionCreate, ResourceStateInProgress},
+	"Still modifying":        {ResourceActionUpdate, ResourceStateInProgress},
+	"Still destroying":       {ResourceActionDelete, ResourceStateInProgress},
+	"Creation complete":      {ResourceActionCreate, ResourceStateComplete},
+	"Modifications complete": {ResourceActionUpdate, ResourceStateComplete},
+	"Destruction complete":   {ResourceActionDelete, ResourceStateComplete},
+	"Creation errored":       {ResourceActionCreate, ResourceStateErrored},
+	"Modifications errored":  {ResourceActionUpdate, ResourceStateErrored},
+	"Destruction errored":    {ResourceActionDelete, ResourceStateErrored},
+}
+
+// parseResourceProgress extracts resource address, action and state from the terraform apply or destroy output line.
+func parseResourceProgress(line string) (address, action, state string, ok bool) {
+	m := resourceProgressRe.FindStringSubmatch(line)
+	if m == nil {
+		return "", "", "", false
+	}
+
+	progress := resourceProgress[m[2]]
+	return m[1], progress[0], progress[1], true
+}
+
+func emitResourceProgress(line string) {
+	address, action, state, ok := parseResourceProgress(line)
+	if !ok {
+		return
+	}
+
+	log.EmitEvent(log.Event{
+		Type:     log.EventTerraformResource,
+		Resource: address,
+		Action:   action,
+		State:    state,
+	})
+}
@@ -0,0 +1,84 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terraform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseResourceProgress(t *testing.T) {
+	tests := []struct {
+		line    string
+		address string
+		action  string
+		state   string
+	}{
+		{
+			line:    "module.master-node.yandex_compute_instance.master: Creating...",
+			address: "module.master-node.yandex_compute_instance.master",
+			action:  ResourceActionCreate,
+			state:   ResourceStateStarted,
+		},
+		{
+			line:    "yandex_vpc_network.kube: Still creating... [10s elapsed]",
+			address: "yandex_vpc_network.kube",
+			action:  ResourceActionCreate,
+			state:   ResourceStateInProgress,
+		},
+		{
+			line:    "yandex_vpc_network.kube: Creation complete after 2s [id=enp0000]",
+			address: "yandex_vpc_network.kube",
+			action:  ResourceActionCreate,
+			state:   ResourceStateComplete,
+		},
+		{
+			line:    "module.node.aws_instance.node: Modifications complete after 5s [id=i-0000]",
+			address: "module.node.aws_instance.node",
+			action:  ResourceActionUpdate,
+			state:   ResourceStateComplete,
+		},
+		{
+			line:    "module.node.aws_instance.node: Destroying... [id=i-0000]",
+			address: "module.node.aws_instance.node",
+			action:  ResourceActionDelete,
+			state:   ResourceStateStarted,
+		},
+		{
+			line:    "aws_vpc.kube: Destruction errored after 1m0s",
+			address: "aws_vpc.kube",
+			action:  ResourceActionDelete,
+			state:   ResourceStateErrored,
+		},
+	}
+
+	for _, tc := range tests {
+		address, action, state, ok := parseResourceProgress(tc.line)
+		require.True(t, ok, tc.line)
+		require.Equal(t, tc.address, address)
+		require.Equal(t, tc.action, action)
+		require.Equal(t, tc.state, state)
+	}
+
+	for _, line := range []string{
+		"",
+		"Apply complete! Resources: 1 added, 0 changed, 0 destroyed.",
+		"yandex_vpc_network.kube: Refreshing state... [id=enp0000]",
+	} {
+		_, _, _, ok := parseResourceProgress(line)
+		require.False(t, ok, line)
+	}
+}
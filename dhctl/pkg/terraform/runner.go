@@ -15,9 +15,11 @@
 package terraform
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os/exec"
 	"path/filepath"
@@ -79,6 +81,7 @@ type Runner struct {
 	changeSettings ChangeActionSettings
 
 	allowedCachedState bool
+	planDestroy        bool
 	changesInPlan      int
 
 	stateCache state.Cache
@@ -95,6 +98,10 @@ type Runner struct {
 	terraformExecutor       Executor
 
 	hook InfraActionHook
+
+	logger log.Logger
+	// planOutput keeps the plan to show it with the confirmation if the runner writes logs with its own logger.
+	planOutput string
 }
 
 func NewRunner(provider, prefix, layout, step string, stateCache state.Cache) *Runner {
@@ -106,7 +113,7 @@ func NewRunner(provider, prefix, layout, step string, stateCache state.Cache) *R
 		confirm:           input.NewConfirmation,
 		stateCache:        stateCache,
 		changeSettings:    ChangeActionSettings{},
-		terraformExecutor: &CMDExecutor{},
+		terraformExecutor: NewCMDExecutor(),
 	}
 
 	var destinations []SaverDestination
@@ -155,13 +162,13 @@ func (r *Runner) WithHook(h InfraActionHook) *Runner {
 func (r *Runner) WithState(stateData []byte) *Runner {
 	tmpFile, err := ioutil.TempFile(app.TmpDirName, r.step+deckhouseClusterStateSuffix)
 	if err != nil {
-		log.ErrorF("can't save terraform state for runner %s: %s\n", r.step, err)
+		r.getLogger().LogErrorF("can't save terraform state for runner %s: %s\n", r.step, err)
 		return r
 	}
 
 	err = ioutil.WriteFile(tmpFile.Name(), stateData, 0o600)
 	if err != nil {
-		log.ErrorF("can't write terraform state for runner %s: %s\n", r.step, err)
+		r.getLogger().LogErrorF("can't write terraform state for runner %s: %s\n", r.step, err)
 		return r
 	}
 
@@ -172,13 +179,13 @@ func (r *Runner) WithState(stateData []byte) *Runner {
 func (r *Runner) WithVariables(variablesData []byte) *Runner {
 	tmpFile, err := ioutil.TempFile(app.TmpDirName, varFileName)
 	if err != nil {
-		log.ErrorF("can't save terraform variables for runner %s: %s\n", r.step, err)
+		r.getLogger().LogErrorF("can't save terraform variables for runner %s: %s\n", r.step, err)
 		return r
 	}
 
 	err = ioutil.WriteFile(tmpFile.Name(), variablesData, 0o600)
 	if err != nil {
-		log.ErrorF("can't write terraform variables for runner %s: %s\n", r.step, err)
+		r.getLogger().LogErrorF("can't write terraform variables for runner %s: %s\n", r.step, err)
 		return r
 	}
 
@@ -201,6 +208,12 @@ func (r *Runner) WithAllowedCachedState(flag bool) *Runner {
 	return r
 }
 
+// WithPlanDestroy makes the runner plan destruction of all resources in the state.
+func (r *Runner) WithPlanDestroy(flag bool) *Runner {
+	r.planDestroy = flag
+	return r
+}
+
 func (r *Runner) WithSkipChangesOnDeny(flag bool) *Runner {
 	r.changeSettings.SkipChangesOnDeny = flag
 	return r
@@ -213,6 +226,21 @@ func (r *Runner) WithAdditionalStateSaverDestination(destinations ...SaverDestin
 	return r
 }
 
+// WithLogger sets the logger for terraform output and messages of the runner.
+// Confirmations are asked with the default logger anyway.
+func (r *Runner) WithLogger(logger log.Logger) *Runner {
+	r.logger = logger
+	return r
+}
+
+func (r *Runner) getLogger() log.Logger {
+	if r.logger == nil {
+		return log.GetDefaultLogger()
+	}
+
+	return r.logger
+}
+
 func (r *Runner) withTerraformExecutor(t Executor) *Runner {
 	r.terraformExecutor = t
 	return r
@@ -242,7 +270,7 @@ func (r *Runner) Init() error {
 		}
 
 		if hasState {
-			log.InfoF("Cached Terraform state found:\n\t%s\n\n", r.statePath)
+			r.getLogger().LogInfoF("Cached Terraform state found:\n\t%s\n\n", r.statePath)
 			if !r.allowedCachedState {
 				var isConfirm bool
 				switch app.UseTfCache {
@@ -252,6 +280,8 @@ func (r *Runner) Init() error {
 					isConfirm = false
 				default:
 					isConfirm = r.confirm().
+						WithAction(input.ActionUseCachedState).
+						WithStep(r.step).
 						WithMessage("Do you want to continue with Terraform state from local cache?").
 						WithYesByDefault().
 						Ask()
@@ -271,7 +301,7 @@ func (r *Runner) Init() error {
 				err := fs.WriteContentIfNeed(r.statePath, stateData)
 				if err != nil {
 					err := fmt.Errorf("can't write terraform state for runner %s: %s", r.step, err)
-					log.ErrorLn(err)
+					r.getLogger().LogErrorLn(err)
 					return err
 				}
 			}
@@ -283,7 +313,7 @@ func (r *Runner) Init() error {
 		r.WithState(nil)
 	}
 
-	return log.Process("default", "terraform init ...", func() error {
+	return log.ProcessWithLogger(r.getLogger(), "default", "terraform init ...", func() error {
 		args := []string{
 			"init",
 			"-get-plugins=false",
@@ -348,7 +378,13 @@ func (r *Runner) isSkipChanges() (skip bool, err error) {
 	}
 
 	if !r.changeSettings.AutoApprove {
-		if !r.confirm().WithMessage("Do you want to CHANGE objects state in the cloud?").Ask() {
+		action := input.ActionTerraformChange
+		if r.changesInPlan == PlanHasDestructiveChanges {
+			action = input.ActionTerraformDestructiveChange
+		}
+
+		confirm := r.confirm().WithAction(action).WithStep(r.step).WithMessage("Do you want to CHANGE objects state in the cloud?").WithDetails(r.planOutput)
+		if !confirm.Ask() {
 			if r.changeSettings.SkipChangesOnDeny {
 				return true, nil
 			}
@@ -366,13 +402,13 @@ func (r *Runner) Apply() error {
 		return ErrRunnerStopped
 	}
 
-	return log.Process("default", "terraform apply ...", func() error {
+	return log.ProcessWithLogger(r.getLogger(), "default", "terraform apply ...", func() error {
 		skip, err := r.isSkipChanges()
 		if err != nil {
 			return err
 		}
 		if skip {
-			log.InfoLn("Skip terraform apply.")
+			r.getLogger().LogInfoLn("Skip terraform apply.")
 			return nil
 		}
 
@@ -419,7 +455,7 @@ func (r *Runner) Plan() error {
 		return ErrRunnerStopped
 	}
 
-	return log.Process("default", "terraform plan ...", func() error {
+	return log.ProcessWithLogger(r.getLogger(), "default", "terraform plan ...", func() error {
 		tmpFile, err := ioutil.TempFile(app.TmpDirName, r.step+deckhousePlanSuffix)
 		if err != nil {
 			return fmt.Errorf("can't create temp file for plan: %w", err)
@@ -435,9 +471,21 @@ func (r *Runner) Plan() error {
 			fmt.Sprintf("-out=%s", tmpFile.Name()),
 		}
 
+		if r.planDestroy {
+			args = append(args, "-destroy")
+		}
+
 		args = append(args, r.workingDir)
 
-		exitCode, err := r.execTerraform(args...)
+		logger := r.getLogger()
+		var planOutput bytes.Buffer
+		if r.logger != nil {
+			// The plan is written into the runner logger and also kept to show it to the user with the confirmation.
+			logger = log.NewBufferLogger(io.MultiWriter(&planOutput, r.logger))
+		}
+
+		exitCode, err := r.execTerraformWithLogger(logger, args...)
+		r.planOutput = planOutput.String()
 		if exitCode == terraformHasChangesExitCode {
 			r.changesInPlan = PlanHasChanges
 			hasDestructiveChanges, err := r.checkPlanDestructiveChanges(tmpFile.Name())
@@ -493,12 +541,13 @@ func (r *Runner) Destroy() error {
 	}
 
 	if r.changeSettings.AutoDismissDestructive {
-		log.InfoLn("terraform destroy skipped")
+		r.getLogger().LogInfoLn("terraform destroy skipped")
 		return nil
 	}
 
 	if !r.changeSettings.AutoApprove {
-		if !r.confirm().WithMessage("Do you want to DELETE objects from the cloud?").Ask() {
+		confirm := r.confirm().WithAction(input.ActionTerraformDestroy).WithStep(r.step).WithMessage("Do you want to DELETE objects from the cloud?")
+		if !confirm.Ask() {
 			return fmt.Errorf("terraform destroy aborted")
 		}
 	}
@@ -508,7 +557,7 @@ func (r *Runner) Destroy() error {
 		return err
 	}
 
-	return log.Process("default", "terraform destroy ...", func() error {
+	return log.ProcessWithLogger(r.getLogger(), "default", "terraform destroy ...", func() error {
 		err := r.stateSaver.Start(r)
 		if err != nil {
 			return err
@@ -545,7 +594,7 @@ func (r *Runner) ResourcesQuantityInState() int {
 
 	data, err := ioutil.ReadFile(r.statePath)
 	if err != nil {
-		log.ErrorLn(err)
+		r.getLogger().LogErrorLn(err)
 		return 0
 	}
 
@@ -554,7 +603,7 @@ func (r *Runner) ResourcesQuantityInState() int {
 	}
 	err = json.Unmarshal(data, &st)
 	if err != nil {
-		log.ErrorLn(err)
+		r.getLogger().LogErrorLn(err)
 		return 0
 	}
 
@@ -584,6 +633,10 @@ func (r *Runner) Stop() {
 }
 
 func (r *Runner) execTerraform(args ...string) (int, error) {
+	return r.execTerraformWithLogger(r.getLogger(), args...)
+}
+
+func (r *Runner) execTerraformWithLogger(logger log.Logger, args ...string) (int, error) {
 	if r.checkTerraformIsRunning() {
 		return 0, fmt.Errorf("Terraform have been already executed.")
 	}
@@ -591,8 +644,8 @@ func (r *Runner) execTerraform(args ...string) (int, error) {
 	r.switchTerraformIsRunning()
 	defer r.switchTerraformIsRunning()
 
-	exitCode, err := r.terraformExecutor.Exec(args...)
-	log.InfoF("Terraform runner %q process exited.\n", r.step)
+	exitCode, err := r.terraformExecutor.Exec(logger, args...)
+	r.getLogger().LogInfoF("Terraform runner %q process exited.\n", r.step)
 
 	return exitCode, err
 }
@@ -16,13 +16,16 @@ package terraform
 
 import (
 	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/deckhouse/deckhouse/dhctl/pkg/app"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/kubernetes/client"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/state"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/util/cache"
 	"github.com/deckhouse/deckhouse/dhctl/pkg/util/input"
@@ -179,7 +182,7 @@ type sleepExecutor struct {
 func (s *sleepExecutor) Output(_ ...string) ([]byte, error) {
 	return nil, nil
 }
-func (s *sleepExecutor) Exec(_ ...string) (int, error) {
+func (s *sleepExecutor) Exec(_ log.Logger, _ ...string) (int, error) {
 	ticker := time.NewTicker(time.Second)
 loop:
 	for {
@@ -210,3 +213,11 @@ func TestConcurrentExec(t *testing.T) {
 
 	require.Equal(t, "Terraform have been already executed.", err.Error())
 }
+
+func TestCMDExecutorDataDir(t *testing.T) {
+	first := NewCMDExecutor()
+	second := NewCMDExecutor()
+
+	require.NotEqual(t, first.dataDir, second.dataDir)
+	require.Equal(t, filepath.Join(app.TmpDirName, "tf_dhctl"), filepath.Dir(first.dataDir))
+}
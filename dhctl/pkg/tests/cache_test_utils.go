@@ -15,6 +15,7 @@
 package tests
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -110,3 +111,27 @@ func RunStateCacheTests(t *testing.T, stateCache state.Cache) {
 	stateCache.CleanWithExceptions("b")
 	assertCacheKeys(t, stateCache, []string{".tombstone", "b"})
 }
+
+// RunStateCacheLockTests checks that two caches pointed to the same storage cannot be locked simultaneously.
+func RunStateCacheLockTests(t *testing.T, first, second state.Locker) {
+	firstInfo := state.NewLockInfo("first")
+	secondInfo := state.NewLockInfo("second")
+
+	err := first.Lock(firstInfo)
+	require.NoError(t, err)
+
+	err = second.Lock(secondInfo)
+	require.Error(t, err)
+
+	var lockedErr *state.ErrLocked
+	require.True(t, errors.As(err, &lockedErr))
+	require.Equal(t, "first", lockedErr.Holder.Owner)
+
+	// unlock of not acquired lock does nothing
+	require.NoError(t, second.Unlock())
+
+	require.NoError(t, first.Unlock())
+
+	require.NoError(t, second.Lock(secondInfo))
+	require.NoError(t, second.Unlock())
+}
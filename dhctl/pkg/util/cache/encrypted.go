@@ -0,0 +1,363 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/state"
+)
+
+const (
+	encryptedCacheSaltKey  = ".dhctl-salt"
+	encryptedCacheCheckKey = ".dhctl-check"
+	encryptedCacheCheckVal = "dhctl-encrypted-cache"
+
+	encryptedCacheKeyLen = 32
+)
+
+var ErrWrongPassphrase = errors.New("can't decrypt the cache, probably the passphrase is wrong")
+
+// EncryptedStateCache stores every key in a separate file in the directory encrypted with AES-256-GCM.
+// The encryption key is derived from the passphrase with scrypt.
+// Terraform works with plain files, so GetPath returns paths in the temporary directory
+// and every intermediate state is saved back into the encrypted directory.
+type EncryptedStateCache struct {
+	dir    string
+	tmpDir string
+
+	aead cipher.AEAD
+	lock *state.LockInfo
+}
+
+func NewEncryptedStateCache(dir, tmpDir string, passphrase []byte) (*EncryptedStateCache, error) {
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase for the encrypted cache is empty")
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("can't create cache directory: %w", err)
+	}
+
+	if err := os.MkdirAll(tmpDir, 0o700); err != nil {
+		return nil, fmt.Errorf("can't create temporary cache directory: %w", err)
+	}
+
+	salt, err := loadOrCreateSalt(filepath.Join(dir, encryptedCacheSaltKey))
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := scrypt.Key(passphrase, salt, 1<<15, 8, 1, encryptedCacheKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("can't derive encryption key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &EncryptedStateCache{dir: dir, tmpDir: tmpDir, aead: aead}
+
+	if err := c.verifyPassphrase(); err != nil {
+		return nil, err
+	}
+
+	hasTombstone, err := c.InCache(state.TombstoneKey)
+	if err != nil {
+		return nil, err
+	}
+	if hasTombstone {
+		return nil, fmt.Errorf("cache %s marked as exhausted", dir)
+	}
+
+	return c, nil
+}
+
+func loadOrCreateSalt(path string) ([]byte, error) {
+	salt, err := ioutil.ReadFile(path)
+	if err == nil {
+		return salt, nil
+	}
+
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("can't read cache salt: %w", err)
+	}
+
+	salt = make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	if err := ioutil.WriteFile(path, salt, 0o600); err != nil {
+		return nil, fmt.Errorf("can't save cache salt: %w", err)
+	}
+
+	return salt, nil
+}
+
+// verifyPassphrase checks that the passphrase is the same as for the previous runs.
+func (s *EncryptedStateCache) verifyPassphrase() error {
+	data, err := s.Load(encryptedCacheCheckKey)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s.Save(encryptedCacheCheckKey, []byte(encryptedCacheCheckVal))
+		}
+		return err
+	}
+
+	if string(data) != encryptedCacheCheckVal {
+		return ErrWrongPassphrase
+	}
+
+	return nil
+}
+
+func (s *EncryptedStateCache) encrypt(content []byte) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return s.aead.Seal(nonce, nonce, content, nil), nil
+}
+
+func (s *EncryptedStateCache) decrypt(content []byte) ([]byte, error) {
+	nonceSize := s.aead.NonceSize()
+	if len(content) < nonceSize {
+		return nil, ErrWrongPassphrase
+	}
+
+	res, err := s.aead.Open(nil, content[:nonceSize], content[nonceSize:], nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	return res, nil
+}
+
+func (s *EncryptedStateCache) filePath(name string) string {
+	return filepath.Join(s.dir, name)
+}
+
+func isInternalKey(name string) bool {
+	return strings.HasPrefix(name, ".dhctl-")
+}
+
+// Save encrypts content and saves it to a file
+func (s *EncryptedStateCache) Save(name string, content []byte) error {
+	data, err := s.encrypt(content)
+	if err != nil {
+		return fmt.Errorf("can't encrypt %s: %w", name, err)
+	}
+
+	// write to the temporary file and rename it to prevent corrupted files on interruption
+	tmpPath := s.filePath(name) + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, data, 0o600); err != nil {
+		return fmt.Errorf("can't save %s in cache: %w", name, err)
+	}
+
+	return os.Rename(tmpPath, s.filePath(name))
+}
+
+// SaveStruct saves go struct into the cache as a blob
+func (s *EncryptedStateCache) SaveStruct(name string, v interface{}) error {
+	b := new(bytes.Buffer)
+	err := gob.NewEncoder(b).Encode(v)
+	if err != nil {
+		return err
+	}
+
+	return s.Save(name, b.Bytes())
+}
+
+func (s *EncryptedStateCache) Load(name string) ([]byte, error) {
+	data, err := ioutil.ReadFile(s.filePath(name))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.decrypt(data)
+}
+
+// LoadStruct loads go struct from the cache
+func (s *EncryptedStateCache) LoadStruct(name string, v interface{}) error {
+	d, err := s.Load(name)
+	if err != nil {
+		return fmt.Errorf("can't load struct for key %s: %v", name, err)
+	}
+
+	return gob.NewDecoder(bytes.NewBuffer(d)).Decode(v)
+}
+
+func (s *EncryptedStateCache) InCache(name string) (bool, error) {
+	info, err := os.Stat(s.filePath(name))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !info.IsDir(), nil
+}
+
+func (s *EncryptedStateCache) Delete(name string) {
+	_ = os.Remove(s.filePath(name))
+	_ = os.Remove(s.GetPath(name))
+}
+
+func (s *EncryptedStateCache) keys() ([]string, error) {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("can't iterate the cache: %w", err)
+	}
+
+	keys := make([]string, 0, len(files))
+	for _, f := range files {
+		if f.IsDir() || isInternalKey(f.Name()) || strings.HasSuffix(f.Name(), ".tmp") {
+			continue
+		}
+		keys = append(keys, f.Name())
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (s *EncryptedStateCache) Clean() {
+	s.CleanWithExceptions()
+}
+
+func (s *EncryptedStateCache) CleanWithExceptions(excludeKeys ...string) {
+	excludeKeysSet := map[string]struct{}{}
+	for _, k := range excludeKeys {
+		excludeKeysSet[k] = struct{}{}
+	}
+
+	keys, err := s.keys()
+	if err != nil {
+		log.WarnF("Can't getting keys to remove: %s ...\n", err)
+		return
+	}
+
+	// yes first write tombstone that is idempotent
+	if err := s.Save(state.TombstoneKey, []byte("yes")); err != nil {
+		log.WarnF("Can't mark the cache as exhausted: %s ...\n", err)
+	}
+
+	for _, key := range keys {
+		if _, ok := excludeKeysSet[key]; ok || key == state.TombstoneKey {
+			continue
+		}
+		s.Delete(key)
+	}
+}
+
+// GetPath returns the path to the plain file in the temporary directory.
+func (s *EncryptedStateCache) GetPath(name string) string {
+	return filepath.Join(s.tmpDir, name)
+}
+
+func (s *EncryptedStateCache) Iterate(iterFunc func(string, []byte) error) error {
+	keys, err := s.keys()
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		content, err := s.Load(key)
+		if err != nil {
+			return fmt.Errorf("can't read %s: %w", key, err)
+		}
+
+		if err := iterFunc(key, content); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *EncryptedStateCache) NeedIntermediateSave() bool {
+	// terraform writes plain state in tmp dir, need to encrypt every intermediate state
+	return true
+}
+
+func (s *EncryptedStateCache) Lock(info state.LockInfo) error {
+	f, err := os.OpenFile(s.filePath(state.LockKey), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		if os.IsExist(err) {
+			data, readErr := ioutil.ReadFile(s.filePath(state.LockKey))
+			if readErr != nil {
+				return fmt.Errorf("can't read cache lock: %w", readErr)
+			}
+			holder, _ := state.ParseLockInfo(data)
+			return &state.ErrLocked{Holder: holder}
+		}
+		return fmt.Errorf("can't lock cache: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(info.Marshal()); err != nil {
+		return fmt.Errorf("can't lock cache: %w", err)
+	}
+
+	s.lock = &info
+	return nil
+}
+
+func (s *EncryptedStateCache) Unlock() error {
+	if s.lock == nil {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(s.filePath(state.LockKey))
+	if err != nil {
+		return fmt.Errorf("can't read cache lock: %w", err)
+	}
+
+	holder, err := state.ParseLockInfo(data)
+	if err != nil {
+		return fmt.Errorf("can't parse cache lock: %w", err)
+	}
+
+	if !holder.Equal(*s.lock) {
+		return fmt.Errorf("cache lock is held by another process (%s)", holder)
+	}
+
+	s.lock = nil
+	return os.Remove(s.filePath(state.LockKey))
+}
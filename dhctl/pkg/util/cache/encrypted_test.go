@@ -0,0 +1,83 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/tests"
+)
+
+func TestEncryptedStateCache(t *testing.T) {
+	log.InitLogger("simple")
+
+	dir, err := ioutil.TempDir(os.TempDir(), "dhctl-test-encrypted-cache-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cacheDir := filepath.Join(dir, "cache")
+	tmpDir := filepath.Join(dir, "tmp")
+
+	t.Run("Save load delete operations", func(t *testing.T) {
+		stateCache, err := NewEncryptedStateCache(cacheDir, tmpDir, []byte("passphrase"))
+		require.NoError(t, err)
+
+		tests.RunStateCacheTests(t, stateCache)
+	})
+
+	t.Run("Content is encrypted on disk", func(t *testing.T) {
+		dir := filepath.Join(dir, "plain")
+		stateCache, err := NewEncryptedStateCache(dir, tmpDir, []byte("passphrase"))
+		require.NoError(t, err)
+
+		err = stateCache.Save("node.tfstate", []byte("secret-state"))
+		require.NoError(t, err)
+
+		raw, err := ioutil.ReadFile(filepath.Join(dir, "node.tfstate"))
+		require.NoError(t, err)
+		require.NotContains(t, string(raw), "secret-state")
+
+		require.Equal(t, filepath.Join(tmpDir, "node.tfstate"), stateCache.GetPath("node.tfstate"))
+	})
+
+	t.Run("Wrong passphrase", func(t *testing.T) {
+		dir := filepath.Join(dir, "wrong")
+		_, err := NewEncryptedStateCache(dir, tmpDir, []byte("passphrase"))
+		require.NoError(t, err)
+
+		_, err = NewEncryptedStateCache(dir, tmpDir, []byte("another"))
+		require.True(t, errors.Is(err, ErrWrongPassphrase))
+
+		_, err = NewEncryptedStateCache(dir, tmpDir, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("Lock", func(t *testing.T) {
+		dir := filepath.Join(dir, "lock")
+		first, err := NewEncryptedStateCache(dir, tmpDir, []byte("passphrase"))
+		require.NoError(t, err)
+		second, err := NewEncryptedStateCache(dir, tmpDir, []byte("passphrase"))
+		require.NoError(t, err)
+
+		tests.RunStateCacheLockTests(t, first, second)
+	})
+}
@@ -0,0 +1,236 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/state"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/util/s3"
+)
+
+// S3StateCache stores every key as a separate object in the S3-compatible bucket.
+// Terraform works with plain files, so GetPath returns paths in the temporary directory
+// and every intermediate state is uploaded to the bucket.
+type S3StateCache struct {
+	client *s3.Client
+	prefix string
+	tmpDir string
+
+	lock *state.LockInfo
+}
+
+func NewS3StateCache(client *s3.Client, prefix, tmpDir string) (*S3StateCache, error) {
+	if err := os.MkdirAll(tmpDir, 0o700); err != nil {
+		return nil, fmt.Errorf("can't create temporary cache directory: %w", err)
+	}
+
+	c := &S3StateCache{
+		client: client,
+		prefix: strings.Trim(prefix, "/"),
+		tmpDir: tmpDir,
+	}
+
+	hasTombstone, err := c.InCache(state.TombstoneKey)
+	if err != nil {
+		return nil, err
+	}
+	if hasTombstone {
+		return nil, fmt.Errorf("cache %s marked as exhausted", c.prefix)
+	}
+
+	return c, nil
+}
+
+func (s *S3StateCache) objectKey(name string) string {
+	return path.Join(s.prefix, name)
+}
+
+func (s *S3StateCache) listPrefix() string {
+	if s.prefix == "" {
+		return ""
+	}
+	return s.prefix + "/"
+}
+
+func (s *S3StateCache) Save(name string, content []byte) error {
+	if err := s.client.PutObject(s.objectKey(name), content, false); err != nil {
+		return fmt.Errorf("can't save %s in cache: %w", name, err)
+	}
+	return nil
+}
+
+// SaveStruct saves go struct into the cache as a blob
+func (s *S3StateCache) SaveStruct(name string, v interface{}) error {
+	b := new(bytes.Buffer)
+	err := gob.NewEncoder(b).Encode(v)
+	if err != nil {
+		return err
+	}
+
+	return s.Save(name, b.Bytes())
+}
+
+func (s *S3StateCache) Load(name string) ([]byte, error) {
+	return s.client.GetObject(s.objectKey(name))
+}
+
+// LoadStruct loads go struct from the cache
+func (s *S3StateCache) LoadStruct(name string, v interface{}) error {
+	d, err := s.Load(name)
+	if err != nil {
+		return fmt.Errorf("can't load struct for key %s: %v", name, err)
+	}
+
+	return gob.NewDecoder(bytes.NewBuffer(d)).Decode(v)
+}
+
+func (s *S3StateCache) InCache(name string) (bool, error) {
+	return s.client.HeadObject(s.objectKey(name))
+}
+
+func (s *S3StateCache) Delete(name string) {
+	if err := s.client.DeleteObject(s.objectKey(name)); err != nil {
+		log.ErrorF("Cannot delete cache %s: %v\n", name, err)
+	}
+	_ = os.Remove(s.GetPath(name))
+}
+
+func (s *S3StateCache) keys() ([]string, error) {
+	objects, err := s.client.ListObjects(s.listPrefix())
+	if err != nil {
+		return nil, fmt.Errorf("can't iterate the cache: %w", err)
+	}
+
+	keys := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		key := strings.TrimPrefix(obj, s.listPrefix())
+		// skip nested "directories" and the lock
+		if key == "" || strings.Contains(key, "/") || key == state.LockKey {
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+func (s *S3StateCache) Clean() {
+	s.CleanWithExceptions()
+}
+
+func (s *S3StateCache) CleanWithExceptions(excludeKeys ...string) {
+	excludeKeysSet := map[string]struct{}{}
+	for _, k := range excludeKeys {
+		excludeKeysSet[k] = struct{}{}
+	}
+
+	keys, err := s.keys()
+	if err != nil {
+		log.WarnF("Can't getting keys to remove: %s ...\n", err)
+		return
+	}
+
+	// yes first write tombstone that is idempotent
+	if err := s.Save(state.TombstoneKey, []byte("yes")); err != nil {
+		log.WarnF("Can't mark the cache as exhausted: %s ...\n", err)
+	}
+
+	for _, key := range keys {
+		if _, ok := excludeKeysSet[key]; ok || key == state.TombstoneKey {
+			continue
+		}
+		s.Delete(key)
+	}
+}
+
+// GetPath returns the path to the plain file in the temporary directory.
+func (s *S3StateCache) GetPath(name string) string {
+	return filepath.Join(s.tmpDir, name)
+}
+
+func (s *S3StateCache) Iterate(iterFunc func(string, []byte) error) error {
+	keys, err := s.keys()
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		content, err := s.Load(key)
+		if err != nil {
+			return fmt.Errorf("can't read %s: %w", key, err)
+		}
+
+		if err := iterFunc(key, content); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *S3StateCache) NeedIntermediateSave() bool {
+	// cache is stored in the bucket, need sync every intermediate states
+	return true
+}
+
+func (s *S3StateCache) Lock(info state.LockInfo) error {
+	err := s.client.PutObject(s.objectKey(state.LockKey), info.Marshal(), true)
+	if errors.Is(err, s3.ErrPreconditionFailed) {
+		data, readErr := s.client.GetObject(s.objectKey(state.LockKey))
+		if readErr != nil {
+			return fmt.Errorf("can't read cache lock: %w", readErr)
+		}
+		holder, _ := state.ParseLockInfo(data)
+		return &state.ErrLocked{Holder: holder}
+	}
+	if err != nil {
+		return fmt.Errorf("can't lock cache: %w", err)
+	}
+
+	s.lock = &info
+	return nil
+}
+
+func (s *S3StateCache) Unlock() error {
+	if s.lock == nil {
+		return nil
+	}
+
+	data, err := s.client.GetObject(s.objectKey(state.LockKey))
+	if err != nil {
+		return fmt.Errorf("can't read cache lock: %w", err)
+	}
+
+	holder, err := state.ParseLockInfo(data)
+	if err != nil {
+		return fmt.Errorf("can't parse cache lock: %w", err)
+	}
+
+	if !holder.Equal(*s.lock) {
+		return fmt.Errorf("cache lock is held by another process (%s)", holder)
+	}
+
+	s.lock = nil
+	return s.client.DeleteObject(s.objectKey(state.LockKey))
+}
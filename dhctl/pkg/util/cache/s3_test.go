@@ -0,0 +1,63 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/tests"
+	"github.com/deckhouse/deckhouse/dhctl/pkg/util/s3/s3test"
+)
+
+func TestS3StateCache(t *testing.T) {
+	log.InitLogger("simple")
+
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "dhctl-test-s3-cache-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	server := s3test.NewServer()
+	defer server.Close()
+
+	t.Run("Save load delete operations", func(t *testing.T) {
+		stateCache, err := NewS3StateCache(server.Client("bucket"), "clusters/test", tmpDir)
+		require.NoError(t, err)
+
+		tests.RunStateCacheTests(t, stateCache)
+
+		objects := server.Objects()
+		require.Contains(t, objects, "bucket/clusters/test/b")
+		require.Contains(t, objects, "bucket/clusters/test/.tombstone")
+	})
+
+	t.Run("Exhausted cache", func(t *testing.T) {
+		_, err := NewS3StateCache(server.Client("bucket"), "clusters/test", tmpDir)
+		require.Error(t, err)
+	})
+
+	t.Run("Lock", func(t *testing.T) {
+		first, err := NewS3StateCache(server.Client("bucket"), "clusters/lock", tmpDir)
+		require.NoError(t, err)
+		second, err := NewS3StateCache(server.Client("bucket"), "clusters/lock", tmpDir)
+		require.NoError(t, err)
+
+		tests.RunStateCacheLockTests(t, first, second)
+	})
+}
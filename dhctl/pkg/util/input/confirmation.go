@@ -19,19 +19,45 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 
 	"golang.org/x/crypto/ssh/terminal"
 
 	"github.com/deckhouse/deckhouse/dhctl/pkg/log"
 )
 
+// Actions of confirmations. They are used to match confirmations in the policy file.
+const (
+	ActionUnknown                    = "unknown"
+	ActionTerraformChange            = "terraform-change"
+	ActionTerraformDestructiveChange = "terraform-destructive-change"
+	ActionTerraformDestroy           = "terraform-destroy"
+	ActionUseCachedState             = "use-cached-state"
+	ActionCreateAllNodes             = "create-all-nodes"
+	ActionNodeTemplateChange         = "node-template-change"
+	ActionMasterQuorumLoss           = "master-quorum-loss"
+	ActionControlPlaneCheck          = "control-plane-check"
+	ActionSSHHosts                   = "ssh-hosts"
+	ActionReleaseLock                = "release-lock"
+	ActionSkipMachinesDeletion       = "skip-machines-deletion"
+)
+
+// askMutex serializes questions asked from concurrently converged nodes.
+var askMutex sync.Mutex
+
 type Confirmation struct {
 	message       string
+	details       string
 	defaultAnswer bool
+
+	action    string
+	step      string
+	nodeGroup string
+	node      string
 }
 
 func NewConfirmation() *Confirmation {
-	return &Confirmation{message: "Should we proceed?"}
+	return &Confirmation{message: "Should we proceed?", action: ActionUnknown}
 }
 
 func (c *Confirmation) WithYesByDefault() *Confirmation {
@@ -44,11 +70,103 @@ func (c *Confirmation) WithMessage(m string) *Confirmation {
 	return c
 }
 
+// WithDetails sets the text which is printed right before the question, e.g. the terraform plan
+// whose output was buffered with logs of the node.
+func (c *Confirmation) WithDetails(d string) *Confirmation {
+	c.details = d
+	return c
+}
+
+func (c *Confirmation) WithAction(action string) *Confirmation {
+	c.action = action
+	return c
+}
+
+func (c *Confirmation) WithStep(step string) *Confirmation {
+	c.step = step
+	return c
+}
+
+func (c *Confirmation) WithNodeGroup(nodeGroup string) *Confirmation {
+	c.nodeGroup = nodeGroup
+	return c
+}
+
+func (c *Confirmation) WithNode(node string) *Confirmation {
+	c.node = node
+	return c
+}
+
+func (c *Confirmation) subject() string {
+	subject := "action=" + c.action
+	if c.step != "" {
+		subject += " step=" + c.step
+	}
+	if c.nodeGroup != "" {
+		subject += " nodeGroup=" + c.nodeGroup
+	}
+	if c.node != "" {
+		subject += " node=" + c.node
+	}
+	return subject
+}
+
 func (c *Confirmation) Ask() bool {
-	if !terminal.IsTerminal(int(os.Stdin.Fd())) {
+	policy := getPolicy()
+	interactive := policy == nil && terminal.IsTerminal(int(os.Stdin.Fd()))
+	log.EmitEvent(log.Event{
+		Type:          log.EventConfirmationRequested,
+		Message:       c.message,
+		DefaultAnswer: &c.defaultAnswer,
+		Interactive:   &interactive,
+	})
+
+	var answer bool
+	if policy != nil {
+		answer = c.askPolicy(policy)
+	} else {
+		answer = c.ask(interactive)
+	}
+
+	log.EmitEvent(log.Event{
+		Type:        log.EventConfirmationAnswered,
+		Message:     c.message,
+		Answer:      &answer,
+		Interactive: &interactive,
+	})
+
+	return answer
+}
+
+// askPolicy answers the confirmation by the policy and fails closed if no rule is matched.
+func (c *Confirmation) askPolicy(policy *Policy) bool {
+	decision := policy.Decide(c)
+	if !decision.Matched {
+		log.WarnF("%s\nConfirmation [%s] is denied: no matching rule in the confirmation policy\n", c.message, c.subject())
+		return false
+	}
+
+	answer := PolicyAnswerDeny
+	if decision.Answer {
+		answer = PolicyAnswerAllow
+	}
+	log.InfoF("%s\nConfirmation [%s]: %s by rule #%d of the confirmation policy\n", c.message, c.subject(), answer, decision.Rule)
+
+	return decision.Answer
+}
+
+func (c *Confirmation) ask(interactive bool) bool {
+	if !interactive {
 		return c.defaultAnswer
 	}
 
+	askMutex.Lock()
+	defer askMutex.Unlock()
+
+	if c.details != "" {
+		log.InfoF("%s\n", strings.TrimRight(c.details, "\n"))
+	}
+
 	reader := bufio.NewReader(os.Stdin)
 	for {
 		log.WarnF(fmt.Sprintf("%s [y/n]: ", c.message))
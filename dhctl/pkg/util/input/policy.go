@@ -0,0 +1,139 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package input
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	PolicyAnswerAllow = "allow"
+	PolicyAnswerDeny  = "deny"
+)
+
+// PolicyRule matches a confirmation by its action, terraform step, NodeGroup and node.
+// Empty fields match any value, other fields are shell patterns.
+type PolicyRule struct {
+	Action    string `json:"action"`
+	Step      string `json:"step,omitempty"`
+	NodeGroup string `json:"nodeGroup,omitempty"`
+	Node      string `json:"node,omitempty"`
+	Answer    string `json:"answer"`
+}
+
+func (r *PolicyRule) validate() error {
+	if r.Action == "" {
+		return fmt.Errorf("action is required, use '*' to match any action")
+	}
+
+	if r.Answer != PolicyAnswerAllow && r.Answer != PolicyAnswerDeny {
+		return fmt.Errorf("answer should be %q or %q, got %q", PolicyAnswerAllow, PolicyAnswerDeny, r.Answer)
+	}
+
+	for _, pattern := range []string{r.Action, r.Step, r.NodeGroup, r.Node} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("bad pattern %q: %v", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *PolicyRule) matches(c *Confirmation) bool {
+	return matchPattern(r.Action, c.action) &&
+		matchPattern(r.Step, c.step) &&
+		matchPattern(r.NodeGroup, c.nodeGroup) &&
+		matchPattern(r.Node, c.node)
+}
+
+func matchPattern(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+
+	ok, _ := path.Match(pattern, value)
+	return ok
+}
+
+// Policy answers confirmations without a user. The first matched rule wins, unmatched confirmations are denied.
+type Policy struct {
+	Rules []PolicyRule `json:"rules"`
+}
+
+type PolicyDecision struct {
+	Answer  bool
+	Matched bool
+	// Rule is a number of the matched rule starting from 1.
+	Rule int
+}
+
+func (p *Policy) Decide(c *Confirmation) PolicyDecision {
+	for i := range p.Rules {
+		if p.Rules[i].matches(c) {
+			return PolicyDecision{Answer: p.Rules[i].Answer == PolicyAnswerAllow, Matched: true, Rule: i + 1}
+		}
+	}
+
+	return PolicyDecision{}
+}
+
+func ParsePolicy(data []byte) (*Policy, error) {
+	var policy Policy
+	if err := yaml.UnmarshalStrict(data, &policy); err != nil {
+		return nil, fmt.Errorf("parse confirmation policy: %v", err)
+	}
+
+	for i := range policy.Rules {
+		if err := policy.Rules[i].validate(); err != nil {
+			return nil, fmt.Errorf("confirmation policy rule #%d: %v", i+1, err)
+		}
+	}
+
+	return &policy, nil
+}
+
+func LoadPolicyFile(path string) (*Policy, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read confirmation policy: %v", err)
+	}
+
+	return ParsePolicy(data)
+}
+
+var (
+	policyMutex  sync.RWMutex
+	activePolicy *Policy
+)
+
+// SetPolicy makes all confirmations answered by the policy. Nil policy returns interactive confirmations.
+func SetPolicy(p *Policy) {
+	policyMutex.Lock()
+	defer policyMutex.Unlock()
+
+	activePolicy = p
+}
+
+func getPolicy() *Policy {
+	policyMutex.RLock()
+	defer policyMutex.RUnlock()
+
+	return activePolicy
+}
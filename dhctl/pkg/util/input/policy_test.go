@@ -0,0 +1,120 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package input
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `
+rules:
+- action: terraform-change
+  nodeGroup: worker
+  answer: allow
+- action: terraform-destructive-change
+  node: test-worker-2
+  answer: allow
+- action: terraform-destructive-change
+  nodeGroup: worker
+  answer: deny
+- action: use-cached-state
+  step: base-*
+  answer: allow
+`
+
+func TestParsePolicy(t *testing.T) {
+	t.Run("Valid policy", func(t *testing.T) {
+		policy, err := ParsePolicy([]byte(testPolicy))
+		require.NoError(t, err)
+		require.Len(t, policy.Rules, 4)
+	})
+
+	for name, data := range map[string]string{
+		"Without action":    "rules: [{answer: allow}]",
+		"Wrong answer":      "rules: [{action: '*', answer: 'yes'}]",
+		"Bad pattern":       "rules: [{action: '[', answer: allow}]",
+		"Unknown field":     "rules: [{action: '*', answer: allow, nodes: a}]",
+		"Malformed content": "rules: {}",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParsePolicy([]byte(data))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestPolicyDecide(t *testing.T) {
+	policy, err := ParsePolicy([]byte(testPolicy))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		confirmation *Confirmation
+		expected     PolicyDecision
+	}{
+		{
+			name:         "Changes for the NodeGroup are allowed",
+			confirmation: NewConfirmation().WithAction(ActionTerraformChange).WithNodeGroup("worker").WithNode("test-worker-0"),
+			expected:     PolicyDecision{Answer: true, Matched: true, Rule: 1},
+		},
+		{
+			name:         "Destructive changes are allowed for the node only",
+			confirmation: NewConfirmation().WithAction(ActionTerraformDestructiveChange).WithNodeGroup("worker").WithNode("test-worker-2"),
+			expected:     PolicyDecision{Answer: true, Matched: true, Rule: 2},
+		},
+		{
+			name:         "Destructive changes are denied for other nodes",
+			confirmation: NewConfirmation().WithAction(ActionTerraformDestructiveChange).WithNodeGroup("worker").WithNode("test-worker-0"),
+			expected:     PolicyDecision{Answer: false, Matched: true, Rule: 3},
+		},
+		{
+			name:         "Step pattern",
+			confirmation: NewConfirmation().WithAction(ActionUseCachedState).WithStep("base-infrastructure"),
+			expected:     PolicyDecision{Answer: true, Matched: true, Rule: 4},
+		},
+		{
+			name:         "Changes for other NodeGroups are not matched",
+			confirmation: NewConfirmation().WithAction(ActionTerraformChange).WithNodeGroup("system"),
+			expected:     PolicyDecision{},
+		},
+		{
+			name:         "Confirmations without action are not matched",
+			confirmation: NewConfirmation(),
+			expected:     PolicyDecision{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, policy.Decide(tc.confirmation))
+		})
+	}
+}
+
+func TestAskWithPolicy(t *testing.T) {
+	policy, err := ParsePolicy([]byte(testPolicy))
+	require.NoError(t, err)
+
+	SetPolicy(policy)
+	defer SetPolicy(nil)
+
+	require.True(t, NewConfirmation().WithAction(ActionTerraformChange).WithNodeGroup("worker").Ask())
+	require.False(t, NewConfirmation().WithAction(ActionTerraformDestructiveChange).WithNodeGroup("worker").Ask())
+
+	// fails closed even if the default answer is yes
+	require.False(t, NewConfirmation().WithAction(ActionUseCachedState).WithYesByDefault().Ask())
+}
@@ -36,6 +36,20 @@ func setupTests(attemptsQuantity *int, wait *time.Duration) {
 	}
 }
 
+// TimeoutError is returned when all attempts of the loop are failed.
+type TimeoutError struct {
+	Name string
+	Err  error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("timeout while %q: last error: %v", e.Name, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
 type BreakPredicate func(err error) bool
 
 func IsErr(err error) BreakPredicate {
@@ -118,13 +132,21 @@ func (l *Loop) Run(task func() error) error {
 			l.logger.LogFail(fmt.Sprintf(attemptMessage, i, l.attemptsQuantity, l.name, l.waitTime))
 			l.logger.LogInfoF("\tError: %v\n\n", err)
 
+			log.EmitEvent(log.Event{
+				Type:        log.EventRetryAttemptFailed,
+				Loop:        l.name,
+				Attempt:     i,
+				MaxAttempts: l.attemptsQuantity,
+				Error:       err.Error(),
+			})
+
 			// Do not waitTime after the last iteration.
 			if i < l.attemptsQuantity {
 				time.Sleep(l.waitTime)
 			}
 		}
 
-		return fmt.Errorf("timeout while %q: last error: %v", l.name, err)
+		return &TimeoutError{Name: l.name, Err: err}
 	}
 
 	return l.logger.LogProcess("default", l.name, loopBody)
@@ -0,0 +1,145 @@
+// Copyright 2022 Flant JSC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package s3test provides an in-memory S3-compatible server for tests.
+package s3test
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/deckhouse/deckhouse/dhctl/pkg/util/s3"
+)
+
+// Server is an in-memory S3-compatible server.
+// It supports path-style put, get, head, delete, list-type=2 requests and If-None-Match: * on put.
+type Server struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func NewServer() *Server {
+	f := &Server{objects: make(map[string][]byte)}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.handle))
+	return f
+}
+
+// Client returns the client configured to use the fake server.
+func (f *Server) Client(bucket string) *s3.Client {
+	c, err := s3.NewClient(s3.Config{
+		Endpoint:        f.URL,
+		Bucket:          bucket,
+		AccessKeyID:     "access",
+		SecretAccessKey: "secret",
+	})
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func (f *Server) Objects() map[string][]byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	res := make(map[string][]byte, len(f.objects))
+	for k, v := range f.objects {
+		res[k] = v
+	}
+	return res
+}
+
+func (f *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	// path is /bucket/key
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	key := parts[0] + "/"
+	if len(parts) > 1 {
+		key += parts[1]
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		if _, ok := f.objects[key]; ok && r.Header.Get("If-None-Match") == "*" {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		f.objects[key] = data
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		if r.URL.Query().Get("list-type") == "2" {
+			f.list(w, parts[0]+"/", r.URL.Query().Get("prefix"))
+			return
+		}
+		data, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	case http.MethodHead:
+		if _, ok := f.objects[key]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *Server) list(w http.ResponseWriter, bucket, prefix string) {
+	var result listBucketResult
+	keys := make([]string, 0)
+	for k := range f.objects {
+		if strings.HasPrefix(k, bucket+prefix) {
+			keys = append(keys, strings.TrimPrefix(k, bucket))
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		result.Contents = append(result.Contents, object{Key: k})
+	}
+
+	data, _ := xml.Marshal(result)
+	_, _ = w.Write(data)
+}
+
+type object struct {
+	Key string `xml:"Key"`
+}
+
+type listBucketResult struct {
+	XMLName  xml.Name `xml:"ListBucketResult"`
+	Contents []object `xml:"Contents"`
+}
@@ -102,6 +102,11 @@ func WaitShutdown() int {
 	return callbacks.exitCode
 }
 
+// ExitCode returns the exit code passed to Shutdown. It is used by teardown callbacks.
+func ExitCode() int {
+	return callbacks.exitCode
+}
+
 func IsInterrupted() bool {
 	select {
 	case <-callbacks.interruptedCh:
@@ -64,6 +64,10 @@ type Helper struct {
 	// DisableEviction forces drain to use delete rather than evict
 	DisableEviction bool
 
+	// DisableDeletionOnTimeout keeps pods that are not evicted before Timeout
+	// instead of deleting them
+	DisableDeletionOnTimeout bool
+
 	// SkipWaitForDeleteTimeoutSeconds ignores pods that have a
 	// DeletionTimeStamp > N seconds. It's up to the user to decide when this
 	// option is appropriate; examples include the Node is unready and the pods
@@ -80,6 +84,10 @@ type Helper struct {
 
 	// OnPodDeletedOrEvicted is called when a pod is evicted/deleted; for printing progress output
 	OnPodDeletedOrEvicted func(pod *corev1.Pod, usingEviction bool)
+
+	// OnPodEvictionBlocked is called when the eviction of a pod is rejected because of
+	// a PodDisruptionBudget; the eviction is retried
+	OnPodEvictionBlocked func(pod *corev1.Pod, err error)
 }
 
 type waitForDeleteParams struct {
@@ -254,11 +262,18 @@ func (d *Helper) evictPods(pods []corev1.Pod, policyGroupVersion string, getPodF
 				select {
 				case <-ctx.Done():
 					// return here or we'll leak a goroutine.
+					if d.DisableDeletionOnTimeout {
+						returnCh <- fmt.Errorf("error when evicting pods/%q -n %q: global timeout reached: %v", pod.Name, pod.Namespace, globalTimeout)
+						return
+					}
 					err := d.DeletePod(pod)
 					if err != nil {
 						returnCh <- fmt.Errorf("error when deleting pods/%q -n %q: global timeout reached: %v, err: %v", pod.Name, pod.Namespace, globalTimeout, err)
 						return
 					}
+					if d.OnPodDeletedOrEvicted != nil {
+						d.OnPodDeletedOrEvicted(&pod, false)
+					}
 					returnCh <- fmt.Errorf("error when evicting pods/%q -n %q: global timeout reached: %v, pod was deleted", pod.Name, pod.Namespace, globalTimeout)
 					return
 				default:
@@ -283,6 +298,9 @@ func (d *Helper) evictPods(pods []corev1.Pod, policyGroupVersion string, getPodF
 					returnCh <- nil
 					return
 				} else if apierrors.IsTooManyRequests(err) {
+					if d.OnPodEvictionBlocked != nil {
+						d.OnPodEvictionBlocked(&activePod, err)
+					}
 					fmt.Fprintf(d.ErrOut, "error when evicting pods/%q -n %q (will retry after %s): %v\n", activePod.Name, activePod.Namespace, PodEvictionRetryInterval.String(), err)
 					time.Sleep(PodEvictionRetryInterval)
 				} else if !activePod.ObjectMeta.DeletionTimestamp.IsZero() && apierrors.IsForbidden(err) && apierrors.HasStatusCause(err, corev1.NamespaceTerminatingCause) {
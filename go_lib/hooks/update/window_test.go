@@ -181,4 +181,155 @@ func TestNextAllowedWindow(t *testing.T) {
 		assert.Equal(t, time.Date(2021, 10, 17, 20, 00, 00, 0, time.UTC), res)
 		assert.Equal(t, time.Sunday, res.Weekday())
 	})
+
+	t.Run("window in time zone", func(t *testing.T) {
+		ws := Windows{
+			{
+				From:     "01:00",
+				To:       "03:00",
+				TimeZone: "Europe/Moscow",
+			},
+		}
+		// sunday 20:00 UTC, 23:00 in Moscow
+		min := time.Date(2021, 10, 17, 20, 00, 00, 0, time.UTC)
+
+		res := ws.NextAllowedTime(min)
+		// beginning of the window: monday 01:00 in Moscow
+		assert.Equal(t, time.Date(2021, 10, 17, 22, 00, 00, 0, time.UTC), res)
+	})
+
+	t.Run("one-off window", func(t *testing.T) {
+		ws := Windows{
+			{
+				From: "16:00",
+				To:   "18:00",
+				Days: []string{"wed"},
+			},
+			{
+				From: "10:00",
+				To:   "12:00",
+				Date: "2021-10-18",
+			},
+		}
+		// sunday 19:35
+		min := time.Date(2021, 10, 17, 19, 35, 00, 0, time.UTC)
+
+		res := ws.NextAllowedTime(min)
+		// beginning of the one-off window: monday 10:00
+		assert.Equal(t, time.Date(2021, 10, 18, 10, 00, 00, 0, time.UTC), res)
+
+		// after the one-off window
+		min = time.Date(2021, 10, 18, 12, 00, 00, 0, time.UTC)
+
+		res = ws.NextAllowedTime(min)
+		// beginning of the weekly window: wednesday 16:00
+		assert.Equal(t, time.Date(2021, 10, 20, 16, 00, 00, 0, time.UTC), res)
+	})
+
+	t.Run("window in blackout", func(t *testing.T) {
+		ws := Windows{
+			{
+				From: "16:00",
+				To:   "18:00",
+				Days: []string{"wed"},
+			},
+			{
+				Blackout: &DateRange{From: "2021-10-13", To: "2021-10-14"},
+			},
+			{
+				Blackout: &DateRange{From: "2021-10-15", To: "2021-10-20"},
+			},
+		}
+		// tuesday 16:35
+		min := time.Date(2021, 10, 12, 16, 35, 00, 0, time.UTC)
+
+		res := ws.NextAllowedTime(min)
+		// beginning of the window after adjoining blackouts: wednesday 16:00 a week later
+		assert.Equal(t, time.Date(2021, 10, 27, 16, 00, 00, 0, time.UTC), res)
+	})
+
+	t.Run("only blackout", func(t *testing.T) {
+		ws := Windows{
+			{
+				Blackout: &DateRange{From: "2021-10-13", To: "2021-10-14"},
+				TimeZone: "Europe/Moscow",
+			},
+		}
+		// wednesday 16:35
+		min := time.Date(2021, 10, 13, 16, 35, 00, 0, time.UTC)
+
+		res := ws.NextAllowedTime(min)
+		// the end of the blackout: friday 00:00 in Moscow
+		assert.Equal(t, time.Date(2021, 10, 14, 21, 00, 00, 0, time.UTC), res)
+	})
+}
+
+func TestIsAllowed(t *testing.T) {
+	ws := Windows{
+		{
+			From:     "08:00",
+			To:       "10:00",
+			Days:     []string{"mon"},
+			TimeZone: "America/New_York",
+		},
+		{
+			From: "08:00",
+			To:   "10:00",
+			Date: "2021-10-20",
+		},
+		{
+			Blackout: &DateRange{From: "2021-10-25", To: "2021-10-25"},
+			TimeZone: "America/New_York",
+		},
+	}
+
+	// monday 09:00 in New York
+	assert.True(t, ws.IsAllowed(time.Date(2021, 10, 18, 13, 00, 00, 0, time.UTC)))
+	// monday 09:00 UTC, 05:00 in New York
+	assert.False(t, ws.IsAllowed(time.Date(2021, 10, 18, 9, 00, 00, 0, time.UTC)))
+	// one-off window on wednesday
+	assert.True(t, ws.IsAllowed(time.Date(2021, 10, 20, 9, 00, 00, 0, time.UTC)))
+	assert.False(t, ws.IsAllowed(time.Date(2021, 10, 27, 9, 00, 00, 0, time.UTC)))
+	// monday 09:00 in New York during the blackout
+	assert.False(t, ws.IsAllowed(time.Date(2021, 10, 25, 13, 00, 00, 0, time.UTC)))
+
+	blackout := Windows{
+		{
+			Blackout: &DateRange{From: "2021-10-25", To: "2021-10-26"},
+		},
+	}
+	assert.False(t, blackout.IsAllowed(time.Date(2021, 10, 26, 23, 59, 00, 0, time.UTC)))
+	assert.True(t, blackout.IsAllowed(time.Date(2021, 10, 27, 0, 00, 00, 0, time.UTC)))
+}
+
+func TestFromJSON(t *testing.T) {
+	ws, err := FromJSON([]byte(`[{"from":"08:00","to":"10:00","timeZone":"Europe/Berlin"},{"blackout":{"from":"2021-12-24","to":"2022-01-02"}}]`))
+	assert.NoError(t, err)
+	assert.Len(t, ws, 2)
+	assert.Equal(t, "2022-01-02", ws[1].Blackout.To)
+
+	_, err = FromJSON([]byte(`[{"from":"08:00","to":"10:00","timeZone":"Mars/Olympus"}]`))
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	valid := Windows{
+		{From: "8:00", To: "10:00", Date: "2024-02-29", TimeZone: "Europe/Berlin"},
+		{Blackout: &DateRange{From: "2021-12-24", To: "2022-01-02"}},
+	}
+	assert.NoError(t, valid.Validate())
+
+	invalid := map[string]Window{
+		"time zone":      {From: "08:00", To: "10:00", TimeZone: "Mars/Olympus"},
+		"date":           {From: "08:00", To: "10:00", Date: "2022-02-30"},
+		"time":           {From: "08:00", To: "24:00"},
+		"blackout start": {Blackout: &DateRange{From: "2022-02-30", To: "2022-03-01"}},
+		"blackout end":   {Blackout: &DateRange{From: "2022-02-01", To: "2022-13-01"}},
+		"blackout range": {Blackout: &DateRange{From: "2022-02-02", To: "2022-02-01"}},
+	}
+	for name, window := range invalid {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, Windows{window}.Validate())
+		})
+	}
 }
@@ -0,0 +1,122 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/flant/addon-operator/pkg/module_manager/go_hook"
+	"github.com/flant/addon-operator/sdk"
+	"github.com/flant/shell-operator/pkg/kube_events_manager/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
+)
+
+// common part for filtering secrets with bashible statuses, the bashible-apiserver stores
+// BashibleStatus objects reported by nodes in these secrets
+
+// BashibleStatus results of the last bashible run on the node
+type BashibleStatus struct {
+	SecretName            string               `json:"secretName"`
+	Node                  string               `json:"node"`
+	NodeGroup             string               `json:"nodeGroup"`
+	ConfigurationChecksum string               `json:"configurationChecksum"`
+	Steps                 []BashibleStepStatus `json:"steps"`
+}
+
+// BashibleStepStatus result of the last execution of the bashible step
+type BashibleStepStatus struct {
+	Name       string          `json:"name"`
+	Checksum   string          `json:"checksum,omitempty"`
+	ExitCode   int32           `json:"exitCode"`
+	Duration   metav1.Duration `json:"duration,omitempty"`
+	Output     string          `json:"output,omitempty"`
+	FinishedAt metav1.Time     `json:"finishedAt,omitempty"`
+}
+
+// FailedStep returns the first failed step, bashible doesn't run steps after it
+func (s BashibleStatus) FailedStep() *BashibleStepStatus {
+	for i := range s.Steps {
+		if s.Steps[i].ExitCode != 0 {
+			return &s.Steps[i]
+		}
+	}
+
+	return nil
+}
+
+func BashibleStatusHookConfig() go_hook.KubernetesConfig {
+	return go_hook.KubernetesConfig{
+		Name:                   "bashible_statuses",
+		WaitForSynchronization: pointer.BoolPtr(false),
+		ApiVersion:             "v1",
+		Kind:                   "Secret",
+		NamespaceSelector: &types.NamespaceSelector{
+			NameSelector: &types.NameSelector{
+				MatchNames: []string{"d8-cloud-instance-manager"},
+			},
+		},
+		LabelSelector: &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{
+					Key:      "node.deckhouse.io/bashible-status",
+					Operator: metav1.LabelSelectorOpExists,
+				},
+			},
+		},
+		FilterFunc: filterBashibleStatusSecret,
+	}
+}
+
+func filterBashibleStatusSecret(obj *unstructured.Unstructured) (go_hook.FilterResult, error) {
+	var sec corev1.Secret
+
+	err := sdk.FromUnstructured(obj, &sec)
+	if err != nil {
+		return nil, err
+	}
+
+	var reported struct {
+		Metadata              metav1.ObjectMeta    `json:"metadata"`
+		NodeGroup             string               `json:"nodeGroup"`
+		ConfigurationChecksum string               `json:"configurationChecksum"`
+		Steps                 []BashibleStepStatus `json:"steps"`
+	}
+
+	err = json.Unmarshal(sec.Data["status"], &reported)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode bashible status from secret %s: %v", sec.Name, err)
+	}
+
+	// only the output of the failed step is used, outputs of other steps are dropped to keep snapshots small
+	for i := range reported.Steps {
+		if reported.Steps[i].ExitCode == 0 {
+			reported.Steps[i].Output = ""
+		}
+	}
+
+	return BashibleStatus{
+		SecretName:            sec.Name,
+		Node:                  reported.Metadata.Name,
+		NodeGroup:             reported.NodeGroup,
+		ConfigurationChecksum: reported.ConfigurationChecksum,
+		Steps:                 reported.Steps,
+	}, nil
+}
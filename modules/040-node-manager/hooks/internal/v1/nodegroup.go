@@ -17,6 +17,8 @@ limitations under the License.
 package v1
 
 import (
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -230,19 +232,88 @@ type Disruptions struct {
 	Automatic AutomaticDisruptions `json:"automatic,omitempty"`
 	// Extra settings for RolloutRestart mode.
 	RollingUpdate RollingUpdateDisruptions `json:"rollingUpdate,omitempty"`
+
+	// Checks to pass before approving disruption. Optional.
+	Gates *DisruptionGates `json:"gates,omitempty"`
 }
 
 func (d Disruptions) IsEmpty() bool {
-	return d.ApprovalMode == "" && d.Automatic.IsEmpty()
+	return d.ApprovalMode == "" && d.Automatic.IsEmpty() && d.Gates == nil
+}
+
+// DisruptionGates describes checks that must all pass before a node disruption is approved.
+type DisruptionGates struct {
+	// Firing Prometheus alerts block disruptions.
+	PrometheusAlerts *PrometheusAlertsGate `json:"prometheusAlerts,omitempty"`
+
+	// Pods on the node must be allowed to be evicted by their PodDisruptionBudgets.
+	PodDisruptionBudgets bool `json:"podDisruptionBudgets,omitempty"`
+
+	// External HTTP endpoint that can veto disruptions.
+	Webhook *WebhookGate `json:"webhook,omitempty"`
+
+	// Maximum number of not ready nodes in the cluster, including the disrupted node.
+	MaxUnreadyNodes *int32 `json:"maxUnreadyNodes,omitempty"`
+}
+
+type PrometheusAlertsGate struct {
+	// Labels of alerts to block disruptions. Any firing alert blocks disruptions if empty.
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+}
+
+type WebhookGate struct {
+	URL string `json:"url"`
+
+	// Request timeout. Default: 10s
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+func (w *WebhookGate) GetTimeout() time.Duration {
+	if w.Timeout == nil {
+		return 10 * time.Second
+	}
+	return w.Timeout.Duration
 }
 
 type Update struct {
 	MaxConcurrent *intstr.IntOrString `json:"maxConcurrent,omitempty"`
+
+	// Canary update settings. Optional.
+	Canary *CanaryUpdate `json:"canary,omitempty"`
+}
+
+// CanaryUpdate describes updating a few nodes first and pausing the group update if they fail.
+type CanaryUpdate struct {
+	// Number of nodes to update first.
+	Nodes int32 `json:"nodes,omitempty"`
+
+	// Time to watch canary nodes after the update. Default: 10m
+	BakeTime *metav1.Duration `json:"bakeTime,omitempty"`
+
+	// Time for an approved node to become Ready, otherwise the group update is paused. Default: 15m
+	NodeReadyTimeout *metav1.Duration `json:"nodeReadyTimeout,omitempty"`
+}
+
+func (c *CanaryUpdate) GetBakeTime() time.Duration {
+	if c.BakeTime == nil {
+		return 10 * time.Minute
+	}
+	return c.BakeTime.Duration
+}
+
+// GetNodeReadyTimeout returns the default timeout if the canary update is not configured.
+func (c *CanaryUpdate) GetNodeReadyTimeout() time.Duration {
+	if c == nil || c.NodeReadyTimeout == nil {
+		return 15 * time.Minute
+	}
+	return c.NodeReadyTimeout.Duration
 }
 
 type AutomaticDisruptions struct {
 	// Indicates if Pods should be drained from node before allow disruption.
 	DrainBeforeApproval *bool `json:"drainBeforeApproval,omitempty"`
+	// Draining settings.
+	Drain *DrainSettings `json:"drain,omitempty"`
 	// Node update windows
 	Windows update.Windows `json:"windows,omitempty"`
 }
@@ -253,7 +324,59 @@ type RollingUpdateDisruptions struct {
 }
 
 func (a AutomaticDisruptions) IsEmpty() bool {
-	return a.DrainBeforeApproval == nil && len(a.Windows) == 0
+	return a.DrainBeforeApproval == nil && a.Drain == nil && len(a.Windows) == 0
+}
+
+const DrainForceAfterNever = "Never"
+
+// DrainSettings limits the draining of nodes in the group.
+type DrainSettings struct {
+	// Maximum number of concurrently draining nodes. Default: unlimited
+	MaxConcurrent *int32 `json:"maxConcurrent,omitempty"`
+
+	// Time to evict Pods in one drain attempt. Default: 5m
+	Timeout string `json:"timeout,omitempty"`
+
+	// Time since the draining start to delete Pods that cannot be evicted, or Never. Default: 5m
+	ForceAfter string `json:"forceAfter,omitempty"`
+}
+
+// GetMaxConcurrent returns 0 if the number of draining nodes is not limited.
+func (d *DrainSettings) GetMaxConcurrent() int {
+	if d == nil || d.MaxConcurrent == nil {
+		return 0
+	}
+	return int(*d.MaxConcurrent)
+}
+
+// GetTimeout returns the default timeout if it is not set or not positive, because
+// the zero timeout means an infinite eviction, which blocks the drain queue.
+func (d *DrainSettings) GetTimeout() time.Duration {
+	if d == nil || d.Timeout == "" {
+		return 5 * time.Minute
+	}
+
+	timeout, err := time.ParseDuration(d.Timeout)
+	if err != nil || timeout <= 0 {
+		return 5 * time.Minute
+	}
+	return timeout
+}
+
+// GetForceAfter returns 0 if Pods that cannot be evicted are never deleted.
+func (d *DrainSettings) GetForceAfter() time.Duration {
+	if d == nil || d.ForceAfter == "" {
+		return 5 * time.Minute
+	}
+	if d.ForceAfter == DrainForceAfterNever {
+		return 0
+	}
+
+	forceAfter, err := time.ParseDuration(d.ForceAfter)
+	if err != nil {
+		return 5 * time.Minute
+	}
+	return forceAfter
 }
 
 func (r RollingUpdateDisruptions) IsEmpty() bool {
@@ -314,6 +437,39 @@ type NodeGroupStatus struct {
 
 	// Status' summary.
 	ConditionSummary ConditionSummary `json:"conditionSummary,omitempty"`
+
+	// Conditions of the group handling, e.g. UpdatePaused.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Progress of the canary update.
+	Update *UpdateStatus `json:"update,omitempty"`
+
+	// Reports of draining and drained nodes.
+	DrainReports []DrainReport `json:"drainReports,omitempty"`
+
+	// Results of the last bashible runs reported by the nodes.
+	Bashible *BashibleStatus `json:"bashible,omitempty"`
+}
+
+// UpdateStatus is the state of the canary update of the configuration.
+type UpdateStatus struct {
+	// Configuration checksum being updated.
+	Checksum string `json:"checksum,omitempty"`
+
+	// Time when the update of the checksum started.
+	StartedTime *metav1.Time `json:"startedTime,omitempty"`
+
+	// Times when nodes were approved for the update.
+	ApprovedTimes map[string]metav1.Time `json:"approvedTimes,omitempty"`
+
+	// Nodes approved for the update first.
+	CanaryNodes []string `json:"canaryNodes,omitempty"`
+
+	// Time when all canary nodes became up to date.
+	CanaryUpdatedTime *metav1.Time `json:"canaryUpdatedTime,omitempty"`
+
+	// Canary nodes are healthy after the bake time, the rest of nodes are updated.
+	CanaryPassed bool `json:"canaryPassed,omitempty"`
 }
 
 type MachineFailure struct {
@@ -362,3 +518,43 @@ func (f *nodeGroupKind) SetGroupVersionKind(_ schema.GroupVersionKind) {}
 func (f *nodeGroupKind) GroupVersionKind() schema.GroupVersionKind {
 	return schema.GroupVersionKind{Group: "deckhouse.io", Version: "v1", Kind: "NodeGroup"}
 }
+
+// DrainReport describes the progress of node draining.
+type DrainReport struct {
+	Node string `json:"node"`
+
+	StartTime metav1.Time     `json:"startTime"`
+	Elapsed   metav1.Duration `json:"elapsed"`
+
+	// Pods evicted with respect to PodDisruptionBudgets.
+	EvictedPods int32 `json:"evictedPods"`
+	// Pods deleted without eviction.
+	DeletedPods int32 `json:"deletedPods"`
+	// Pods that cannot be evicted due to PodDisruptionBudgets.
+	BlockedPods []string `json:"blockedPods,omitempty"`
+
+	Drained bool   `json:"drained"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BashibleStatus is the summary of the last bashible runs on the nodes of the group.
+type BashibleStatus struct {
+	// Number of nodes that reported the results of bashible steps.
+	Reported int32 `json:"reported"`
+	// Number of nodes with a failed step in the last bashible run.
+	Failed int32 `json:"failed"`
+	// Failed steps of the nodes.
+	FailedSteps []BashibleFailedStep `json:"failedSteps,omitempty"`
+}
+
+// BashibleFailedStep describes the failed bashible step on a node.
+type BashibleFailedStep struct {
+	Node     string `json:"node"`
+	Step     string `json:"step"`
+	Checksum string `json:"checksum,omitempty"`
+	ExitCode int32  `json:"exitCode"`
+
+	// Last lines of the step output.
+	Output     string      `json:"output,omitempty"`
+	FinishedAt metav1.Time `json:"finishedAt,omitempty"`
+}
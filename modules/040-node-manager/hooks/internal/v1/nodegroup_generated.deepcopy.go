@@ -22,6 +22,7 @@ limitations under the License.
 package v1
 
 import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	intstr "k8s.io/apimachinery/pkg/util/intstr"
 )
 
@@ -33,6 +34,11 @@ func (in *AutomaticDisruptions) DeepCopyInto(out *AutomaticDisruptions) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Drain != nil {
+		in, out := &in.Drain, &out.Drain
+		*out = new(DrainSettings)
+		(*in).DeepCopyInto(*out)
+	}
 	out.Windows = in.Windows.DeepCopy()
 	return
 }
@@ -47,6 +53,46 @@ func (in *AutomaticDisruptions) DeepCopy() *AutomaticDisruptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BashibleFailedStep) DeepCopyInto(out *BashibleFailedStep) {
+	*out = *in
+	in.FinishedAt.DeepCopyInto(&out.FinishedAt)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BashibleFailedStep.
+func (in *BashibleFailedStep) DeepCopy() *BashibleFailedStep {
+	if in == nil {
+		return nil
+	}
+	out := new(BashibleFailedStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BashibleStatus) DeepCopyInto(out *BashibleStatus) {
+	*out = *in
+	if in.FailedSteps != nil {
+		in, out := &in.FailedSteps, &out.FailedSteps
+		*out = make([]BashibleFailedStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BashibleStatus.
+func (in *BashibleStatus) DeepCopy() *BashibleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BashibleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CRI) DeepCopyInto(out *CRI) {
 	*out = *in
@@ -78,6 +124,32 @@ func (in *CRI) DeepCopy() *CRI {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryUpdate) DeepCopyInto(out *CanaryUpdate) {
+	*out = *in
+	if in.BakeTime != nil {
+		in, out := &in.BakeTime, &out.BakeTime
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.NodeReadyTimeout != nil {
+		in, out := &in.NodeReadyTimeout, &out.NodeReadyTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryUpdate.
+func (in *CanaryUpdate) DeepCopy() *CanaryUpdate {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryUpdate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Chaos) DeepCopyInto(out *Chaos) {
 	*out = *in
@@ -200,11 +272,47 @@ func (in *Containerd) DeepCopy() *Containerd {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DisruptionGates) DeepCopyInto(out *DisruptionGates) {
+	*out = *in
+	if in.PrometheusAlerts != nil {
+		in, out := &in.PrometheusAlerts, &out.PrometheusAlerts
+		*out = new(PrometheusAlertsGate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(WebhookGate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxUnreadyNodes != nil {
+		in, out := &in.MaxUnreadyNodes, &out.MaxUnreadyNodes
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisruptionGates.
+func (in *DisruptionGates) DeepCopy() *DisruptionGates {
+	if in == nil {
+		return nil
+	}
+	out := new(DisruptionGates)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Disruptions) DeepCopyInto(out *Disruptions) {
 	*out = *in
 	in.Automatic.DeepCopyInto(&out.Automatic)
 	in.RollingUpdate.DeepCopyInto(&out.RollingUpdate)
+	if in.Gates != nil {
+		in, out := &in.Gates, &out.Gates
+		*out = new(DisruptionGates)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -244,6 +352,50 @@ func (in *Docker) DeepCopy() *Docker {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DrainReport) DeepCopyInto(out *DrainReport) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	out.Elapsed = in.Elapsed
+	if in.BlockedPods != nil {
+		in, out := &in.BlockedPods, &out.BlockedPods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainReport.
+func (in *DrainReport) DeepCopy() *DrainReport {
+	if in == nil {
+		return nil
+	}
+	out := new(DrainReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DrainSettings) DeepCopyInto(out *DrainSettings) {
+	*out = *in
+	if in.MaxConcurrent != nil {
+		in, out := &in.MaxConcurrent, &out.MaxConcurrent
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainSettings.
+func (in *DrainSettings) DeepCopy() *DrainSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(DrainSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Kubelet) DeepCopyInto(out *Kubelet) {
 	*out = *in
@@ -351,6 +503,30 @@ func (in *NodeGroupStatus) DeepCopyInto(out *NodeGroupStatus) {
 		copy(*out, *in)
 	}
 	out.ConditionSummary = in.ConditionSummary
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Update != nil {
+		in, out := &in.Update, &out.Update
+		*out = new(UpdateStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DrainReports != nil {
+		in, out := &in.DrainReports, &out.DrainReports
+		*out = make([]DrainReport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Bashible != nil {
+		in, out := &in.Bashible, &out.Bashible
+		*out = new(BashibleStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -406,6 +582,29 @@ func (in *OperatingSystem) DeepCopy() *OperatingSystem {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusAlertsGate) DeepCopyInto(out *PrometheusAlertsGate) {
+	*out = *in
+	if in.MatchLabels != nil {
+		in, out := &in.MatchLabels, &out.MatchLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusAlertsGate.
+func (in *PrometheusAlertsGate) DeepCopy() *PrometheusAlertsGate {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusAlertsGate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Resources) DeepCopyInto(out *Resources) {
 	*out = *in
@@ -466,6 +665,11 @@ func (in *Update) DeepCopyInto(out *Update) {
 		*out = new(intstr.IntOrString)
 		**out = **in
 	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(CanaryUpdate)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -478,3 +682,60 @@ func (in *Update) DeepCopy() *Update {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpdateStatus) DeepCopyInto(out *UpdateStatus) {
+	*out = *in
+	if in.StartedTime != nil {
+		in, out := &in.StartedTime, &out.StartedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ApprovedTimes != nil {
+		in, out := &in.ApprovedTimes, &out.ApprovedTimes
+		*out = make(map[string]v1.Time, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.CanaryNodes != nil {
+		in, out := &in.CanaryNodes, &out.CanaryNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CanaryUpdatedTime != nil {
+		in, out := &in.CanaryUpdatedTime, &out.CanaryUpdatedTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdateStatus.
+func (in *UpdateStatus) DeepCopy() *UpdateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UpdateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookGate) DeepCopyInto(out *WebhookGate) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookGate.
+func (in *WebhookGate) DeepCopy() *WebhookGate {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookGate)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -24,9 +24,12 @@ import (
 	"github.com/flant/addon-operator/sdk"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/utils/pointer"
+
+	"github.com/deckhouse/deckhouse/modules/040-node-manager/hooks/internal/shared"
 )
 
-// Count user defined NodeGroupConfigurations, aggregate them by NodeGroups and export as metric
+// Count user defined NodeGroupConfigurations, aggregate them by NodeGroups and export as metric.
+// Export results of bashible steps reported by nodes as metrics.
 
 var _ = sdk.RegisterFunc(&go_hook.HookConfig{
 	Queue: "/modules/node-manager/metrics",
@@ -38,6 +41,7 @@ var _ = sdk.RegisterFunc(&go_hook.HookConfig{
 			Kind:                         "NodeGroupConfiguration",
 			FilterFunc:                   filterNGConfigurations,
 		},
+		shared.BashibleStatusHookConfig(),
 	},
 }, handleNodeGroupConfigurations)
 
@@ -62,10 +66,6 @@ func handleNodeGroupConfigurations(input *go_hook.HookInput) error {
 
 	input.MetricsCollector.Expire("node_group_configurations")
 
-	if len(snap) == 0 {
-		return nil
-	}
-
 	countByNodeGroup := make(map[string]uint)
 	for _, sn := range snap {
 		ngc := sn.(nodeGroupConfigurationMetric)
@@ -78,6 +78,27 @@ func handleNodeGroupConfigurations(input *go_hook.HookInput) error {
 		input.MetricsCollector.Set("d8_node_group_configurations_total", float64(count), map[string]string{"node_group": ng}, metrics.WithGroup("node_group_configurations"))
 	}
 
+	return handleBashibleStepsMetrics(input)
+}
+
+func handleBashibleStepsMetrics(input *go_hook.HookInput) error {
+	snap := input.Snapshots["bashible_statuses"]
+
+	input.MetricsCollector.Expire("bashible_steps")
+
+	for _, sn := range snap {
+		status := sn.(shared.BashibleStatus)
+		for _, step := range status.Steps {
+			labels := map[string]string{
+				"node_group": status.NodeGroup,
+				"node":       status.Node,
+				"step":       step.Name,
+			}
+			input.MetricsCollector.Set("d8_bashible_step_exit_code", float64(step.ExitCode), labels, metrics.WithGroup("bashible_steps"))
+			input.MetricsCollector.Set("d8_bashible_step_duration_seconds", step.Duration.Seconds(), labels, metrics.WithGroup("bashible_steps"))
+		}
+	}
+
 	return nil
 }
 
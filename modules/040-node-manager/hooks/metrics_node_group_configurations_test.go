@@ -0,0 +1,120 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"github.com/flant/shell-operator/pkg/metric_storage/operation"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/pointer"
+
+	. "github.com/deckhouse/deckhouse/testing/hooks"
+)
+
+var _ = Describe("Modules :: node-manager :: hooks :: metrics_node_group_configurations ::", func() {
+	const (
+		stateConfigurations = `
+---
+apiVersion: deckhouse.io/v1alpha1
+kind: NodeGroupConfiguration
+metadata:
+  name: sysctl.sh
+spec:
+  nodeGroups: ["ng1"]
+  bundles: ["*"]
+  weight: 50
+  content: "echo"
+`
+		stateBashibleStatus = `
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: bashible-status-node-ng1-aaa
+  namespace: d8-cloud-instance-manager
+  labels:
+    app: bashible-apiserver
+    node.deckhouse.io/bashible-status: ""
+data:
+  status: eyJhcGlWZXJzaW9uIjoiYmFzaGlibGUuZGVja2hvdXNlLmlvL3YxYWxwaGExIiwia2luZCI6IkJhc2hpYmxlU3RhdHVzIiwibWV0YWRhdGEiOnsibmFtZSI6Im5vZGUtbmcxLWFhYSIsImNyZWF0aW9uVGltZXN0YW1wIjoiMjAyMi0wNS0xNVQxNTowMToxM1oifSwibm9kZUdyb3VwIjoibmcxIiwiY29uZmlndXJhdGlvbkNoZWNrc3VtIjoiMTIzIiwic3RlcHMiOlt7Im5hbWUiOiIwMDBfY29uZmlndXJlX2t1YmVsZXQuc2giLCJjaGVja3N1bSI6ImFhYSIsImV4aXRDb2RlIjowLCJkdXJhdGlvbiI6IjJzIiwiZmluaXNoZWRBdCI6IjIwMjItMDUtMTVUMTU6MDE6MTBaIn0seyJuYW1lIjoiMDAxX2luc3RhbGxfY29udGFpbmVyZC5zaCIsImNoZWNrc3VtIjoiYmJiIiwiZXhpdENvZGUiOjEsImR1cmF0aW9uIjoiNXMiLCJvdXRwdXQiOiJFOiBVbmFibGUgdG8gbG9jYXRlIHBhY2thZ2UgY29udGFpbmVyZCIsImZpbmlzaGVkQXQiOiIyMDIyLTA1LTE1VDE1OjAxOjEzWiJ9XX0=
+`
+	)
+
+	f := HookExecutionConfigInit(`{"nodeManager":{"internal":{}}}`, `{}`)
+	f.RegisterCRD("deckhouse.io", "v1alpha1", "NodeGroupConfiguration", false)
+
+	Context("Cluster with NodeGroupConfiguration and bashible status", func() {
+		BeforeEach(func() {
+			f.BindingContexts.Set(f.KubeStateSet(stateConfigurations + stateBashibleStatus))
+			f.RunHook()
+		})
+
+		It("Must export configurations and bashible steps metrics", func() {
+			Expect(f).To(ExecuteSuccessfully())
+			ops := f.MetricsCollector.CollectedMetrics()
+			Expect(ops).To(HaveLen(7))
+
+			Expect(ops[0]).To(BeEquivalentTo(operation.MetricOperation{
+				Group:  "node_group_configurations",
+				Action: "expire",
+			}))
+			Expect(ops[1]).To(BeEquivalentTo(operation.MetricOperation{
+				Name:   "d8_node_group_configurations_total",
+				Group:  "node_group_configurations",
+				Action: "set",
+				Value:  pointer.Float64Ptr(1.0),
+				Labels: map[string]string{"node_group": "ng1"},
+			}))
+			Expect(ops[2]).To(BeEquivalentTo(operation.MetricOperation{
+				Group:  "bashible_steps",
+				Action: "expire",
+			}))
+
+			labels := map[string]string{"node_group": "ng1", "node": "node-ng1-aaa", "step": "000_configure_kubelet.sh"}
+			Expect(ops[3]).To(BeEquivalentTo(operation.MetricOperation{
+				Name:   "d8_bashible_step_exit_code",
+				Group:  "bashible_steps",
+				Action: "set",
+				Value:  pointer.Float64Ptr(0.0),
+				Labels: labels,
+			}))
+			Expect(ops[4]).To(BeEquivalentTo(operation.MetricOperation{
+				Name:   "d8_bashible_step_duration_seconds",
+				Group:  "bashible_steps",
+				Action: "set",
+				Value:  pointer.Float64Ptr(2.0),
+				Labels: labels,
+			}))
+
+			labels = map[string]string{"node_group": "ng1", "node": "node-ng1-aaa", "step": "001_install_containerd.sh"}
+			Expect(ops[5]).To(BeEquivalentTo(operation.MetricOperation{
+				Name:   "d8_bashible_step_exit_code",
+				Group:  "bashible_steps",
+				Action: "set",
+				Value:  pointer.Float64Ptr(1.0),
+				Labels: labels,
+			}))
+			Expect(ops[6]).To(BeEquivalentTo(operation.MetricOperation{
+				Name:   "d8_bashible_step_duration_seconds",
+				Group:  "bashible_steps",
+				Action: "set",
+				Value:  pointer.Float64Ptr(5.0),
+				Labels: labels,
+			}))
+		})
+	})
+})
@@ -28,9 +28,11 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	apimtypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/pointer"
 
+	"github.com/deckhouse/deckhouse/go_lib/dependency"
 	"github.com/deckhouse/deckhouse/modules/040-node-manager/hooks/internal/shared"
 	ngv1 "github.com/deckhouse/deckhouse/modules/040-node-manager/hooks/internal/v1"
 )
@@ -41,6 +43,13 @@ var _ = sdk.RegisterFunc(&go_hook.HookConfig{
 		ExecutionBurst:       3,
 	},
 	Queue: "/modules/node-manager/update_approval",
+	Schedule: []go_hook.ScheduleConfig{
+		// Canary update waits for the bake time and for nodes to become Ready
+		{
+			Name:    "canary",
+			Crontab: "* * * * *",
+		},
+	},
 	Kubernetes: []go_hook.KubernetesConfig{
 		// snapshot: "configuration_checksums_secret"
 		// api: "v1",
@@ -71,14 +80,23 @@ var _ = sdk.RegisterFunc(&go_hook.HookConfig{
 			FilterFunc: updateApprovalFilterNode,
 		},
 	},
-}, handleUpdateApproval)
+}, dependency.WithExternalDependencies(handleUpdateApproval))
 
-func handleUpdateApproval(input *go_hook.HookInput) error {
+func handleUpdateApproval(input *go_hook.HookInput, dc dependency.Container) error {
 	approver := &updateApprover{
 		finished: false,
 
 		nodes:      make(map[string]updateApprovalNode),
 		nodeGroups: make(map[string]updateNodeGroup),
+		rollouts:   make(map[string]*nodeGroupRollout),
+
+		dc: dc,
+
+		now: time.Now(),
+	}
+
+	if os.Getenv("D8_IS_TESTS_ENVIRONMENT") != "" {
+		approver.now = time.Date(2021, 01, 01, 13, 30, 00, 00, time.UTC)
 	}
 
 	snap := input.Snapshots["configuration_checksums_secret"]
@@ -91,6 +109,14 @@ func handleUpdateApproval(input *go_hook.HookInput) error {
 	snap = input.Snapshots["ngs"]
 	for _, s := range snap {
 		ng := s.(updateNodeGroup)
+		if err := ng.Disruptions.Automatic.Windows.Validate(); err != nil {
+			input.LogEntry.Errorf("NodeGroup %s has invalid automatic disruption windows, disruptions are not approved: %v", ng.Name, err)
+			ng.HasInvalidWindows = true
+		}
+		if err := ng.Disruptions.RollingUpdate.Windows.Validate(); err != nil {
+			input.LogEntry.Errorf("NodeGroup %s has invalid rolling update windows, disruptions are not approved: %v", ng.Name, err)
+			ng.HasInvalidWindows = true
+		}
 		approver.nodeGroups[ng.Name] = ng
 	}
 
@@ -103,29 +129,38 @@ func handleUpdateApproval(input *go_hook.HookInput) error {
 	}
 
 	approver.deckhouseNodeName = os.Getenv("DECKHOUSE_NODE_NAME")
+	approver.disruptionGates = newDisruptionGates(dc, approver.nodes)
 
-	err := approver.processUpdatedNodes(input)
+	approver.processRollouts(input)
+
+	err := approver.approve(input)
 	if err != nil {
 		return err
 	}
-	if approver.finished {
-		return nil
-	}
 
-	err = approver.approveDisruptions(input)
+	approver.patchRollouts(input)
+
+	return nil
+}
+
+func (ar *updateApprover) approve(input *go_hook.HookInput) error {
+	err := ar.processUpdatedNodes(input)
 	if err != nil {
 		return err
 	}
-	if approver.finished {
+	if ar.finished {
 		return nil
 	}
 
-	err = approver.approveUpdates(input)
+	err = ar.approveDisruptions(input)
 	if err != nil {
 		return err
 	}
+	if ar.finished {
+		return nil
+	}
 
-	return nil
+	return ar.approveUpdates(input)
 }
 
 type updateApprover struct {
@@ -135,6 +170,14 @@ type updateApprover struct {
 	nodes             map[string]updateApprovalNode
 	nodeGroups        map[string]updateNodeGroup
 	deckhouseNodeName string
+
+	// rollouts are canary update states by node group names
+	rollouts map[string]*nodeGroupRollout
+
+	dc              dependency.Container
+	disruptionGates *disruptionGates
+
+	now time.Time
 }
 
 func calculateConcurrency(ngCon *intstr.IntOrString, totalNodes int) int {
@@ -164,6 +207,10 @@ func calculateConcurrency(ngCon *intstr.IntOrString, totalNodes int) int {
 //  * If there are not ready nodes in the group, they'll be updated first
 func (ar *updateApprover) approveUpdates(input *go_hook.HookInput) error {
 	for _, ng := range ar.nodeGroups {
+		if ng.IsUpdatePaused {
+			continue
+		}
+
 		nodeGroupNodes := make([]updateApprovalNode, 0)
 		currentUpdates := 0
 
@@ -200,6 +247,18 @@ func (ar *updateApprover) approveUpdates(input *go_hook.HookInput) error {
 
 		countToApprove := concurrency - currentUpdates
 
+		// Only canary nodes are approved until they pass
+		rollout := ar.rollouts[ng.Name]
+		if rollout.inCanary(ng) {
+			canaryLeft := int(ng.Canary.Nodes) - len(rollout.Status.CanaryNodes)
+			if canaryLeft <= 0 {
+				continue
+			}
+			if countToApprove > canaryLeft {
+				countToApprove = canaryLeft
+			}
+		}
+
 		approvedNodeNames := make(map[string]struct{}, countToApprove)
 
 		//     Allow one node, if 100% nodes in NodeGroup are ready
@@ -243,6 +302,11 @@ func (ar *updateApprover) approveUpdates(input *go_hook.HookInput) error {
 		for approvedNodeName := range approvedNodeNames {
 			input.PatchCollector.MergePatch(approvedPatch, "v1", "Node", "", approvedNodeName)
 			setNodeStatusesMetrics(input, approvedNodeName, ng.Name, "Approved")
+
+			if rollout.inCanary(ng) {
+				rollout.addCanaryNode(approvedNodeName)
+			}
+			rollout.setApproved(approvedNodeName, ar.now)
 		}
 
 		ar.finished = true
@@ -283,11 +347,7 @@ func (ar *updateApprover) needDrainNode(input *go_hook.HookInput, node *updateAp
 // Approve disruption updates for NodeGroups with approvalMode == Automatic
 // We don't limit number of Nodes here, because it's already limited
 func (ar *updateApprover) approveDisruptions(input *go_hook.HookInput) error {
-	now := time.Now()
-
-	if os.Getenv("D8_IS_TESTS_ENVIRONMENT") != "" {
-		now = time.Date(2021, 01, 01, 13, 30, 00, 00, time.UTC)
-	}
+	now := ar.now
 
 	for _, node := range ar.nodes {
 		if !(node.IsDisruptionRequired && !node.IsDraining) {
@@ -298,6 +358,16 @@ func (ar *updateApprover) approveDisruptions(input *go_hook.HookInput) error {
 
 		ng := ar.nodeGroups[ngName]
 
+		// Skip nodes in NodeGroup with the paused update
+		if ng.IsUpdatePaused {
+			continue
+		}
+
+		// Skip nodes in NodeGroup with windows which cannot be checked
+		if ng.HasInvalidWindows {
+			continue
+		}
+
 		switch ng.Disruptions.ApprovalMode {
 		// Skip nodes in NodeGroup not allowing disruptive updates
 		case "Manual":
@@ -315,6 +385,13 @@ func (ar *updateApprover) approveDisruptions(input *go_hook.HookInput) error {
 			}
 		}
 
+		// Skip node if the cluster state does not allow disruptions
+		if reason := ar.disruptionGates.check(&ng, &node); reason != "" {
+			input.LogEntry.Infof("Disruption of node %s is blocked by NodeGroup %s gates: %s", node.Name, ngName, reason)
+			continue
+		}
+		ar.disruptionGates.markDisrupted(node.Name)
+
 		ar.finished = true
 
 		// If approvalMode == RollingUpdate simply delete machine
@@ -438,6 +515,9 @@ type updateApprovalNode struct {
 	IsUnschedulable      bool
 	IsDraining           bool
 	IsDrained            bool
+
+	// NotReadySince is the time of the last Ready condition transition for not ready nodes
+	NotReadySince time.Time
 }
 
 type updateNodeGroup struct {
@@ -447,6 +527,16 @@ type updateNodeGroup struct {
 	Status      ngv1.NodeGroupStatus
 
 	Concurrency *intstr.IntOrString
+
+	Canary         *ngv1.CanaryUpdate
+	IsUpdatePaused bool
+
+	// HasInvalidWindows is set if disruption windows cannot be parsed
+	HasInvalidWindows bool
+
+	// for event generation
+	UID             apimtypes.UID
+	ResourceVersion string
 }
 
 func updateApprovalNodeGroupFilter(obj *unstructured.Unstructured) (go_hook.FilterResult, error) {
@@ -460,6 +550,17 @@ func updateApprovalNodeGroupFilter(obj *unstructured.Unstructured) (go_hook.Filt
 	ung := updateNodeGroup{
 		Name:     ng.Name,
 		NodeType: ng.Spec.NodeType,
+
+		UID:             ng.UID,
+		ResourceVersion: ng.ResourceVersion,
+	}
+
+	if ng.Spec.Update.Canary != nil && ng.Spec.Update.Canary.Nodes > 0 {
+		ung.Canary = ng.Spec.Update.Canary
+	}
+
+	if _, ok := ng.Annotations[updatePausedAnnotation]; ok {
+		ung.IsUpdatePaused = true
 	}
 
 	if ng.Spec.Update.MaxConcurrent != nil {
@@ -477,6 +578,8 @@ func updateApprovalNodeGroupFilter(obj *unstructured.Unstructured) (go_hook.Filt
 		ung.Disruptions.RollingUpdate.Windows = ng.Spec.Disruptions.RollingUpdate.Windows
 	}
 
+	ung.Disruptions.Gates = ng.Spec.Disruptions.Gates
+
 	if ng.Spec.Disruptions.ApprovalMode != "" {
 		ung.Disruptions.ApprovalMode = ng.Spec.Disruptions.ApprovalMode
 	} else {
@@ -533,11 +636,17 @@ func updateApprovalFilterNode(obj *unstructured.Unstructured) (go_hook.FilterRes
 		isDrained = true
 	}
 
+	var notReadySince time.Time
 	for _, cond := range node.Status.Conditions {
-		if cond.Type == corev1.NodeReady && cond.Status == corev1.ConditionTrue {
+		if cond.Type != corev1.NodeReady {
+			continue
+		}
+		if cond.Status == corev1.ConditionTrue {
 			isReady = true
-			break
+		} else {
+			notReadySince = cond.LastTransitionTime.Time
 		}
+		break
 	}
 
 	n := updateApprovalNode{
@@ -552,6 +661,7 @@ func updateApprovalFilterNode(obj *unstructured.Unstructured) (go_hook.FilterRes
 		IsUnschedulable:       node.Spec.Unschedulable,
 		IsWaitingForApproval:  isWaitingForApproval,
 		IsDrained:             isDrained,
+		NotReadySince:         notReadySince,
 	}
 
 	return n, nil
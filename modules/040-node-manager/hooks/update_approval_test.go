@@ -18,13 +18,21 @@ package hooks
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"os"
 	"text/template"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
 
+	"github.com/deckhouse/deckhouse/go_lib/dependency"
 	. "github.com/deckhouse/deckhouse/testing/hooks"
 )
 
@@ -920,6 +928,60 @@ spec:
 			})
 		})
 
+		Context("inside update windows with an invalid blackout", func() {
+			BeforeEach(func() {
+				f.BindingContexts.Set(f.KubeStateSet(`
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: configuration-checksums
+  namespace: d8-cloud-instance-manager
+data:
+  test: dXBkYXRlZA== # updated
+---
+apiVersion: deckhouse.io/v1
+kind: NodeGroup
+metadata:
+  name: ng2
+spec:
+  nodeType: Static
+  disruptions:
+    approvalMode: Automatic
+    automatic:
+      windows:
+        - from: "8:00"
+          to: "18:00"
+        - blackout:
+            from: "2020-12-30"
+            to: "2021-02-30"
+      drainBeforeApproval: true
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: worker-2
+  labels:
+    node.deckhouse.io/group: ng2
+  annotations:
+    update.node.deckhouse.io/approved: ""
+    update.node.deckhouse.io/disruption-required: ""
+spec:
+  unschedulable: true
+
+`))
+				f.RunHook()
+			})
+
+			It("Should not be approved", func() {
+				Expect(f).To(ExecuteSuccessfully())
+
+				n := f.KubernetesGlobalResource("Node", "worker-2")
+				Expect(n.Field(`metadata.annotations.update\.node\.deckhouse\.io/disruption-approved`).Exists()).To(BeFalse())
+				Expect(n.Field(`metadata.annotations.update\.node\.deckhouse\.io/disruption-required`).Exists()).To(BeTrue())
+			})
+		})
+
 		Context("With maxConcurrent update set", func() {
 			BeforeEach(func() {
 				f.BindingContexts.Set(f.KubeStateSet(`
@@ -1227,6 +1289,649 @@ metadata:
 			})
 		})
 	})
+
+	Context("Canary update", func() {
+		const canaryNodeGroup = `
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: configuration-checksums
+  namespace: d8-cloud-instance-manager
+data:
+  ng1: dXBkYXRlZA== # updated
+---
+apiVersion: deckhouse.io/v1
+kind: NodeGroup
+metadata:
+  name: ng1
+%s
+spec:
+  nodeType: Static
+  update:
+    maxConcurrent: 2
+    canary:
+      nodes: 1
+      bakeTime: 10m
+status:
+%s
+`
+		const waitingNodes = `
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: worker-2
+  labels:
+    node.deckhouse.io/group: ng1
+  annotations:
+    node.deckhouse.io/configuration-checksum: notupdated
+    update.node.deckhouse.io/waiting-for-approval: ""
+status:
+  conditions:
+  - type: Ready
+    status: 'True'
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: worker-3
+  labels:
+    node.deckhouse.io/group: ng1
+  annotations:
+    node.deckhouse.io/configuration-checksum: notupdated
+    update.node.deckhouse.io/waiting-for-approval: ""
+status:
+  conditions:
+  - type: Ready
+    status: 'True'
+`
+		const updatedCanaryNode = `
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: worker-1
+  labels:
+    node.deckhouse.io/group: ng1
+  annotations:
+    node.deckhouse.io/configuration-checksum: updated
+status:
+  conditions:
+  - type: Ready
+    status: 'True'
+`
+		// DaemonSet Pods of canary nodes are listed with the client, not from snapshots
+		createCrashLoopingPod := func() {
+			_, err := f.KubeClient().CoreV1().Pods("d8-monitoring").Create(context.TODO(), &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "agent-abcde",
+					Namespace: "d8-monitoring",
+					OwnerReferences: []metav1.OwnerReference{
+						{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "agent", UID: "11111111-2222-3333-4444-555555555555", Controller: pointer.BoolPtr(true)},
+					},
+				},
+				Spec: corev1.PodSpec{NodeName: "worker-1"},
+				Status: corev1.PodStatus{
+					ContainerStatuses: []corev1.ContainerStatus{
+						{Name: "agent", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+					},
+				},
+			}, metav1.CreateOptions{})
+			Expect(err).ToNot(HaveOccurred())
+		}
+		const canaryUpdated = `
+  update:
+    checksum: updated
+    canaryNodes: ["worker-1"]
+    canaryUpdatedTime: "2021-01-01T13:00:00Z"
+`
+
+		isApproved := func(name string) bool {
+			return f.KubernetesGlobalResource("Node", name).Field(`metadata.annotations.update\.node\.deckhouse\.io/approved`).Exists()
+		}
+
+		BeforeEach(func() {
+			f.ValuesSet("global.discovery.kubernetesVersion", "1.21.0")
+		})
+
+		Context("update is started", func() {
+			BeforeEach(func() {
+				f.BindingContexts.Set(f.KubeStateSet(fmt.Sprintf(canaryNodeGroup, "", "  ready: 3") + `
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: worker-1
+  labels:
+    node.deckhouse.io/group: ng1
+  annotations:
+    node.deckhouse.io/configuration-checksum: notupdated
+    update.node.deckhouse.io/waiting-for-approval: ""
+status:
+  conditions:
+  - type: Ready
+    status: 'True'
+` + waitingNodes))
+				f.RunHook()
+			})
+
+			It("Should approve only canary nodes", func() {
+				Expect(f).To(ExecuteSuccessfully())
+
+				approved := 0
+				for _, name := range []string{"worker-1", "worker-2", "worker-3"} {
+					if isApproved(name) {
+						approved++
+					}
+				}
+				Expect(approved).To(Equal(1))
+
+				ng := f.KubernetesGlobalResource("NodeGroup", "ng1")
+				Expect(ng.Field("status.update.checksum").String()).To(Equal("updated"))
+				Expect(ng.Field("status.update.canaryNodes").Array()).To(HaveLen(1))
+				Expect(isApproved(ng.Field("status.update.canaryNodes.0").String())).To(BeTrue())
+				Expect(ng.Field("status.update.canaryPassed").Bool()).To(BeFalse())
+				Expect(ng.Field("status.update.startedTime").String()).To(Equal("2021-01-01T13:30:00Z"))
+				Expect(ng.Field("status.update.approvedTimes").Map()).To(HaveLen(1))
+			})
+		})
+
+		Context("canary nodes are updated", func() {
+			BeforeEach(func() {
+				f.BindingContexts.Set(f.KubeStateSet(fmt.Sprintf(canaryNodeGroup, "", `  ready: 3
+  update:
+    checksum: updated
+    canaryNodes: ["worker-1"]`) + updatedCanaryNode + waitingNodes))
+				f.RunHook()
+			})
+
+			It("Should start the bake time", func() {
+				Expect(f).To(ExecuteSuccessfully())
+
+				ng := f.KubernetesGlobalResource("NodeGroup", "ng1")
+				Expect(ng.Field("status.update.canaryUpdatedTime").String()).To(Equal("2021-01-01T13:30:00Z"))
+				Expect(ng.Field("status.update.canaryPassed").Bool()).To(BeFalse())
+				Expect(isApproved("worker-2")).To(BeFalse())
+				Expect(isApproved("worker-3")).To(BeFalse())
+			})
+		})
+
+		Context("bake time is over and canary nodes are healthy", func() {
+			BeforeEach(func() {
+				f.BindingContexts.Set(f.KubeStateSet(fmt.Sprintf(canaryNodeGroup, "", "  ready: 3"+canaryUpdated) + updatedCanaryNode + waitingNodes))
+				f.RunHook()
+			})
+
+			It("Should approve the rest of nodes", func() {
+				Expect(f).To(ExecuteSuccessfully())
+
+				ng := f.KubernetesGlobalResource("NodeGroup", "ng1")
+				Expect(ng.Field("status.update.canaryPassed").Bool()).To(BeTrue())
+				Expect(ng.Field(`metadata.annotations.update\.node\.deckhouse\.io/paused`).Exists()).To(BeFalse())
+				Expect(isApproved("worker-2")).To(BeTrue())
+				Expect(isApproved("worker-3")).To(BeTrue())
+			})
+		})
+
+		Context("bake time is over and DaemonSet Pods are crashlooping on canary nodes", func() {
+			BeforeEach(func() {
+				f.BindingContexts.Set(f.KubeStateSet(fmt.Sprintf(canaryNodeGroup, "", "  ready: 3"+canaryUpdated) + updatedCanaryNode + waitingNodes))
+				createCrashLoopingPod()
+				f.RunHook()
+			})
+
+			It("Should pause the update", func() {
+				Expect(f).To(ExecuteSuccessfully())
+
+				ng := f.KubernetesGlobalResource("NodeGroup", "ng1")
+				Expect(ng.Field(`metadata.annotations.update\.node\.deckhouse\.io/paused`).String()).To(Equal("CanaryFailed"))
+				Expect(ng.Field("status.update.canaryPassed").Bool()).To(BeFalse())
+				Expect(ng.Field("status.conditions.0.type").String()).To(Equal("UpdatePaused"))
+				Expect(ng.Field("status.conditions.0.status").String()).To(Equal("True"))
+				Expect(ng.Field("status.conditions.0.reason").String()).To(Equal("CanaryFailed"))
+				Expect(isApproved("worker-2")).To(BeFalse())
+				Expect(isApproved("worker-3")).To(BeFalse())
+			})
+		})
+
+		Context("updated node is not Ready for too long", func() {
+			BeforeEach(func() {
+				f.BindingContexts.Set(f.KubeStateSet(fmt.Sprintf(canaryNodeGroup, "", `  ready: 2
+  update:
+    checksum: updated
+    startedTime: "2021-01-01T12:50:00Z"
+    approvedTimes:
+      worker-1: "2021-01-01T12:55:00Z"
+    canaryNodes: ["worker-1"]`) + `
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: worker-1
+  labels:
+    node.deckhouse.io/group: ng1
+  annotations:
+    node.deckhouse.io/configuration-checksum: updated
+    update.node.deckhouse.io/approved: ""
+status:
+  conditions:
+  - type: Ready
+    status: 'False'
+    lastTransitionTime: "2021-01-01T13:00:00Z"
+` + waitingNodes))
+				f.RunHook()
+			})
+
+			It("Should pause the update", func() {
+				Expect(f).To(ExecuteSuccessfully())
+
+				ng := f.KubernetesGlobalResource("NodeGroup", "ng1")
+				Expect(ng.Field(`metadata.annotations.update\.node\.deckhouse\.io/paused`).String()).To(Equal("NodeNotReady"))
+				Expect(ng.Field("status.conditions.0.reason").String()).To(Equal("NodeNotReady"))
+				Expect(ng.Field("status.conditions.0.message").String()).To(ContainSubstring("worker-1"))
+			})
+		})
+
+		Context("updated node of the NodeGroup without canary is not Ready for too long", func() {
+			BeforeEach(func() {
+				f.BindingContexts.Set(f.KubeStateSet(`
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: configuration-checksums
+  namespace: d8-cloud-instance-manager
+data:
+  ng1: dXBkYXRlZA== # updated
+---
+apiVersion: deckhouse.io/v1
+kind: NodeGroup
+metadata:
+  name: ng1
+spec:
+  nodeType: Static
+  update:
+    maxConcurrent: 2
+status:
+  ready: 2
+  update:
+    checksum: updated
+    startedTime: "2021-01-01T12:50:00Z"
+    approvedTimes:
+      worker-1: "2021-01-01T12:55:00Z"
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: worker-1
+  labels:
+    node.deckhouse.io/group: ng1
+  annotations:
+    node.deckhouse.io/configuration-checksum: updated
+    update.node.deckhouse.io/approved: ""
+status:
+  conditions:
+  - type: Ready
+    status: 'False'
+    lastTransitionTime: "2021-01-01T13:00:00Z"
+` + waitingNodes))
+				f.RunHook()
+			})
+
+			It("Should pause the update after the default timeout", func() {
+				Expect(f).To(ExecuteSuccessfully())
+
+				ng := f.KubernetesGlobalResource("NodeGroup", "ng1")
+				Expect(ng.Field(`metadata.annotations.update\.node\.deckhouse\.io/paused`).String()).To(Equal("NodeNotReady"))
+				Expect(ng.Field("status.conditions.0.reason").String()).To(Equal("NodeNotReady"))
+				Expect(isApproved("worker-2")).To(BeFalse())
+				Expect(isApproved("worker-3")).To(BeFalse())
+			})
+		})
+
+		Context("updated node was not Ready before the approval", func() {
+			BeforeEach(func() {
+				f.BindingContexts.Set(f.KubeStateSet(fmt.Sprintf(canaryNodeGroup, "", `  ready: 2
+  update:
+    checksum: updated
+    startedTime: "2021-01-01T12:50:00Z"
+    approvedTimes:
+      worker-1: "2021-01-01T13:25:00Z"
+    canaryNodes: ["worker-1"]`) + `
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: worker-1
+  labels:
+    node.deckhouse.io/group: ng1
+  annotations:
+    node.deckhouse.io/configuration-checksum: updated
+    update.node.deckhouse.io/approved: ""
+status:
+  conditions:
+  - type: Ready
+    status: 'False'
+    lastTransitionTime: "2021-01-01T10:00:00Z"
+` + waitingNodes))
+				f.RunHook()
+			})
+
+			It("Should measure the timeout from the approval", func() {
+				Expect(f).To(ExecuteSuccessfully())
+
+				ng := f.KubernetesGlobalResource("NodeGroup", "ng1")
+				Expect(ng.Field(`metadata.annotations.update\.node\.deckhouse\.io/paused`).Exists()).To(BeFalse())
+				Expect(ng.Field("status.update.approvedTimes.worker-1").String()).To(Equal("2021-01-01T13:25:00Z"))
+			})
+		})
+
+		Context("canary node is not Ready during the bake time", func() {
+			BeforeEach(func() {
+				f.BindingContexts.Set(f.KubeStateSet(fmt.Sprintf(canaryNodeGroup, "", "  ready: 2"+canaryUpdated) + `
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: worker-1
+  labels:
+    node.deckhouse.io/group: ng1
+  annotations:
+    node.deckhouse.io/configuration-checksum: updated
+status:
+  conditions:
+  - type: Ready
+    status: 'False'
+    lastTransitionTime: "2021-01-01T13:05:00Z"
+` + waitingNodes))
+				f.RunHook()
+			})
+
+			It("Should pause the update", func() {
+				Expect(f).To(ExecuteSuccessfully())
+
+				ng := f.KubernetesGlobalResource("NodeGroup", "ng1")
+				Expect(ng.Field(`metadata.annotations.update\.node\.deckhouse\.io/paused`).String()).To(Equal("CanaryFailed"))
+				Expect(ng.Field("status.conditions.0.reason").String()).To(Equal("CanaryFailed"))
+				Expect(ng.Field("status.conditions.0.message").String()).To(ContainSubstring("worker-1"))
+				Expect(isApproved("worker-2")).To(BeFalse())
+				Expect(isApproved("worker-3")).To(BeFalse())
+			})
+		})
+
+		Context("update is paused manually", func() {
+			BeforeEach(func() {
+				f.BindingContexts.Set(f.KubeStateSet(fmt.Sprintf(canaryNodeGroup, `  annotations:
+    update.node.deckhouse.io/paused: ""`, "  ready: 3"+canaryUpdated) + updatedCanaryNode + waitingNodes))
+				f.RunHook()
+			})
+
+			It("Should not approve nodes", func() {
+				Expect(f).To(ExecuteSuccessfully())
+
+				ng := f.KubernetesGlobalResource("NodeGroup", "ng1")
+				Expect(ng.Field("status.conditions.0.status").String()).To(Equal("True"))
+				Expect(ng.Field("status.conditions.0.reason").String()).To(Equal("Manual"))
+				Expect(ng.Field("status.update.canaryPassed").Bool()).To(BeFalse())
+				Expect(isApproved("worker-2")).To(BeFalse())
+				Expect(isApproved("worker-3")).To(BeFalse())
+			})
+		})
+
+		Context("update is resumed after the canary failure", func() {
+			BeforeEach(func() {
+				f.BindingContexts.Set(f.KubeStateSet(fmt.Sprintf(canaryNodeGroup, "", "  ready: 3"+canaryUpdated+`
+  conditions:
+  - type: UpdatePaused
+    status: "True"
+    reason: CanaryFailed
+    message: DaemonSet Pods are crashlooping
+    lastTransitionTime: "2021-01-01T13:10:00Z"`) + updatedCanaryNode + waitingNodes))
+				createCrashLoopingPod()
+				f.RunHook()
+			})
+
+			It("Should approve the rest of nodes", func() {
+				Expect(f).To(ExecuteSuccessfully())
+
+				ng := f.KubernetesGlobalResource("NodeGroup", "ng1")
+				Expect(ng.Field("status.conditions.0.status").String()).To(Equal("False"))
+				Expect(ng.Field("status.conditions.0.reason").String()).To(Equal("Resumed"))
+				Expect(ng.Field("status.update.canaryPassed").Bool()).To(BeTrue())
+				Expect(isApproved("worker-2")).To(BeTrue())
+				Expect(isApproved("worker-3")).To(BeTrue())
+			})
+		})
+	})
+
+	Context("Disruption gates", func() {
+		const gatesNodeGroup = `
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: configuration-checksums
+  namespace: d8-cloud-instance-manager
+data:
+  ng1: dXBkYXRlZA== # updated
+---
+apiVersion: deckhouse.io/v1
+kind: NodeGroup
+metadata:
+  name: ng1
+spec:
+  nodeType: Static
+  disruptions:
+    approvalMode: Automatic
+    automatic:
+      drainBeforeApproval: false
+    gates:
+%s
+status:
+  nodes: 2
+  ready: 2
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: worker-1
+  labels:
+    node.deckhouse.io/group: ng1
+  annotations:
+    update.node.deckhouse.io/approved: ""
+    update.node.deckhouse.io/disruption-required: ""
+status:
+  conditions:
+  - type: Ready
+    status: 'True'
+`
+		const readyNode = `
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: worker-2
+  labels:
+    node.deckhouse.io/group: ng1
+status:
+  conditions:
+  - type: Ready
+    status: 'True'
+`
+		const notReadyNode = `
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: worker-2
+  labels:
+    node.deckhouse.io/group: ng1
+status:
+  conditions:
+  - type: Ready
+    status: 'False'
+`
+		// Pods and PodDisruptionBudgets are listed with the client, not from snapshots
+		createPodWithPDB := func(disruptionsAllowed int32) {
+			_, err := f.KubeClient().CoreV1().Pods("default").Create(context.TODO(), &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "app-1", Namespace: "default", Labels: map[string]string{"app": "app"}},
+				Spec:       corev1.PodSpec{NodeName: "worker-1"},
+				Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+			}, metav1.CreateOptions{})
+			Expect(err).ToNot(HaveOccurred())
+
+			pdb := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "policy/v1",
+				"kind":       "PodDisruptionBudget",
+				"metadata":   map[string]interface{}{"name": "app", "namespace": "default"},
+				"spec": map[string]interface{}{
+					"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"app": "app"}},
+				},
+				"status": map[string]interface{}{"disruptionsAllowed": int64(disruptionsAllowed)},
+			}}
+			_, err = f.KubeClient().Dynamic().Resource(pdbResource).Namespace("default").Create(context.TODO(), pdb, metav1.CreateOptions{})
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		isDisruptionApproved := func() bool {
+			return f.KubernetesGlobalResource("Node", "worker-1").Field(`metadata.annotations.update\.node\.deckhouse\.io/disruption-approved`).Exists()
+		}
+
+		respond := func(code int, body string) *http.Response {
+			return &http.Response{
+				StatusCode: code,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+			}
+		}
+
+		Context("maxUnreadyNodes is reached", func() {
+			BeforeEach(func() {
+				f.BindingContexts.Set(f.KubeStateSet(fmt.Sprintf(gatesNodeGroup, "      maxUnreadyNodes: 1") + notReadyNode))
+				f.RunHook()
+			})
+
+			It("Should not approve disruption", func() {
+				Expect(f).To(ExecuteSuccessfully())
+				Expect(isDisruptionApproved()).To(BeFalse())
+			})
+		})
+
+		Context("maxUnreadyNodes is not reached", func() {
+			BeforeEach(func() {
+				f.BindingContexts.Set(f.KubeStateSet(fmt.Sprintf(gatesNodeGroup, "      maxUnreadyNodes: 1") + readyNode))
+				f.RunHook()
+			})
+
+			It("Should approve disruption", func() {
+				Expect(f).To(ExecuteSuccessfully())
+				Expect(isDisruptionApproved()).To(BeTrue())
+			})
+		})
+
+		Context("PodDisruptionBudget does not allow eviction", func() {
+			BeforeEach(func() {
+				f.BindingContexts.Set(f.KubeStateSet(fmt.Sprintf(gatesNodeGroup, "      podDisruptionBudgets: true") + readyNode))
+				createPodWithPDB(0)
+				f.RunHook()
+			})
+
+			It("Should not approve disruption", func() {
+				Expect(f).To(ExecuteSuccessfully())
+				Expect(isDisruptionApproved()).To(BeFalse())
+			})
+		})
+
+		Context("PodDisruptionBudget allows eviction", func() {
+			BeforeEach(func() {
+				f.BindingContexts.Set(f.KubeStateSet(fmt.Sprintf(gatesNodeGroup, "      podDisruptionBudgets: true") + readyNode))
+				createPodWithPDB(1)
+				f.RunHook()
+			})
+
+			It("Should approve disruption", func() {
+				Expect(f).To(ExecuteSuccessfully())
+				Expect(isDisruptionApproved()).To(BeTrue())
+			})
+		})
+
+		Context("Prometheus alerts are firing", func() {
+			var query string
+
+			BeforeEach(func() {
+				dependency.TestDC.HTTPClient.DoMock.
+					Set(func(req *http.Request) (*http.Response, error) {
+						query = req.URL.Query().Get("query")
+						return respond(http.StatusOK, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"alertname":"NodeDiskPressure"},"value":[1609507800,"1"]}]}}`), nil
+					})
+				f.BindingContexts.Set(f.KubeStateSet(fmt.Sprintf(gatesNodeGroup, `      prometheusAlerts:
+        matchLabels:
+          severity_level: "4"`) + readyNode))
+				f.RunHook()
+			})
+
+			It("Should not approve disruption", func() {
+				Expect(f).To(ExecuteSuccessfully())
+				Expect(query).To(Equal(`ALERTS{alertstate="firing",severity_level="4"}`))
+				Expect(isDisruptionApproved()).To(BeFalse())
+			})
+		})
+
+		Context("Prometheus alerts are not firing", func() {
+			BeforeEach(func() {
+				dependency.TestDC.HTTPClient.DoMock.
+					Set(func(req *http.Request) (*http.Response, error) {
+						return respond(http.StatusOK, `{"status":"success","data":{"resultType":"vector","result":[]}}`), nil
+					})
+				f.BindingContexts.Set(f.KubeStateSet(fmt.Sprintf(gatesNodeGroup, `      prometheusAlerts: {}`) + readyNode))
+				f.RunHook()
+			})
+
+			It("Should approve disruption", func() {
+				Expect(f).To(ExecuteSuccessfully())
+				Expect(isDisruptionApproved()).To(BeTrue())
+			})
+		})
+
+		Context("Webhook vetoes disruption", func() {
+			var request string
+
+			BeforeEach(func() {
+				dependency.TestDC.HTTPClient.DoMock.
+					Set(func(req *http.Request) (*http.Response, error) {
+						body, _ := ioutil.ReadAll(req.Body)
+						request = req.Method + " " + req.URL.String() + " " + string(body)
+						return respond(http.StatusForbidden, "change freeze"), nil
+					})
+				f.BindingContexts.Set(f.KubeStateSet(fmt.Sprintf(gatesNodeGroup, `      webhook:
+        url: https://change-control.example.com/approve`) + readyNode))
+				f.RunHook()
+			})
+
+			It("Should not approve disruption", func() {
+				Expect(f).To(ExecuteSuccessfully())
+				Expect(request).To(Equal(`POST https://change-control.example.com/approve {"nodeGroup":"ng1","node":"worker-1"}`))
+				Expect(isDisruptionApproved()).To(BeFalse())
+			})
+		})
+
+		Context("Webhook allows disruption", func() {
+			BeforeEach(func() {
+				dependency.TestDC.HTTPClient.DoMock.
+					Set(func(req *http.Request) (*http.Response, error) {
+						return respond(http.StatusOK, ""), nil
+					})
+				f.BindingContexts.Set(f.KubeStateSet(fmt.Sprintf(gatesNodeGroup, `      webhook:
+        url: https://change-control.example.com/approve`) + readyNode))
+				f.RunHook()
+			})
+
+			It("Should approve disruption", func() {
+				Expect(f).To(ExecuteSuccessfully())
+				Expect(isDisruptionApproved()).To(BeTrue())
+			})
+		})
+	})
 })
 
 type skipDrainingState struct {
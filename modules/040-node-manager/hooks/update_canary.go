@@ -0,0 +1,332 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/flant/addon-operator/pkg/module_manager/go_hook"
+	"github.com/flant/shell-operator/pkg/kube/object_patch"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+
+	ngv1 "github.com/deckhouse/deckhouse/modules/040-node-manager/hooks/internal/v1"
+)
+
+// Canary update of NodeGroup configuration:
+//   * First, only `update.canary.nodes` nodes are approved for the update.
+//   * When canary nodes are up to date, they are watched for the bake time.
+//   * If canary nodes are Ready and have no crashlooping DaemonSet Pods after the bake time,
+//     the rest of nodes are approved in waves of `update.maxConcurrent` nodes.
+//   * If canary nodes are unhealthy or not Ready during the bake time, the NodeGroup update is paused with
+//     the annotation. Remove the annotation to resume the update.
+//
+// The update of any NodeGroup, with or without the canary, is paused the same way if an updated node is not Ready
+// for `update.canary.nodeReadyTimeout` (15m by default) after the approval.
+
+const (
+	updatePausedAnnotation = "update.node.deckhouse.io/paused"
+
+	conditionUpdatePaused = "UpdatePaused"
+
+	reasonCanaryFailed = "CanaryFailed"
+	reasonNodeNotReady = "NodeNotReady"
+	reasonManual       = "Manual"
+	reasonResumed      = "Resumed"
+)
+
+type nodeGroupRollout struct {
+	Status     *ngv1.UpdateStatus
+	Conditions []v1.Condition
+}
+
+// inCanary returns true if only canary nodes can be approved
+func (r *nodeGroupRollout) inCanary(ng updateNodeGroup) bool {
+	return ng.Canary != nil && r != nil && r.Status != nil && !r.Status.CanaryPassed
+}
+
+func (r *nodeGroupRollout) addCanaryNode(name string) {
+	r.Status.CanaryNodes = append(r.Status.CanaryNodes, name)
+	sort.Strings(r.Status.CanaryNodes)
+}
+
+// setApproved records the approval time of the node to measure the Ready timeout from it
+func (r *nodeGroupRollout) setApproved(name string, now time.Time) {
+	if r == nil || r.Status == nil {
+		return
+	}
+	if r.Status.ApprovedTimes == nil {
+		r.Status.ApprovedTimes = make(map[string]v1.Time)
+	}
+	r.Status.ApprovedTimes[name] = v1.NewTime(now)
+}
+
+// processRollouts checks canary nodes and updated nodes, and pauses node groups if they are unhealthy.
+func (ar *updateApprover) processRollouts(input *go_hook.HookInput) {
+	for name, ng := range ar.nodeGroups {
+		pausedCondition := meta.FindStatusCondition(ng.Status.Conditions, conditionUpdatePaused)
+
+		rollout := &nodeGroupRollout{
+			Conditions: make([]v1.Condition, len(ng.Status.Conditions)),
+		}
+		copy(rollout.Conditions, ng.Status.Conditions)
+
+		checksum := ar.ngChecksums[ng.Name]
+
+		rollout.Status = ng.Status.Update.DeepCopy()
+		if rollout.Status == nil || rollout.Status.Checksum != checksum {
+			rollout.Status = &ngv1.UpdateStatus{Checksum: checksum}
+		}
+		if rollout.Status.StartedTime == nil {
+			started := v1.NewTime(ar.now)
+			rollout.Status.StartedTime = &started
+		}
+
+		// Approval times are kept only until nodes are updated
+		for nodeName := range rollout.Status.ApprovedTimes {
+			if node, ok := ar.nodes[nodeName]; !ok || !node.IsApproved {
+				delete(rollout.Status.ApprovedTimes, nodeName)
+			}
+		}
+		if len(rollout.Status.ApprovedTimes) == 0 {
+			rollout.Status.ApprovedTimes = nil
+		}
+
+		ar.rollouts[name] = rollout
+
+		wasPaused := pausedCondition != nil && pausedCondition.Status == v1.ConditionTrue
+
+		switch {
+		case ng.IsUpdatePaused && !wasPaused:
+			ar.setPausedCondition(rollout, v1.ConditionTrue, reasonManual,
+				fmt.Sprintf("The update is paused with the %s annotation", updatePausedAnnotation))
+
+		case !ng.IsUpdatePaused && wasPaused:
+			// The user accepts failed canary nodes by resuming the update
+			if pausedCondition.Reason == reasonCanaryFailed && rollout.Status != nil {
+				rollout.Status.CanaryPassed = true
+			}
+			ar.setPausedCondition(rollout, v1.ConditionFalse, reasonResumed, "The update is resumed")
+		}
+
+		if ng.IsUpdatePaused {
+			continue
+		}
+
+		reason, message, err := ar.checkRollout(ng, rollout)
+		if err != nil {
+			input.LogEntry.Warnf("Cannot check update of NodeGroup %s: %s", ng.Name, err)
+			continue
+		}
+		if reason == "" {
+			continue
+		}
+
+		input.LogEntry.Warnf("Pause update of NodeGroup %s: %s", ng.Name, message)
+
+		patch := map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]interface{}{
+					updatePausedAnnotation: reason,
+				},
+			},
+		}
+		input.PatchCollector.MergePatch(patch, "deckhouse.io/v1", "NodeGroup", "", ng.Name)
+
+		ar.setPausedCondition(rollout, v1.ConditionTrue, reason, message)
+
+		err = createNodeGroupEvent(input, statusNodeGroup{
+			Name:            ng.Name,
+			UID:             ng.UID,
+			ResourceVersion: ng.ResourceVersion,
+		}, corev1.EventTypeWarning, "UpdatePaused", message)
+		if err != nil {
+			input.LogEntry.Warnf("Cannot create event for NodeGroup %s: %s", ng.Name, err)
+		}
+
+		// Do not approve anything in this run
+		ng.IsUpdatePaused = true
+		ar.nodeGroups[name] = ng
+	}
+}
+
+// checkRollout returns the reason and the message to pause the node group update, or empty strings.
+func (ar *updateApprover) checkRollout(ng updateNodeGroup, rollout *nodeGroupRollout) (string, string, error) {
+	status := rollout.Status
+
+	// The timeout is measured from the start of the update, and starts again after the update is resumed
+	checkedSince := status.StartedTime.Time
+	if cond := meta.FindStatusCondition(rollout.Conditions, conditionUpdatePaused); cond != nil && cond.Status == v1.ConditionFalse {
+		checkedSince = latestTime(checkedSince, cond.LastTransitionTime.Time)
+	}
+
+	var hasWaitingForApproval bool
+
+	for _, node := range ar.nodes {
+		if node.NodeGroup != ng.Name {
+			continue
+		}
+
+		if node.IsWaitingForApproval {
+			hasWaitingForApproval = true
+		}
+
+		// Node is updated by bashible, but is not Ready
+		if !node.IsApproved || node.IsReady || node.ConfigurationChecksum != status.Checksum || node.NotReadySince.IsZero() {
+			continue
+		}
+
+		// Nodes which are not Ready before the approval get the whole timeout after it
+		notReadySince := latestTime(node.NotReadySince, checkedSince, status.ApprovedTimes[node.Name].Time)
+
+		if timeout := ng.Canary.GetNodeReadyTimeout(); ar.now.Sub(notReadySince) > timeout {
+			return reasonNodeNotReady, fmt.Sprintf("Node %s is not Ready for %s after the update", node.Name, timeout), nil
+		}
+	}
+
+	if ng.Canary == nil || status.CanaryPassed || len(status.CanaryNodes) == 0 {
+		return "", "", nil
+	}
+
+	// Not all canary nodes are approved yet
+	if len(status.CanaryNodes) < int(ng.Canary.Nodes) && hasWaitingForApproval {
+		return "", "", nil
+	}
+
+	baking := status.CanaryUpdatedTime != nil
+
+	for _, name := range status.CanaryNodes {
+		node, ok := ar.nodes[name]
+		if !ok {
+			// The node is deleted
+			continue
+		}
+		if node.IsApproved || node.ConfigurationChecksum != status.Checksum {
+			return "", "", nil
+		}
+		if !node.IsReady {
+			if baking {
+				return reasonCanaryFailed, fmt.Sprintf("The canary node %s is not Ready during the bake time", name), nil
+			}
+			return "", "", nil
+		}
+	}
+
+	if !baking {
+		updated := v1.NewTime(ar.now)
+		status.CanaryUpdatedTime = &updated
+		return "", "", nil
+	}
+
+	if ar.now.Before(status.CanaryUpdatedTime.Add(ng.Canary.GetBakeTime())) {
+		return "", "", nil
+	}
+
+	for _, name := range status.CanaryNodes {
+		pods, err := ar.crashLoopingDaemonSetPods(name)
+		if err != nil {
+			return "", "", err
+		}
+		if len(pods) > 0 {
+			return reasonCanaryFailed, fmt.Sprintf("DaemonSet Pods are crashlooping on the canary node %s: %v", name, pods), nil
+		}
+	}
+
+	status.CanaryPassed = true
+
+	return "", "", nil
+}
+
+// crashLoopingDaemonSetPods returns DaemonSet Pods in CrashLoopBackOff on the node. Pods are listed only for canary
+// nodes after the bake time instead of keeping all Pods of the cluster in a snapshot.
+func (ar *updateApprover) crashLoopingDaemonSetPods(nodeName string) ([]string, error) {
+	k8sCli, err := ar.dc.GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
+
+	podList, err := k8sCli.CoreV1().Pods("").List(context.TODO(), v1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var pods []string
+	for _, pod := range podList.Items {
+		owner := v1.GetControllerOf(&pod)
+		if pod.Spec.NodeName != nodeName || owner == nil || owner.Kind != "DaemonSet" {
+			continue
+		}
+
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff" {
+				pods = append(pods, pod.Namespace+"/"+pod.Name)
+				break
+			}
+		}
+	}
+
+	return pods, nil
+}
+
+func latestTime(times ...time.Time) time.Time {
+	var latest time.Time
+	for _, t := range times {
+		if t.After(latest) {
+			latest = t
+		}
+	}
+	return latest
+}
+
+func (ar *updateApprover) setPausedCondition(rollout *nodeGroupRollout, status v1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&rollout.Conditions, v1.Condition{
+		Type:               conditionUpdatePaused,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: v1.NewTime(ar.now),
+	})
+}
+
+// patchRollouts saves canary update states and conditions to node group statuses if they are changed.
+func (ar *updateApprover) patchRollouts(input *go_hook.HookInput) {
+	for name, rollout := range ar.rollouts {
+		ng := ar.nodeGroups[name]
+
+		current, _ := json.Marshal(map[string]interface{}{"update": ng.Status.Update, "conditions": ng.Status.Conditions})
+		desired, _ := json.Marshal(map[string]interface{}{"update": rollout.Status, "conditions": rollout.Conditions})
+		if string(current) == string(desired) {
+			continue
+		}
+
+		patch := map[string]interface{}{
+			"status": map[string]interface{}{
+				"update":     rollout.Status,
+				"conditions": rollout.Conditions,
+			},
+		}
+		input.PatchCollector.MergePatch(patch, "deckhouse.io/v1", "NodeGroup", "", name, object_patch.WithSubresource("/status"))
+	}
+}
@@ -0,0 +1,320 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/flant/addon-operator/sdk"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/deckhouse/deckhouse/go_lib/dependency"
+	d8http "github.com/deckhouse/deckhouse/go_lib/dependency/http"
+	ngv1 "github.com/deckhouse/deckhouse/modules/040-node-manager/hooks/internal/v1"
+)
+
+// Disruption gates are checks of the cluster state from `disruptions.gates` of the NodeGroup.
+// All of them must pass before the disruption of a node is approved:
+//   * There are no firing Prometheus alerts with the labels from the selector.
+//   * PodDisruptionBudgets of Pods on the node allow eviction.
+//   * The webhook responds with 2xx status code.
+//   * Not ready nodes in the cluster do not exceed the limit together with the disrupted node.
+// Gates are closed if their state cannot be checked. Gates are checked on every run for every node waiting
+// for the disruption, so the cluster state and webhook responses are loaded once per run.
+
+type disruptionGates struct {
+	dc dependency.Container
+
+	// unreadyNodes are not ready nodes and nodes being disrupted
+	unreadyNodes map[string]struct{}
+
+	// lazily loaded data
+	blockingPDBs    []podDisruptionBudget
+	pdbsLoaded      bool
+	pdbPods         map[string][]corev1.Pod
+	firingAlerts    map[string]int
+	webhookVerdicts map[string]string
+}
+
+var pdbResource = schema.GroupVersionResource{Group: "policy", Version: "v1", Resource: "poddisruptionbudgets"}
+
+// podDisruptionBudget is the part of the policy/v1 PodDisruptionBudget used by the gate. PodDisruptionBudgets
+// are listed with the dynamic client, since the typed client does not support policy/v1 yet.
+type podDisruptionBudget struct {
+	v1.ObjectMeta `json:"metadata"`
+
+	Spec struct {
+		Selector *v1.LabelSelector `json:"selector"`
+	} `json:"spec"`
+
+	Status struct {
+		DisruptionsAllowed int32 `json:"disruptionsAllowed"`
+	} `json:"status"`
+}
+
+func newDisruptionGates(dc dependency.Container, nodes map[string]updateApprovalNode) *disruptionGates {
+	g := &disruptionGates{
+		dc:              dc,
+		unreadyNodes:    make(map[string]struct{}),
+		pdbPods:         make(map[string][]corev1.Pod),
+		firingAlerts:    make(map[string]int),
+		webhookVerdicts: make(map[string]string),
+	}
+
+	for _, node := range nodes {
+		if !node.IsReady || node.IsDisruptionApproved || node.IsDraining {
+			g.unreadyNodes[node.Name] = struct{}{}
+		}
+	}
+
+	return g
+}
+
+// check returns the reason why the node cannot be disrupted, or an empty string.
+func (g *disruptionGates) check(ng *updateNodeGroup, node *updateApprovalNode) string {
+	gates := ng.Disruptions.Gates
+	if gates == nil {
+		return ""
+	}
+
+	if gates.MaxUnreadyNodes != nil {
+		unready := len(g.unreadyNodes)
+		if _, ok := g.unreadyNodes[node.Name]; !ok {
+			unready++
+		}
+		if unready > int(*gates.MaxUnreadyNodes) {
+			return fmt.Sprintf("%d nodes would be not ready, the limit is %d", unready, *gates.MaxUnreadyNodes)
+		}
+	}
+
+	if gates.PrometheusAlerts != nil {
+		count, err := g.countFiringAlerts(gates.PrometheusAlerts.MatchLabels)
+		if err != nil {
+			return fmt.Sprintf("cannot get firing alerts: %s", err)
+		}
+		if count > 0 {
+			return fmt.Sprintf("%d Prometheus alerts are firing", count)
+		}
+	}
+
+	if gates.PodDisruptionBudgets {
+		reason, err := g.checkPodDisruptionBudgets(node.Name)
+		if err != nil {
+			return fmt.Sprintf("cannot check PodDisruptionBudgets: %s", err)
+		}
+		if reason != "" {
+			return reason
+		}
+	}
+
+	if gates.Webhook != nil {
+		reason := g.callWebhook(gates.Webhook, ng.Name, node.Name)
+		if reason != "" {
+			return reason
+		}
+	}
+
+	return ""
+}
+
+// markDisrupted counts the node as not ready for the next checks.
+func (g *disruptionGates) markDisrupted(nodeName string) {
+	g.unreadyNodes[nodeName] = struct{}{}
+}
+
+type alertsResponse struct {
+	Data struct {
+		Result []json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+func (g *disruptionGates) countFiringAlerts(matchLabels map[string]string) (int, error) {
+	selectors := []string{`alertstate="firing"`}
+	for name, value := range matchLabels {
+		selectors = append(selectors, fmt.Sprintf("%s=%q", name, value))
+	}
+	sort.Strings(selectors)
+
+	query := "ALERTS{" + strings.Join(selectors, ",") + "}"
+	if count, ok := g.firingAlerts[query]; ok {
+		return count, nil
+	}
+
+	cl := g.dc.GetHTTPClient(d8http.WithInsecureSkipVerify())
+
+	promURL := "https://prometheus.d8-monitoring:9090/api/v1/query?query=" + url.QueryEscape(query)
+	req, err := http.NewRequest(http.MethodGet, promURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	err = d8http.SetKubeAuthToken(req)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := cl.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	var response alertsResponse
+	err = json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		return 0, err
+	}
+
+	g.firingAlerts[query] = len(response.Data.Result)
+
+	return len(response.Data.Result), nil
+}
+
+// checkPodDisruptionBudgets returns the reason if a PodDisruptionBudget does not allow eviction of a Pod on the node.
+// Only Pods of PodDisruptionBudgets not allowing disruptions are listed.
+func (g *disruptionGates) checkPodDisruptionBudgets(nodeName string) (string, error) {
+	k8sCli, err := g.dc.GetK8sClient()
+	if err != nil {
+		return "", err
+	}
+
+	if !g.pdbsLoaded {
+		list, err := k8sCli.Dynamic().Resource(pdbResource).Namespace(v1.NamespaceAll).List(context.TODO(), v1.ListOptions{})
+		if err != nil {
+			return "", err
+		}
+		for _, item := range list.Items {
+			var pdb podDisruptionBudget
+			err := sdk.FromUnstructured(&item, &pdb)
+			if err != nil {
+				return "", err
+			}
+			if pdb.Status.DisruptionsAllowed > 0 {
+				continue
+			}
+			g.blockingPDBs = append(g.blockingPDBs, pdb)
+		}
+		g.pdbsLoaded = true
+	}
+
+	for _, pdb := range g.blockingPDBs {
+		selector, err := v1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || selector.Empty() {
+			continue
+		}
+
+		key := pdb.Namespace + "/" + pdb.Name
+		pods, ok := g.pdbPods[key]
+		if !ok {
+			podList, err := k8sCli.CoreV1().Pods(pdb.Namespace).List(context.TODO(), v1.ListOptions{
+				LabelSelector: selector.String(),
+			})
+			if err != nil {
+				return "", err
+			}
+			pods = podList.Items
+			g.pdbPods[key] = pods
+		}
+
+		for _, pod := range pods {
+			if pod.Spec.NodeName != nodeName || !isEvictedOnDrain(&pod) || !selector.Matches(labels.Set(pod.Labels)) {
+				continue
+			}
+			return fmt.Sprintf("PodDisruptionBudget %s/%s does not allow eviction of Pod %s", pdb.Namespace, pdb.Name, pod.Name), nil
+		}
+	}
+
+	return "", nil
+}
+
+// isEvictedOnDrain returns false for Pods that are skipped by drain
+func isEvictedOnDrain(pod *corev1.Pod) bool {
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return false
+	}
+
+	if _, ok := pod.Annotations[corev1.MirrorPodAnnotationKey]; ok {
+		return false
+	}
+
+	if owner := v1.GetControllerOf(pod); owner != nil && owner.Kind == "DaemonSet" {
+		return false
+	}
+
+	return true
+}
+
+type disruptionWebhookRequest struct {
+	NodeGroup string `json:"nodeGroup"`
+	Node      string `json:"node"`
+}
+
+// callWebhook returns the reason of the veto, or an empty string if the webhook allows the disruption.
+// The webhook is called once per run for the node.
+func (g *disruptionGates) callWebhook(webhook *ngv1.WebhookGate, ngName, nodeName string) string {
+	key := webhook.URL + " " + ngName + "/" + nodeName
+	if verdict, ok := g.webhookVerdicts[key]; ok {
+		return verdict
+	}
+
+	verdict := g.doWebhookRequest(webhook, ngName, nodeName)
+	g.webhookVerdicts[key] = verdict
+
+	return verdict
+}
+
+func (g *disruptionGates) doWebhookRequest(webhook *ngv1.WebhookGate, ngName, nodeName string) string {
+	body, err := json.Marshal(disruptionWebhookRequest{NodeGroup: ngName, Node: nodeName})
+	if err != nil {
+		return fmt.Sprintf("cannot build webhook request: %s", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Sprintf("cannot build webhook request: %s", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := g.dc.GetHTTPClient(d8http.WithTimeout(webhook.GetTimeout())).Do(req)
+	if err != nil {
+		return fmt.Sprintf("webhook request failed: %s", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return ""
+	}
+
+	message, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+
+	return fmt.Sprintf("webhook vetoed with status code %d: %s", res.StatusCode, strings.TrimSpace(string(message)))
+}
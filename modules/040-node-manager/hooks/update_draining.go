@@ -18,19 +18,27 @@ package hooks
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/flant/addon-operator/pkg/module_manager/go_hook"
 	"github.com/flant/addon-operator/sdk"
+	"github.com/flant/shell-operator/pkg/kube/object_patch"
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	apimtypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
 
 	"github.com/deckhouse/deckhouse/go_lib/dependency"
 	"github.com/deckhouse/deckhouse/go_lib/dependency/k8s/drain"
+	ngv1 "github.com/deckhouse/deckhouse/modules/040-node-manager/hooks/internal/v1"
 )
 
 var _ = sdk.RegisterFunc(&go_hook.HookConfig{
@@ -53,6 +61,15 @@ var _ = sdk.RegisterFunc(&go_hook.HookConfig{
 			},
 			FilterFunc: drainFilter,
 		},
+		{
+			Name:                         "ngs_for_draining",
+			WaitForSynchronization:       pointer.BoolPtr(false),
+			ExecuteHookOnSynchronization: pointer.BoolPtr(false),
+			ExecuteHookOnEvents:          pointer.BoolPtr(false),
+			ApiVersion:                   "deckhouse.io/v1",
+			Kind:                         "NodeGroup",
+			FilterFunc:                   drainNodeGroupFilter,
+		},
 	},
 	Schedule: []go_hook.ScheduleConfig{
 		{
@@ -75,15 +92,40 @@ func drainFilter(obj *unstructured.Unstructured) (go_hook.FilterResult, error) {
 		isDraining = true
 	}
 
+	var isDrained bool
+	if _, ok := node.Annotations["update.node.deckhouse.io/drained"]; ok {
+		isDrained = true
+	}
+
 	return drainingNode{
 		Name:          node.Name,
+		NodeGroup:     node.Labels["node.deckhouse.io/group"],
 		IsDraining:    isDraining,
+		IsDrained:     isDrained,
 		Unschedulable: node.Spec.Unschedulable,
 	}, nil
 }
 
+func drainNodeGroupFilter(obj *unstructured.Unstructured) (go_hook.FilterResult, error) {
+	var ng ngv1.NodeGroup
+
+	err := sdk.FromUnstructured(obj, &ng)
+	if err != nil {
+		return nil, err
+	}
+
+	return drainingNodeGroup{
+		Name:            ng.Name,
+		UID:             ng.UID,
+		ResourceVersion: ng.ResourceVersion,
+		Settings:        ng.Spec.Disruptions.Automatic.Drain,
+		Reports:         ng.Status.DrainReports,
+	}, nil
+}
+
 // Drain nodes: If node is marked for draining – drain it!
-// all nodes in one node group drain concurrently. If we need to limit this behavior - put here some queue implementation
+// Nodes are drained in the queue limited by `disruptions.automatic.drain.maxConcurrent` of the NodeGroup,
+// the rest of nodes wait for the next run. Drain reports are saved to the NodeGroup status.
 func handleDraining(input *go_hook.HookInput, dc dependency.Container) error {
 	k8sCli, err := dc.GetK8sClient()
 	if err != nil {
@@ -98,18 +140,26 @@ func handleDraining(input *go_hook.HookInput, dc dependency.Container) error {
 		}
 	}(errOut)
 
-	drainHelper := drain.NewDrainer(k8sCli, errOut)
-	drainHelper.Ctx = context.Background()
+	cordonHelper := drain.NewDrainer(k8sCli, errOut)
+	cordonHelper.Ctx = context.Background()
+
+	now := time.Now()
+
+	nodeGroups := make(map[string]drainingNodeGroup)
+	for _, s := range input.Snapshots["ngs_for_draining"] {
+		ng := s.(drainingNodeGroup)
+		nodeGroups[ng.Name] = ng
+	}
+
+	queues := newDrainQueues(nodeGroups)
+	for _, s := range input.Snapshots["nodes_for_draining"] {
+		queues.add(s.(drainingNode))
+	}
 
 	var wg = &sync.WaitGroup{}
-	drainingNodesC := make(chan drainedNodeRes, 1)
+	drainingNodesC := make(chan *drainedNodeRes, 1)
 
-	snap := input.Snapshots["nodes_for_draining"]
-	for _, s := range snap {
-		dNode := s.(drainingNode)
-		if !dNode.IsDraining {
-			continue
-		}
+	for _, dNode := range queues.next() {
 		cordonNode := &corev1.Node{
 			TypeMeta: v1.TypeMeta{
 				Kind:       "Node",
@@ -120,17 +170,27 @@ func handleDraining(input *go_hook.HookInput, dc dependency.Container) error {
 			},
 			Spec: corev1.NodeSpec{Unschedulable: dNode.Unschedulable},
 		}
-		err := drain.RunCordonOrUncordon(drainHelper, cordonNode, true)
+		err := drain.RunCordonOrUncordon(cordonHelper, cordonNode, true)
 		if err != nil {
 			input.LogEntry.Errorf("Cordon node '%s' failed: %s", dNode.Name, err)
 			continue
 		}
 
+		report := queues.report(dNode, now)
+		settings := nodeGroups[dNode.NodeGroup].Settings
+		result := &drainedNodeRes{NodeName: dNode.Name, NodeGroup: dNode.NodeGroup, blockedPods: make(map[string]struct{})}
+
+		drainHelper := drain.NewDrainer(k8sCli, errOut)
+		drainHelper.Ctx = context.Background()
+		drainHelper.OnPodDeletedOrEvicted = result.podDeletedOrEvicted
+		drainHelper.OnPodEvictionBlocked = result.podEvictionBlocked
+		drainHelper.Timeout, drainHelper.DisableDeletionOnTimeout = drainAttemptTimeout(settings, now.Sub(report.StartTime.Time))
+
 		wg.Add(1)
 		go func(nodeName string) {
 			defer wg.Done()
-			err = drain.RunNodeDrain(drainHelper, nodeName)
-			drainingNodesC <- drainedNodeRes{NodeName: nodeName, Err: err}
+			result.Err = drain.RunNodeDrain(drainHelper, nodeName)
+			drainingNodesC <- result
 		}(dNode.Name)
 	}
 
@@ -140,16 +200,183 @@ func handleDraining(input *go_hook.HookInput, dc dependency.Container) error {
 	}()
 
 	for drainedNode := range drainingNodesC {
+		report := queues.reports[drainedNode.NodeGroup][drainedNode.NodeName]
+		drainedNode.fillReport(report, time.Now())
+
 		if drainedNode.Err != nil {
 			input.LogEntry.Errorf("node drain failed: %s", drainedNode.Err)
+		} else {
+			input.PatchCollector.MergePatch(drainAnnotationsPatch, "v1", "Node", "", drainedNode.NodeName)
+		}
+
+		ng, ok := nodeGroups[drainedNode.NodeGroup]
+		if !ok {
 			continue
 		}
-		input.PatchCollector.MergePatch(drainAnnotationsPatch, "v1", "Node", "", drainedNode.NodeName)
+
+		eventType, reason := corev1.EventTypeNormal, "NodeDrained"
+		if drainedNode.Err != nil {
+			eventType, reason = corev1.EventTypeWarning, "NodeDrainFailed"
+		}
+		err := createNodeGroupEvent(input, statusNodeGroup{
+			Name:            ng.Name,
+			UID:             ng.UID,
+			ResourceVersion: ng.ResourceVersion,
+		}, eventType, reason, drainReportMessage(report))
+		if err != nil {
+			input.LogEntry.Warnf("Cannot create event for NodeGroup %s: %s", ng.Name, err)
+		}
 	}
 
+	queues.patchReports(input)
+
 	return nil
 }
 
+// drainAttemptTimeout returns the time to evict Pods and if Pods that are not evicted should be kept.
+// Pods are deleted at the end of the attempt, if the force time is reached by this moment.
+func drainAttemptTimeout(settings *ngv1.DrainSettings, elapsed time.Duration) (time.Duration, bool) {
+	timeout := settings.GetTimeout()
+
+	forceAfter := settings.GetForceAfter()
+	if forceAfter == 0 {
+		return timeout, true
+	}
+
+	left := forceAfter - elapsed
+	if left > timeout {
+		return timeout, true
+	}
+
+	// Zero timeout means infinite eviction
+	if left < time.Second {
+		left = time.Second
+	}
+
+	return left, false
+}
+
+// drainQueues are draining nodes and drain reports by node groups.
+type drainQueues struct {
+	nodeGroups map[string]drainingNodeGroup
+
+	nodes   map[string][]drainingNode
+	reports map[string]map[string]*ngv1.DrainReport
+}
+
+func newDrainQueues(nodeGroups map[string]drainingNodeGroup) *drainQueues {
+	q := &drainQueues{
+		nodeGroups: nodeGroups,
+		nodes:      make(map[string][]drainingNode),
+		reports:    make(map[string]map[string]*ngv1.DrainReport),
+	}
+
+	for name := range nodeGroups {
+		q.reports[name] = make(map[string]*ngv1.DrainReport)
+	}
+
+	return q
+}
+
+func (q *drainQueues) add(node drainingNode) {
+	ng, ok := q.nodeGroups[node.NodeGroup]
+	if _, exists := q.reports[node.NodeGroup]; !ok && !exists {
+		// Nodes without NodeGroup are drained without saving reports
+		q.reports[node.NodeGroup] = make(map[string]*ngv1.DrainReport)
+	}
+
+	// Reports are kept until the node is updated
+	if node.IsDraining || node.IsDrained {
+		for i := range ng.Reports {
+			if ng.Reports[i].Node == node.Name {
+				q.reports[node.NodeGroup][node.Name] = ng.Reports[i].DeepCopy()
+				break
+			}
+		}
+	}
+
+	if node.IsDraining {
+		q.nodes[node.NodeGroup] = append(q.nodes[node.NodeGroup], node)
+	}
+}
+
+// next returns nodes to drain in this run. Nodes that started draining earlier go first.
+func (q *drainQueues) next() []drainingNode {
+	var res []drainingNode
+
+	for ngName, nodes := range q.nodes {
+		reports := q.reports[ngName]
+
+		sort.Slice(nodes, func(i, j int) bool {
+			ri, rj := reports[nodes[i].Name], reports[nodes[j].Name]
+			if (ri != nil) != (rj != nil) {
+				return ri != nil
+			}
+			if ri != nil && !ri.StartTime.Equal(&rj.StartTime) {
+				return ri.StartTime.Before(&rj.StartTime)
+			}
+			return nodes[i].Name < nodes[j].Name
+		})
+
+		if limit := q.nodeGroups[ngName].Settings.GetMaxConcurrent(); limit > 0 && len(nodes) > limit {
+			nodes = nodes[:limit]
+		}
+
+		res = append(res, nodes...)
+	}
+
+	return res
+}
+
+// report returns the drain report of the node, the new one is started at the passed time.
+func (q *drainQueues) report(node drainingNode, now time.Time) *ngv1.DrainReport {
+	report, ok := q.reports[node.NodeGroup][node.Name]
+	if !ok || report.Drained {
+		report = &ngv1.DrainReport{
+			Node:      node.Name,
+			StartTime: v1.NewTime(now),
+		}
+		q.reports[node.NodeGroup][node.Name] = report
+	}
+
+	return report
+}
+
+// patchReports saves drain reports to node group statuses if they are changed.
+func (q *drainQueues) patchReports(input *go_hook.HookInput) {
+	for ngName, ng := range q.nodeGroups {
+		reports := make([]ngv1.DrainReport, 0, len(q.reports[ngName]))
+		for _, report := range q.reports[ngName] {
+			reports = append(reports, *report)
+		}
+		sort.Slice(reports, func(i, j int) bool {
+			return reports[i].Node < reports[j].Node
+		})
+
+		if len(reports) == 0 && len(ng.Reports) == 0 {
+			continue
+		}
+
+		current, _ := json.Marshal(ng.Reports)
+		desired, _ := json.Marshal(reports)
+		if string(current) == string(desired) {
+			continue
+		}
+
+		patch := map[string]interface{}{
+			"status": map[string]interface{}{
+				"drainReports": reports,
+			},
+		}
+		if len(reports) == 0 {
+			patch["status"] = map[string]interface{}{
+				"drainReports": nil,
+			}
+		}
+		input.PatchCollector.MergePatch(patch, "deckhouse.io/v1", "NodeGroup", "", ngName, object_patch.WithSubresource("/status"))
+	}
+}
+
 var (
 	drainAnnotationsPatch = map[string]interface{}{
 		"metadata": map[string]interface{}{
@@ -163,11 +390,88 @@ var (
 
 type drainingNode struct {
 	Name          string
+	NodeGroup     string
 	IsDraining    bool
+	IsDrained     bool
 	Unschedulable bool
 }
 
+type drainingNodeGroup struct {
+	Name     string
+	Settings *ngv1.DrainSettings
+	Reports  []ngv1.DrainReport
+
+	// for event generation
+	UID             apimtypes.UID
+	ResourceVersion string
+}
+
 type drainedNodeRes struct {
-	NodeName string
-	Err      error
+	NodeName  string
+	NodeGroup string
+	Err       error
+
+	// Drain callbacks are called from concurrent goroutines
+	mu          sync.Mutex
+	evictedPods int32
+	deletedPods int32
+	blockedPods map[string]struct{}
+}
+
+func drainReportMessage(report *ngv1.DrainReport) string {
+	msg := fmt.Sprintf("Node %s is drained in %s", report.Node, report.Elapsed.Duration)
+	if !report.Drained {
+		msg = fmt.Sprintf("Node %s is not drained in %s", report.Node, report.Elapsed.Duration)
+	}
+
+	msg += fmt.Sprintf(": %d Pods evicted, %d Pods deleted", report.EvictedPods, report.DeletedPods)
+
+	if len(report.BlockedPods) > 0 {
+		msg += fmt.Sprintf(", Pods blocked by PodDisruptionBudgets: %s", strings.Join(report.BlockedPods, ", "))
+	}
+	if report.Error != "" {
+		msg += ": " + report.Error
+	}
+
+	return msg
+}
+
+func (r *drainedNodeRes) podDeletedOrEvicted(pod *corev1.Pod, usingEviction bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if usingEviction {
+		r.evictedPods++
+	} else {
+		r.deletedPods++
+	}
+	delete(r.blockedPods, pod.Namespace+"/"+pod.Name)
+}
+
+func (r *drainedNodeRes) podEvictionBlocked(pod *corev1.Pod, _ error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.blockedPods[pod.Namespace+"/"+pod.Name] = struct{}{}
+}
+
+func (r *drainedNodeRes) fillReport(report *ngv1.DrainReport, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report.Elapsed = v1.Duration{Duration: now.Sub(report.StartTime.Time).Round(time.Second)}
+	report.EvictedPods += r.evictedPods
+	report.DeletedPods += r.deletedPods
+
+	report.BlockedPods = nil
+	for pod := range r.blockedPods {
+		report.BlockedPods = append(report.BlockedPods, pod)
+	}
+	sort.Strings(report.BlockedPods)
+
+	report.Drained = r.Err == nil
+	report.Error = ""
+	if r.Err != nil {
+		report.Error = r.Err.Error()
+	}
 }
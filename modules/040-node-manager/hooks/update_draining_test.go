@@ -26,6 +26,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
 
 	. "github.com/deckhouse/deckhouse/testing/hooks"
 )
@@ -160,4 +161,132 @@ data:
 			}
 		}
 	})
+
+	Context("NodeGroup with drain settings", func() {
+		var state = `
+---
+apiVersion: deckhouse.io/v1
+kind: NodeGroup
+metadata:
+  name: worker
+spec:
+  nodeType: Static
+  disruptions:
+    approvalMode: Automatic
+    automatic:
+      drain:
+        maxConcurrent: 1
+        timeout: 1m
+        forceAfter: Never
+status:
+  drainReports:
+  - node: worker-3
+    startTime: "2021-01-01T13:00:00Z"
+    elapsed: 1m0s
+    evictedPods: 1
+    deletedPods: 0
+    drained: true
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: worker-1
+  labels:
+    node.deckhouse.io/group: worker
+  annotations:
+    update.node.deckhouse.io/draining: ""
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: worker-2
+  labels:
+    node.deckhouse.io/group: worker
+  annotations:
+    update.node.deckhouse.io/draining: ""
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: worker-3
+  labels:
+    node.deckhouse.io/group: worker
+`
+
+		BeforeEach(func() {
+			f.ValuesSet("global.discovery.kubernetesVersion", "1.21.0")
+			f.KubeStateSet(state)
+			f.BindingContexts.Set(f.GenerateScheduleContext("* * * * *"))
+			k8sClient := f.BindingContextController.FakeCluster().Client
+			// drainHelper works with CoreV1 from kubernetes.Interface client
+			for _, name := range []string{"worker-1", "worker-2", "worker-3"} {
+				_, _ = k8sClient.CoreV1().Nodes().Create(context.Background(), &corev1.Node{ObjectMeta: v1.ObjectMeta{Name: name}}, v1.CreateOptions{})
+			}
+			_, _ = k8sClient.CoreV1().Pods("default").Create(context.Background(), &corev1.Pod{
+				ObjectMeta: v1.ObjectMeta{
+					Name:            "app-1",
+					Namespace:       "default",
+					OwnerReferences: []v1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "app", Controller: pointer.BoolPtr(true)}},
+				},
+				Spec: corev1.PodSpec{NodeName: "worker-1"},
+			}, v1.CreateOptions{})
+			f.RunHook()
+		})
+
+		It("Must drain one node and report it", func() {
+			Expect(f).To(ExecuteSuccessfully())
+
+			Expect(f.KubernetesGlobalResource("Node", "worker-1").Field(`metadata.annotations.update\.node\.deckhouse\.io/drained`).Exists()).To(BeTrue())
+			Expect(f.KubernetesGlobalResource("Node", "worker-2").Field(`metadata.annotations.update\.node\.deckhouse\.io/draining`).Exists()).To(BeTrue())
+			Expect(f.KubernetesGlobalResource("Node", "worker-2").Field(`metadata.annotations.update\.node\.deckhouse\.io/drained`).Exists()).To(BeFalse())
+
+			reports := f.KubernetesGlobalResource("NodeGroup", "worker").Field("status.drainReports").Array()
+			Expect(reports).To(HaveLen(1))
+			Expect(reports[0].Get("node").String()).To(Equal("worker-1"))
+			Expect(reports[0].Get("drained").Bool()).To(BeTrue())
+			Expect(reports[0].Get("evictedPods").Int() + reports[0].Get("deletedPods").Int()).To(Equal(int64(1)))
+			Expect(reports[0].Get("startTime").Exists()).To(BeTrue())
+		})
+	})
+
+	Context("NodeGroup with empty drain timeout", func() {
+		var state = `
+---
+apiVersion: deckhouse.io/v1
+kind: NodeGroup
+metadata:
+  name: worker
+spec:
+  nodeType: Static
+  disruptions:
+    approvalMode: Automatic
+    automatic:
+      drain:
+        timeout: ""
+---
+apiVersion: v1
+kind: Node
+metadata:
+  name: worker-1
+  labels:
+    node.deckhouse.io/group: worker
+  annotations:
+    update.node.deckhouse.io/draining: ""
+`
+
+		BeforeEach(func() {
+			f.ValuesSet("global.discovery.kubernetesVersion", "1.21.0")
+			f.KubeStateSet(state)
+			f.BindingContexts.Set(f.GenerateScheduleContext("* * * * *"))
+			k8sClient := f.BindingContextController.FakeCluster().Client
+			_, _ = k8sClient.CoreV1().Nodes().Create(context.Background(), &corev1.Node{ObjectMeta: v1.ObjectMeta{Name: "worker-1"}}, v1.CreateOptions{})
+			f.RunHook()
+		})
+
+		It("Must drain the node with the default timeout", func() {
+			Expect(f).To(ExecuteSuccessfully())
+
+			Expect(f.KubernetesGlobalResource("Node", "worker-1").Field(`metadata.annotations.update\.node\.deckhouse\.io/drained`).Exists()).To(BeTrue())
+		})
+	})
 })
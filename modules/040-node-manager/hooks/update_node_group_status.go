@@ -60,6 +60,12 @@ var _ = sdk.RegisterFunc(&go_hook.HookConfig{
 		// ns: "d8-cloud-instance-manager"
 		// name: "configuration-checksums"
 		shared.ConfigurationChecksumHookConfig(),
+		// snapshot: "bashible_statuses"
+		// api: "v1",
+		// kind: "Secret",
+		// ns: "d8-cloud-instance-manager"
+		// labelSelector: "node.deckhouse.io/bashible-status"
+		shared.BashibleStatusHookConfig(),
 		{
 			Name:                   "ngs",
 			Kind:                   "NodeGroup",
@@ -287,9 +293,24 @@ func handleUpdateNGStatus(input *go_hook.HookInput) error {
 
 	snap = input.Snapshots["nodes"]
 	nodes := make([]statusNode, 0, len(snap))
+	existingNodes := make(map[string]struct{}, len(snap))
 	for _, sn := range snap {
 		node := sn.(statusNode)
 		nodes = append(nodes, node)
+		existingNodes[node.Name] = struct{}{}
+	}
+
+	// bashible statuses reported by nodes
+	snap = input.Snapshots["bashible_statuses"]
+	bashibleStatuses := make(map[string]shared.BashibleStatus, len(snap))
+	for _, sn := range snap {
+		status := sn.(shared.BashibleStatus)
+		if _, ok := existingNodes[status.Node]; !ok {
+			// the node is deleted, nobody will update its status
+			input.PatchCollector.Delete("v1", "Secret", "d8-cloud-instance-manager", status.SecretName)
+			continue
+		}
+		bashibleStatuses[status.Node] = status
 	}
 
 	// iterate over all node groups and calculate desired and current status
@@ -371,12 +392,15 @@ func handleUpdateNGStatus(input *go_hook.HookInput) error {
 
 		instancesCount := instances[ngName]
 
+		bashibleStatus := buildBashibleStatus(ngName, nodes, bashibleStatuses)
+
 		patch := buildUpdateStatusPatch(
 			nodesNum, readyNodesNum, uptodateNodesCount,
 			minPerZone, maxPerZone,
 			desiredMax, instancesCount,
 			nodeGroup.NodeType, statusMsg,
 			lastMachineFailures,
+			bashibleStatus,
 		)
 
 		input.PatchCollector.MergePatch(patch, "deckhouse.io/v1", "NodeGroup", "", ngName, object_patch.WithSubresource("/status"))
@@ -393,6 +417,11 @@ func createEvent(input *go_hook.HookInput, nodeGroup statusNodeGroup, msg string
 		eventType = corev1.EventTypeNormal
 		reason = "MachineCreating"
 	}
+
+	return createNodeGroupEvent(input, nodeGroup, eventType, reason, msg)
+}
+
+func createNodeGroupEvent(input *go_hook.HookInput, nodeGroup statusNodeGroup, eventType, reason, msg string) error {
 	now := time.Now()
 	minK8sVersionStr := input.Values.Get("global.discovery.kubernetesVersion").String()
 
@@ -482,6 +511,7 @@ func buildUpdateStatusPatch(
 	desiredMax, instancesNum int32,
 	nodeType ngv1.NodeType, statusMsg string,
 	lastMachineFailures []*v1alpha1.MachineSummary,
+	bashibleStatus *ngv1.BashibleStatus,
 ) interface{} {
 	ready := "True"
 	if len(statusMsg) > 0 {
@@ -510,6 +540,9 @@ func buildUpdateStatusPatch(
 		"statusMessage": statusMsg,
 	}
 
+	// nil removes the summary when nodes of the group have not reported yet
+	patch["bashible"] = bashibleStatus
+
 	statusPatch := map[string]interface{}{
 		"status": patch,
 	}
@@ -517,6 +550,55 @@ func buildUpdateStatusPatch(
 	return statusPatch
 }
 
+// failed steps are kept in the NodeGroup status, their output should not bloat the object
+const maxBashibleFailedSteps = 10
+
+// buildBashibleStatus aggregates bashible statuses reported by the nodes of the group
+func buildBashibleStatus(ngName string, nodes []statusNode, statuses map[string]shared.BashibleStatus) *ngv1.BashibleStatus {
+	var result ngv1.BashibleStatus
+
+	for _, node := range nodes {
+		if node.CloudInstanceGroup != ngName {
+			continue
+		}
+
+		status, ok := statuses[node.Name]
+		if !ok {
+			continue
+		}
+
+		result.Reported++
+
+		step := status.FailedStep()
+		if step == nil {
+			continue
+		}
+
+		result.Failed++
+		result.FailedSteps = append(result.FailedSteps, ngv1.BashibleFailedStep{
+			Node:       node.Name,
+			Step:       step.Name,
+			Checksum:   step.Checksum,
+			ExitCode:   step.ExitCode,
+			Output:     step.Output,
+			FinishedAt: step.FinishedAt,
+		})
+	}
+
+	if result.Reported == 0 {
+		return nil
+	}
+
+	sort.Slice(result.FailedSteps, func(i, j int) bool {
+		return result.FailedSteps[i].Node < result.FailedSteps[j].Node
+	})
+	if len(result.FailedSteps) > maxBashibleFailedSteps {
+		result.FailedSteps = result.FailedSteps[:maxBashibleFailedSteps]
+	}
+
+	return &result
+}
+
 type statusNodeGroup struct {
 	Name       string
 	NodeType   ngv1.NodeType
@@ -341,4 +341,54 @@ status:
 			Expect(f.KubernetesGlobalResource("NodeGroup", "ng-2").Field("status").String()).To(MatchJSON(`{"max":9,"min":6,"desired":6,"instances":0,"nodes":0,"ready":0,"upToDate": 0, "lastMachineFailures": [{"lastOperation":{"description":"Cloud provider message - rpc error: code = FailedPrecondition desc = Image not found.","lastUpdateTime":"2020-05-15T15:01:13Z","state":"Failed","type":"Create"},"name":"machine-ng-2-bbb","ownerRef":"korker-3e52ee98-8649499f7"},{"lastOperation":{"description":"Cloud provider message - rpc error: code = FailedPrecondition desc = Image not found #2.","lastUpdateTime":"2020-05-15T15:01:15Z","state":"Failed","type":"Create"},"name":"machine-ng-2-aaa","ownerRef":"korker-3e52ee98-8649499f7"},{"lastOperation":{"description":"Cloud provider message - rpc error: code = FailedPrecondition desc = Image not found #3.","lastUpdateTime":"2020-05-15T15:05:12Z","state":"Failed","type":"Create"},"name":"machine-ng-2-ccc","ownerRef":"korker-3e52ee98-8649499f7"}], "error": "Wrong classReference: Kind ImproperInstanceClass is not allowed, the only allowed kind is D8TestInstanceClass.",  "conditionSummary": {"statusMessage": "Machine creation failed. Check events for details.", "ready": "False"}}`))
 		})
 	})
+
+	Context("NG with nodes reported bashible statuses", func() {
+		const stateBashibleStatuses = `
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: bashible-status-node-ng1-aaa
+  namespace: d8-cloud-instance-manager
+  labels:
+    app: bashible-apiserver
+    node.deckhouse.io/bashible-status: ""
+data:
+  status: eyJhcGlWZXJzaW9uIjoiYmFzaGlibGUuZGVja2hvdXNlLmlvL3YxYWxwaGExIiwia2luZCI6IkJhc2hpYmxlU3RhdHVzIiwibWV0YWRhdGEiOnsibmFtZSI6Im5vZGUtbmcxLWFhYSIsImNyZWF0aW9uVGltZXN0YW1wIjoiMjAyMi0wNS0xNVQxNTowMToxM1oifSwibm9kZUdyb3VwIjoibmcxIiwiY29uZmlndXJhdGlvbkNoZWNrc3VtIjoiMTIzIiwic3RlcHMiOlt7Im5hbWUiOiIwMDBfY29uZmlndXJlX2t1YmVsZXQuc2giLCJjaGVja3N1bSI6ImFhYSIsImV4aXRDb2RlIjowLCJkdXJhdGlvbiI6IjJzIiwiZmluaXNoZWRBdCI6IjIwMjItMDUtMTVUMTU6MDE6MTBaIn0seyJuYW1lIjoiMDAxX2luc3RhbGxfY29udGFpbmVyZC5zaCIsImNoZWNrc3VtIjoiYmJiIiwiZXhpdENvZGUiOjEsImR1cmF0aW9uIjoiNXMiLCJvdXRwdXQiOiJFOiBVbmFibGUgdG8gbG9jYXRlIHBhY2thZ2UgY29udGFpbmVyZCIsImZpbmlzaGVkQXQiOiIyMDIyLTA1LTE1VDE1OjAxOjEzWiJ9XX0=
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: bashible-status-node-ng1-bbb
+  namespace: d8-cloud-instance-manager
+  labels:
+    app: bashible-apiserver
+    node.deckhouse.io/bashible-status: ""
+data:
+  status: eyJhcGlWZXJzaW9uIjoiYmFzaGlibGUuZGVja2hvdXNlLmlvL3YxYWxwaGExIiwia2luZCI6IkJhc2hpYmxlU3RhdHVzIiwibWV0YWRhdGEiOnsibmFtZSI6Im5vZGUtbmcxLWJiYiIsImNyZWF0aW9uVGltZXN0YW1wIjoiMjAyMi0wNS0xNVQxNTowMToxM1oifSwibm9kZUdyb3VwIjoibmcxIiwiY29uZmlndXJhdGlvbkNoZWNrc3VtIjoiMTIzIiwic3RlcHMiOlt7Im5hbWUiOiIwMDBfY29uZmlndXJlX2t1YmVsZXQuc2giLCJjaGVja3N1bSI6ImFhYSIsImV4aXRDb2RlIjowLCJkdXJhdGlvbiI6IjJzIiwiZmluaXNoZWRBdCI6IjIwMjItMDUtMTVUMTU6MDE6MTBaIn1dfQ==
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: bashible-status-node-deleted
+  namespace: d8-cloud-instance-manager
+  labels:
+    app: bashible-apiserver
+    node.deckhouse.io/bashible-status: ""
+data:
+  status: eyJhcGlWZXJzaW9uIjoiYmFzaGlibGUuZGVja2hvdXNlLmlvL3YxYWxwaGExIiwia2luZCI6IkJhc2hpYmxlU3RhdHVzIiwibWV0YWRhdGEiOnsibmFtZSI6Im5vZGUtZGVsZXRlZCIsImNyZWF0aW9uVGltZXN0YW1wIjoiMjAyMi0wNS0xNVQxNTowMToxM1oifSwibm9kZUdyb3VwIjoibmcxIiwiY29uZmlndXJhdGlvbkNoZWNrc3VtIjoiMTIzIiwic3RlcHMiOlt7Im5hbWUiOiIwMDBfY29uZmlndXJlX2t1YmVsZXQuc2giLCJjaGVja3N1bSI6ImFhYSIsImV4aXRDb2RlIjowLCJkdXJhdGlvbiI6IjJzIiwiZmluaXNoZWRBdCI6IjIwMjItMDUtMTVUMTU6MDE6MTBaIn1dfQ==
+`
+
+		BeforeEach(func() {
+			f.BindingContexts.Set(f.KubeStateSetAndWaitForBindingContexts(stateNG1+stateNodes+configurationChecksums+stateBashibleStatuses, 1))
+			f.RunHook()
+		})
+
+		It("NG's status.bashible must be filled, stale statuses must be deleted", func() {
+			Expect(f).To(ExecuteSuccessfully())
+			Expect(f.KubernetesGlobalResource("NodeGroup", "ng1").Field("status.bashible").String()).To(MatchJSON(`{"reported":2,"failed":1,"failedSteps":[{"node":"node-ng1-aaa","step":"001_install_containerd.sh","checksum":"bbb","exitCode":1,"output":"E: Unable to locate package containerd","finishedAt":"2022-05-15T15:01:13Z"}]}`))
+			Expect(f.KubernetesResource("Secret", "d8-cloud-instance-manager", "bashible-status-node-ng1-aaa").Exists()).To(BeTrue())
+			Expect(f.KubernetesResource("Secret", "d8-cloud-instance-manager", "bashible-status-node-deleted").Exists()).To(BeFalse())
+		})
+	})
 })
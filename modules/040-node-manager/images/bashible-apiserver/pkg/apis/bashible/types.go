@@ -26,6 +26,7 @@ type ReferenceType string
 const (
 	BashibleReferenceType        = ReferenceType("Bashible")
 	NodeGroupBundleReferenceType = ReferenceType("NodeGroupBundle")
+	BashibleStatusReferenceType  = ReferenceType("BashibleStatus")
 )
 
 // +genclient
@@ -75,3 +76,47 @@ type NodeGroupBundleList struct {
 
 	Items []NodeGroupBundle
 }
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BashibleStatus contains results of the last bashible run on a node
+type BashibleStatus struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	// NodeGroup of the node
+	NodeGroup string
+	// ConfigurationChecksum of the bashible run
+	ConfigurationChecksum string
+	// Steps contains results of the executed steps
+	Steps []BashibleStepStatus
+}
+
+// BashibleStepStatus is the result of the last execution of a bashible step
+type BashibleStepStatus struct {
+	// Name of the step
+	Name string
+	// Checksum of the step content
+	Checksum string
+	// ExitCode of the step
+	ExitCode int32
+	// Duration of the step execution
+	Duration metav1.Duration
+	// Output contains the last lines of the step output
+	Output string
+	// FinishedAt is the time when the step finished
+	FinishedAt metav1.Time
+}
+
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BashibleStatusList is a list of BashibleStatus objects.
+type BashibleStatusList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+
+	Items []BashibleStatus
+}
@@ -24,6 +24,7 @@ type ReferenceType string
 const (
 	BashibleReferenceType        = ReferenceType("Bashible")
 	NodeGroupBundleReferenceType = ReferenceType("NodeGroupBundle")
+	BashibleStatusReferenceType  = ReferenceType("BashibleStatus")
 )
 
 // +genclient
@@ -74,3 +75,47 @@ type NodeGroupBundleList struct {
 
 	Items []NodeGroupBundle `json:"items" protobuf:"bytes,2,rep,name=items"`
 }
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BashibleStatus contains results of the last bashible run on a node, the name is the node name
+type BashibleStatus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// NodeGroup of the node
+	NodeGroup string `json:"nodeGroup,omitempty" protobuf:"bytes,2,opt,name=nodeGroup"`
+	// ConfigurationChecksum of the bashible run
+	ConfigurationChecksum string `json:"configurationChecksum,omitempty" protobuf:"bytes,3,opt,name=configurationChecksum"`
+	// Steps contains results of the executed steps in the order of execution
+	Steps []BashibleStepStatus `json:"steps,omitempty" protobuf:"bytes,4,rep,name=steps"`
+}
+
+// BashibleStepStatus is the result of the last execution of a bashible step
+type BashibleStepStatus struct {
+	// Name of the step
+	Name string `json:"name" protobuf:"bytes,1,opt,name=name"`
+	// Checksum of the step content
+	Checksum string `json:"checksum,omitempty" protobuf:"bytes,2,opt,name=checksum"`
+	// ExitCode of the step
+	ExitCode int32 `json:"exitCode" protobuf:"varint,3,opt,name=exitCode"`
+	// Duration of the step execution
+	Duration metav1.Duration `json:"duration,omitempty" protobuf:"bytes,4,opt,name=duration"`
+	// Output contains the last lines of the step output
+	Output string `json:"output,omitempty" protobuf:"bytes,5,opt,name=output"`
+	// FinishedAt is the time when the step finished
+	FinishedAt metav1.Time `json:"finishedAt,omitempty" protobuf:"bytes,6,opt,name=finishedAt"`
+}
+
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BashibleStatusList is a list of BashibleStatus objects.
+type BashibleStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	Items []BashibleStatus `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
@@ -1,3 +1,4 @@
+//go:build !ignore_autogenerated
 // +build !ignore_autogenerated
 
 /*
@@ -56,6 +57,36 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*BashibleStatus)(nil), (*bashible.BashibleStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_BashibleStatus_To_bashible_BashibleStatus(a.(*BashibleStatus), b.(*bashible.BashibleStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*bashible.BashibleStatus)(nil), (*BashibleStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_bashible_BashibleStatus_To_v1alpha1_BashibleStatus(a.(*bashible.BashibleStatus), b.(*BashibleStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*BashibleStatusList)(nil), (*bashible.BashibleStatusList)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_BashibleStatusList_To_bashible_BashibleStatusList(a.(*BashibleStatusList), b.(*bashible.BashibleStatusList), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*bashible.BashibleStatusList)(nil), (*BashibleStatusList)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_bashible_BashibleStatusList_To_v1alpha1_BashibleStatusList(a.(*bashible.BashibleStatusList), b.(*BashibleStatusList), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*BashibleStepStatus)(nil), (*bashible.BashibleStepStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_BashibleStepStatus_To_bashible_BashibleStepStatus(a.(*BashibleStepStatus), b.(*bashible.BashibleStepStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*bashible.BashibleStepStatus)(nil), (*BashibleStepStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_bashible_BashibleStepStatus_To_v1alpha1_BashibleStepStatus(a.(*bashible.BashibleStepStatus), b.(*BashibleStepStatus), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*NodeGroupBundle)(nil), (*bashible.NodeGroupBundle)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_NodeGroupBundle_To_bashible_NodeGroupBundle(a.(*NodeGroupBundle), b.(*bashible.NodeGroupBundle), scope)
 	}); err != nil {
@@ -123,6 +154,84 @@ func Convert_bashible_BashibleList_To_v1alpha1_BashibleList(in *bashible.Bashibl
 	return autoConvert_bashible_BashibleList_To_v1alpha1_BashibleList(in, out, s)
 }
 
+func autoConvert_v1alpha1_BashibleStatus_To_bashible_BashibleStatus(in *BashibleStatus, out *bashible.BashibleStatus, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	out.NodeGroup = in.NodeGroup
+	out.ConfigurationChecksum = in.ConfigurationChecksum
+	out.Steps = *(*[]bashible.BashibleStepStatus)(unsafe.Pointer(&in.Steps))
+	return nil
+}
+
+// Convert_v1alpha1_BashibleStatus_To_bashible_BashibleStatus is an autogenerated conversion function.
+func Convert_v1alpha1_BashibleStatus_To_bashible_BashibleStatus(in *BashibleStatus, out *bashible.BashibleStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_BashibleStatus_To_bashible_BashibleStatus(in, out, s)
+}
+
+func autoConvert_bashible_BashibleStatus_To_v1alpha1_BashibleStatus(in *bashible.BashibleStatus, out *BashibleStatus, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	out.NodeGroup = in.NodeGroup
+	out.ConfigurationChecksum = in.ConfigurationChecksum
+	out.Steps = *(*[]BashibleStepStatus)(unsafe.Pointer(&in.Steps))
+	return nil
+}
+
+// Convert_bashible_BashibleStatus_To_v1alpha1_BashibleStatus is an autogenerated conversion function.
+func Convert_bashible_BashibleStatus_To_v1alpha1_BashibleStatus(in *bashible.BashibleStatus, out *BashibleStatus, s conversion.Scope) error {
+	return autoConvert_bashible_BashibleStatus_To_v1alpha1_BashibleStatus(in, out, s)
+}
+
+func autoConvert_v1alpha1_BashibleStatusList_To_bashible_BashibleStatusList(in *BashibleStatusList, out *bashible.BashibleStatusList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	out.Items = *(*[]bashible.BashibleStatus)(unsafe.Pointer(&in.Items))
+	return nil
+}
+
+// Convert_v1alpha1_BashibleStatusList_To_bashible_BashibleStatusList is an autogenerated conversion function.
+func Convert_v1alpha1_BashibleStatusList_To_bashible_BashibleStatusList(in *BashibleStatusList, out *bashible.BashibleStatusList, s conversion.Scope) error {
+	return autoConvert_v1alpha1_BashibleStatusList_To_bashible_BashibleStatusList(in, out, s)
+}
+
+func autoConvert_bashible_BashibleStatusList_To_v1alpha1_BashibleStatusList(in *bashible.BashibleStatusList, out *BashibleStatusList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	out.Items = *(*[]BashibleStatus)(unsafe.Pointer(&in.Items))
+	return nil
+}
+
+// Convert_bashible_BashibleStatusList_To_v1alpha1_BashibleStatusList is an autogenerated conversion function.
+func Convert_bashible_BashibleStatusList_To_v1alpha1_BashibleStatusList(in *bashible.BashibleStatusList, out *BashibleStatusList, s conversion.Scope) error {
+	return autoConvert_bashible_BashibleStatusList_To_v1alpha1_BashibleStatusList(in, out, s)
+}
+
+func autoConvert_v1alpha1_BashibleStepStatus_To_bashible_BashibleStepStatus(in *BashibleStepStatus, out *bashible.BashibleStepStatus, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Checksum = in.Checksum
+	out.ExitCode = in.ExitCode
+	out.Duration = in.Duration
+	out.Output = in.Output
+	out.FinishedAt = in.FinishedAt
+	return nil
+}
+
+// Convert_v1alpha1_BashibleStepStatus_To_bashible_BashibleStepStatus is an autogenerated conversion function.
+func Convert_v1alpha1_BashibleStepStatus_To_bashible_BashibleStepStatus(in *BashibleStepStatus, out *bashible.BashibleStepStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_BashibleStepStatus_To_bashible_BashibleStepStatus(in, out, s)
+}
+
+func autoConvert_bashible_BashibleStepStatus_To_v1alpha1_BashibleStepStatus(in *bashible.BashibleStepStatus, out *BashibleStepStatus, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Checksum = in.Checksum
+	out.ExitCode = in.ExitCode
+	out.Duration = in.Duration
+	out.Output = in.Output
+	out.FinishedAt = in.FinishedAt
+	return nil
+}
+
+// Convert_bashible_BashibleStepStatus_To_v1alpha1_BashibleStepStatus is an autogenerated conversion function.
+func Convert_bashible_BashibleStepStatus_To_v1alpha1_BashibleStepStatus(in *bashible.BashibleStepStatus, out *BashibleStepStatus, s conversion.Scope) error {
+	return autoConvert_bashible_BashibleStepStatus_To_v1alpha1_BashibleStepStatus(in, out, s)
+}
+
 func autoConvert_v1alpha1_NodeGroupBundle_To_bashible_NodeGroupBundle(in *NodeGroupBundle, out *bashible.NodeGroupBundle, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	out.Data = *(*map[string]string)(unsafe.Pointer(&in.Data))
@@ -28,6 +28,7 @@ import (
 type BashibleV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	BashiblesGetter
+	BashibleStatusesGetter
 	NodeGroupBundlesGetter
 }
 
@@ -40,6 +41,10 @@ func (c *BashibleV1alpha1Client) Bashibles() BashibleInterface {
 	return newBashibles(c)
 }
 
+func (c *BashibleV1alpha1Client) BashibleStatuses() BashibleStatusInterface {
+	return newBashibleStatuses(c)
+}
+
 func (c *BashibleV1alpha1Client) NodeGroupBundles() NodeGroupBundleInterface {
 	return newNodeGroupBundles(c)
 }
@@ -33,6 +33,10 @@ func (c *FakeBashibleV1alpha1) Bashibles() v1alpha1.BashibleInterface {
 	return &FakeBashibles{c}
 }
 
+func (c *FakeBashibleV1alpha1) BashibleStatuses() v1alpha1.BashibleStatusInterface {
+	return &FakeBashibleStatuses{c}
+}
+
 func (c *FakeBashibleV1alpha1) NodeGroupBundles() v1alpha1.NodeGroupBundleInterface {
 	return &FakeNodeGroupBundles{c}
 }
@@ -20,4 +20,6 @@ package v1alpha1
 
 type BashibleExpansion interface{}
 
+type BashibleStatusExpansion interface{}
+
 type NodeGroupBundleExpansion interface{}
@@ -26,6 +26,8 @@ import (
 type Interface interface {
 	// Bashibles returns a BashibleInformer.
 	Bashibles() BashibleInformer
+	// BashibleStatuses returns a BashibleStatusInformer.
+	BashibleStatuses() BashibleStatusInformer
 	// NodeGroupBundles returns a NodeGroupBundleInformer.
 	NodeGroupBundles() NodeGroupBundleInformer
 }
@@ -46,6 +48,11 @@ func (v *version) Bashibles() BashibleInformer {
 	return &bashibleInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
 
+// BashibleStatuses returns a BashibleStatusInformer.
+func (v *version) BashibleStatuses() BashibleStatusInformer {
+	return &bashibleStatusInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // NodeGroupBundles returns a NodeGroupBundleInformer.
 func (v *version) NodeGroupBundles() NodeGroupBundleInformer {
 	return &nodeGroupBundleInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
@@ -56,6 +56,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	// Group=bashible.deckhouse.io, Version=v1alpha1
 	case v1alpha1.SchemeGroupVersion.WithResource("bashibles"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Bashible().V1alpha1().Bashibles().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("bashiblestatuses"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Bashible().V1alpha1().BashibleStatuses().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("nodegroupbundles"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Bashible().V1alpha1().NodeGroupBundles().Informer()}, nil
 
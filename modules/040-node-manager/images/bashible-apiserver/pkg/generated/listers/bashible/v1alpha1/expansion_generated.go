@@ -22,6 +22,10 @@ package v1alpha1
 // BashibleLister.
 type BashibleListerExpansion interface{}
 
+// BashibleStatusListerExpansion allows custom methods to be added to
+// BashibleStatusLister.
+type BashibleStatusListerExpansion interface{}
+
 // NodeGroupBundleListerExpansion allows custom methods to be added to
 // NodeGroupBundleLister.
 type NodeGroupBundleListerExpansion interface{}
@@ -1,3 +1,4 @@
+//go:build !ignore_autogenerated
 // +build !ignore_autogenerated
 
 /*
@@ -32,6 +33,9 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 	return map[string]common.OpenAPIDefinition{
 		"d8.io/bashible/pkg/apis/bashible/v1alpha1.Bashible":             schema_pkg_apis_bashible_v1alpha1_Bashible(ref),
 		"d8.io/bashible/pkg/apis/bashible/v1alpha1.BashibleList":         schema_pkg_apis_bashible_v1alpha1_BashibleList(ref),
+		"d8.io/bashible/pkg/apis/bashible/v1alpha1.BashibleStatus":       schema_pkg_apis_bashible_v1alpha1_BashibleStatus(ref),
+		"d8.io/bashible/pkg/apis/bashible/v1alpha1.BashibleStatusList":   schema_pkg_apis_bashible_v1alpha1_BashibleStatusList(ref),
+		"d8.io/bashible/pkg/apis/bashible/v1alpha1.BashibleStepStatus":   schema_pkg_apis_bashible_v1alpha1_BashibleStepStatus(ref),
 		"d8.io/bashible/pkg/apis/bashible/v1alpha1.NodeGroupBundle":      schema_pkg_apis_bashible_v1alpha1_NodeGroupBundle(ref),
 		"d8.io/bashible/pkg/apis/bashible/v1alpha1.NodeGroupBundleList":  schema_pkg_apis_bashible_v1alpha1_NodeGroupBundleList(ref),
 		"k8s.io/apimachinery/pkg/apis/meta/v1.APIGroup":                  schema_pkg_apis_meta_v1_APIGroup(ref),
@@ -192,6 +196,181 @@ func schema_pkg_apis_bashible_v1alpha1_BashibleList(ref common.ReferenceCallback
 	}
 }
 
+func schema_pkg_apis_bashible_v1alpha1_BashibleStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "BashibleStatus contains results of the last bashible run on a node, the name is the node name",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"nodeGroup": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NodeGroup of the node",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"configurationChecksum": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ConfigurationChecksum of the bashible run",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"steps": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Steps contains results of the executed steps in the order of execution",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("d8.io/bashible/pkg/apis/bashible/v1alpha1.BashibleStepStatus"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"d8.io/bashible/pkg/apis/bashible/v1alpha1.BashibleStepStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta",
+		},
+	}
+}
+
+func schema_pkg_apis_bashible_v1alpha1_BashibleStatusList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "BashibleStatusList is a list of BashibleStatus objects.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("d8.io/bashible/pkg/apis/bashible/v1alpha1.BashibleStatus"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"items"},
+			},
+		},
+		Dependencies: []string{
+			"d8.io/bashible/pkg/apis/bashible/v1alpha1.BashibleStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta",
+		},
+	}
+}
+
+func schema_pkg_apis_bashible_v1alpha1_BashibleStepStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "BashibleStepStatus is the result of the last execution of a bashible step",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the step",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"checksum": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Checksum of the step content",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"exitCode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExitCode of the step",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"duration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Duration of the step execution",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"output": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Output contains the last lines of the step output",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"finishedAt": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FinishedAt is the time when the step finished",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+				Required: []string{"name", "exitCode"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Duration", "k8s.io/apimachinery/pkg/apis/meta/v1.Time",
+		},
+	}
+}
+
 func schema_pkg_apis_bashible_v1alpha1_NodeGroupBundle(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -0,0 +1,219 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bashiblestatus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+
+	"d8.io/bashible/pkg/apis/bashible"
+	"d8.io/bashible/pkg/apis/bashible/v1alpha1"
+)
+
+const (
+	// Statuses are kept in secrets to be read by Deckhouse hooks, one secret per node
+	secretsNamespace = "d8-cloud-instance-manager"
+	secretNamePrefix = "bashible-status-"
+	secretDataKey    = "status"
+	// StatusLabel marks secrets with bashible statuses
+	StatusLabel = "node.deckhouse.io/bashible-status"
+
+	nodeUserPrefix = "system:node:"
+	nodesGroup     = "system:nodes"
+
+	// Nodes send last lines of the step output, the limit protects the secret from the huge output
+	maxOutputLength = 4096
+)
+
+var resource = bashible.Resource("bashiblestatuses")
+
+// NewREST returns the storage of statuses reported by nodes after bashible runs.
+func NewREST(client kubernetes.Interface) *REST {
+	return &REST{client: client}
+}
+
+// REST implements Getter and Creater. Nodes create the status after every bashible run,
+// the previous status of the node is replaced.
+type REST struct {
+	client kubernetes.Interface
+}
+
+var (
+	_ rest.Getter  = &REST{}
+	_ rest.Creater = &REST{}
+	_ rest.Scoper  = &REST{}
+)
+
+func (r *REST) New() runtime.Object {
+	return &bashible.BashibleStatus{}
+}
+
+func (r *REST) NamespaceScoped() bool {
+	return false
+}
+
+func (r *REST) Get(ctx context.Context, name string, _ *metav1.GetOptions) (runtime.Object, error) {
+	secret, err := r.client.CoreV1().Secrets(secretsNamespace).Get(ctx, secretName(name), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, apierrors.NewNotFound(resource, name)
+		}
+		return nil, apierrors.NewInternalError(err)
+	}
+
+	var external v1alpha1.BashibleStatus
+	err = json.Unmarshal(secret.Data[secretDataKey], &external)
+	if err != nil {
+		return nil, apierrors.NewInternalError(fmt.Errorf("cannot decode status of %s: %v", name, err))
+	}
+
+	status := &bashible.BashibleStatus{}
+	err = v1alpha1.Convert_v1alpha1_BashibleStatus_To_bashible_BashibleStatus(&external, status, nil)
+	if err != nil {
+		return nil, apierrors.NewInternalError(err)
+	}
+
+	return status, nil
+}
+
+func (r *REST) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, _ *metav1.CreateOptions) (runtime.Object, error) {
+	status, ok := obj.(*bashible.BashibleStatus)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("not a BashibleStatus: %T", obj))
+	}
+
+	if status.Name == "" {
+		return nil, apierrors.NewBadRequest("name of the node is required")
+	}
+
+	if err := checkReporter(ctx, status.Name); err != nil {
+		return nil, err
+	}
+
+	if createValidation != nil {
+		if err := createValidation(ctx, obj); err != nil {
+			return nil, err
+		}
+	}
+
+	status.CreationTimestamp = metav1.Now()
+	for i := range status.Steps {
+		status.Steps[i].Output = truncateOutput(status.Steps[i].Output)
+	}
+
+	err := r.save(ctx, status)
+	if err != nil {
+		return nil, apierrors.NewInternalError(err)
+	}
+
+	return status, nil
+}
+
+// save stores the external version of the status to be decoded by Deckhouse hooks
+func (r *REST) save(ctx context.Context, status *bashible.BashibleStatus) error {
+	var external v1alpha1.BashibleStatus
+	err := v1alpha1.Convert_bashible_BashibleStatus_To_v1alpha1_BashibleStatus(status, &external, nil)
+	if err != nil {
+		return err
+	}
+	external.APIVersion = v1alpha1.SchemeGroupVersion.String()
+	external.Kind = "BashibleStatus"
+
+	data, err := json.Marshal(external)
+	if err != nil {
+		return err
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName(status.Name),
+			Namespace: secretsNamespace,
+			Labels: map[string]string{
+				"app":       "bashible-apiserver",
+				StatusLabel: "",
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{secretDataKey: data},
+	}
+
+	secrets := r.client.CoreV1().Secrets(secretsNamespace)
+
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		_, err := secrets.Get(ctx, secret.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			_, err = secrets.Create(ctx, secret, metav1.CreateOptions{})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// checkReporter allows nodes to report only their own status
+func checkReporter(ctx context.Context, name string) error {
+	user, ok := request.UserFrom(ctx)
+	if !ok {
+		return nil
+	}
+
+	var isNode bool
+	for _, group := range user.GetGroups() {
+		if group == nodesGroup {
+			isNode = true
+			break
+		}
+	}
+
+	if !isNode {
+		return nil
+	}
+
+	nodeName := strings.TrimPrefix(user.GetName(), nodeUserPrefix)
+	if nodeName != name {
+		return apierrors.NewForbidden(resource, name, fmt.Errorf("node %q can report only its own status", nodeName))
+	}
+
+	return nil
+}
+
+func truncateOutput(output string) string {
+	if len(output) <= maxOutputLength {
+		return output
+	}
+
+	return output[len(output)-maxOutputLength:]
+}
+
+func secretName(node string) string {
+	return secretNamePrefix + node
+}
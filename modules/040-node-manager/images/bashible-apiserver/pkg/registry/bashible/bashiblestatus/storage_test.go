@@ -0,0 +1,122 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bashiblestatus
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"d8.io/bashible/pkg/apis/bashible"
+)
+
+func TestBashibleStatus(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "BashibleStatus Suite")
+}
+
+func nodeContext(name string) context.Context {
+	return request.WithUser(context.Background(), &user.DefaultInfo{
+		Name:   nodeUserPrefix + name,
+		Groups: []string{nodesGroup, "system:authenticated"},
+	})
+}
+
+func newStatus(name string) *bashible.BashibleStatus {
+	status := &bashible.BashibleStatus{
+		NodeGroup:             "worker",
+		ConfigurationChecksum: "abc",
+		Steps: []bashible.BashibleStepStatus{
+			{
+				Name:     "001_configure_kubelet.sh",
+				Checksum: "123",
+				ExitCode: 1,
+				Duration: metav1.Duration{Duration: 3 * time.Second},
+				Output:   strings.Repeat("a", maxOutputLength) + "error",
+			},
+		},
+	}
+	status.Name = name
+
+	return status
+}
+
+var _ = Describe("Module :: node-manager :: bashible-apiserver :: bashible statuses storage", func() {
+	var (
+		client *fake.Clientset
+		rest   *REST
+	)
+
+	BeforeEach(func() {
+		client = fake.NewSimpleClientset()
+		rest = NewREST(client)
+	})
+
+	It("stores the status reported by the node", func() {
+		_, err := rest.Create(nodeContext("worker-1"), newStatus("worker-1"), nil, &metav1.CreateOptions{})
+		Expect(err).To(BeNil())
+
+		secret, err := client.CoreV1().Secrets(secretsNamespace).Get(context.Background(), "bashible-status-worker-1", metav1.GetOptions{})
+		Expect(err).To(BeNil())
+		Expect(secret.Labels).To(HaveKey(StatusLabel))
+		Expect(string(secret.Data[secretDataKey])).To(ContainSubstring(`"kind":"BashibleStatus"`))
+		Expect(string(secret.Data[secretDataKey])).To(ContainSubstring(`"exitCode":1`))
+
+		obj, err := rest.Get(context.Background(), "worker-1", &metav1.GetOptions{})
+		Expect(err).To(BeNil())
+
+		status := obj.(*bashible.BashibleStatus)
+		Expect(status.NodeGroup).To(Equal("worker"))
+		Expect(status.Steps).To(HaveLen(1))
+		Expect(status.Steps[0].Duration.Duration).To(Equal(3 * time.Second))
+		Expect(status.Steps[0].Output).To(HaveLen(maxOutputLength))
+		Expect(status.Steps[0].Output).To(HaveSuffix("error"))
+	})
+
+	It("replaces the previous status", func() {
+		_, err := rest.Create(nodeContext("worker-1"), newStatus("worker-1"), nil, &metav1.CreateOptions{})
+		Expect(err).To(BeNil())
+
+		status := newStatus("worker-1")
+		status.Steps[0].ExitCode = 0
+		_, err = rest.Create(nodeContext("worker-1"), status, nil, &metav1.CreateOptions{})
+		Expect(err).To(BeNil())
+
+		obj, err := rest.Get(context.Background(), "worker-1", &metav1.GetOptions{})
+		Expect(err).To(BeNil())
+		Expect(obj.(*bashible.BashibleStatus).Steps[0].ExitCode).To(BeEquivalentTo(0))
+	})
+
+	It("forbids reporting the status of another node", func() {
+		_, err := rest.Create(nodeContext("worker-2"), newStatus("worker-1"), nil, &metav1.CreateOptions{})
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+	})
+
+	It("returns NotFound for unknown nodes", func() {
+		_, err := rest.Get(context.Background(), "worker-3", &metav1.GetOptions{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
@@ -18,13 +18,15 @@ package registry
 
 import (
 	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/client-go/kubernetes"
 
 	"d8.io/bashible/pkg/registry/bashible/bashible"
+	"d8.io/bashible/pkg/registry/bashible/bashiblestatus"
 	"d8.io/bashible/pkg/registry/bashible/nodegroupbundle"
 	"d8.io/bashible/pkg/template"
 )
 
-func GetStorage(rootDir string, bashibleContext *template.BashibleContext, stepsStorage *template.StepsStorage, manager CachesManager) map[string]rest.Storage {
+func GetStorage(rootDir string, bashibleContext *template.BashibleContext, stepsStorage *template.StepsStorage, manager CachesManager, kubeClient kubernetes.Interface) map[string]rest.Storage {
 	v1alpha1storage := map[string]rest.Storage{}
 
 	bashiblesStorage, err := bashible.NewStorage(rootDir, bashibleContext)
@@ -33,5 +35,8 @@ func GetStorage(rootDir string, bashibleContext *template.BashibleContext, steps
 	ngStorage, err := nodegroupbundle.NewStorage(rootDir, stepsStorage, bashibleContext)
 	v1alpha1storage["nodegroupbundles"] = RESTInPeace(ngStorage, err, manager.GetCache())
 
+	// statuses are reported by nodes, they are not rendered from templates
+	v1alpha1storage["bashiblestatuses"] = bashiblestatus.NewREST(kubeClient)
+
 	return v1alpha1storage
 }
@@ -37,7 +37,7 @@ type ClusterLogDestination struct {
 }
 
 type ClusterLogDestinationSpec struct {
-	// Type of cluster log source: Loki, Elasticsearch, Logstash, Vector
+	// Type of cluster log source: Loki, Elasticsearch, Logstash, Vector, Kafka, Splunk, Syslog, S3, ClickHouse
 	Type string `json:"type,omitempty"`
 
 	// Loki describes spec for loki endpoint
@@ -55,6 +55,18 @@ type ClusterLogDestinationSpec struct {
 	// Vector spec for the Vector endpoint
 	Vector VectorSpec `json:"vector"`
 
+	// Splunk spec for the Splunk HTTP Event Collector endpoint
+	Splunk SplunkSpec `json:"splunk"`
+
+	// Syslog spec for the RFC5424 syslog endpoint
+	Syslog SyslogSpec `json:"syslog"`
+
+	// S3 spec for the S3-compatible object storage
+	S3 S3Spec `json:"s3"`
+
+	// ClickHouse spec for the ClickHouse endpoint
+	ClickHouse ClickHouseSpec `json:"clickhouse"`
+
 	// Add extra labels for sources
 	ExtraLabels map[string]string `json:"extraLabels,omitempty"`
 
@@ -63,6 +75,30 @@ type ClusterLogDestinationSpec struct {
 }
 
 type ClusterLogDestinationStatus struct {
+	// Conditions show whether the destination is accepted, and the reason if it is not
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ReferencedBy lists logging configs sending logs to the destination
+	ReferencedBy []string `json:"referencedBy,omitempty"`
+
+	// Components are names of generated vector components
+	Components []string `json:"components,omitempty"`
+
+	// Delivery shows errors of sending logs, scraped from vector metrics
+	Delivery *DeliveryStatus `json:"delivery,omitempty"`
+}
+
+type DeliveryStatus struct {
+	// Errors of the last five minutes by type
+	Errors []DeliveryError `json:"errors,omitempty"`
+
+	// LastErrorTime is the last time when errors were observed
+	LastErrorTime *metav1.Time `json:"lastErrorTime,omitempty"`
+}
+
+type DeliveryError struct {
+	Type  string `json:"type"`
+	Count int64  `json:"count"`
 }
 
 type LokiAuthSpec struct {
@@ -141,3 +177,66 @@ type VectorSpec struct {
 
 	TLS CommonTLSSpec `json:"tls,omitempty"`
 }
+
+type SplunkSpec struct {
+	Endpoint string `json:"endpoint,omitempty"`
+
+	Token string `json:"token,omitempty"`
+
+	Index      string `json:"index,omitempty"`
+	SourceType string `json:"sourceType,omitempty"`
+
+	TLS CommonTLSSpec `json:"tls,omitempty"`
+}
+
+type SyslogSpec struct {
+	Endpoint string `json:"endpoint,omitempty"`
+
+	Mode     string `json:"mode,omitempty"`
+	AppName  string `json:"appName,omitempty"`
+	Facility string `json:"facility,omitempty"`
+
+	TLS CommonTLSSpec `json:"tls,omitempty"`
+}
+
+type S3AuthSpec struct {
+	AccessKeyID     string `json:"accessKeyID,omitempty"`
+	SecretAccessKey string `json:"secretAccessKey,omitempty"`
+}
+
+type S3BatchSpec struct {
+	MaxBytes    uint32 `json:"maxBytes,omitempty"`
+	TimeoutSecs uint32 `json:"timeoutSecs,omitempty"`
+}
+
+type S3Spec struct {
+	Endpoint string `json:"endpoint,omitempty"`
+	Region   string `json:"region,omitempty"`
+	Bucket   string `json:"bucket,omitempty"`
+
+	KeyPrefix   string `json:"keyPrefix,omitempty"`
+	Compression string `json:"compression,omitempty"`
+	Encoding    string `json:"encoding,omitempty"`
+
+	Batch S3BatchSpec `json:"batch,omitempty"`
+
+	Auth S3AuthSpec `json:"auth,omitempty"`
+
+	TLS CommonTLSSpec `json:"tls,omitempty"`
+}
+
+type ClickHouseAuthSpec struct {
+	User     string `json:"user,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+type ClickHouseSpec struct {
+	Endpoint string `json:"endpoint,omitempty"`
+
+	Database string `json:"database,omitempty"`
+	Table    string `json:"table,omitempty"`
+
+	Auth ClickHouseAuthSpec `json:"auth,omitempty"`
+
+	TLS CommonTLSSpec `json:"tls,omitempty"`
+}
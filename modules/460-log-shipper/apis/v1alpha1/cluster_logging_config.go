@@ -38,7 +38,7 @@ type ClusterLoggingConfig struct {
 }
 
 type ClusterLoggingConfigSpec struct {
-	// Type of cluster log source: KubernetesPods, File
+	// Type of cluster log source: KubernetesPods, File, Journald, KubernetesEvents
 	Type string `json:"type,omitempty"`
 
 	// KubernetesPods describes spec for kubernetes pod source
@@ -47,6 +47,12 @@ type ClusterLoggingConfigSpec struct {
 	// File describes spec for file source
 	File FileSpec `json:"file,omitempty"`
 
+	// Journald describes spec for node journal source
+	Journald JournaldSpec `json:"journald,omitempty"`
+
+	// KubernetesEvents describes spec for kubernetes events source
+	KubernetesEvents KubernetesEventsSpec `json:"kubernetesEvents,omitempty"`
+
 	// Filters
 	LogFilters   []Filter `json:"logFilter,omitempty"`
 	LabelFilters []Filter `json:"labelFilter,omitempty"`
@@ -54,11 +60,19 @@ type ClusterLoggingConfigSpec struct {
 	// Multiline parsers
 	MultiLineParser MultiLineParser `json:"multilineParser,omitempty"`
 
+	// Redact sensitive data
+	Redact Redact `json:"redact,omitempty"`
+
 	// DestinationRefs slice of ClusterLogDestination names
 	DestinationRefs []string `json:"destinationRefs,omitempty"`
 }
 
 type ClusterLoggingConfigStatus struct {
+	// Conditions show whether the config is accepted, and the reason if it is not
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Components are names of generated vector components
+	Components []string `json:"components,omitempty"`
 }
 
 type KubernetesPodsSpec struct {
@@ -79,3 +93,23 @@ type FileSpec struct {
 	Exclude       []string `json:"exclude,omitempty"`
 	LineDelimiter string   `json:"lineDelimiter,omitempty"`
 }
+
+type JournaldSpec struct {
+	IncludeUnits []string `json:"includeUnits,omitempty"`
+	ExcludeUnits []string `json:"excludeUnits,omitempty"`
+
+	// Priority is the lowest priority (the highest level) of messages to collect, e.g., Warning
+	Priority string `json:"priority,omitempty"`
+}
+
+type KubernetesEventsSpec struct {
+	NamespaceSelector KubernetesEventsNamespaceSelector `json:"namespaceSelector,omitempty"`
+
+	Reasons []string `json:"reasons,omitempty"`
+	Types   []string `json:"types,omitempty"`
+}
+
+type KubernetesEventsNamespaceSelector struct {
+	MatchNames   []string `json:"matchNames,omitempty"`
+	ExcludeNames []string `json:"excludeNames,omitempty"`
+}
@@ -46,9 +46,17 @@ type PodLoggingConfigSpec struct {
 	// Multiline parsers
 	MultiLineParser MultiLineParser `json:"multilineParser,omitempty"`
 
+	// Redact sensitive data
+	Redact Redact `json:"redact,omitempty"`
+
 	// ClusterDestinationRefs slice of ClusterLogDestination names
 	ClusterDestinationRefs []string `json:"clusterDestinationRefs,omitempty"`
 }
 
 type PodLoggingConfigStatus struct {
+	// Conditions show whether the config is accepted, and the reason if it is not
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Components are names of generated vector components
+	Components []string `json:"components,omitempty"`
 }
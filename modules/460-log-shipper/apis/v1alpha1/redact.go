@@ -0,0 +1,43 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+type Redact struct {
+	Patterns []RedactPattern `json:"patterns,omitempty"`
+	Regexes  []string        `json:"regexes,omitempty"`
+	Fields   []string        `json:"fields,omitempty"`
+	Action   RedactAction    `json:"action,omitempty"`
+}
+
+type RedactPattern string
+
+const (
+	RedactPatternBearerToken RedactPattern = "BearerToken"
+	RedactPatternJWT         RedactPattern = "JWT"
+	RedactPatternEmail       RedactPattern = "Email"
+	RedactPatternCreditCard  RedactPattern = "CreditCard"
+	RedactPatternIPv4        RedactPattern = "IPv4"
+	RedactPatternIPv6        RedactPattern = "IPv6"
+)
+
+type RedactAction string
+
+const (
+	RedactActionMask RedactAction = "Mask"
+	RedactActionHash RedactAction = "Hash"
+	RedactActionDrop RedactAction = "Drop"
+)
@@ -22,9 +22,25 @@ const (
 	DestLoki          = "Loki"
 	DestVector        = "Vector"
 	DestKafka         = "Kafka"
+	DestSplunk        = "Splunk"
+	DestSyslog        = "Syslog"
+	DestS3            = "S3"
+	DestClickHouse    = "ClickHouse"
 )
 
 const (
-	SourceKubernetesPods = "KubernetesPods"
-	SourceFile           = "File"
+	SourceKubernetesPods   = "KubernetesPods"
+	SourceFile             = "File"
+	SourceJournald         = "Journald"
+	SourceKubernetesEvents = "KubernetesEvents"
+)
+
+// Condition types and reasons for statuses of log-shipper resources
+const (
+	ConditionAccepted = "Accepted"
+
+	ReasonAccepted            = "Accepted"
+	ReasonInvalidSpec         = "InvalidSpec"
+	ReasonDestinationNotFound = "DestinationNotFound"
+	ReasonNotReferenced       = "NotReferenced"
 )
@@ -0,0 +1,202 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/flant/addon-operator/pkg/module_manager/go_hook"
+	"github.com/flant/addon-operator/sdk"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
+
+	"github.com/deckhouse/deckhouse/go_lib/dependency"
+	d8http "github.com/deckhouse/deckhouse/go_lib/dependency/http"
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/apis/v1alpha1"
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/hooks/internal/vector/destination"
+)
+
+// This hook puts delivery errors of vector sinks to statuses of ClusterLogDestinations.
+// Errors are taken from Prometheus, because vector agents run on every node.
+
+const deliveryErrorsQuery = `sum by (component_id, error_type) (increase(vector_component_errors_total{component_kind="sink"}[5m]))`
+
+type deliveryDestination struct {
+	Name     string
+	Delivery *v1alpha1.DeliveryStatus
+}
+
+func filterDeliveryDestination(obj *unstructured.Unstructured) (go_hook.FilterResult, error) {
+	var dst v1alpha1.ClusterLogDestination
+
+	err := sdk.FromUnstructured(obj, &dst)
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveryDestination{Name: dst.Name, Delivery: dst.Status.Delivery}, nil
+}
+
+var _ = sdk.RegisterFunc(&go_hook.HookConfig{
+	Queue: "/modules/log-shipper/delivery_status",
+	Schedule: []go_hook.ScheduleConfig{
+		{
+			Name:    "delivery_status",
+			Crontab: "* * * * *", // every minute
+		},
+	},
+	Kubernetes: []go_hook.KubernetesConfig{
+		{
+			Name:                         "cluster_log_destination",
+			ApiVersion:                   "deckhouse.io/v1alpha1",
+			Kind:                         "ClusterLogDestination",
+			ExecuteHookOnSynchronization: pointer.BoolPtr(false),
+			ExecuteHookOnEvents:          pointer.BoolPtr(false),
+			FilterFunc:                   filterDeliveryDestination,
+		},
+	},
+}, dependency.WithExternalDependencies(updateDeliveryStatus))
+
+func updateDeliveryStatus(input *go_hook.HookInput, dc dependency.Container) error {
+	snap := input.Snapshots["cluster_log_destination"]
+	if len(snap) == 0 || !input.Values.Get("logShipper.internal.activated").Bool() {
+		return nil
+	}
+
+	errorsBySink, err := queryDeliveryErrors(dc)
+	if err != nil {
+		input.LogEntry.Warnf("Prometheus request for vector delivery errors failed: %s", err)
+		return nil // don't fail the hook
+	}
+
+	now := metav1.NewTime(time.Now().UTC())
+
+	for _, d := range snap {
+		dst := d.(deliveryDestination)
+
+		errs := errorsBySink[destination.ComposeName(dst.Name)]
+
+		var current []v1alpha1.DeliveryError
+		if dst.Delivery != nil {
+			current = dst.Delivery.Errors
+		}
+		if !statusChanged(current, errs) {
+			continue
+		}
+
+		var lastErrorTime *metav1.Time
+		if dst.Delivery != nil {
+			lastErrorTime = dst.Delivery.LastErrorTime
+		}
+		if len(errs) > 0 {
+			lastErrorTime = &now
+		}
+
+		patchStatus(input, "ClusterLogDestination", "", dst.Name, map[string]interface{}{
+			"delivery": map[string]interface{}{
+				"errors":        errs,
+				"lastErrorTime": lastErrorTime,
+			},
+		})
+	}
+
+	return nil
+}
+
+// queryDeliveryErrors returns the number of errors by sink names, sorted by error type.
+func queryDeliveryErrors(dc dependency.Container) (map[string][]v1alpha1.DeliveryError, error) {
+	cl := dc.GetHTTPClient(d8http.WithInsecureSkipVerify())
+
+	promURL := "https://prometheus.d8-monitoring:9090/api/v1/query?query=" + url.QueryEscape(deliveryErrorsQuery)
+	req, err := http.NewRequest(http.MethodGet, promURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = d8http.SetKubeAuthToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := cl.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	var response deliveryErrorsResponse
+	err = json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		return nil, err
+	}
+
+	errorsBySink := make(map[string][]v1alpha1.DeliveryError)
+	for _, record := range response.Data.Result {
+		if len(record.Value) < 2 {
+			continue
+		}
+		raw, ok := record.Value[1].(string)
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(value) {
+			continue
+		}
+
+		count := int64(math.Round(value))
+		if count <= 0 {
+			continue
+		}
+
+		errorsBySink[record.Metric.ComponentID] = append(errorsBySink[record.Metric.ComponentID], v1alpha1.DeliveryError{
+			Type:  record.Metric.ErrorType,
+			Count: count,
+		})
+	}
+
+	for _, errs := range errorsBySink {
+		sort.Slice(errs, func(i, j int) bool {
+			return errs[i].Type < errs[j].Type
+		})
+	}
+
+	return errorsBySink, nil
+}
+
+type deliveryErrorsResponse struct {
+	Data struct {
+		Result []struct {
+			Metric struct {
+				ComponentID string `json:"component_id"`
+				ErrorType   string `json:"error_type"`
+			} `json:"metric"`
+			Value []interface{} `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
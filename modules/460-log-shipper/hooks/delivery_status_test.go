@@ -0,0 +1,125 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/deckhouse/deckhouse/go_lib/dependency"
+	. "github.com/deckhouse/deckhouse/testing/hooks"
+)
+
+const deliveryDestinations = `
+---
+apiVersion: deckhouse.io/v1alpha1
+kind: ClusterLogDestination
+metadata:
+  name: loki-storage
+spec:
+  type: Loki
+  loki:
+    endpoint: http://loki.loki:3100
+---
+apiVersion: deckhouse.io/v1alpha1
+kind: ClusterLogDestination
+metadata:
+  name: es-storage
+spec:
+  type: Elasticsearch
+  elasticsearch:
+    endpoint: http://192.168.1.1:9200
+status:
+  delivery:
+    errors:
+      - type: request_failed
+        count: 3
+    lastErrorTime: "2022-06-01T10:00:00Z"
+`
+
+const deliveryErrorsPromResponse = `{
+  "status": "success",
+  "data": {
+    "resultType": "vector",
+    "result": [
+      {"metric": {"component_id": "destination/cluster/loki-storage", "error_type": "request_failed"}, "value": [1654077600, "12.5"]},
+      {"metric": {"component_id": "destination/cluster/loki-storage", "error_type": "encoder_failed"}, "value": [1654077600, "2"]},
+      {"metric": {"component_id": "destination/cluster/es-storage", "error_type": "request_failed"}, "value": [1654077600, "0"]}
+    ]
+  }
+}`
+
+var _ = Describe("Log shipper :: delivery status ::", func() {
+	f := HookExecutionConfigInit(`{"logShipper": {"internal": {"activated": true}}}`, ``)
+	f.RegisterCRD("deckhouse.io", "v1alpha1", "ClusterLogDestination", false)
+
+	Context("With delivery errors in Prometheus", func() {
+		BeforeEach(func() {
+			dependency.TestDC.HTTPClient.DoMock.
+				Set(func(req *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       ioutil.NopCloser(bytes.NewBufferString(deliveryErrorsPromResponse)),
+					}, nil
+				})
+
+			f.KubeStateSet(deliveryDestinations)
+			f.BindingContexts.Set(f.GenerateScheduleContext("* * * * *"))
+			f.RunHook()
+		})
+
+		It("Should put errors to statuses of destinations", func() {
+			Expect(f).To(ExecuteSuccessfully())
+
+			loki := f.KubernetesGlobalResource("ClusterLogDestination", "loki-storage")
+			Expect(loki.Field("status.delivery.errors").String()).To(MatchJSON(
+				`[{"type": "encoder_failed", "count": 2}, {"type": "request_failed", "count": 13}]`))
+			Expect(loki.Field("status.delivery.lastErrorTime").Exists()).To(BeTrue())
+
+			es := f.KubernetesGlobalResource("ClusterLogDestination", "es-storage")
+			Expect(es.Field("status.delivery.errors").Exists()).To(BeFalse())
+			Expect(es.Field("status.delivery.lastErrorTime").String()).To(Equal("2022-06-01T10:00:00Z"))
+		})
+	})
+
+	Context("With unavailable Prometheus", func() {
+		BeforeEach(func() {
+			dependency.TestDC.HTTPClient.DoMock.
+				Set(func(req *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: http.StatusServiceUnavailable,
+						Body:       ioutil.NopCloser(bytes.NewBufferString("")),
+					}, nil
+				})
+
+			f.KubeStateSet(deliveryDestinations)
+			f.BindingContexts.Set(f.GenerateScheduleContext("* * * * *"))
+			f.RunHook()
+		})
+
+		It("Should keep statuses", func() {
+			Expect(f).To(ExecuteSuccessfully())
+
+			es := f.KubernetesGlobalResource("ClusterLogDestination", "es-storage")
+			Expect(es.Field("status.delivery.errors.0.count").Int()).To(Equal(int64(3)))
+		})
+	})
+})
@@ -59,6 +59,10 @@ func filterClusterLogDestination(obj *unstructured.Unstructured) (go_hook.Filter
 	if err != nil {
 		return nil, err
 	}
+
+	// Delivery errors are updated by another hook, ignore them to not regenerate the config on every update
+	dst.Status.Delivery = nil
+
 	return dst, nil
 }
 
@@ -112,11 +116,13 @@ func generateConfig(input *go_hook.HookInput) error {
 		return nil
 	}
 
-	configContent, err := composer.FromInput(input).Do()
+	configContent, report, err := composer.FromInput(input).Do()
 	if err != nil {
 		return err
 	}
 
+	updateStatuses(input, report)
+
 	activated := len(configContent) != 0
 	input.Values.Set("logShipper.internal.activated", activated)
 
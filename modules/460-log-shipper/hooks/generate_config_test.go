@@ -85,6 +85,90 @@ spec:
 		})
 	})
 
+	Context("Statuses", func() {
+		BeforeEach(func() {
+			f.BindingContexts.Set(f.KubeStateSet(namespaceManifest + `
+---
+apiVersion: deckhouse.io/v1alpha1
+kind: ClusterLoggingConfig
+metadata:
+  name: partial-source
+spec:
+  type: File
+  file:
+    include: ["/var/log/kube-audit/audit.log"]
+  destinationRefs:
+    - loki-storage
+    - non-existed
+---
+apiVersion: deckhouse.io/v1alpha1
+kind: PodLoggingConfig
+metadata:
+  name: invalid-source
+  namespace: tests-whispers
+spec:
+  redact:
+    regexes: ["(unclosed"]
+  clusterDestinationRefs:
+    - loki-storage
+---
+apiVersion: deckhouse.io/v1alpha1
+kind: ClusterLogDestination
+metadata:
+  name: loki-storage
+spec:
+  type: Loki
+  loki:
+    endpoint: http://loki.loki:3100
+---
+apiVersion: deckhouse.io/v1alpha1
+kind: ClusterLogDestination
+metadata:
+  name: unused-storage
+spec:
+  type: Loki
+  loki:
+    endpoint: http://loki.loki:3100
+`))
+			f.RunHook()
+		})
+
+		It("Should report accepted and rejected resources", func() {
+			Expect(f).To(ExecuteSuccessfully())
+			Expect(f.ValuesGet("logShipper.internal.activated").Bool()).To(BeTrue())
+
+			src := f.KubernetesGlobalResource("ClusterLoggingConfig", "partial-source")
+			Expect(src.Field("status.conditions.0.type").String()).To(Equal("Accepted"))
+			Expect(src.Field("status.conditions.0.status").String()).To(Equal("True"))
+			Expect(src.Field("status.conditions.0.reason").String()).To(Equal("DestinationNotFound"))
+			Expect(src.Field("status.conditions.0.message").String()).To(ContainSubstring("non-existed"))
+			Expect(src.Field("status.components").AsStringSlice()).To(Equal([]string{
+				"cluster_logging_config/partial-source",
+				"transform/source/partial-source/00_clean_up",
+			}))
+
+			invalid := f.KubernetesResource("PodLoggingConfig", "tests-whispers", "invalid-source")
+			Expect(invalid.Field("status.conditions.0.status").String()).To(Equal("False"))
+			Expect(invalid.Field("status.conditions.0.reason").String()).To(Equal("InvalidSpec"))
+			Expect(invalid.Field("status.conditions.0.message").String()).To(ContainSubstring("invalid redact regex"))
+			Expect(invalid.Field("status.components").Exists()).To(BeFalse())
+
+			dest := f.KubernetesGlobalResource("ClusterLogDestination", "loki-storage")
+			Expect(dest.Field("status.conditions.0.status").String()).To(Equal("True"))
+			Expect(dest.Field("status.conditions.0.reason").String()).To(Equal("Accepted"))
+			Expect(dest.Field("status.referencedBy").AsStringSlice()).To(Equal([]string{
+				"ClusterLoggingConfig/partial-source",
+				"PodLoggingConfig/tests-whispers/invalid-source",
+			}))
+			Expect(dest.Field("status.components").AsStringSlice()).To(ContainElement("destination/cluster/loki-storage"))
+
+			unused := f.KubernetesGlobalResource("ClusterLogDestination", "unused-storage")
+			Expect(unused.Field("status.conditions.0.status").String()).To(Equal("True"))
+			Expect(unused.Field("status.conditions.0.reason").String()).To(Equal("NotReferenced"))
+			Expect(unused.Field("status.referencedBy").Exists()).To(BeFalse())
+		})
+	})
+
 	DescribeTable("React to Custom Resources",
 		func(folder string) {
 			folder = filepath.Join("testdata", folder)
@@ -134,6 +218,13 @@ spec:
 		Entry("File to Elasticsearch", "file-to-elastic"),
 		Entry("File to Vector", "file-to-vector"),
 		Entry("File to Kafka", "file-to-kafka"),
+		Entry("File to Splunk", "file-to-splunk"),
+		Entry("File to Syslog", "file-to-syslog"),
+		Entry("File to S3", "file-to-s3"),
+		Entry("File to ClickHouse", "file-to-clickhouse"),
+		Entry("Journald to Loki", "journald-to-loki"),
+		Entry("Kubernetes events to Elasticsearch", "kubernetes-events-to-elastic"),
 		Entry("Two sources to single destination", "many-to-one"),
+		Entry("Redact sensitive data", "redact"),
 	)
 })
@@ -0,0 +1,78 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"fmt"
+
+	"github.com/flant/addon-operator/pkg/module_manager/go_hook"
+	"github.com/flant/addon-operator/sdk"
+	"github.com/flant/shell-operator/pkg/kube_events_manager/types"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/deckhouse/deckhouse/go_lib/pwgen"
+)
+
+// The key of hashes of redacted data. It is kept in the Secret, so that hashes of equal values
+// stay the same after restarts of Deckhouse.
+
+const redactHashKeyPath = "logShipper.internal.redactHashKey"
+
+var _ = sdk.RegisterFunc(&go_hook.HookConfig{
+	Kubernetes: []go_hook.KubernetesConfig{
+		{
+			Name:       "redact_hash_key",
+			ApiVersion: "v1",
+			Kind:       "Secret",
+			NamespaceSelector: &types.NamespaceSelector{
+				NameSelector: &types.NameSelector{
+					MatchNames: []string{"d8-log-shipper"},
+				},
+			},
+			NameSelector: &types.NameSelector{
+				MatchNames: []string{"log-shipper-redact-hash-key"},
+			},
+			FilterFunc: filterRedactHashKeySecret,
+		},
+	},
+}, generateRedactHashKey)
+
+func filterRedactHashKeySecret(obj *unstructured.Unstructured) (go_hook.FilterResult, error) {
+	secret := &v1.Secret{}
+	err := sdk.FromUnstructured(obj, secret)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert redact hash key secret to secret: %v", err)
+	}
+
+	return string(secret.Data["key"]), nil
+}
+
+func generateRedactHashKey(input *go_hook.HookInput) error {
+	if input.Values.Get(redactHashKeyPath).String() != "" {
+		return nil
+	}
+
+	snap := input.Snapshots["redact_hash_key"]
+	if len(snap) > 0 && snap[0].(string) != "" {
+		input.Values.Set(redactHashKeyPath, snap[0].(string))
+		return nil
+	}
+
+	input.Values.Set(redactHashKeyPath, pwgen.AlphaNum(32))
+	return nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/deckhouse/deckhouse/testing/hooks"
+)
+
+var _ = Describe("Log shipper :: generate redact hash key ::", func() {
+	f := HookExecutionConfigInit(`{"logShipper":{"internal":{}}}`, "")
+
+	Context("With secret", func() {
+		BeforeEach(func() {
+			f.BindingContexts.Set(f.KubeStateSet(`
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: log-shipper-redact-hash-key
+  namespace: d8-log-shipper
+data:
+  key: QUJD # ABC
+`))
+			f.RunHook()
+		})
+
+		It("Should fill the key from the secret", func() {
+			Expect(f).To(ExecuteSuccessfully())
+			Expect(f.ValuesGet("logShipper.internal.redactHashKey").String()).To(Equal("ABC"))
+		})
+	})
+
+	Context("With empty cluster", func() {
+		BeforeEach(func() {
+			f.BindingContexts.Set(f.KubeStateSet(""))
+			f.RunHook()
+		})
+
+		It("Should generate the key", func() {
+			Expect(f).To(ExecuteSuccessfully())
+			Expect(f.ValuesGet("logShipper.internal.redactHashKey").String()).To(HaveLen(32))
+		})
+
+		Context("With another run", func() {
+			var key string
+
+			BeforeEach(func() {
+				key = f.ValuesGet("logShipper.internal.redactHashKey").String()
+				f.RunHook()
+			})
+
+			It("Should not change the key", func() {
+				Expect(f).To(ExecuteSuccessfully())
+				Expect(f.ValuesGet("logShipper.internal.redactHashKey").String()).To(Equal(key))
+			})
+		})
+	})
+})
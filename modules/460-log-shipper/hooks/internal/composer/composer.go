@@ -17,6 +17,8 @@ limitations under the License.
 package composer
 
 import (
+	"strings"
+
 	"github.com/flant/addon-operator/pkg/module_manager/go_hook"
 
 	"github.com/deckhouse/deckhouse/modules/460-log-shipper/apis"
@@ -59,11 +61,12 @@ func FromInput(input *go_hook.HookInput) *Composer {
 	return res
 }
 
-func (c *Composer) Do() ([]byte, error) {
-	destinationRefs, err := c.composeDestinations()
-	if err != nil {
-		return nil, err
-	}
+// Do composes the vector config file. Invalid sources and destinations do not fail composing, they are skipped
+// and the reasons are returned in the report.
+func (c *Composer) Do() ([]byte, *Report, error) {
+	report := NewReport()
+
+	destinationRefs := c.composeDestinations(report)
 
 	file := NewVectorFile()
 
@@ -73,9 +76,13 @@ func (c *Composer) Do() ([]byte, error) {
 			MultilineType: s.Spec.MultiLineParser.Type,
 			LabelFilter:   s.Spec.LabelFilters,
 			LogFilter:     s.Spec.LogFilters,
+
+			KubernetesEvents: s.Spec.KubernetesEvents,
+			Redact:           s.Spec.Redact,
 		})
 		if err != nil {
-			return nil, err
+			report.Sources[s.Name] = rejected(v1alpha1.ReasonInvalidSpec, err.Error())
+			continue
 		}
 
 		src := PipelineSource{
@@ -83,48 +90,92 @@ func (c *Composer) Do() ([]byte, error) {
 			Transforms: transforms,
 		}
 
-		var destinations []PipelineDestination
+		var (
+			destinations []PipelineDestination
+			missing      []string
+		)
 
 		for _, ref := range s.Spec.DestinationRefs {
 			dst := destinationRefs[destination.ComposeName(ref)]
 
 			if dst.Destination != nil {
 				destinations = append(destinations, dst)
+			} else {
+				missing = append(missing, ref)
 			}
 		}
 
-		if len(destinations) > 0 {
-			err = file.AppendLogPipeline(&Pipeline{
-				Source:       src,
-				Destinations: destinations,
-			})
-			if err != nil {
-				return nil, err
-			}
+		if len(destinations) == 0 {
+			report.Sources[s.Name] = rejected(v1alpha1.ReasonDestinationNotFound,
+				"None of the destinations are found or accepted: "+strings.Join(missing, ", "))
+			continue
+		}
+
+		err = file.AppendLogPipeline(&Pipeline{
+			Source:       src,
+			Destinations: destinations,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		components := make([]string, 0, len(transforms)+1)
+		for _, compiled := range src.Source.BuildSources() {
+			components = append(components, compiled.GetName())
+		}
+		sourceReport := accepted(append(components, transformNames(transforms)...))
+		if len(missing) > 0 {
+			sourceReport.Reason = v1alpha1.ReasonDestinationNotFound
+			sourceReport.Message = "Some of the destinations are not found or accepted: " + strings.Join(missing, ", ")
 		}
+		report.Sources[s.Name] = sourceReport
 	}
 
-	return file.ConvertToJSON()
+	// Unreferenced destinations are not rendered to the config
+	for _, d := range c.Dest {
+		destReport := report.Destinations[d.Name]
+		if !destReport.Accepted {
+			continue
+		}
+		if _, ok := file.Sinks[destination.ComposeName(d.Name)]; !ok {
+			destReport.Reason = v1alpha1.ReasonNotReferenced
+			destReport.Message = "The destination is not referenced by any accepted logging config"
+			destReport.Components = nil
+		}
+	}
+
+	content, err := file.ConvertToJSON()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return content, report, nil
 }
 
-func (c *Composer) composeDestinations() (map[string]PipelineDestination, error) {
+func (c *Composer) composeDestinations(report *Report) map[string]PipelineDestination {
 	destinationByName := make(map[string]PipelineDestination)
 
 	for _, d := range c.Dest {
 		dest := newLogDest(d.Spec.Type, d.Name, d.Spec)
+		if dest == nil {
+			report.Destinations[d.Name] = rejected(v1alpha1.ReasonInvalidSpec, "Unknown destination type "+d.Spec.Type)
+			continue
+		}
 
 		transforms, err := transform.CreateLogDestinationTransforms(d.Name, d)
 		if err != nil {
-			return nil, err
+			report.Destinations[d.Name] = rejected(v1alpha1.ReasonInvalidSpec, err.Error())
+			continue
 		}
 
 		destinationByName[dest.GetName()] = PipelineDestination{
 			Destination: dest,
 			Transforms:  transforms,
 		}
+		report.Destinations[d.Name] = accepted(append(transformNames(transforms), dest.GetName()))
 	}
 
-	return destinationByName, nil
+	return destinationByName
 }
 
 func newLogSource(typ, name string, spec v1alpha1.ClusterLoggingConfigSpec) apis.LogSource {
@@ -133,6 +184,10 @@ func newLogSource(typ, name string, spec v1alpha1.ClusterLoggingConfigSpec) apis
 		return source.NewFile(name, spec.File)
 	case v1alpha1.SourceKubernetesPods:
 		return source.NewKubernetes(name, spec.KubernetesPods, false)
+	case v1alpha1.SourceJournald:
+		return source.NewJournald(name, spec.Journald)
+	case v1alpha1.SourceKubernetesEvents:
+		return source.NewKubernetesEvents(name)
 	}
 	return nil
 }
@@ -149,6 +204,14 @@ func newLogDest(typ, name string, spec v1alpha1.ClusterLogDestinationSpec) apis.
 		return destination.NewVector(name, spec)
 	case v1alpha1.DestKafka:
 		return destination.NewKafka(name, spec)
+	case v1alpha1.DestSplunk:
+		return destination.NewSplunk(name, spec)
+	case v1alpha1.DestSyslog:
+		return destination.NewSyslog(name, spec)
+	case v1alpha1.DestS3:
+		return destination.NewS3(name, spec)
+	case v1alpha1.DestClickHouse:
+		return destination.NewClickHouse(name, spec)
 	}
 	return nil
 }
@@ -0,0 +1,68 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composer
+
+import (
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/apis"
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/apis/v1alpha1"
+)
+
+// Report is the result of composing for every source and destination by their names.
+// It is used to fill statuses of custom resources.
+type Report struct {
+	Sources      map[string]*Status
+	Destinations map[string]*Status
+}
+
+func NewReport() *Report {
+	return &Report{
+		Sources:      make(map[string]*Status),
+		Destinations: make(map[string]*Status),
+	}
+}
+
+// Status tells whether the resource is accepted, why, and which vector components are generated for it.
+type Status struct {
+	Accepted bool
+	Reason   string
+	Message  string
+
+	Components []string
+}
+
+func accepted(components []string) *Status {
+	return &Status{
+		Accepted:   true,
+		Reason:     v1alpha1.ReasonAccepted,
+		Components: components,
+	}
+}
+
+func rejected(reason, message string) *Status {
+	return &Status{
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+func transformNames(transforms []apis.LogTransform) []string {
+	names := make([]string, 0, len(transforms))
+	for _, t := range transforms {
+		names = append(names, t.GetName())
+	}
+	return names
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package destination
+
+import (
+	"github.com/deckhouse/deckhouse/go_lib/set"
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/apis/v1alpha1"
+)
+
+type ClickHouse struct {
+	CommonSettings
+
+	Endpoint string `json:"endpoint"`
+
+	Database string `json:"database,omitempty"`
+
+	Table string `json:"table"`
+
+	Encoding Encoding `json:"encoding,omitempty"`
+
+	Compression string `json:"compression,omitempty"`
+
+	SkipUnknownFields bool `json:"skip_unknown_fields"`
+
+	Auth ClickHouseAuth `json:"auth,omitempty"`
+
+	TLS CommonTLS `json:"tls"`
+}
+
+type ClickHouseAuth struct {
+	Strategy string `json:"strategy,omitempty"`
+	User     string `json:"user,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+func NewClickHouse(name string, cspec v1alpha1.ClusterLogDestinationSpec) *ClickHouse {
+	spec := cspec.ClickHouse
+
+	var auth ClickHouseAuth
+	if spec.Auth.User != "" {
+		auth = ClickHouseAuth{
+			Strategy: "basic",
+			User:     spec.Auth.User,
+			Password: decodeB64(spec.Auth.Password),
+		}
+	}
+
+	return &ClickHouse{
+		CommonSettings: CommonSettings{
+			Name:   ComposeName(name),
+			Type:   "clickhouse",
+			Inputs: set.New(),
+		},
+		Endpoint: spec.Endpoint,
+		Database: spec.Database,
+		Table:    spec.Table,
+		// DateTime columns do not accept RFC3339 strings without best effort parsing
+		Encoding: Encoding{
+			TimestampFormat: "unix",
+		},
+		Compression:       "gzip",
+		SkipUnknownFields: true,
+		Auth:              auth,
+		TLS:               newCommonTLS(spec.TLS),
+	}
+}
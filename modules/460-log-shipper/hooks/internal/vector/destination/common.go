@@ -21,6 +21,7 @@ import (
 
 	"github.com/deckhouse/deckhouse/go_lib/set"
 	"github.com/deckhouse/deckhouse/modules/460-log-shipper/apis"
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/apis/v1alpha1"
 )
 
 var _ apis.LogDestination = (*CommonSettings)(nil)
@@ -44,6 +45,7 @@ type Encoding struct {
 }
 
 type CommonTLS struct {
+	Enabled           bool   `json:"enabled,omitempty"`
 	CAFile            string `json:"ca_file,omitempty"`
 	CertFile          string `json:"crt_file,omitempty"`
 	KeyFile           string `json:"key_file,omitempty"`
@@ -65,6 +67,26 @@ func (cs *CommonSettings) GetName() string {
 	return cs.Name
 }
 
+// newCommonTLS decodes certificates and keys of the spec. Certificate and hostname verification
+// are enabled by default.
+func newCommonTLS(spec v1alpha1.CommonTLSSpec) CommonTLS {
+	tls := CommonTLS{
+		CAFile:            decodeB64(spec.CAFile),
+		CertFile:          decodeB64(spec.CertFile),
+		KeyFile:           decodeB64(spec.KeyFile),
+		KeyPass:           decodeB64(spec.KeyPass),
+		VerifyCertificate: true,
+		VerifyHostname:    true,
+	}
+	if spec.VerifyCertificate != nil {
+		tls.VerifyCertificate = *spec.VerifyCertificate
+	}
+	if spec.VerifyHostname != nil {
+		tls.VerifyHostname = *spec.VerifyHostname
+	}
+	return tls
+}
+
 func decodeB64(input string) string {
 	res, _ := base64.StdEncoding.DecodeString(input)
 	return string(res)
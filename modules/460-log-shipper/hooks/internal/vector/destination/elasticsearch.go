@@ -91,20 +91,7 @@ func NewElasticsearch(name string, cspec v1alpha1.ClusterLogDestinationSpec) *El
 		mode = "data_stream"
 	}
 
-	tls := CommonTLS{
-		CAFile:            decodeB64(spec.TLS.CAFile),
-		CertFile:          decodeB64(spec.TLS.CertFile),
-		KeyFile:           decodeB64(spec.TLS.KeyFile),
-		KeyPass:           decodeB64(spec.TLS.KeyPass),
-		VerifyCertificate: true,
-		VerifyHostname:    true,
-	}
-	if spec.TLS.VerifyCertificate != nil {
-		tls.VerifyCertificate = *spec.TLS.VerifyCertificate
-	}
-	if spec.TLS.VerifyHostname != nil {
-		tls.VerifyHostname = *spec.TLS.VerifyHostname
-	}
+	tls := newCommonTLS(spec.TLS)
 
 	return &Elasticsearch{
 		CommonSettings: CommonSettings{
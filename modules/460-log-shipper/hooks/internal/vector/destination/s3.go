@@ -0,0 +1,97 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package destination
+
+import (
+	"strings"
+
+	"github.com/deckhouse/deckhouse/go_lib/set"
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/apis/v1alpha1"
+)
+
+type S3 struct {
+	CommonSettings
+
+	Endpoint string `json:"endpoint,omitempty"`
+
+	Region string `json:"region,omitempty"`
+
+	Bucket string `json:"bucket"`
+
+	KeyPrefix string `json:"key_prefix,omitempty"`
+
+	Compression string `json:"compression"`
+
+	Encoding Encoding `json:"encoding,omitempty"`
+
+	Batch S3Batch `json:"batch,omitempty"`
+
+	Auth S3Auth `json:"auth,omitempty"`
+
+	TLS CommonTLS `json:"tls"`
+}
+
+type S3Auth struct {
+	AccessKeyID     string `json:"access_key_id,omitempty"`
+	SecretAccessKey string `json:"secret_access_key,omitempty"`
+}
+
+type S3Batch struct {
+	MaxBytes    uint32 `json:"max_bytes,omitempty"`
+	TimeoutSecs uint32 `json:"timeout_secs,omitempty"`
+}
+
+func NewS3(name string, cspec v1alpha1.ClusterLogDestinationSpec) *S3 {
+	spec := cspec.S3
+
+	compression := "gzip"
+	if spec.Compression != "" {
+		compression = strings.ToLower(spec.Compression)
+	}
+
+	encoding := Encoding{
+		Codec:           "json",
+		TimestampFormat: "rfc3339",
+	}
+	if strings.EqualFold(spec.Encoding, "Text") {
+		encoding.Codec = "text"
+		encoding.OnlyFields = []string{"message"}
+	}
+
+	return &S3{
+		CommonSettings: CommonSettings{
+			Name:   ComposeName(name),
+			Type:   "aws_s3",
+			Inputs: set.New(),
+		},
+		Endpoint:    spec.Endpoint,
+		Region:      spec.Region,
+		Bucket:      spec.Bucket,
+		KeyPrefix:   spec.KeyPrefix,
+		Compression: compression,
+		Encoding:    encoding,
+		Batch: S3Batch{
+			MaxBytes:    spec.Batch.MaxBytes,
+			TimeoutSecs: spec.Batch.TimeoutSecs,
+		},
+		Auth: S3Auth{
+			AccessKeyID:     decodeB64(spec.Auth.AccessKeyID),
+			SecretAccessKey: decodeB64(spec.Auth.SecretAccessKey),
+		},
+		TLS: newCommonTLS(spec.TLS),
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package destination
+
+import (
+	"github.com/deckhouse/deckhouse/go_lib/set"
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/apis/v1alpha1"
+)
+
+type Splunk struct {
+	CommonSettings
+
+	Endpoint string `json:"endpoint"`
+
+	DefaultToken string `json:"default_token"`
+
+	Encoding Encoding `json:"encoding,omitempty"`
+
+	Compression string `json:"compression,omitempty"`
+
+	Index string `json:"index,omitempty"`
+
+	SourceType string `json:"sourcetype,omitempty"`
+
+	TLS CommonTLS `json:"tls"`
+}
+
+func NewSplunk(name string, cspec v1alpha1.ClusterLogDestinationSpec) *Splunk {
+	spec := cspec.Splunk
+
+	return &Splunk{
+		CommonSettings: CommonSettings{
+			Name:   ComposeName(name),
+			Type:   "splunk_hec_logs",
+			Inputs: set.New(),
+		},
+		Endpoint:     spec.Endpoint,
+		DefaultToken: spec.Token,
+		Encoding: Encoding{
+			Codec:           "json",
+			TimestampFormat: "rfc3339",
+		},
+		Compression: "gzip",
+		Index:       spec.Index,
+		SourceType:  spec.SourceType,
+		TLS:         newCommonTLS(spec.TLS),
+	}
+}
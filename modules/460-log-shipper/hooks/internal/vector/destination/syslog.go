@@ -0,0 +1,74 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package destination
+
+import (
+	"strings"
+
+	"github.com/deckhouse/deckhouse/go_lib/set"
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/apis/v1alpha1"
+)
+
+// Syslog sends RFC5424 lines over the socket sink. Lines are formatted by the syslog transform,
+// only the message field is sent.
+type Syslog struct {
+	CommonSettings
+
+	Address string `json:"address"`
+
+	Encoding Encoding `json:"encoding,omitempty"`
+
+	Mode string `json:"mode"`
+
+	TLS *CommonTLS `json:"tls,omitempty"`
+
+	Keepalive *LogstashKeepalive `json:"keepalive,omitempty"`
+}
+
+func NewSyslog(name string, cspec v1alpha1.ClusterLogDestinationSpec) *Syslog {
+	spec := cspec.Syslog
+
+	dest := &Syslog{
+		CommonSettings: CommonSettings{
+			Name:   ComposeName(name),
+			Type:   "socket",
+			Inputs: set.New(),
+		},
+		Address: spec.Endpoint,
+		Encoding: Encoding{
+			Codec:      "text",
+			OnlyFields: []string{"message"},
+		},
+		Mode: "udp",
+	}
+
+	// UDP is the only mode without a connection to keep alive or secure
+	if strings.EqualFold(spec.Mode, "UDP") {
+		return dest
+	}
+
+	tls := newCommonTLS(spec.TLS)
+	tls.Enabled = strings.EqualFold(spec.Mode, "TLS")
+
+	dest.Mode = "tcp"
+	dest.TLS = &tls
+	dest.Keepalive = &LogstashKeepalive{
+		TimeSecs: 7200,
+	}
+
+	return dest
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/apis"
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/apis/v1alpha1"
+)
+
+var _ apis.LogSource = (*Journald)(nil)
+
+// journaldPriorities are syslog severities in the order of journald PRIORITY field values.
+var journaldPriorities = []string{
+	"Emergency",
+	"Alert",
+	"Critical",
+	"Error",
+	"Warning",
+	"Notice",
+	"Informational",
+	"Debug",
+}
+
+// Journald represents `journald` vector source
+// https://vector.dev/docs/reference/configuration/sources/journald/
+type Journald struct {
+	commonSource
+
+	IncludeUnits   []string            `json:"include_units,omitempty"`
+	ExcludeUnits   []string            `json:"exclude_units,omitempty"`
+	IncludeMatches map[string][]string `json:"include_matches,omitempty"`
+}
+
+func NewJournald(name string, spec v1alpha1.JournaldSpec) *Journald {
+	var matches map[string][]string
+
+	// Collect messages of the priority and all more important ones
+	for i, priority := range journaldPriorities {
+		if !strings.EqualFold(priority, spec.Priority) {
+			continue
+		}
+
+		values := make([]string, 0, i+1)
+		for p := 0; p <= i; p++ {
+			values = append(values, strconv.Itoa(p))
+		}
+		matches = map[string][]string{"PRIORITY": values}
+		break
+	}
+
+	return &Journald{
+		commonSource: commonSource{
+			Name: "cluster_logging_config/" + name,
+			Type: "journald",
+		},
+		IncludeUnits:   spec.IncludeUnits,
+		ExcludeUnits:   spec.ExcludeUnits,
+		IncludeMatches: matches,
+	}
+}
+
+func (j *Journald) BuildSources() []apis.LogSource {
+	return []apis.LogSource{j}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/apis"
+)
+
+var _ apis.LogSource = (*KubernetesEvents)(nil)
+
+// kubernetesEventsCommand is built into the vector image. It streams watch events as JSON lines from the agent
+// holding the leader lease, so events are collected once in the cluster.
+const (
+	kubernetesEventsCommand = "/usr/bin/kubernetes-events"
+	// Every source elects its own leader, the lease is named after the source.
+	kubernetesEventsLeasePrefix = "log-shipper-kubernetes-events-"
+)
+
+// KubernetesEvents represents `exec` vector source streaming Kubernetes events from the API server
+// https://vector.dev/docs/reference/configuration/sources/exec/
+type KubernetesEvents struct {
+	commonSource
+
+	Mode          string                    `json:"mode"`
+	Command       []string                  `json:"command"`
+	IncludeStderr bool                      `json:"include_stderr"`
+	Streaming     KubernetesEventsStreaming `json:"streaming"`
+}
+
+type KubernetesEventsStreaming struct {
+	RespawnOnExit       bool `json:"respawn_on_exit"`
+	RespawnIntervalSecs int  `json:"respawn_interval_secs"`
+}
+
+// NewKubernetesEvents creates the source collecting events of the cluster.
+func NewKubernetesEvents(name string) *KubernetesEvents {
+	return &KubernetesEvents{
+		commonSource: commonSource{
+			Name: "cluster_logging_config/" + name,
+			Type: "exec",
+		},
+		Mode:          "streaming",
+		Command:       []string{kubernetesEventsCommand, "--lease-name=" + kubernetesEventsLeasePrefix + name},
+		IncludeStderr: false,
+		Streaming: KubernetesEventsStreaming{
+			RespawnOnExit:       true,
+			RespawnIntervalSecs: 5,
+		},
+	}
+}
+
+func (k *KubernetesEvents) BuildSources() []apis.LogSource {
+	return []apis.LogSource{k}
+}
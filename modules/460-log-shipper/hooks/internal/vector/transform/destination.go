@@ -30,7 +30,7 @@ func CreateLogDestinationTransforms(name string, dest v1alpha1.ClusterLogDestina
 	case v1alpha1.DestElasticsearch, v1alpha1.DestLogstash:
 		transforms = append(transforms, DeDotTransform())
 		fallthrough
-	case v1alpha1.DestVector, v1alpha1.DestKafka:
+	case v1alpha1.DestVector, v1alpha1.DestKafka, v1alpha1.DestSplunk, v1alpha1.DestS3, v1alpha1.DestClickHouse:
 		if len(dest.Spec.ExtraLabels) > 0 {
 			transforms = append(transforms, ExtraFieldTransform(dest.Spec.ExtraLabels))
 		}
@@ -45,8 +45,15 @@ func CreateLogDestinationTransforms(name string, dest v1alpha1.ClusterLogDestina
 	}
 
 	switch dest.Spec.Type {
-	case v1alpha1.DestElasticsearch, v1alpha1.DestLogstash, v1alpha1.DestVector:
+	case v1alpha1.DestElasticsearch, v1alpha1.DestLogstash, v1alpha1.DestVector,
+		v1alpha1.DestKafka, v1alpha1.DestSplunk, v1alpha1.DestS3, v1alpha1.DestClickHouse:
 		transforms = append(transforms, CleanUpParsedDataTransform())
+	case v1alpha1.DestSyslog:
+		syslog, err := SyslogTransform(dest.Spec.Syslog)
+		if err != nil {
+			return nil, err
+		}
+		transforms = append(transforms, syslog)
 	case v1alpha1.DestLoki:
 		if len(dest.Spec.ExtraLabels) > 0 {
 			transforms = append(transforms, CreateParseDataTransforms())
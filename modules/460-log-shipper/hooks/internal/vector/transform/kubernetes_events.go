@@ -0,0 +1,69 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transform
+
+import (
+	"github.com/deckhouse/deckhouse/go_lib/set"
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/apis"
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/apis/v1alpha1"
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/hooks/internal/vrl"
+)
+
+func KubernetesEventsSourceTransform() *DynamicTransform {
+	return &DynamicTransform{
+		CommonTransform: CommonTransform{
+			Name:   "kubernetes_events",
+			Type:   "remap",
+			Inputs: set.New(),
+		},
+		DynamicArgsMap: map[string]interface{}{
+			"source":        vrl.KubernetesEventsRule.String(),
+			"drop_on_abort": true,
+		},
+	}
+}
+
+// CreateKubernetesEventsFilterTransforms returns the filter transform for namespaces, reasons and types
+// of events, or nothing if no filters are set.
+func CreateKubernetesEventsFilterTransforms(spec v1alpha1.KubernetesEventsSpec) ([]apis.LogTransform, error) {
+	condition, err := vrl.KubernetesEventsFilterRule.Render(vrl.Args{
+		"matchNames":   spec.NamespaceSelector.MatchNames,
+		"excludeNames": spec.NamespaceSelector.ExcludeNames,
+		"reasons":      spec.Reasons,
+		"types":        spec.Types,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if condition == "" {
+		return nil, nil
+	}
+
+	return []apis.LogTransform{
+		&DynamicTransform{
+			CommonTransform: CommonTransform{
+				Name:   "events_filter",
+				Type:   "filter",
+				Inputs: set.New(),
+			},
+			DynamicArgsMap: map[string]interface{}{
+				"condition": condition,
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transform
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/deckhouse/deckhouse/go_lib/set"
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/apis"
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/apis/v1alpha1"
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/hooks/internal/vrl"
+)
+
+// redactPatterns are built-in regular expressions for sensitive data.
+var redactPatterns = map[v1alpha1.RedactPattern]string{
+	v1alpha1.RedactPatternBearerToken: `(?i)\bbearer\s+[a-z0-9\-._~+/]+=*`,
+	v1alpha1.RedactPatternJWT:         `\beyJ[a-zA-Z0-9_\-]+\.eyJ[a-zA-Z0-9_\-]+\.[a-zA-Z0-9_\-]*`,
+	v1alpha1.RedactPatternEmail:       `[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`,
+	v1alpha1.RedactPatternCreditCard:  `\b(?:\d[ \-]?){12,18}\d\b`,
+	v1alpha1.RedactPatternIPv4:        `\b(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\.){3}(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\b`,
+	v1alpha1.RedactPatternIPv6:        `(?i)\b(?:[0-9a-f]{1,4}:){7}[0-9a-f]{1,4}\b|(?i)\b(?:[0-9a-f]{1,4}:){1,6}:(?:[0-9a-f]{1,4}:){0,5}[0-9a-f]{1,4}\b`,
+}
+
+// CreateRedactTransforms returns the transform replacing sensitive data, or nothing if no patterns are set.
+func CreateRedactTransforms(redact v1alpha1.Redact) ([]apis.LogTransform, error) {
+	regexes := make([]string, 0, len(redact.Patterns)+len(redact.Regexes))
+
+	for _, pattern := range redact.Patterns {
+		regex, ok := redactPatterns[pattern]
+		if !ok {
+			return nil, fmt.Errorf("unknown redact pattern %q", pattern)
+		}
+		regexes = append(regexes, regex)
+	}
+
+	for _, regex := range redact.Regexes {
+		// Vector uses the Rust regex crate, its syntax is close to RE2
+		if _, err := regexp.Compile(regex); err != nil {
+			return nil, fmt.Errorf("invalid redact regex %q: %v", regex, err)
+		}
+		regexes = append(regexes, regex)
+	}
+
+	if len(regexes) == 0 {
+		return nil, nil
+	}
+
+	// Escape quotes for VRL raw strings
+	for i := range regexes {
+		regexes[i] = strings.ReplaceAll(regexes[i], `'`, `\'`)
+	}
+
+	fields := redact.Fields
+	if len(fields) == 0 {
+		fields = []string{"message"}
+	}
+
+	// Fields other than the message are fields of the message parsed as JSON, they are redacted first
+	// to encode them back to the message before redacting the message itself
+	paths := make([]string, 0, len(fields))
+	var redactMessage bool
+	for _, field := range fields {
+		if field == "message" {
+			redactMessage = true
+			continue
+		}
+		paths = append(paths, ".parsed_data."+field)
+	}
+	parsedFields := len(paths) > 0
+	if redactMessage {
+		paths = append(paths, ".message")
+	}
+
+	action := redact.Action
+	if action == "" {
+		action = v1alpha1.RedactActionMask
+	}
+
+	rule, err := vrl.RedactRule.Render(vrl.Args{
+		"fields":       paths,
+		"parsedFields": parsedFields,
+		"regexes":      regexes,
+		"action":       string(action),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the message to have fields to redact, parsed data is deleted by the rule
+	if parsedFields {
+		rule = vrl.Combine(vrl.ParseJSONRule, vrl.Rule(rule)).String()
+	}
+
+	return []apis.LogTransform{
+		&DynamicTransform{
+			CommonTransform: CommonTransform{
+				Name:   "redact",
+				Type:   "remap",
+				Inputs: set.New(),
+			},
+			DynamicArgsMap: map[string]interface{}{
+				"source":        rule,
+				"drop_on_abort": false,
+			},
+		},
+	}, nil
+}
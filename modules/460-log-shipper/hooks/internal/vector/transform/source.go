@@ -60,13 +60,28 @@ type LogSourceConfig struct {
 
 	LabelFilter []v1alpha1.Filter
 	LogFilter   []v1alpha1.Filter
+
+	KubernetesEvents v1alpha1.KubernetesEventsSpec
+
+	Redact v1alpha1.Redact
 }
 
 func CreateLogSourceTransforms(name string, cfg *LogSourceConfig) ([]apis.LogTransform, error) {
 	var transforms []apis.LogTransform
 
-	if cfg.SourceType == v1alpha1.SourceKubernetesPods {
+	switch cfg.SourceType {
+	case v1alpha1.SourceKubernetesPods:
 		transforms = append(transforms, OwnerReferenceSourceTransform())
+	case v1alpha1.SourceJournald:
+		transforms = append(transforms, JournaldSourceTransform())
+	case v1alpha1.SourceKubernetesEvents:
+		transforms = append(transforms, KubernetesEventsSourceTransform())
+
+		eventsFilterTransforms, err := CreateKubernetesEventsFilterTransforms(cfg.KubernetesEvents)
+		if err != nil {
+			return nil, err
+		}
+		transforms = append(transforms, eventsFilterTransforms...)
 	}
 
 	transforms = append(transforms, CleanUpAfterSourceTransform())
@@ -85,6 +100,13 @@ func CreateLogSourceTransforms(name string, cfg *LogSourceConfig) ([]apis.LogTra
 	}
 	transforms = append(transforms, logFilterTransforms...)
 
+	// Redact the data after filtering, so that filters can still match it
+	redactTransforms, err := CreateRedactTransforms(cfg.Redact)
+	if err != nil {
+		return nil, err
+	}
+	transforms = append(transforms, redactTransforms...)
+
 	sTransforms, err := BuildFromMapSlice("source", name, transforms)
 	if err != nil {
 		return nil, fmt.Errorf("add source transforms: %v", err)
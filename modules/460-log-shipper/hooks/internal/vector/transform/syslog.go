@@ -0,0 +1,89 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transform
+
+import (
+	"fmt"
+
+	"github.com/deckhouse/deckhouse/go_lib/set"
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/apis/v1alpha1"
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/hooks/internal/vrl"
+)
+
+// syslogFacilities are RFC5424 facility codes.
+var syslogFacilities = map[string]int{
+	"kern":         0,
+	"user":         1,
+	"mail":         2,
+	"daemon":       3,
+	"auth":         4,
+	"syslog":       5,
+	"lpr":          6,
+	"news":         7,
+	"uucp":         8,
+	"cron":         9,
+	"authpriv":     10,
+	"ftp":          11,
+	"ntp":          12,
+	"security":     13,
+	"console":      14,
+	"solaris-cron": 15,
+	"local0":       16,
+	"local1":       17,
+	"local2":       18,
+	"local3":       19,
+	"local4":       20,
+	"local5":       21,
+	"local6":       22,
+	"local7":       23,
+}
+
+// syslogSeverityInformational is used for all messages since log levels are not normalized.
+const syslogSeverityInformational = 6
+
+// SyslogTransform formats events as RFC5424 lines.
+func SyslogTransform(spec v1alpha1.SyslogSpec) (*DynamicTransform, error) {
+	facility := spec.Facility
+	if facility == "" {
+		facility = "local0"
+	}
+
+	code, ok := syslogFacilities[facility]
+	if !ok {
+		return nil, fmt.Errorf("unknown syslog facility %q", facility)
+	}
+
+	rule, err := vrl.SyslogRule.Render(vrl.Args{
+		"priority": code*8 + syslogSeverityInformational,
+		"appName":  spec.AppName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &DynamicTransform{
+		CommonTransform: CommonTransform{
+			Name:   "syslog",
+			Type:   "remap",
+			Inputs: set.New(),
+		},
+		DynamicArgsMap: map[string]interface{}{
+			"source":        rule,
+			"drop_on_abort": false,
+		},
+	}, nil
+}
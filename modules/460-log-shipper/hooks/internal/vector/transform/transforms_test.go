@@ -19,6 +19,7 @@ package transform
 import (
 	"os"
 	"path/filepath"
+	"regexp"
 	"testing"
 
 	"github.com/clarketm/json"
@@ -155,4 +156,45 @@ func TestTransformSnippet(t *testing.T) {
 
 		compareMock(t, data, "multiline.json")
 	})
+	t.Run("Test redact", func(t *testing.T) {
+		redactTransforms, err := CreateRedactTransforms(v1alpha1.Redact{
+			Patterns: []v1alpha1.RedactPattern{v1alpha1.RedactPatternBearerToken, v1alpha1.RedactPatternEmail},
+			Regexes:  []string{`password='[^']*'`},
+			Fields:   []string{"message", "request.query"},
+			Action:   v1alpha1.RedactActionHash,
+		})
+		require.NoError(t, err)
+
+		tr, err := BuildFromMapSlice("prefix", "testit", redactTransforms)
+		require.NoError(t, err)
+
+		assert.Len(t, tr, 1)
+		assert.Len(t, tr[0].GetInputs(), 0)
+
+		data, err := json.MarshalIndent(tr, "", "\t")
+		require.NoError(t, err)
+
+		compareMock(t, data, "redact.json")
+	})
+
+	t.Run("Test redact empty", func(t *testing.T) {
+		redactTransforms, err := CreateRedactTransforms(v1alpha1.Redact{Action: v1alpha1.RedactActionDrop})
+		require.NoError(t, err)
+		assert.Len(t, redactTransforms, 0)
+	})
+
+	t.Run("Test redact invalid regex", func(t *testing.T) {
+		_, err := CreateRedactTransforms(v1alpha1.Redact{Regexes: []string{`(unclosed`}})
+		require.Error(t, err)
+
+		_, err = CreateRedactTransforms(v1alpha1.Redact{Patterns: []v1alpha1.RedactPattern{"Passport"}})
+		require.Error(t, err)
+	})
+
+	t.Run("Test redact built-in patterns", func(t *testing.T) {
+		for pattern, regex := range redactPatterns {
+			_, err := regexp.Compile(regex)
+			require.NoError(t, err, pattern)
+		}
+	})
 }
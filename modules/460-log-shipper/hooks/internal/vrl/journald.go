@@ -0,0 +1,31 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vrl
+
+// JournaldRule keeps the essential journal fields. The node name is used instead of the hostname
+// to match Kubernetes Pods logs.
+const JournaldRule Rule = `
+. = {
+    "timestamp": .timestamp,
+    "message": .message,
+    "node": get_env_var("VECTOR_SELF_NODE_NAME") ?? .host,
+    "unit": ._SYSTEMD_UNIT,
+    "syslog_identifier": .SYSLOG_IDENTIFIER,
+    "priority": .PRIORITY,
+    "pid": ._PID
+}
+`
@@ -0,0 +1,53 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vrl
+
+// KubernetesEventsRule converts watch events to log messages. Only added and modified events are kept,
+// watch errors and bookmarks are dropped.
+const KubernetesEventsRule Rule = `
+watch, err = parse_json(.message)
+if err != null || !includes(["ADDED", "MODIFIED"], watch.type) {
+    abort
+}
+event = object!(watch.object)
+ts, err = parse_timestamp(string(event.lastTimestamp) ?? string(event.eventTime) ?? "", format: "%+")
+if err != null {
+    ts = now()
+}
+. = {
+    "timestamp": ts,
+    "message": event.message,
+    "namespace": event.involvedObject.namespace,
+    "reason": event.reason,
+    "type": event.type,
+    "count": event.count,
+    "object": event.involvedObject,
+    "source": event.source,
+    "reporting_component": event.reportingComponent,
+    "node": event.source.host
+}
+`
+
+// KubernetesEventsFilterRule matches events by namespaces, reasons and types.
+const KubernetesEventsFilterRule Rule = `
+{{- $conditions := list }}
+{{- with .matchNames }}{{ $conditions = append $conditions (printf "includes(%s, .namespace)" (toJson .)) }}{{ end }}
+{{- with .excludeNames }}{{ $conditions = append $conditions (printf "!includes(%s, .namespace)" (toJson .)) }}{{ end }}
+{{- with .reasons }}{{ $conditions = append $conditions (printf "includes(%s, .reason)" (toJson .)) }}{{ end }}
+{{- with .types }}{{ $conditions = append $conditions (printf "includes(%s, .type)" (toJson .)) }}{{ end }}
+{{ join " && " $conditions }}
+`
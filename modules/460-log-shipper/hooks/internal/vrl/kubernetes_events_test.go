@@ -0,0 +1,57 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vrl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKubernetesEventsFilterRule(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		args Args
+		res  string
+	}{
+		{
+			name: "No filters",
+			args: Args{},
+			res:  "",
+		},
+		{
+			name: "Excluded namespaces",
+			args: Args{"excludeNames": []string{"kube-system", "d8-system"}},
+			res:  `!includes(["kube-system","d8-system"], .namespace)`,
+		},
+		{
+			name: "All filters",
+			args: Args{
+				"matchNames": []string{"production"},
+				"reasons":    []string{"BackOff"},
+				"types":      []string{"Warning"},
+			},
+			res: `includes(["production"], .namespace) && includes(["BackOff"], .reason) && includes(["Warning"], .type)`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := KubernetesEventsFilterRule.Render(tc.args)
+			require.NoError(t, err)
+			require.Equal(t, tc.res, res)
+		})
+	}
+}
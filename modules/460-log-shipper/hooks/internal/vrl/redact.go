@@ -0,0 +1,62 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vrl
+
+// RedactRule replaces sensitive data in the message and in fields of the message parsed as JSON.
+//
+// The Mask action replaces matches with the [REDACTED] string, the Hash action replaces matches with their
+// keyed hashes to keep them comparable, and the Drop action deletes the field if anything matches.
+// Hashed values are looked up in the original field, so that patterns do not match hashes of previous patterns.
+//
+// Vector 0.24 has no hmac function, so the keyed hash is SHA-256 of the key and the SHA-256 of the key
+// and the value. The key is generated by the module and passed to vector in the environment.
+//
+// Redacted fields of the parsed message are encoded back to the message. The parsed data is deleted afterwards,
+// because it has been parsed from the original message, following transforms parse the redacted message again.
+const RedactRule Rule = `
+{{- if eq .action "Hash" }}
+key = get_env_var!("VECTOR_REDACT_HASH_KEY")
+{{- end }}
+{{- range $field := .fields }}
+if is_string({{ $field }}) {
+    value = string!({{ $field }})
+{{- if eq $.action "Drop" }}
+    if match_any(value, [{{ range $i, $regex := $.regexes }}{{ if $i }}, {{ end }}r'{{ $regex }}'{{ end }}]) {
+        del({{ $field }})
+    }
+{{- else }}
+{{- range $regex := $.regexes }}
+{{- if eq $.action "Hash" }}
+    for_each(parse_regex_all!({{ $field }}, r'{{ $regex }}', numeric_groups: true)) -> |_index, matched| {
+        secret = string!(matched."0")
+        value = replace(value, secret, "sha256:" + sha2(key + sha2(key + secret, variant: "SHA-256"), variant: "SHA-256"))
+    }
+{{- else }}
+    value = replace(value, r'{{ $regex }}', "[REDACTED]")
+{{- end }}
+{{- end }}
+    {{ $field }} = value
+{{- end }}
+}
+{{- end }}
+{{- if .parsedFields }}
+if is_object(.parsed_data) {
+    .message = encode_json(.parsed_data)
+}
+{{- end }}
+del(.parsed_data)
+`
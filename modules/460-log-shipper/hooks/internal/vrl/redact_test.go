@@ -0,0 +1,194 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vrl
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactRule(t *testing.T) {
+	t.Run("Mask", func(t *testing.T) {
+		res, err := RedactRule.Render(Args{
+			"fields":       []string{".parsed_data.request.header", ".message"},
+			"parsedFields": true,
+			"regexes":      []string{`\d+`, `it\'s`},
+			"action":       "Mask",
+		})
+		require.NoError(t, err)
+		require.Equal(t, strings.TrimSpace(`
+if is_string(.parsed_data.request.header) {
+    value = string!(.parsed_data.request.header)
+    value = replace(value, r'\d+', "[REDACTED]")
+    value = replace(value, r'it\'s', "[REDACTED]")
+    .parsed_data.request.header = value
+}
+if is_string(.message) {
+    value = string!(.message)
+    value = replace(value, r'\d+', "[REDACTED]")
+    value = replace(value, r'it\'s', "[REDACTED]")
+    .message = value
+}
+if is_object(.parsed_data) {
+    .message = encode_json(.parsed_data)
+}
+del(.parsed_data)
+`), res)
+	})
+
+	t.Run("Hash", func(t *testing.T) {
+		res, err := RedactRule.Render(Args{
+			"fields":  []string{".message"},
+			"regexes": []string{`\d+`},
+			"action":  "Hash",
+		})
+		require.NoError(t, err)
+		require.Equal(t, strings.TrimSpace(`
+key = get_env_var!("VECTOR_REDACT_HASH_KEY")
+if is_string(.message) {
+    value = string!(.message)
+    for_each(parse_regex_all!(.message, r'\d+', numeric_groups: true)) -> |_index, matched| {
+        secret = string!(matched."0")
+        value = replace(value, secret, "sha256:" + sha2(key + sha2(key + secret, variant: "SHA-256"), variant: "SHA-256"))
+    }
+    .message = value
+}
+del(.parsed_data)
+`), res)
+	})
+
+	t.Run("Drop", func(t *testing.T) {
+		res, err := RedactRule.Render(Args{
+			"fields":  []string{".message"},
+			"regexes": []string{`\d+`, `[a-z]+@example\.com`},
+			"action":  "Drop",
+		})
+		require.NoError(t, err)
+		require.Equal(t, strings.TrimSpace(`
+if is_string(.message) {
+    value = string!(.message)
+    if match_any(value, [r'\d+', r'[a-z]+@example\.com']) {
+        del(.message)
+    }
+}
+del(.parsed_data)
+`), res)
+	})
+}
+
+// TestRedactRuleWithVector runs rendered rules on sample events with the vrl command of vector.
+func TestRedactRuleWithVector(t *testing.T) {
+	if os.Getenv("D8_LOG_SHIPPER_VECTOR_VALIDATE") != "yes" {
+		t.Skip("Do not run this on CI")
+	}
+
+	dockerImage := "timberio/vector:0.24.2-debian"
+
+	tests := []struct {
+		name   string
+		args   Args
+		event  string
+		expect map[string]interface{}
+	}{
+		{
+			name: "Mask the message and drop unredacted parsed data",
+			args: Args{
+				"fields":  []string{".message"},
+				"regexes": []string{`password=\w+`},
+				"action":  "Mask",
+			},
+			event:  `{"message":"{\"msg\":\"login password=secret\"}","parsed_data":{"msg":"login password=secret"}}`,
+			expect: map[string]interface{}{"message": `{"msg":"login [REDACTED]"}`},
+		},
+		{
+			name: "Mask a nested field of the parsed message",
+			args: Args{
+				"fields":       []string{".parsed_data.request.query", ".message"},
+				"parsedFields": true,
+				"regexes":      []string{`token=\w+`},
+				"action":       "Mask",
+			},
+			event:  `{"message":"{\"request\":{\"query\":\"a=1&token=abc\"},\"token\":\"token=abc\"}"}`,
+			expect: map[string]interface{}{"message": `{"request":{"query":"a=1&[REDACTED]"},"token":"[REDACTED]"}`},
+		},
+		{
+			name: "Hash with the key",
+			args: Args{
+				"fields":  []string{".message"},
+				"regexes": []string{`\d+`},
+				"action":  "Hash",
+			},
+			event:  `{"message":"card 42"}`,
+			expect: map[string]interface{}{"message": "card sha256:" + keyedHash("key", "42")},
+		},
+		{
+			name: "Drop the message",
+			args: Args{
+				"fields":  []string{".message"},
+				"regexes": []string{`\d+`},
+				"action":  "Drop",
+			},
+			event:  `{"message":"card 42","parsed_data":"card 42"}`,
+			expect: map[string]interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := RedactRule.Render(tt.args)
+			require.NoError(t, err)
+			if tt.args["parsedFields"] == true {
+				rule = Combine(ParseJSONRule, Rule(rule)).String()
+			}
+
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "program.vrl"), []byte(rule), 0600))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "input.json"), []byte(tt.event+"\n"), 0600))
+
+			var stdout bytes.Buffer
+			cmd := exec.Command(
+				"docker", "run", "--rm",
+				"-v", dir+":/test",
+				"-e", "VECTOR_REDACT_HASH_KEY=key",
+				dockerImage,
+				"vrl", "--input", "/test/input.json", "--program", "/test/program.vrl", "--print-object",
+			)
+			cmd.Stdout = &stdout
+			cmd.Stderr = os.Stderr
+			require.NoError(t, cmd.Run())
+
+			var result map[string]interface{}
+			require.NoError(t, json.Unmarshal(stdout.Bytes(), &result))
+			require.Equal(t, tt.expect, result)
+		})
+	}
+}
+
+func keyedHash(key, value string) string {
+	inner := sha256.Sum256([]byte(key + value))
+	outer := sha256.Sum256([]byte(key + hex.EncodeToString(inner[:])))
+	return hex.EncodeToString(outer[:])
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vrl
+
+// SyslogRule formats the event as an RFC5424 line and puts it to the message field.
+// The node is used as a hostname, the pod as a process id, and the container as an application name
+// unless the application name is set explicitly.
+//
+// Example:
+// ---
+// <134>1 2022-05-03T10:00:00.000000Z node-1 nginx nginx-6d4cf56db6-4pxzw - - GET / HTTP/1.1 200
+const SyslogRule Rule = `
+{{- if .appName }}
+app_name = "{{ .appName }}"
+{{- else }}
+app_name = string(.container) ?? "-"
+{{- end }}
+hostname = string(.node) ?? "-"
+proc_id = string(.pod) ?? "-"
+ts = format_timestamp!(timestamp(.timestamp) ?? now(), format: "%Y-%m-%dT%H:%M:%S%.6fZ")
+message = string(.message) ?? encode_json(.message)
+.message = "<{{ .priority }}>1 " + ts + " " + hostname + " " + app_name + " " + proc_id + " - - " + message
+`
@@ -0,0 +1,42 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vrl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyslogRule(t *testing.T) {
+	res, err := SyslogRule.Render(Args{"priority": 134, "appName": "billing"})
+	require.NoError(t, err)
+	require.Equal(t, strings.TrimSpace(`
+app_name = "billing"
+hostname = string(.node) ?? "-"
+proc_id = string(.pod) ?? "-"
+ts = format_timestamp!(timestamp(.timestamp) ?? now(), format: "%Y-%m-%dT%H:%M:%S%.6fZ")
+message = string(.message) ?? encode_json(.message)
+.message = "<134>1 " + ts + " " + hostname + " " + app_name + " " + proc_id + " - - " + message
+`), res)
+
+	res, err = SyslogRule.Render(Args{"priority": 14})
+	require.NoError(t, err)
+	require.Contains(t, res, `app_name = string(.container) ?? "-"`)
+	require.Contains(t, res, `"<14>1 "`)
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/flant/addon-operator/pkg/module_manager/go_hook"
+	"github.com/flant/shell-operator/pkg/kube/object_patch"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/apis/v1alpha1"
+	"github.com/deckhouse/deckhouse/modules/460-log-shipper/hooks/internal/composer"
+)
+
+// updateStatuses patches statuses of logging configs and destinations with the composing results.
+// Statuses are patched only if they are changed, because the hook is subscribed to the resources.
+func updateStatuses(input *go_hook.HookInput, report *composer.Report) {
+	referencedBy := make(map[string][]string)
+
+	for _, s := range input.Snapshots["cluster_log_source"] {
+		src := s.(v1alpha1.ClusterLoggingConfig)
+
+		for _, ref := range src.Spec.DestinationRefs {
+			referencedBy[ref] = append(referencedBy[ref], "ClusterLoggingConfig/"+src.Name)
+		}
+
+		status := v1alpha1.ClusterLoggingConfigStatus{
+			Conditions: conditionsFromReport(src.Status.Conditions, report.Sources[src.Name]),
+			Components: componentsFromReport(report.Sources[src.Name]),
+		}
+		if statusChanged(src.Status, status) {
+			patchStatus(input, "ClusterLoggingConfig", "", src.Name, map[string]interface{}{
+				"conditions": status.Conditions,
+				"components": status.Components,
+			})
+		}
+	}
+
+	for _, s := range input.Snapshots["namespaced_log_source"] {
+		src := s.(v1alpha1.PodLoggingConfig)
+		name := v1alpha1.NamespacedToCluster(src).Name
+
+		for _, ref := range src.Spec.ClusterDestinationRefs {
+			referencedBy[ref] = append(referencedBy[ref], fmt.Sprintf("PodLoggingConfig/%s/%s", src.Namespace, src.Name))
+		}
+
+		status := v1alpha1.PodLoggingConfigStatus{
+			Conditions: conditionsFromReport(src.Status.Conditions, report.Sources[name]),
+			Components: componentsFromReport(report.Sources[name]),
+		}
+		if statusChanged(src.Status, status) {
+			patchStatus(input, "PodLoggingConfig", src.Namespace, src.Name, map[string]interface{}{
+				"conditions": status.Conditions,
+				"components": status.Components,
+			})
+		}
+	}
+
+	for _, d := range input.Snapshots["cluster_log_destination"] {
+		dst := d.(v1alpha1.ClusterLogDestination)
+
+		refs := referencedBy[dst.Name]
+		sort.Strings(refs)
+
+		status := v1alpha1.ClusterLogDestinationStatus{
+			Conditions:   conditionsFromReport(dst.Status.Conditions, report.Destinations[dst.Name]),
+			ReferencedBy: refs,
+			Components:   componentsFromReport(report.Destinations[dst.Name]),
+		}
+		if statusChanged(dst.Status, status) {
+			patchStatus(input, "ClusterLogDestination", "", dst.Name, map[string]interface{}{
+				"conditions":   status.Conditions,
+				"referencedBy": status.ReferencedBy,
+				"components":   status.Components,
+			})
+		}
+	}
+}
+
+// conditionsFromReport sets the Accepted condition keeping the transition time if the condition status is the same.
+func conditionsFromReport(current []metav1.Condition, status *composer.Status) []metav1.Condition {
+	if status == nil {
+		return current
+	}
+
+	condition := metav1.Condition{
+		Type:    v1alpha1.ConditionAccepted,
+		Status:  metav1.ConditionFalse,
+		Reason:  status.Reason,
+		Message: status.Message,
+	}
+	if status.Accepted {
+		condition.Status = metav1.ConditionTrue
+	}
+
+	conditions := make([]metav1.Condition, len(current))
+	copy(conditions, current)
+	meta.SetStatusCondition(&conditions, condition)
+
+	return conditions
+}
+
+func componentsFromReport(status *composer.Status) []string {
+	if status == nil {
+		return nil
+	}
+	return status.Components
+}
+
+func statusChanged(current, desired interface{}) bool {
+	currentJSON, _ := json.Marshal(current)
+	desiredJSON, _ := json.Marshal(desired)
+	return string(currentJSON) != string(desiredJSON)
+}
+
+// patchStatus merges fields to the status subresource. Nil fields are removed from the status.
+func patchStatus(input *go_hook.HookInput, kind, namespace, name string, fields map[string]interface{}) {
+	patch := map[string]interface{}{"status": fields}
+	input.PatchCollector.MergePatch(patch, "deckhouse.io/v1alpha1", kind, namespace, name, object_patch.WithSubresource("/status"))
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	watchtools "k8s.io/client-go/tools/watch"
+)
+
+// kubernetes-events prints Kubernetes events as JSON watch events to stdout. It is run by the vector exec
+// source in every log-shipper agent. Only the agent holding the lease streams events, so they are not
+// duplicated, and another agent takes over if the leader is gone.
+func main() {
+	var namespace, leaseName, identity string
+	flag.StringVar(&namespace, "namespace", "d8-log-shipper", "namespace of the lease")
+	flag.StringVar(&leaseName, "lease-name", "log-shipper-kubernetes-events", "name of the lease")
+	flag.StringVar(&identity, "identity", os.Getenv("VECTOR_SELF_NODE_NAME"), "identity of the lease holder")
+	flag.Parse()
+
+	if identity == "" {
+		identity, _ = os.Hostname()
+	}
+
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		log.Fatalf("cannot get cluster config: %v", err)
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		log.Fatalf("cannot create kubernetes client: %v", err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta:  metav1.ObjectMeta{Name: leaseName, Namespace: namespace},
+		Client:     client.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+	}
+
+	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		ReleaseOnCancel: true,
+		LeaseDuration:   15 * time.Second,
+		RenewDeadline:   10 * time.Second,
+		RetryPeriod:     2 * time.Second,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				log.Printf("%s is the leader, streaming events", identity)
+				if err := stream(ctx, client, os.Stdout); err != nil {
+					log.Printf("streaming events: %v", err)
+				}
+				cancel()
+			},
+			OnStoppedLeading: func() {
+				// Vector respawns the process, it joins the election again
+				log.Printf("%s is not the leader anymore", identity)
+				os.Exit(0)
+			},
+		},
+	})
+}
+
+// stream watches events from the current resource version. The watch is resumed from the last seen
+// resource version, so events are neither lost nor repeated when the API server closes the connection.
+// Events are listed again only if the resource version is too old.
+func stream(ctx context.Context, client kubernetes.Interface, out io.Writer) error {
+	lw := &cache.ListWatch{
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return client.CoreV1().Events(metav1.NamespaceAll).Watch(ctx, options)
+		},
+	}
+
+	enc := json.NewEncoder(out)
+	for {
+		list, err := client.CoreV1().Events(metav1.NamespaceAll).List(ctx, metav1.ListOptions{Limit: 1})
+		if err != nil {
+			return fmt.Errorf("cannot list events: %v", err)
+		}
+
+		w, err := watchtools.NewRetryWatcher(list.ResourceVersion, lw)
+		if err != nil {
+			return fmt.Errorf("cannot watch events: %v", err)
+		}
+
+		err = forward(ctx, w.ResultChan(), enc)
+		w.Stop()
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		log.Printf("resource version is too old, events are listed again")
+	}
+}
+
+// watchEvent is the JSON representation of a watch event
+type watchEvent struct {
+	Type   watch.EventType `json:"type"`
+	Object *corev1.Event   `json:"object"`
+}
+
+// forward writes events from the watch channel until the context is done or the watch is expired. Nil
+// error is returned if the watch is expired.
+func forward(ctx context.Context, ch <-chan watch.Event, enc *json.Encoder) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-ch:
+			if !ok {
+				return nil
+			}
+
+			switch ev.Type {
+			case watch.Added, watch.Modified, watch.Deleted:
+				event, ok := ev.Object.(*corev1.Event)
+				if !ok {
+					continue
+				}
+				if err := enc.Encode(watchEvent{Type: ev.Type, Object: event}); err != nil {
+					return fmt.Errorf("cannot write event: %v", err)
+				}
+			case watch.Error:
+				err := apierrors.FromObject(ev.Object)
+				if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+					return nil
+				}
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+func Test_forward(t *testing.T) {
+	ch := make(chan watch.Event, 3)
+	ch <- watch.Event{Type: watch.Added, Object: &corev1.Event{ObjectMeta: metav1.ObjectMeta{Name: "a"}, Reason: "Pulled"}}
+	ch <- watch.Event{Type: watch.Modified, Object: &corev1.Event{ObjectMeta: metav1.ObjectMeta{Name: "a"}, Count: 2}}
+	ch <- watch.Event{Type: watch.Error, Object: &metav1.Status{Status: metav1.StatusFailure, Code: http.StatusGone, Reason: metav1.StatusReasonExpired}}
+
+	var buf bytes.Buffer
+	if err := forward(context.Background(), ch, json.NewEncoder(&buf)); err != nil {
+		t.Fatalf("expired watch must not return an error, got %v", err)
+	}
+
+	dec := json.NewDecoder(&buf)
+	var got []watchEvent
+	for dec.More() {
+		var ev watchEvent
+		if err := dec.Decode(&ev); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, ev)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(got))
+	}
+	if got[0].Type != watch.Added || got[0].Object.Reason != "Pulled" {
+		t.Errorf("unexpected first event %+v", got[0])
+	}
+	if got[1].Type != watch.Modified || got[1].Object.Count != 2 {
+		t.Errorf("unexpected second event %+v", got[1])
+	}
+}
+
+func Test_forward_Error(t *testing.T) {
+	ch := make(chan watch.Event, 1)
+	ch <- watch.Event{Type: watch.Error, Object: &metav1.Status{Status: metav1.StatusFailure, Code: http.StatusForbidden, Reason: metav1.StatusReasonForbidden}}
+
+	var buf bytes.Buffer
+	if err := forward(context.Background(), ch, json.NewEncoder(&buf)); err == nil {
+		t.Fatal("expected an error")
+	}
+}
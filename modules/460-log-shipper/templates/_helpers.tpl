@@ -17,6 +17,11 @@
   valueFrom:
     fieldRef:
       fieldPath: metadata.namespace
+- name: VECTOR_REDACT_HASH_KEY
+  valueFrom:
+    secretKeyRef:
+      name: log-shipper-redact-hash-key
+      key: key
 {{- end }}
 
 {{- define "vectorMounts" }}
@@ -0,0 +1,176 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/flant/addon-operator/pkg/module_manager/go_hook"
+	"github.com/flant/addon-operator/sdk"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Defaults of the upmeter binary, they are used to compare the timeout with the period
+const (
+	defaultCustomProbePeriod  = 30 * time.Second
+	defaultCustomProbeTimeout = 5 * time.Second
+)
+
+// This hook collects UpmeterCustomProbe objects into internal values. Upmeter agents receive the
+// probes as arguments and run them in the "custom" group, the server uses them to list probes.
+var _ = sdk.RegisterFunc(
+	&go_hook.HookConfig{
+		Queue: "/modules/upmeter/custom_probes",
+		Kubernetes: []go_hook.KubernetesConfig{
+			{
+				Name:       "custom_probes",
+				ApiVersion: "deckhouse.io/v1alpha1",
+				Kind:       "UpmeterCustomProbe",
+				FilterFunc: filterCustomProbe,
+			},
+		},
+	},
+	collectCustomProbes,
+)
+
+// customProbe is the spec of UpmeterCustomProbe with the object name. The JSON is parsed by the
+// upmeter binary, so field names must be kept in sync with it.
+type customProbe struct {
+	Name string `json:"name"`
+	customProbeSpec
+}
+
+type customProbeSpec struct {
+	Type             string `json:"type"`
+	Period           string `json:"period,omitempty"`
+	Timeout          string `json:"timeout,omitempty"`
+	FailureThreshold int    `json:"failureThreshold,omitempty"`
+	SuccessThreshold int    `json:"successThreshold,omitempty"`
+
+	HTTP             *customProbeHTTP             `json:"http,omitempty"`
+	TCP              *customProbeTCP              `json:"tcp,omitempty"`
+	DNS              *customProbeDNS              `json:"dns,omitempty"`
+	KubernetesObject *customProbeKubernetesObject `json:"kubernetesObject,omitempty"`
+}
+
+type customProbeHTTP struct {
+	URL                 string `json:"url"`
+	ExpectedStatusCodes []int  `json:"expectedStatusCodes,omitempty"`
+	InsecureSkipVerify  bool   `json:"insecureSkipVerify,omitempty"`
+}
+
+type customProbeTCP struct {
+	Address string `json:"address"`
+}
+
+type customProbeDNS struct {
+	Name   string `json:"name"`
+	Server string `json:"server,omitempty"`
+}
+
+type customProbeKubernetesObject struct {
+	APIVersion string `json:"apiVersion"`
+	Resource   string `json:"resource"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+// validate mirrors the validation in the upmeter binary, so that an invalid probe is skipped here
+// instead of failing the start of upmeter agents and server.
+func (p customProbe) validate() error {
+	period, err := parseProbeDuration(p.Period, defaultCustomProbePeriod)
+	if err != nil {
+		return fmt.Errorf("cannot parse period: %v", err)
+	}
+	timeout, err := parseProbeDuration(p.Timeout, defaultCustomProbeTimeout)
+	if err != nil {
+		return fmt.Errorf("cannot parse timeout: %v", err)
+	}
+	if period <= 0 || timeout <= 0 {
+		return fmt.Errorf("period and timeout must be positive")
+	}
+	if timeout > period {
+		return fmt.Errorf("timeout %s exceeds period %s", timeout, period)
+	}
+
+	switch p.Type {
+	case "HTTP":
+		if p.HTTP == nil || p.HTTP.URL == "" {
+			return fmt.Errorf("http.url is required")
+		}
+	case "TCP":
+		if p.TCP == nil || p.TCP.Address == "" {
+			return fmt.Errorf("tcp.address is required")
+		}
+	case "DNS":
+		if p.DNS == nil || p.DNS.Name == "" {
+			return fmt.Errorf("dns.name is required")
+		}
+	case "KubernetesObject":
+		o := p.KubernetesObject
+		if o == nil || o.APIVersion == "" || o.Resource == "" || o.Name == "" {
+			return fmt.Errorf("kubernetesObject.apiVersion, kubernetesObject.resource and kubernetesObject.name are required")
+		}
+		if _, err := schema.ParseGroupVersion(o.APIVersion); err != nil {
+			return fmt.Errorf("cannot parse kubernetesObject.apiVersion: %v", err)
+		}
+	default:
+		return fmt.Errorf("unknown type %q", p.Type)
+	}
+	return nil
+}
+
+func parseProbeDuration(s string, def time.Duration) (time.Duration, error) {
+	if s == "" {
+		return def, nil
+	}
+	return time.ParseDuration(s)
+}
+
+func filterCustomProbe(obj *unstructured.Unstructured) (go_hook.FilterResult, error) {
+	var cp struct {
+		Spec customProbeSpec `json:"spec"`
+	}
+
+	err := sdk.FromUnstructured(obj, &cp)
+	if err != nil {
+		return nil, err
+	}
+
+	return customProbe{Name: obj.GetName(), customProbeSpec: cp.Spec}, nil
+}
+
+func collectCustomProbes(input *go_hook.HookInput) error {
+	probes := make([]customProbe, 0, len(input.Snapshots["custom_probes"]))
+	for _, s := range input.Snapshots["custom_probes"] {
+		probe := s.(customProbe)
+		if err := probe.validate(); err != nil {
+			input.LogEntry.Warnf("UpmeterCustomProbe %s is invalid, skipping: %v", probe.Name, err)
+			continue
+		}
+		probes = append(probes, probe)
+	}
+
+	// Stable order does not restart upmeter pods on every hook run
+	sort.Slice(probes, func(i, j int) bool { return probes[i].Name < probes[j].Name })
+
+	input.Values.Set("upmeter.internal.customProbes", probes)
+	return nil
+}
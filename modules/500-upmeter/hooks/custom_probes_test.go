@@ -0,0 +1,150 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/deckhouse/deckhouse/testing/hooks"
+)
+
+var _ = Describe("Modules :: upmeter :: hooks :: custom_probes ::", func() {
+	f := HookExecutionConfigInit(`{"upmeter":{"internal":{"customProbes":[]}}}`, `{}`)
+	f.RegisterCRD("deckhouse.io", "v1alpha1", "UpmeterCustomProbe", false)
+
+	Context("Empty cluster", func() {
+		BeforeEach(func() {
+			f.BindingContexts.Set(f.KubeStateSet(``))
+			f.RunHook()
+		})
+
+		It("Sets empty list", func() {
+			Expect(f).To(ExecuteSuccessfully())
+			Expect(f.ValuesGet("upmeter.internal.customProbes").String()).To(MatchJSON(`[]`))
+		})
+	})
+
+	Context("Cluster with custom probes", func() {
+		BeforeEach(func() {
+			f.BindingContexts.Set(f.KubeStateSet(`
+apiVersion: deckhouse.io/v1alpha1
+kind: UpmeterCustomProbe
+metadata:
+  name: site
+spec:
+  type: HTTP
+  period: 1m
+  timeout: 5s
+  failureThreshold: 3
+  http:
+    url: https://example.com/healthz
+    expectedStatusCodes: [200, 204]
+---
+apiVersion: deckhouse.io/v1alpha1
+kind: UpmeterCustomProbe
+metadata:
+  name: config
+spec:
+  type: KubernetesObject
+  kubernetesObject:
+    apiVersion: v1
+    resource: configmaps
+    namespace: default
+    name: app-config
+`))
+			f.RunHook()
+		})
+
+		It("Sets probes sorted by name", func() {
+			Expect(f).To(ExecuteSuccessfully())
+			Expect(f.ValuesGet("upmeter.internal.customProbes").String()).To(MatchJSON(`[
+{
+  "name": "config",
+  "type": "KubernetesObject",
+  "kubernetesObject": {"apiVersion": "v1", "resource": "configmaps", "namespace": "default", "name": "app-config"}
+},
+{
+  "name": "site",
+  "type": "HTTP",
+  "period": "1m",
+  "timeout": "5s",
+  "failureThreshold": 3,
+  "http": {"url": "https://example.com/healthz", "expectedStatusCodes": [200, 204]}
+}
+]`))
+		})
+	})
+
+	Context("Cluster with invalid custom probes", func() {
+		BeforeEach(func() {
+			f.BindingContexts.Set(f.KubeStateSet(`
+apiVersion: deckhouse.io/v1alpha1
+kind: UpmeterCustomProbe
+metadata:
+  name: db
+spec:
+  type: TCP
+  tcp:
+    address: db.example.com:5432
+---
+apiVersion: deckhouse.io/v1alpha1
+kind: UpmeterCustomProbe
+metadata:
+  name: slow
+spec:
+  type: TCP
+  period: 10s
+  timeout: 1m
+  tcp:
+    address: slow.example.com:5432
+---
+apiVersion: deckhouse.io/v1alpha1
+kind: UpmeterCustomProbe
+metadata:
+  name: no-url
+spec:
+  type: HTTP
+  http:
+    url: ""
+---
+apiVersion: deckhouse.io/v1alpha1
+kind: UpmeterCustomProbe
+metadata:
+  name: bad-api-version
+spec:
+  type: KubernetesObject
+  kubernetesObject:
+    apiVersion: a/b/c
+    resource: configmaps
+    name: app-config
+`))
+			f.RunHook()
+		})
+
+		It("Skips invalid probes", func() {
+			Expect(f).To(ExecuteSuccessfully())
+			Expect(f.ValuesGet("upmeter.internal.customProbes").String()).To(MatchJSON(`[
+{
+  "name": "db",
+  "type": "TCP",
+  "tcp": {"address": "db.example.com:5432"}
+}
+]`))
+		})
+	})
+})
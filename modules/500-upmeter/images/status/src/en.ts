@@ -41,6 +41,10 @@ const known: { [name: string]: IGroupData } = {
 		name: "Nginx",
 		description: "The availability of Nginx Ingress Controllers",
 	},
+	custom: {
+		name: "Custom",
+		description: "The availability of services checked by user-defined probes",
+	},
 };
 
 export function getGroupData(name: string): IGroupData {
@@ -39,6 +39,8 @@ func main() {
 		agentConfig = agent.NewConfig()
 
 		serverConfig = server.NewConfig()
+
+		reportConfig = &reportConfig{}
 	)
 
 	app := kingpin.New("upmeter", "upmeter")
@@ -126,6 +128,14 @@ func main() {
 		return nil
 	})
 
+	// Report
+
+	reportCommand := app.Command("report", "Export availability report from upmeter server")
+	parseReportArgs(reportCommand, reportConfig)
+	reportCommand.Action(func(c *kingpin.ParseContext) error {
+		return exportReport(reportConfig)
+	})
+
 	kingpin.MustParse(app.Parse(os.Args[1:]))
 }
 
@@ -45,6 +45,22 @@ func parseServerArgs(cmd *kingpin.CmdClause, config *server.Config) {
 		Default("upmeter.db").
 		StringVar(&config.DatabasePath)
 
+	// Retention of episodes by resolution. 5m episodes cover the longest SLO window.
+	cmd.Flag("retention-5m", "Time to keep 5m episodes, zero to keep forever.").
+		Envar("UPMETER_RETENTION_5M").
+		Default("9600h").
+		DurationVar(&config.Retention.Episodes5m)
+
+	cmd.Flag("retention-1h", "Time to keep hourly episodes, zero to keep forever.").
+		Envar("UPMETER_RETENTION_1H").
+		Default("17520h").
+		DurationVar(&config.Retention.Episodes1h)
+
+	cmd.Flag("retention-1d", "Time to keep daily episodes, zero to keep forever.").
+		Envar("UPMETER_RETENTION_1D").
+		Default("0s").
+		DurationVar(&config.Retention.Episodes1d)
+
 	// Origins count
 	cmd.Flag("origins", "The expected number of origins, used for exporting episodes as metrics when they are fulfilled by this number of agents.").
 		Required().
@@ -64,6 +80,14 @@ func parseServerArgs(cmd *kingpin.CmdClause, config *server.Config) {
 	cmd.Flag("dynamic-probe-nodegroup", "Node Group name tracked by probes").
 		StringsVar(&config.DynamicProbes.NodeGroups)
 
+	// Custom probes from UpmeterCustomProbe resources
+	cmd.Flag("custom-probe", "Custom probe definition in JSON").
+		StringsVar(&config.DynamicProbes.CustomProbes)
+
+	// Calculated probes from the module configuration
+	cmd.Flag("calculated-probe", "Calculated probe definition in JSON").
+		StringsVar(&config.DynamicProbes.CalculatedProbes)
+
 	// User-Agent
 	// TODO generate from CI?
 	cmd.Flag("user-agent", "User Agent for HTTP client").
@@ -127,6 +151,14 @@ func parseAgentArgs(cmd *kingpin.CmdClause, config *agent.Config) {
 	cmd.Flag("dynamic-probe-known-zone", "A known zone for node group").
 		StringsVar(&config.DynamicProbes.Zones)
 
+	// Custom probes from UpmeterCustomProbe resources
+	cmd.Flag("custom-probe", "Custom probe definition in JSON to run").
+		StringsVar(&config.DynamicProbes.CustomProbes)
+
+	// Calculated probes from the module configuration
+	cmd.Flag("calculated-probe", "Calculated probe definition in JSON to evaluate").
+		StringsVar(&config.DynamicProbes.CalculatedProbes)
+
 	// User-Agent
 	// TODO generate from CI?
 	cmd.Flag("user-agent", "User Agent for HTTP client").
@@ -135,6 +167,34 @@ func parseAgentArgs(cmd *kingpin.CmdClause, config *agent.Config) {
 		StringVar(&config.UserAgent)
 }
 
+func parseReportArgs(cmd *kingpin.CmdClause, config *reportConfig) {
+	cmd.Flag("server", "Upmeter server address.").
+		Envar("UPMETER_SERVER").
+		Default("http://127.0.0.1:8091").
+		StringVar(&config.Server)
+
+	cmd.Flag("period", "Calendar month (2022-05) or quarter (2022-Q2) in UTC.").
+		Required().
+		StringVar(&config.Period)
+
+	cmd.Flag("format", "Output format.").
+		Default("csv").
+		EnumVar(&config.Format, "csv", "json")
+
+	cmd.Flag("table", "CSV table to output.").
+		Default("availability").
+		EnumVar(&config.Table, "availability", "incidents")
+
+	cmd.Flag("mute-downtime-types", "Downtime types to mute separated with '!': Mnt, Acd, InfMnt, InfAcd.").
+		Default("Mnt!InfMnt!InfAcd").
+		StringVar(&config.MuteDowntimeTypes)
+
+	cmd.Flag("output", "Output file, stdout by default.").
+		Short('o').
+		Default("").
+		StringVar(&config.Output)
+}
+
 func parseKubeArgs(cmd *kingpin.CmdClause, config *kubernetes.Config) {
 	cmd.Flag("kube-context", "The name of the kubeconfig context to use. Can be set with $KUBE_CONTEXT.").
 		Envar("KUBE_CONTEXT").
@@ -0,0 +1,70 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+type reportConfig struct {
+	Server            string
+	Period            string
+	Format            string
+	Table             string
+	MuteDowntimeTypes string
+	Output            string
+}
+
+// exportReport fetches the report from the upmeter server and writes it to the output
+func exportReport(config *reportConfig) error {
+	query := url.Values{}
+	query.Set("period", config.Period)
+	query.Set("format", config.Format)
+	query.Set("table", config.Table)
+	query.Set("muteDowntimeTypes", config.MuteDowntimeTypes)
+
+	client := &http.Client{Timeout: time.Minute}
+	resp, err := client.Get(config.Server + "/api/report?" + query.Encode())
+	if err != nil {
+		return fmt.Errorf("cannot fetch report: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("cannot fetch report: %s: %s", resp.Status, body)
+	}
+
+	var out io.Writer = os.Stdout
+	if config.Output != "" {
+		f, err := os.Create(config.Output)
+		if err != nil {
+			return fmt.Errorf("cannot create output file: %v", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	_, err = io.Copy(out, resp.Body)
+	return err
+}
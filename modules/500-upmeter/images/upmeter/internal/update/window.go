@@ -18,12 +18,17 @@ package update
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 	"time"
+
+	// embed the time zone database, update windows may be set in any IANA time zone
+	_ "time/tzdata"
 )
 
 const (
-	hh_mm = "15:04" // nolint: revive
+	hh_mm      = "15:04"      // nolint: revive
+	yyyy_mm_dd = "2006-01-02" // nolint: revive
 )
 
 // Windows update windows
@@ -31,9 +36,21 @@ type Windows []Window
 
 // Window single window
 type Window struct {
-	From string   `json:"from"`
-	To   string   `json:"to"`
-	Days []string `json:"days"`
+	From string   `json:"from,omitempty"`
+	To   string   `json:"to,omitempty"`
+	Days []string `json:"days,omitempty"`
+	// Date makes a one-off window, which is open only on the specified date (YYYY-MM-DD). Days are ignored.
+	Date string `json:"date,omitempty"`
+	// Blackout forbids updates for the range of dates regardless of other windows. From and To are not used.
+	Blackout *DateRange `json:"blackout,omitempty"`
+	// TimeZone is an IANA time zone of the window, UTC by default
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// DateRange inclusive range of dates in the YYYY-MM-DD format
+type DateRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
 }
 
 // FromJSON returns update Windows from json
@@ -41,42 +58,107 @@ func FromJSON(data []byte) (Windows, error) {
 	var w Windows
 
 	err := json.Unmarshal(data, &w)
+	if err != nil {
+		return nil, err
+	}
+
+	return w, w.Validate()
+}
+
+// Validate checks the fields which can't be checked by the openapi spec
+func (ws Windows) Validate() error {
+	for _, window := range ws {
+		err := window.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Validate checks that the time zone, times and dates of the window can be parsed
+func (uw Window) Validate() error {
+	if uw.TimeZone != "" {
+		if _, err := time.LoadLocation(uw.TimeZone); err != nil {
+			return fmt.Errorf("invalid time zone %q: %v", uw.TimeZone, err)
+		}
+	}
+
+	if uw.Blackout != nil {
+		from, err := time.Parse(yyyy_mm_dd, uw.Blackout.From)
+		if err != nil {
+			return fmt.Errorf("invalid blackout start %q: %v", uw.Blackout.From, err)
+		}
+
+		to, err := time.Parse(yyyy_mm_dd, uw.Blackout.To)
+		if err != nil {
+			return fmt.Errorf("invalid blackout end %q: %v", uw.Blackout.To, err)
+		}
 
-	return w, err
+		if to.Before(from) {
+			return fmt.Errorf("blackout %s..%s ends before it starts", uw.Blackout.From, uw.Blackout.To)
+		}
+
+		return nil
+	}
+
+	if _, err := time.Parse(hh_mm, uw.From); err != nil {
+		return fmt.Errorf("invalid window start %q: %v", uw.From, err)
+	}
+
+	if _, err := time.Parse(hh_mm, uw.To); err != nil {
+		return fmt.Errorf("invalid window end %q: %v", uw.To, err)
+	}
+
+	if uw.Date != "" {
+		if _, err := time.Parse(yyyy_mm_dd, uw.Date); err != nil {
+			return fmt.Errorf("invalid window date %q: %v", uw.Date, err)
+		}
+	}
+
+	return nil
 }
 
-// IsAllowed returns if specified time get into windows
+// IsAllowed returns if specified time get into windows.
+// Blackout periods forbid updates even if the time gets into other windows.
 func (ws Windows) IsAllowed(t time.Time) bool {
-	if len(ws) == 0 {
-		return true
+	if _, inBlackout := ws.blackoutEnd(t); inBlackout {
+		return false
 	}
 
+	var hasWindows bool
+
 	for _, window := range ws {
+		if window.Blackout != nil {
+			continue
+		}
+
+		hasWindows = true
+
 		if window.IsAllowed(t) {
 			return true
 		}
 	}
 
-	return false
+	return !hasWindows
 }
 
 // IsAllowed check if specified window is allowed at the moment or not
 func (uw Window) IsAllowed(now time.Time) bool {
-	now = now.UTC()
-	// input is validated through the openapi spec
-	// we must have only a valid time here
-	fromInput, _ := time.Parse(hh_mm, uw.From)
-	toInput, _ := time.Parse(hh_mm, uw.To)
-
-	fromTime := time.Date(now.Year(), now.Month(), now.Day(), fromInput.Hour(), fromInput.Minute(), 0, 0, time.UTC)
-	toTime := time.Date(now.Year(), now.Month(), now.Day(), toInput.Hour(), toInput.Minute(), 0, 0, time.UTC)
+	if uw.Blackout != nil {
+		_, inBlackout := uw.blackoutEnd(now)
+		return !inBlackout
+	}
 
-	updateToday := uw.isTodayAllowed(now, uw.Days)
+	now = now.In(uw.location())
 
-	if !updateToday {
+	if !uw.isDateAllowed(now) {
 		return false
 	}
 
+	fromTime, toTime := uw.bounds(now)
+
 	if now.After(fromTime) && now.Before(toTime) {
 		return true
 	}
@@ -84,6 +166,80 @@ func (uw Window) IsAllowed(now time.Time) bool {
 	return false
 }
 
+// blackoutEnd returns the latest end of the blackout periods the time gets into
+func (ws Windows) blackoutEnd(t time.Time) (time.Time, bool) {
+	var (
+		end        time.Time
+		inBlackout bool
+	)
+
+	for _, window := range ws {
+		windowEnd, ok := window.blackoutEnd(t)
+		if ok && windowEnd.After(end) {
+			end = windowEnd
+			inBlackout = true
+		}
+	}
+
+	return end, inBlackout
+}
+
+// bounds returns the window start and end on the specified date
+func (uw Window) bounds(date time.Time) (time.Time, time.Time) {
+	// input is checked by Validate
+	// we must have only a valid time here
+	fromInput, _ := time.Parse(hh_mm, uw.From)
+	toInput, _ := time.Parse(hh_mm, uw.To)
+
+	fromTime := time.Date(date.Year(), date.Month(), date.Day(), fromInput.Hour(), fromInput.Minute(), 0, 0, date.Location())
+	toTime := time.Date(date.Year(), date.Month(), date.Day(), toInput.Hour(), toInput.Minute(), 0, 0, date.Location())
+
+	return fromTime, toTime
+}
+
+// blackoutEnd returns the end of the blackout period if the time gets into it
+func (uw Window) blackoutEnd(t time.Time) (time.Time, bool) {
+	if uw.Blackout == nil {
+		return time.Time{}, false
+	}
+
+	loc := uw.location()
+	// input is checked by Validate
+	from, _ := time.ParseInLocation(yyyy_mm_dd, uw.Blackout.From, loc)
+	to, _ := time.ParseInLocation(yyyy_mm_dd, uw.Blackout.To, loc)
+	// the last day is included
+	end := to.AddDate(0, 0, 1)
+
+	if t.Before(from) || !t.Before(end) {
+		return time.Time{}, false
+	}
+
+	return end.UTC(), true
+}
+
+// location returns the window time zone, UTC is used for the empty one.
+// Windows must be validated before use, the unknown time zone also falls back to UTC.
+func (uw Window) location() *time.Location {
+	if uw.TimeZone == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(uw.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+func (uw Window) isDateAllowed(date time.Time) bool {
+	if uw.Date != "" {
+		return date.Format(yyyy_mm_dd) == uw.Date
+	}
+
+	return uw.isTodayAllowed(date, uw.Days)
+}
+
 func (uw Window) isDayEqual(today time.Time, dayString string) bool {
 	var day time.Weekday
 
@@ -137,6 +293,11 @@ func (uw *Window) DeepCopyInto(out *Window) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if uw.Blackout != nil {
+		in, out := &uw.Blackout, &out.Blackout
+		*out = new(DateRange)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdateWindow.
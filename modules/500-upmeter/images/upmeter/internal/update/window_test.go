@@ -0,0 +1,99 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package update
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsAllowed(t *testing.T) {
+	ws := Windows{
+		{
+			From:     "08:00",
+			To:       "10:00",
+			Days:     []string{"mon"},
+			TimeZone: "America/New_York",
+		},
+		{
+			From: "08:00",
+			To:   "10:00",
+			Date: "2021-10-20",
+		},
+		{
+			Blackout: &DateRange{From: "2021-10-25", To: "2021-10-25"},
+			TimeZone: "America/New_York",
+		},
+	}
+
+	// monday 09:00 in New York
+	assert.True(t, ws.IsAllowed(time.Date(2021, 10, 18, 13, 00, 00, 0, time.UTC)))
+	// monday 09:00 UTC, 05:00 in New York
+	assert.False(t, ws.IsAllowed(time.Date(2021, 10, 18, 9, 00, 00, 0, time.UTC)))
+	// one-off window on wednesday
+	assert.True(t, ws.IsAllowed(time.Date(2021, 10, 20, 9, 00, 00, 0, time.UTC)))
+	assert.False(t, ws.IsAllowed(time.Date(2021, 10, 27, 9, 00, 00, 0, time.UTC)))
+	// monday 09:00 in New York during the blackout
+	assert.False(t, ws.IsAllowed(time.Date(2021, 10, 25, 13, 00, 00, 0, time.UTC)))
+
+	blackout := Windows{
+		{
+			Blackout: &DateRange{From: "2021-10-25", To: "2021-10-26"},
+		},
+	}
+	assert.False(t, blackout.IsAllowed(time.Date(2021, 10, 26, 23, 59, 00, 0, time.UTC)))
+	assert.True(t, blackout.IsAllowed(time.Date(2021, 10, 27, 0, 00, 00, 0, time.UTC)))
+}
+
+func TestValidate(t *testing.T) {
+	valid := Windows{
+		{From: "8:00", To: "10:00", Date: "2024-02-29", TimeZone: "Europe/Berlin"},
+		{Blackout: &DateRange{From: "2021-12-24", To: "2022-01-02"}},
+	}
+	assert.NoError(t, valid.Validate())
+
+	invalid := map[string]Window{
+		"time zone":      {From: "08:00", To: "10:00", TimeZone: "Mars/Olympus"},
+		"date":           {From: "08:00", To: "10:00", Date: "2022-02-30"},
+		"time":           {From: "08:00", To: "24:00"},
+		"blackout start": {Blackout: &DateRange{From: "2022-02-30", To: "2022-03-01"}},
+		"blackout end":   {Blackout: &DateRange{From: "2022-02-01", To: "2022-13-01"}},
+		"blackout range": {Blackout: &DateRange{From: "2022-02-02", To: "2022-02-01"}},
+	}
+	for name, window := range invalid {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, Windows{window}.Validate())
+		})
+	}
+}
+
+func TestWindow_DeepCopy(t *testing.T) {
+	var ws Windows
+	err := json.Unmarshal([]byte(`[{"from":"08:00","to":"10:00","days":["Mon"]},{"blackout":{"from":"2021-12-24","to":"2022-01-02"}}]`), &ws)
+	assert.NoError(t, err)
+
+	cp := ws.DeepCopy()
+	assert.Equal(t, ws, cp)
+
+	cp[0].Days[0] = "Tue"
+	cp[1].Blackout.To = "2022-01-03"
+	assert.Equal(t, "Mon", ws[0].Days[0])
+	assert.Equal(t, "2022-01-02", ws[1].Blackout.To)
+}
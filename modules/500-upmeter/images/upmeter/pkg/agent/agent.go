@@ -60,6 +60,8 @@ type DynamicProbesConfig struct {
 	IngressControllers []string
 	NodeGroups         []string
 	Zones              []string
+	CustomProbes       []string
+	CalculatedProbes   []string
 }
 
 func NewConfig() *Config {
@@ -85,12 +87,22 @@ func (a *Agent) Start(ctx context.Context) error {
 		return fmt.Errorf("cannot init access to Kubernetes cluster: %v", err)
 	}
 
+	customProbes, err := probe.ParseCustomProbes(a.config.DynamicProbes.CustomProbes)
+	if err != nil {
+		a.logger.Warnf("cannot parse custom probes: %v", err)
+	}
+	calculatedProbes, err := calculated.ParseDefinitions(a.config.DynamicProbes.CalculatedProbes)
+	if err != nil {
+		a.logger.Warnf("cannot parse calculated probes: %v", err)
+	}
+
 	// Probe registry
 	ftr := probe.NewProbeFilter(a.config.DisabledProbes)
 	dynamicConfig := probe.DynamicConfig{
 		IngressNginxControllers: a.config.DynamicProbes.IngressControllers,
 		NodeGroups:              a.config.DynamicProbes.NodeGroups,
 		Zones:                   a.config.DynamicProbes.Zones,
+		CustomProbes:            customProbes,
 	}
 
 	nodeMon := node.NewMonitor(kubeAccess.Kubernetes(), log.NewEntry(a.logger))
@@ -105,7 +117,7 @@ func (a *Agent) Start(ctx context.Context) error {
 	controlPlanePreflight.Start()
 
 	runnerLoader := probe.NewLoader(ftr, kubeAccess, nodeMon, dynamicConfig, controlPlanePreflight, a.logger)
-	calcLoader := calculated.NewLoader(ftr, a.logger)
+	calcLoader := calculated.NewLoader(ftr, calculatedProbes, a.logger)
 	registry := registry.New(runnerLoader, calcLoader)
 
 	// Database connection with pool
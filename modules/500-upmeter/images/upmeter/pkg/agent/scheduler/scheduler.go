@@ -195,19 +195,16 @@ func (e *Scheduler) convert(start time.Time) ([]check.Episode, error) {
 	episodes := make([]check.Episode, 0, len(e.results))
 
 	// Collect episodes for calculated probes.
+	calcByGroup := make(map[string][]*check.StatusSeries)
 	for _, calc := range e.registry.Calculators() {
-		sss := make([]*check.StatusSeries, 0)
-		for _, id := range calc.MergeIds() {
-			if ss, ok := e.series[id]; ok {
-				sss = append(sss, ss)
-			}
-		}
-
-		series, err := check.MergeStatusSeries(e.seriesSize, sss)
+		series, err := calc.Calculate(e.seriesSize, e.series)
 		if err != nil {
 			return nil, fmt.Errorf("cannot calculate episode stats for %q: %v", calc.ProbeRef().Id(), err)
 		}
 
+		group := calc.ProbeRef().Group
+		calcByGroup[group] = append(calcByGroup[group], series)
+
 		ep := check.NewEpisode(calc.ProbeRef(), start, e.scrapePeriod, series.Stats())
 		episodes = append(episodes, ep)
 	}
@@ -227,6 +224,14 @@ func (e *Scheduler) convert(start time.Time) ([]check.Episode, error) {
 		episodes = append(episodes, ep)
 	}
 
+	// Groups of calculated probes only have no other source for the group episode. In other
+	// groups, calculated probes are skipped since they contain no new data.
+	for group, probeSeriesList := range calcByGroup {
+		if _, ok := byGroup[group]; !ok {
+			byGroup[group] = probeSeriesList
+		}
+	}
+
 	// Collect group episodes.
 	for group, probeSeriesList := range byGroup {
 		groupSeries, err := check.MergeStatusSeries(e.seriesSize, probeSeriesList)
@@ -151,6 +151,8 @@ type DowntimeIncident struct {
 	Description  string
 	Affected     []string // a list of affected groups
 	DowntimeName string   // a checkName of a Downtime custom resource
+	Created      int64    // creation time of the Downtime custom resource
+	Public       bool     // whether the downtime is shown on the public status page
 }
 
 type Stats struct {
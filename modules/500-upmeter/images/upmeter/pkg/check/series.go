@@ -115,3 +115,54 @@ func MergeStatusSeries(size int, sss []*StatusSeries) (*StatusSeries, error) {
 
 	return acc, nil
 }
+
+// QuorumStatusSeries merges status series by weighted quorum. For every slot, the result is Up when
+// the weight of Up statuses reaches the quorum, Unknown when the quorum can be reached only with
+// Unknown statuses counted as Up, and Down otherwise. The slot is left without data when all the
+// series have no data in it. Nil series are treated as series without data.
+func QuorumStatusSeries(size int, sss []*StatusSeries, weights []float64, quorum float64) (*StatusSeries, error) {
+	if len(sss) != len(weights) {
+		return nil, fmt.Errorf("the number of weights must match the number of series, got %d and %d", len(weights), len(sss))
+	}
+	for _, ss := range sss {
+		if ss != nil && ss.size() != size {
+			return nil, fmt.Errorf("the capacity of status series must be equal, got %d and %d", size, ss.size())
+		}
+	}
+
+	// Avoid float rounding errors, e.g. 3 × (2/3) should reach the quorum of 2.
+	const epsilon = 1e-9
+
+	acc := NewStatusSeries(size)
+	for i := 0; i < size; i++ {
+		var up, unknown float64
+		var hasData bool
+		for j, ss := range sss {
+			if ss == nil {
+				continue
+			}
+			switch ss.series[i] {
+			case Up:
+				up += weights[j]
+			case Unknown:
+				unknown += weights[j]
+			case nodata:
+				continue
+			}
+			hasData = true
+		}
+
+		switch {
+		case !hasData:
+			acc.series[i] = nodata
+		case up+epsilon >= quorum:
+			acc.series[i] = Up
+		case up+unknown+epsilon >= quorum:
+			acc.series[i] = Unknown
+		default:
+			acc.series[i] = Down
+		}
+	}
+
+	return acc, nil
+}
@@ -556,3 +556,82 @@ func Test_MergeStatusSeries(t *testing.T) {
 		})
 	}
 }
+
+func Test_QuorumStatusSeries(t *testing.T) {
+	newSeries := func(statuses ...Status) *StatusSeries {
+		ss := NewStatusSeries(len(statuses))
+		for _, s := range statuses {
+			_ = ss.Add(s)
+		}
+		return ss
+	}
+
+	tests := []struct {
+		name    string
+		args    []*StatusSeries
+		weights []float64
+		quorum  float64
+		want    *StatusSeries
+		wantErr bool
+	}{
+		{
+			name:    "any up",
+			args:    []*StatusSeries{newSeries(Up, Down, Down, Down), newSeries(Down, Up, Unknown, nodata)},
+			weights: []float64{1, 1},
+			quorum:  1,
+			want:    newSeries(Up, Up, Unknown, Down),
+		},
+		{
+			name: "2 of 3",
+			args: []*StatusSeries{
+				newSeries(Up, Up, Up, Down, nodata),
+				newSeries(Up, Down, Unknown, Down, nodata),
+				newSeries(Down, Down, Down, Down, nodata),
+			},
+			weights: []float64{1, 1, 1},
+			quorum:  2,
+			want:    newSeries(Up, Down, Unknown, Down, nodata),
+		},
+		{
+			name:    "nil series has no data",
+			args:    []*StatusSeries{newSeries(Up, Down), nil},
+			weights: []float64{1, 1},
+			quorum:  1,
+			want:    newSeries(Up, Down),
+		},
+		{
+			name:    "weighted",
+			args:    []*StatusSeries{newSeries(Up, Down, Down), newSeries(Down, Up, Up), newSeries(Down, Down, Up)},
+			weights: []float64{2, 1, 1},
+			quorum:  4 * 0.5,
+			want:    newSeries(Up, Down, Up),
+		},
+		{
+			name:    "weight count mismatch results in error",
+			args:    []*StatusSeries{newSeries(Up)},
+			weights: []float64{1, 1},
+			quorum:  1,
+			wantErr: true,
+		},
+		{
+			name:    "size mismatch results in error",
+			args:    []*StatusSeries{newSeries(Up), newSeries(Up, Up)},
+			weights: []float64{1, 1},
+			quorum:  1,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size := len(tt.args[0].series)
+			got, err := QuorumStatusSeries(size, tt.args, tt.weights, tt.quorum)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want.series, got.series)
+		})
+	}
+}
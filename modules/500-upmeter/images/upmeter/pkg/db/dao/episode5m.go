@@ -155,13 +155,30 @@ func (d *EpisodeDao5m) ListEpisodesByRange(from, to int64, ref check.ProbeRef) (
 
 // ListEpisodeSumsForRanges returns sums of seconds for each group_name+probe_name to reduce
 // calculations over full table.
-// FIXME rewrite this quick hack code.
 func (d *EpisodeDao5m) ListEpisodeSumsForRanges(rng ranges.StepRange, ref check.ProbeRef) ([]check.Episode, error) {
+	return listEpisodeSumsForRanges(d.DbCtx, d.Table, rng, ref)
+}
+
+// DeleteUpTo deletes episodes older than the slot
+func (d *EpisodeDao5m) DeleteUpTo(slot time.Time) error {
+	return deleteEpisodesUpTo(d.DbCtx, d.Table, slot)
+}
+
+func deleteEpisodesUpTo(dbCtx *dbcontext.DbContext, table string, slot time.Time) error {
+	query := `DELETE FROM ` + table + ` WHERE timeslot < ?`
+	_, err := dbCtx.StmtRunner().Exec(query, slot.Unix())
+	return err
+}
+
+// listEpisodeSumsForRanges returns sums of seconds for each group_name+probe_name from the table
+// for each subrange. It is shared by tables of all resolutions.
+// FIXME rewrite this quick hack code.
+func listEpisodeSumsForRanges(dbCtx *dbcontext.DbContext, table string, rng ranges.StepRange, ref check.ProbeRef) ([]check.Episode, error) {
 	res := make([]check.Episode, 0)
 
 	queryParts := map[string]string{
 		"select": `SELECT sum(nano_up), sum(nano_down), sum(nano_unknown), sum(nano_unmeasured)`,
-		"from":   "FROM episodes_5m",
+		"from":   "FROM " + table,
 		"where":  "WHERE timeslot >= ? AND timeslot < ?",
 	}
 
@@ -200,7 +217,7 @@ func (d *EpisodeDao5m) ListEpisodeSumsForRanges(rng ranges.StepRange, ref check.
 
 		// Exec and parse
 
-		rows, err := d.DbCtx.StmtRunner().Query(query, queryArgs...)
+		rows, err := dbCtx.StmtRunner().Query(query, queryArgs...)
 		if err != nil {
 			return nil, fmt.Errorf("select for TimeslotRange: %v", err)
 		}
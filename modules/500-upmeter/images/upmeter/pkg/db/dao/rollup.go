@@ -0,0 +1,203 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dao
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"d8.io/upmeter/pkg/check"
+	dbcontext "d8.io/upmeter/pkg/db/context"
+	"d8.io/upmeter/pkg/server/ranges"
+)
+
+// Resolution describes the table of episodes with the specific slot size
+type Resolution struct {
+	Table string
+	Slot  time.Duration
+}
+
+var (
+	Resolution5m = Resolution{Table: "episodes_5m", Slot: 5 * time.Minute}
+	Resolution1h = Resolution{Table: "episodes_1h", Slot: time.Hour}
+	Resolution1d = Resolution{Table: "episodes_1d", Slot: 24 * time.Hour}
+)
+
+// resolutions are ordered from the coarsest to the finest
+var resolutions = []Resolution{Resolution1d, Resolution1h, Resolution5m}
+
+// isAligned returns true if all subrange edges are aligned to slots of the resolution. Episodes of
+// the resolution sum up exactly to such subranges.
+func (r Resolution) isAligned(subranges []ranges.Range) bool {
+	slot := int64(r.Slot.Seconds())
+	for _, rng := range subranges {
+		if rng.From%slot != 0 || rng.To%slot != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// CoarsestResolution returns the resolution with the largest slot that all subrange edges are aligned
+// to.
+func CoarsestResolution(subranges []ranges.Range) Resolution {
+	for _, res := range resolutions {
+		if res.isAligned(subranges) {
+			return res
+		}
+	}
+	return Resolution5m
+}
+
+// ChooseResolution returns the resolution to read the subranges from. Tables are cleaned by their own
+// retention, so the coarsest aligned resolution is used only if it keeps episodes since the start of
+// the subranges. Otherwise, the finest resolution keeping them is used, even if the subranges are not
+// aligned to it. oldest returns the time of the oldest kept episode of the resolution, or false if
+// there are none.
+func ChooseResolution(subranges []ranges.Range, oldest func(Resolution) (int64, bool)) Resolution {
+	if len(subranges) == 0 {
+		return Resolution5m
+	}
+	from := subranges[0].From
+
+	keeps := func(res Resolution) bool {
+		ts, ok := oldest(res)
+		return ok && ts <= from
+	}
+
+	for _, res := range resolutions {
+		if res.isAligned(subranges) && keeps(res) {
+			return res
+		}
+	}
+
+	for i := len(resolutions) - 1; i >= 0; i-- {
+		if keeps(resolutions[i]) {
+			return resolutions[i]
+		}
+	}
+
+	// No table keeps episodes since the start, e.g. right after the installation
+	return CoarsestResolution(subranges)
+}
+
+// EpisodeRollupDao manages episodes summed up from the table of a finer resolution
+type EpisodeRollupDao struct {
+	DbCtx  *dbcontext.DbContext
+	Source Resolution
+	Target Resolution
+}
+
+// NewEpisodeDao1h returns DAO for hourly episodes rolled up from 5m episodes
+func NewEpisodeDao1h(dbCtx *dbcontext.DbContext) *EpisodeRollupDao {
+	return &EpisodeRollupDao{
+		DbCtx:  dbCtx,
+		Source: Resolution5m,
+		Target: Resolution1h,
+	}
+}
+
+// NewEpisodeDao1d returns DAO for daily episodes rolled up from hourly episodes
+func NewEpisodeDao1d(dbCtx *dbcontext.DbContext) *EpisodeRollupDao {
+	return &EpisodeRollupDao{
+		DbCtx:  dbCtx,
+		Source: Resolution1h,
+		Target: Resolution1d,
+	}
+}
+
+// Rollup sums up source episodes into target slots that intersect with [from, to). Target episodes
+// are replaced, so the rollup can be repeated when source episodes are updated. Missing source
+// episodes are not counted as unmeasured, the same as in the source table.
+func (d *EpisodeRollupDao) Rollup(from, to time.Time) error {
+	slot := int64(d.Target.Slot.Seconds())
+	query := fmt.Sprintf(`
+	INSERT OR REPLACE INTO %[1]s
+		(timeslot, nano_up, nano_down, nano_unknown, nano_unmeasured, group_name, probe_name)
+	SELECT
+		timeslot - timeslot %% %[3]d,
+		sum(nano_up),
+		sum(nano_down),
+		sum(nano_unknown),
+		sum(nano_unmeasured),
+		group_name,
+		probe_name
+	FROM
+		%[2]s
+	WHERE
+		timeslot >= ? AND timeslot < ?
+	GROUP BY
+		timeslot - timeslot %% %[3]d, group_name, probe_name
+	`, d.Target.Table, d.Source.Table, slot)
+
+	start := from.Truncate(d.Target.Slot)
+	end := to.Truncate(d.Target.Slot)
+	if end.Before(to) {
+		end = end.Add(d.Target.Slot)
+	}
+
+	_, err := d.DbCtx.StmtRunner().Exec(query, start.Unix(), end.Unix())
+	if err != nil {
+		return fmt.Errorf("cannot roll up %s into %s: %v", d.Source.Table, d.Target.Table, err)
+	}
+	return nil
+}
+
+// ListEpisodeSumsForRanges returns sums of seconds for each group_name+probe_name
+func (d *EpisodeRollupDao) ListEpisodeSumsForRanges(rng ranges.StepRange, ref check.ProbeRef) ([]check.Episode, error) {
+	return listEpisodeSumsForRanges(d.DbCtx, d.Target.Table, rng, ref)
+}
+
+// DeleteUpTo deletes episodes older than the slot
+func (d *EpisodeRollupDao) DeleteUpTo(slot time.Time) error {
+	return deleteEpisodesUpTo(d.DbCtx, d.Target.Table, slot)
+}
+
+// ListEpisodeSumsForRanges returns sums of seconds for each group_name+probe_name from the table of
+// the resolution suitable for the subranges.
+func ListEpisodeSumsForRanges(dbCtx *dbcontext.DbContext, rng ranges.StepRange, ref check.ProbeRef) ([]check.Episode, error) {
+	var queryErr error
+	res := ChooseResolution(rng.Subranges, func(res Resolution) (int64, bool) {
+		ts, ok, err := oldestTimeslot(dbCtx, res.Table)
+		if err != nil && queryErr == nil {
+			queryErr = err
+		}
+		return ts, ok
+	})
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	return listEpisodeSumsForRanges(dbCtx, res.Table, rng, ref)
+}
+
+// oldestTimeslot returns the oldest timeslot in the table, or false if the table is empty
+func oldestTimeslot(dbCtx *dbcontext.DbContext, table string) (int64, bool, error) {
+	rows, err := dbCtx.StmtRunner().Query(`SELECT min(timeslot) FROM ` + table)
+	if err != nil {
+		return 0, false, fmt.Errorf("cannot get the oldest timeslot of %s: %v", table, err)
+	}
+	defer rows.Close()
+
+	var ts sql.NullInt64
+	if rows.Next() {
+		if err := rows.Scan(&ts); err != nil {
+			return 0, false, fmt.Errorf("cannot get the oldest timeslot of %s: %v", table, err)
+		}
+	}
+	return ts.Int64, ts.Valid, rows.Err()
+}
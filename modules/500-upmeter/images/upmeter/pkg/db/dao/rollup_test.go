@@ -0,0 +1,132 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dao
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"d8.io/upmeter/pkg/check"
+	"d8.io/upmeter/pkg/server/ranges"
+)
+
+func Test_CoarsestResolution(t *testing.T) {
+	g := NewWithT(t)
+
+	day := int64(24 * 3600)
+
+	g.Expect(CoarsestResolution(ranges.NewStepRange(0, 3*day, day).Subranges)).To(Equal(Resolution1d))
+	g.Expect(CoarsestResolution(ranges.NewStepRange(0, day, 3600).Subranges)).To(Equal(Resolution1h))
+	g.Expect(CoarsestResolution(ranges.NewStepRange(0, 3600, 300).Subranges)).To(Equal(Resolution5m))
+	// a day-long step not aligned to days
+	g.Expect(CoarsestResolution([]ranges.Range{{From: 3600, To: 3600 + day}})).To(Equal(Resolution1h))
+}
+
+func Test_ChooseResolution(t *testing.T) {
+	g := NewWithT(t)
+
+	day := int64(24 * 3600)
+	now := 100 * day
+
+	oldestOf := func(oldest map[Resolution]int64) func(Resolution) (int64, bool) {
+		return func(res Resolution) (int64, bool) {
+			ts, ok := oldest[res]
+			return ts, ok
+		}
+	}
+	kept := oldestOf(map[Resolution]int64{
+		Resolution5m: now - 30*day,
+		Resolution1h: now - 60*day,
+		Resolution1d: 0,
+	})
+
+	// recent 5m-aligned ranges are read from the 5m table
+	g.Expect(ChooseResolution(ranges.NewStepRange(now-day, now-day+3600, 300).Subranges, kept)).To(Equal(Resolution5m))
+	// old 5m-aligned ranges are read from the table keeping them
+	g.Expect(ChooseResolution(ranges.NewStepRange(now-40*day, now-40*day+3600, 300).Subranges, kept)).To(Equal(Resolution1h))
+	g.Expect(ChooseResolution(ranges.NewStepRange(now-90*day, now-90*day+3600, 300).Subranges, kept)).To(Equal(Resolution1d))
+	// aligned ranges are read from the coarsest table
+	g.Expect(ChooseResolution(ranges.NewStepRange(now-10*day, now, day).Subranges, kept)).To(Equal(Resolution1d))
+	// the coarsest aligned table is used while there are no episodes
+	g.Expect(ChooseResolution(ranges.NewStepRange(0, 3600, 300).Subranges, oldestOf(nil))).To(Equal(Resolution5m))
+	g.Expect(ChooseResolution(ranges.NewStepRange(0, day, 3600).Subranges, oldestOf(nil))).To(Equal(Resolution1h))
+}
+
+func Test_EpisodeRollupDao_Rollup(t *testing.T) {
+	g := NewWithT(t)
+
+	dbCtx := getTestDatabase(t)
+	daoCtx := dbCtx.Start()
+	defer daoCtx.Stop()
+
+	dao5m := NewEpisodeDao5m(daoCtx)
+	dao1h := NewEpisodeDao1h(daoCtx)
+	dao1d := NewEpisodeDao1d(daoCtx)
+
+	ref := check.ProbeRef{Group: "nginx", Probe: "main"}
+
+	// Two hours of 5m episodes without the last slot in every hour
+	for i := 0; i < 24; i++ {
+		if i%12 == 11 {
+			continue
+		}
+		err := dao5m.Insert(check.Episode{
+			ProbeRef: ref,
+			TimeSlot: time.Unix(int64(i)*300, 0),
+			Up:       4 * time.Minute,
+			Down:     30 * time.Second,
+			NoData:   30 * time.Second,
+		})
+		g.Expect(err).ShouldNot(HaveOccurred())
+	}
+
+	err := dao1h.Rollup(time.Unix(0, 0), time.Unix(2*3600, 0))
+	g.Expect(err).ShouldNot(HaveOccurred())
+	// repeated rollup replaces episodes
+	err = dao1h.Rollup(time.Unix(0, 0), time.Unix(2*3600, 0))
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	hourly, err := dao1h.ListEpisodeSumsForRanges(ranges.NewStepRange(0, 7200, 3600), ref)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(hourly).To(HaveLen(2))
+	for _, ep := range hourly {
+		g.Expect(ep.Up).To(Equal(44 * time.Minute))
+		g.Expect(ep.Down).To(Equal(330 * time.Second))
+		// missing 5m episodes are not counted as unmeasured, the same as in the 5m table
+		g.Expect(ep.NoData).To(Equal(330 * time.Second))
+	}
+
+	err = dao1d.Rollup(time.Unix(0, 0), time.Unix(2*3600, 0))
+	g.Expect(err).ShouldNot(HaveOccurred())
+
+	daily, err := dao1d.ListEpisodeSumsForRanges(ranges.NewStepRange(0, 24*3600, 24*3600), ref)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(daily).To(HaveLen(1))
+	g.Expect(daily[0].Up).To(Equal(88 * time.Minute))
+	g.Expect(daily[0].Down).To(Equal(11 * time.Minute))
+	g.Expect(daily[0].NoData).To(Equal(11 * time.Minute))
+
+	// Retention
+	err = dao1h.DeleteUpTo(time.Unix(3600, 0))
+	g.Expect(err).ShouldNot(HaveOccurred())
+	hourly, err = dao1h.ListEpisodeSumsForRanges(ranges.NewStepRange(0, 7200, 3600), ref)
+	g.Expect(err).ShouldNot(HaveOccurred())
+	g.Expect(hourly).To(HaveLen(1))
+	g.Expect(hourly[0].TimeSlot.Unix()).To(Equal(int64(3600)))
+}
@@ -0,0 +1,87 @@
+/*
+
+ Create tables for hourly and daily episodes rolled up from 5m episodes. Long ranges are read from
+ them instead of scanning the whole 5m table. The tables are filled from existing 5m episodes.
+
+ Unmeasured time is summed up as well, missing 5m episodes are not counted as unmeasured, the same
+ as in the 5m table.
+
+ */
+BEGIN IMMEDIATE;
+
+CREATE TABLE IF NOT EXISTS episodes_1h
+(
+    timeslot        INTEGER NOT NULL,
+    nano_up         INTEGER NOT NULL,
+    nano_down       INTEGER NOT NULL,
+    nano_unknown    INTEGER NOT NULL DEFAULT 0,
+    nano_unmeasured INTEGER NOT NULL DEFAULT 0,
+    group_name      TEXT    NOT NULL,
+    probe_name      TEXT    NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS episodes_1d
+(
+    timeslot        INTEGER NOT NULL,
+    nano_up         INTEGER NOT NULL,
+    nano_down       INTEGER NOT NULL,
+    nano_unknown    INTEGER NOT NULL DEFAULT 0,
+    nano_unmeasured INTEGER NOT NULL DEFAULT 0,
+    group_name      TEXT    NOT NULL,
+    probe_name      TEXT    NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS episodes_1h_time_group_probe ON episodes_1h (timeslot, group_name, probe_name);
+CREATE UNIQUE INDEX IF NOT EXISTS episodes_1d_time_group_probe ON episodes_1d (timeslot, group_name, probe_name);
+
+INSERT
+        OR REPLACE INTO episodes_1h (
+                timeslot,
+                nano_up,
+                nano_down,
+                nano_unknown,
+                nano_unmeasured,
+                group_name,
+                probe_name
+        )
+SELECT
+        timeslot - timeslot % 3600,
+        SUM(nano_up),
+        SUM(nano_down),
+        SUM(nano_unknown),
+        SUM(nano_unmeasured),
+        group_name,
+        probe_name
+FROM
+        episodes_5m
+GROUP BY
+        timeslot - timeslot % 3600,
+        group_name,
+        probe_name;
+
+INSERT
+        OR REPLACE INTO episodes_1d (
+                timeslot,
+                nano_up,
+                nano_down,
+                nano_unknown,
+                nano_unmeasured,
+                group_name,
+                probe_name
+        )
+SELECT
+        timeslot - timeslot % 86400,
+        SUM(nano_up),
+        SUM(nano_down),
+        SUM(nano_unknown),
+        SUM(nano_unmeasured),
+        group_name,
+        probe_name
+FROM
+        episodes_1h
+GROUP BY
+        timeslot - timeslot % 86400,
+        group_name,
+        probe_name;
+
+COMMIT;
@@ -33,6 +33,7 @@ type Spec struct {
 	Type        string   `json:"type"`
 	Description string   `json:"description"`
 	Affected    []string `json:"affected"`
+	Public      bool     `json:"public,omitempty"`
 }
 
 // Downtime is the Schema for the downtime incidents
@@ -72,6 +73,8 @@ func (d Downtime) GetDowntimeIncidents() []check.DowntimeIncident {
 			Description:  obj.Description,
 			Affected:     obj.Affected,
 			DowntimeName: d.Name,
+			Created:      d.CreationTimestamp.Unix(),
+			Public:       obj.Public,
 		}
 		res = append(res, inc)
 	}
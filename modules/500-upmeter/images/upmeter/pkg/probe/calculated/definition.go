@@ -0,0 +1,169 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package calculated
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"d8.io/upmeter/pkg/check"
+	"d8.io/upmeter/pkg/db/dao"
+)
+
+// Merge modes of calculated probes
+const (
+	// MergeAllUp is Up only when all included probes are Up
+	MergeAllUp = "AllUp"
+	// MergeAnyUp is Up when at least one of included probes is Up
+	MergeAnyUp = "AnyUp"
+	// MergeKOfN is Up when at least MinUp of included probes are Up
+	MergeKOfN = "KOfN"
+	// MergeWeighted is Up when the weight of Up probes is at least Threshold of the total weight
+	MergeWeighted = "Weighted"
+)
+
+// Definition is the calculated probe from the module configuration. The JSON is rendered into the
+// command-line arguments.
+type Definition struct {
+	Group     string   `json:"group"`
+	Probe     string   `json:"probe"`
+	Mode      string   `json:"mode,omitempty"`
+	MinUp     int      `json:"minUp,omitempty"`
+	Threshold float64  `json:"threshold,omitempty"`
+	Probes    []Member `json:"probes"`
+}
+
+// Member is the probe included in the calculated probe
+type Member struct {
+	// Ref is the probe ID in the form of "group/probe"
+	Ref    string  `json:"ref"`
+	Weight float64 `json:"weight,omitempty"`
+}
+
+// ParseDefinitions parses JSON definitions of calculated probes and fills defaults. Invalid definitions
+// are skipped, so that a single invalid custom resource does not stop upmeter, they are returned in the error.
+func ParseDefinitions(raw []string) ([]Definition, error) {
+	defs := make([]Definition, 0, len(raw))
+	var errs []string
+	for _, s := range raw {
+		var d Definition
+		if err := json.Unmarshal([]byte(s), &d); err != nil {
+			errs = append(errs, fmt.Sprintf("cannot parse calculated probe %q: %v", s, err))
+			continue
+		}
+		if err := d.init(); err != nil {
+			errs = append(errs, fmt.Sprintf("calculated probe %s/%s: %v", d.Group, d.Probe, err))
+			continue
+		}
+		defs = append(defs, d)
+	}
+
+	if len(errs) > 0 {
+		return defs, fmt.Errorf("invalid calculated probes are skipped: %s", strings.Join(errs, "; "))
+	}
+	return defs, nil
+}
+
+func (d *Definition) init() error {
+	if d.Group == "" || d.Probe == "" {
+		return fmt.Errorf("group and probe are required")
+	}
+	if d.Probe == dao.GroupAggregation {
+		return fmt.Errorf("probe name %q is reserved", d.Probe)
+	}
+	if len(d.Probes) == 0 {
+		return fmt.Errorf("probes are required")
+	}
+
+	self := check.ProbeRef{Group: d.Group, Probe: d.Probe}.Id()
+	for i := range d.Probes {
+		m := &d.Probes[i]
+		parts := strings.Split(m.Ref, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("probe ref %q must be in the form of \"group/probe\"", m.Ref)
+		}
+		if m.Ref == self {
+			return fmt.Errorf("probe ref %q refers to the calculated probe itself", m.Ref)
+		}
+		if m.Weight < 0 {
+			return fmt.Errorf("weight of %q must not be negative", m.Ref)
+		}
+		if m.Weight == 0 {
+			m.Weight = 1
+		}
+	}
+
+	if d.Mode == "" {
+		d.Mode = MergeAllUp
+	}
+	switch d.Mode {
+	case MergeAllUp, MergeAnyUp:
+	case MergeKOfN:
+		if d.MinUp < 1 || d.MinUp > len(d.Probes) {
+			return fmt.Errorf("minUp must be in range [1, %d], got %d", len(d.Probes), d.MinUp)
+		}
+	case MergeWeighted:
+		if d.Threshold <= 0 || d.Threshold > 1 {
+			return fmt.Errorf("threshold must be in range (0, 1], got %v", d.Threshold)
+		}
+	default:
+		return fmt.Errorf("unsupported mode %q", d.Mode)
+	}
+
+	return nil
+}
+
+func (d Definition) config() config {
+	ids := make([]string, len(d.Probes))
+	weights := make([]float64, len(d.Probes))
+	var total float64
+	for i, m := range d.Probes {
+		ids[i] = m.Ref
+		weights[i] = m.Weight
+		total += m.Weight
+	}
+
+	c := config{
+		group:    d.Group,
+		probe:    d.Probe,
+		mode:     d.Mode,
+		mergeIds: ids,
+		weights:  weights,
+	}
+
+	switch d.Mode {
+	case MergeAnyUp:
+		c.weights = ones(len(ids))
+		c.quorum = 1
+	case MergeKOfN:
+		c.weights = ones(len(ids))
+		c.quorum = float64(d.MinUp)
+	case MergeWeighted:
+		c.quorum = d.Threshold * total
+	}
+
+	return c
+}
+
+func ones(n int) []float64 {
+	xs := make([]float64, n)
+	for i := range xs {
+		xs[i] = 1
+	}
+	return xs
+}
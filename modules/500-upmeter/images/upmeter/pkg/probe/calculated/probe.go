@@ -17,6 +17,8 @@ limitations under the License.
 package calculated
 
 import (
+	"fmt"
+
 	log "github.com/sirupsen/logrus"
 
 	"d8.io/upmeter/pkg/check"
@@ -24,16 +26,18 @@ import (
 	"d8.io/upmeter/pkg/set"
 )
 
-func NewLoader(filter probe.Filter, logger *log.Logger) *Loader {
+func NewLoader(filter probe.Filter, dynamic []Definition, logger *log.Logger) *Loader {
 	return &Loader{
-		filter: filter,
-		logger: logger,
+		filter:  filter,
+		dynamic: dynamic,
+		logger:  logger,
 	}
 }
 
 type Loader struct {
-	filter probe.Filter
-	logger *log.Logger
+	filter  probe.Filter
+	dynamic []Definition
+	logger  *log.Logger
 
 	groups []string
 	probes []check.ProbeRef
@@ -50,6 +54,7 @@ func (l *Loader) collectConfigs() []config {
 		{
 			group: "monitoring-and-autoscaling",
 			probe: "horizontal-pod-autoscaler",
+			mode:  MergeAllUp,
 			mergeIds: []string{
 				"monitoring-and-autoscaling/prometheus-metrics-adapter",
 				"control-plane/controller-manager",
@@ -57,12 +62,22 @@ func (l *Loader) collectConfigs() []config {
 		},
 	}
 
+	for _, d := range l.dynamic {
+		configs = append(configs, d.config())
+	}
+
 	l.configs = make([]config, 0)
+	seen := set.New()
 	for _, c := range configs {
 		ref := check.ProbeRef{Group: c.group, Probe: c.probe}
 		if !l.filter.Enabled(ref) {
 			continue
 		}
+		if seen.Has(ref.Id()) {
+			l.logger.Warnf("Skipping calculated probe %s: already defined", ref.Id())
+			continue
+		}
+		seen.Add(ref.Id())
 		l.configs = append(l.configs, c)
 	}
 	return l.configs
@@ -109,7 +124,10 @@ func (l *Loader) Probes() []check.ProbeRef {
 type config struct {
 	group    string
 	probe    string
+	mode     string
 	mergeIds []string
+	weights  []float64
+	quorum   float64
 }
 
 func (c config) Probe() *Probe {
@@ -117,13 +135,22 @@ func (c config) Probe() *Probe {
 		Group: c.group,
 		Probe: c.probe,
 	}
-	return &Probe{ref, c.mergeIds}
+	return &Probe{
+		ref:      ref,
+		mode:     c.mode,
+		mergeIds: c.mergeIds,
+		weights:  c.weights,
+		quorum:   c.quorum,
+	}
 }
 
 // Probe combines check.Episode for included probe IDs.
 type Probe struct {
 	ref      *check.ProbeRef
+	mode     string
 	mergeIds []string
+	weights  []float64
+	quorum   float64
 }
 
 func (p *Probe) ProbeRef() check.ProbeRef {
@@ -135,3 +162,27 @@ func (p *Probe) MergeIds() []string {
 	copy(ids, p.mergeIds)
 	return ids
 }
+
+// Calculate merges the status series of included probes according to the merge mode. Series
+// missing in the map are considered as having no data.
+func (p *Probe) Calculate(size int, series map[string]*check.StatusSeries) (*check.StatusSeries, error) {
+	if p.mode == MergeAllUp {
+		sss := make([]*check.StatusSeries, 0, len(p.mergeIds))
+		for _, id := range p.mergeIds {
+			if ss, ok := series[id]; ok {
+				sss = append(sss, ss)
+			}
+		}
+		return check.MergeStatusSeries(size, sss)
+	}
+
+	sss := make([]*check.StatusSeries, len(p.mergeIds))
+	for i, id := range p.mergeIds {
+		sss[i] = series[id]
+	}
+	ss, err := check.QuorumStatusSeries(size, sss, p.weights, p.quorum)
+	if err != nil {
+		return nil, fmt.Errorf("cannot merge status series: %v", err)
+	}
+	return ss, nil
+}
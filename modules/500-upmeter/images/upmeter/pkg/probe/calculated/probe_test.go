@@ -0,0 +1,160 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package calculated
+
+import (
+	"io/ioutil"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"d8.io/upmeter/pkg/check"
+	"d8.io/upmeter/pkg/probe"
+)
+
+func Test_ParseDefinitions(t *testing.T) {
+	defs, err := ParseDefinitions([]string{
+		`{"group":"checkout","probe":"api","probes":[{"ref":"custom/api"},{"ref":"custom/db"}]}`,
+		`{"group":"checkout","probe":"frontends","mode":"Weighted","threshold":0.5,
+		  "probes":[{"ref":"custom/a","weight":3},{"ref":"custom/b"}]}`,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, defs, 2)
+
+	assert.Equal(t, MergeAllUp, defs[0].Mode)
+	assert.Equal(t, 1.0, defs[1].Probes[1].Weight)
+
+	c := defs[1].config()
+	assert.Equal(t, []string{"custom/a", "custom/b"}, c.mergeIds)
+	assert.Equal(t, []float64{3, 1}, c.weights)
+	assert.Equal(t, 2.0, c.quorum)
+}
+
+func Test_ParseDefinitions_Invalid(t *testing.T) {
+	invalid := map[string]string{
+		"no probes":        `{"group":"g","probe":"p"}`,
+		"bad ref":          `{"group":"g","probe":"p","probes":[{"ref":"nogroup"}]}`,
+		"self ref":         `{"group":"g","probe":"p","probes":[{"ref":"g/p"}]}`,
+		"reserved name":    `{"group":"g","probe":"__total__","probes":[{"ref":"g/x"}]}`,
+		"unknown mode":     `{"group":"g","probe":"p","mode":"Most","probes":[{"ref":"g/x"}]}`,
+		"k exceeds n":      `{"group":"g","probe":"p","mode":"KOfN","minUp":2,"probes":[{"ref":"g/x"}]}`,
+		"no threshold":     `{"group":"g","probe":"p","mode":"Weighted","probes":[{"ref":"g/x"}]}`,
+		"negative weight":  `{"group":"g","probe":"p","probes":[{"ref":"g/x","weight":-1}]}`,
+		"malformed json":   `{"group":`,
+		"no calculated id": `{"probes":[{"ref":"g/x"}]}`,
+	}
+	for name, raw := range invalid {
+		t.Run(name, func(t *testing.T) {
+			defs, err := ParseDefinitions([]string{raw, `{"group":"g","probe":"valid","probes":[{"ref":"g/x"}]}`})
+			assert.Error(t, err)
+			assert.Len(t, defs, 1)
+			assert.Equal(t, "valid", defs[0].Probe)
+		})
+	}
+}
+
+func Test_Probe_Calculate(t *testing.T) {
+	newSeries := func(statuses ...check.Status) *check.StatusSeries {
+		ss := check.NewStatusSeries(len(statuses))
+		for _, s := range statuses {
+			_ = ss.Add(s)
+		}
+		return ss
+	}
+
+	series := map[string]*check.StatusSeries{
+		"g/a": newSeries(check.Up, check.Up, check.Down),
+		"g/b": newSeries(check.Up, check.Down, check.Down),
+		"g/c": newSeries(check.Down, check.Up, check.Up),
+	}
+	refs := []Member{{Ref: "g/a"}, {Ref: "g/b"}, {Ref: "g/c"}}
+
+	tests := []struct {
+		name string
+		def  Definition
+		want *check.StatusSeries
+	}{
+		{
+			name: "all up",
+			def:  Definition{Mode: MergeAllUp},
+			want: newSeries(check.Down, check.Down, check.Down),
+		},
+		{
+			name: "any up",
+			def:  Definition{Mode: MergeAnyUp},
+			want: newSeries(check.Up, check.Up, check.Up),
+		},
+		{
+			name: "2 of 3",
+			def:  Definition{Mode: MergeKOfN, MinUp: 2},
+			want: newSeries(check.Up, check.Up, check.Down),
+		},
+		{
+			name: "weighted",
+			def: Definition{Mode: MergeWeighted, Threshold: 0.6, Probes: []Member{
+				{Ref: "g/a", Weight: 1}, {Ref: "g/b", Weight: 1}, {Ref: "g/c", Weight: 2},
+			}},
+			want: newSeries(check.Down, check.Up, check.Down),
+		},
+		{
+			name: "missing probe counts as no data",
+			def:  Definition{Mode: MergeKOfN, MinUp: 2, Probes: []Member{{Ref: "g/a"}, {Ref: "g/missing"}}},
+			want: newSeries(check.Down, check.Down, check.Down),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def := tt.def
+			def.Group, def.Probe = "calc", "p"
+			if def.Probes == nil {
+				def.Probes = refs
+			}
+			assert.NoError(t, def.init())
+
+			got, err := def.config().Probe().Calculate(3, series)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want.Stats(), got.Stats())
+		})
+	}
+}
+
+func Test_Loader_Dynamic(t *testing.T) {
+	logger := log.New()
+	logger.SetOutput(ioutil.Discard)
+
+	defs, err := ParseDefinitions([]string{
+		`{"group":"checkout","probe":"service","mode":"AnyUp","probes":[{"ref":"custom/a"},{"ref":"custom/b"}]}`,
+		`{"group":"monitoring-and-autoscaling","probe":"horizontal-pod-autoscaler","probes":[{"ref":"custom/a"}]}`,
+	})
+	assert.NoError(t, err)
+
+	loader := NewLoader(probe.NewProbeFilter(nil), defs, logger)
+
+	assert.ElementsMatch(t, []string{"checkout", "monitoring-and-autoscaling"}, loader.Groups())
+	assert.Equal(t, []check.ProbeRef{
+		{Group: "monitoring-and-autoscaling", Probe: "horizontal-pod-autoscaler"},
+		{Group: "checkout", Probe: "service"},
+	}, loader.Probes())
+
+	// The built-in probe is not overridden
+	hpa := loader.Load()[0]
+	assert.Equal(t, []string{
+		"monitoring-and-autoscaling/prometheus-metrics-adapter",
+		"control-plane/controller-manager",
+	}, hpa.MergeIds())
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+
+	"d8.io/upmeter/pkg/check"
+)
+
+// HTTPEndpointAvailable is a checker constructor and configurator. The checker requests the URL
+// and expects one of the listed response status codes.
+type HTTPEndpointAvailable struct {
+	URL                 string
+	ExpectedStatusCodes []int
+	InsecureSkipVerify  bool
+	UserAgent           string
+	Timeout             time.Duration
+}
+
+func (c HTTPEndpointAvailable) Checker() check.Checker {
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify},
+		},
+		Timeout: c.Timeout,
+	}
+
+	expected := make(map[int]struct{}, len(c.ExpectedStatusCodes))
+	for _, code := range c.ExpectedStatusCodes {
+		expected[code] = struct{}{}
+	}
+
+	return &httpEndpointChecker{
+		client:    client,
+		url:       c.URL,
+		userAgent: c.UserAgent,
+		expected:  expected,
+	}
+}
+
+type httpEndpointChecker struct {
+	client    *http.Client
+	url       string
+	userAgent string
+	expected  map[int]struct{}
+}
+
+func (c *httpEndpointChecker) Check() check.Error {
+	req, err := http.NewRequest(http.MethodGet, c.url, nil)
+	if err != nil {
+		return check.ErrUnknown("cannot create request: %v", err)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return check.ErrFail("cannot request %q: %v", c.url, err)
+	}
+	// Drain the body to reuse the connection
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+
+	if _, ok := c.expected[resp.StatusCode]; !ok {
+		return check.ErrFail("HTTP: GET %s returned unexpected status %d", c.url, resp.StatusCode)
+	}
+	return nil
+}
+
+// TCPEndpointAvailable is a checker constructor and configurator. The checker establishes TCP
+// connection to the address.
+type TCPEndpointAvailable struct {
+	Address string
+	Timeout time.Duration
+}
+
+func (c TCPEndpointAvailable) Checker() check.Checker {
+	return &tcpEndpointChecker{address: c.Address, timeout: c.Timeout}
+}
+
+type tcpEndpointChecker struct {
+	address string
+	timeout time.Duration
+}
+
+func (c *tcpEndpointChecker) Check() check.Error {
+	conn, err := net.DialTimeout("tcp", c.address, c.timeout)
+	if err != nil {
+		return check.ErrFail("cannot connect to %s: %v", c.address, err)
+	}
+	_ = conn.Close()
+	return nil
+}
+
+// DNSResolvable is a checker constructor and configurator. The checker expects the name to be
+// resolved to at least one address. If the server is empty, the system resolver is used.
+type DNSResolvable struct {
+	Name    string
+	Server  string
+	Timeout time.Duration
+}
+
+func (c DNSResolvable) Checker() check.Checker {
+	resolver := net.DefaultResolver
+	if c.Server != "" {
+		server := c.Server
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, server)
+			},
+		}
+	}
+
+	return &dnsResolveChecker{resolver: resolver, name: c.Name, timeout: c.Timeout}
+}
+
+type dnsResolveChecker struct {
+	resolver *net.Resolver
+	name     string
+	timeout  time.Duration
+}
+
+func (c *dnsResolveChecker) Check() check.Error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	addrs, err := c.resolver.LookupHost(ctx, c.name)
+	if err != nil {
+		return check.ErrFail("cannot resolve %q: %v", c.name, err)
+	}
+	if len(addrs) == 0 {
+		return check.ErrFail("%q resolved to no addresses", c.name)
+	}
+	return nil
+}
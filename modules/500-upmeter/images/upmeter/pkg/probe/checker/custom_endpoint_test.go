@@ -0,0 +1,87 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"d8.io/upmeter/pkg/check"
+)
+
+func Test_HTTPEndpointAvailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		name     string
+		path     string
+		expected []int
+		status   check.Status
+	}{
+		{name: "expected status", path: "/", expected: []int{200}, status: check.Up},
+		{name: "unexpected status", path: "/missing", expected: []int{200}, status: check.Down},
+		{name: "expected 404", path: "/missing", expected: []int{200, 404}, status: check.Up},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			checker := HTTPEndpointAvailable{
+				URL:                 server.URL + tc.path,
+				ExpectedStatusCodes: tc.expected,
+				Timeout:             time.Second,
+			}.Checker()
+
+			status := check.Up
+			if err := checker.Check(); err != nil {
+				status = err.Status()
+			}
+			if status != tc.status {
+				t.Errorf("expected status %s, got %s", tc.status, status)
+			}
+		})
+	}
+}
+
+func Test_TCPEndpointAvailable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+
+	checker := TCPEndpointAvailable{Address: addr, Timeout: time.Second}.Checker()
+	if err := checker.Check(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	listener.Close()
+
+	checkErr := checker.Check()
+	if checkErr == nil || checkErr.Status() != check.Down {
+		t.Errorf("expected fail after the listener is closed, got %v", checkErr)
+	}
+}
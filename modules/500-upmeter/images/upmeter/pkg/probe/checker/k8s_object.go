@@ -0,0 +1,86 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"d8.io/upmeter/pkg/check"
+	"d8.io/upmeter/pkg/kubernetes"
+)
+
+// KubeObjectExists is a checker constructor and configurator. The checker expects the object to
+// be present in the cluster. Empty namespace stands for cluster-scoped objects.
+type KubeObjectExists struct {
+	Access           kubernetes.Access
+	PreflightChecker check.Checker
+
+	GVR       schema.GroupVersionResource
+	Namespace string
+	Name      string
+
+	Timeout time.Duration
+}
+
+func (c KubeObjectExists) Checker() check.Checker {
+	getter := &kubeObjectGetter{
+		access:    c.Access,
+		gvr:       c.GVR,
+		namespace: c.Namespace,
+		name:      c.Name,
+	}
+
+	return sequence(
+		c.PreflightChecker,
+		withTimeout(getter, c.Timeout),
+	)
+}
+
+type kubeObjectGetter struct {
+	access    kubernetes.Access
+	gvr       schema.GroupVersionResource
+	namespace string
+	name      string
+}
+
+func (g *kubeObjectGetter) Check() check.Error {
+	_, err := g.access.Kubernetes().Dynamic().
+		Resource(g.gvr).
+		Namespace(g.namespace).
+		Get(context.TODO(), g.name, metav1.GetOptions{})
+
+	if apierrors.IsNotFound(err) {
+		return check.ErrFail("%s not found", g.ref())
+	}
+	if err != nil {
+		return check.ErrUnknown("cannot get %s: %v", g.ref(), err)
+	}
+	return nil
+}
+
+func (g *kubeObjectGetter) ref() string {
+	if g.namespace == "" {
+		return fmt.Sprintf("%s %q", g.gvr.Resource, g.name)
+	}
+	return fmt.Sprintf("%s %q in namespace %q", g.gvr.Resource, g.name, g.namespace)
+}
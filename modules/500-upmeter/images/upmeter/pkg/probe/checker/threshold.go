@@ -0,0 +1,81 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"d8.io/upmeter/pkg/check"
+)
+
+// ThresholdChecker wraps a checker to tolerate short failures and flapping. The probe is
+// considered down after FailureThreshold consecutive failures, and up again after
+// SuccessThreshold consecutive successes. Unknown results do not affect counters.
+type ThresholdChecker struct {
+	Config           Config
+	FailureThreshold int
+	SuccessThreshold int
+}
+
+func (c ThresholdChecker) Checker() check.Checker {
+	return &thresholdChecker{
+		checker:          c.Config.Checker(),
+		failureThreshold: c.FailureThreshold,
+		successThreshold: c.SuccessThreshold,
+	}
+}
+
+// thresholdChecker is stateful, thus should not be reused.
+type thresholdChecker struct {
+	checker          check.Checker
+	failureThreshold int
+	successThreshold int
+
+	failures  int
+	successes int
+	down      bool
+	lastErr   check.Error
+}
+
+func (c *thresholdChecker) Check() check.Error {
+	err := c.checker.Check()
+	if err != nil && err.Status() != check.Down {
+		return err
+	}
+
+	if err == nil {
+		c.successes++
+		c.failures = 0
+	} else {
+		c.failures++
+		c.successes = 0
+		c.lastErr = err
+	}
+
+	if c.down && c.successes >= c.successThreshold {
+		c.down = false
+	}
+	if !c.down && c.failures >= c.failureThreshold {
+		c.down = true
+	}
+
+	if !c.down {
+		return nil
+	}
+	if err == nil {
+		return check.ErrFail("recovering after %v: %d/%d successful checks", c.lastErr, c.successes, c.successThreshold)
+	}
+	return err
+}
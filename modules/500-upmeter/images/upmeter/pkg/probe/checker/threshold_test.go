@@ -0,0 +1,108 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checker
+
+import (
+	"testing"
+
+	"d8.io/upmeter/pkg/check"
+)
+
+// sequentialChecker returns predefined errors one by one
+type sequentialChecker struct {
+	errs []check.Error
+	i    int
+}
+
+func (c *sequentialChecker) Check() check.Error {
+	err := c.errs[c.i]
+	c.i++
+	return err
+}
+
+type sequentialConfig struct {
+	checker *sequentialChecker
+}
+
+func (c sequentialConfig) Checker() check.Checker {
+	return c.checker
+}
+
+func Test_thresholdChecker(t *testing.T) {
+	var (
+		up      check.Error
+		down    = check.ErrFail("down")
+		unknown = check.ErrUnknown("unknown")
+	)
+
+	cases := []struct {
+		name     string
+		failure  int
+		success  int
+		results  []check.Error
+		expected []check.Status
+	}{
+		{
+			name:     "thresholds of 1 do not change results",
+			failure:  1,
+			success:  1,
+			results:  []check.Error{up, down, up, unknown, down},
+			expected: []check.Status{check.Up, check.Down, check.Up, check.Unknown, check.Down},
+		},
+		{
+			name:     "failures below threshold are tolerated",
+			failure:  3,
+			success:  1,
+			results:  []check.Error{down, down, up, down, down, down, down},
+			expected: []check.Status{check.Up, check.Up, check.Up, check.Up, check.Up, check.Down, check.Down},
+		},
+		{
+			name:     "unknown results do not reset failures",
+			failure:  2,
+			success:  1,
+			results:  []check.Error{down, unknown, down},
+			expected: []check.Status{check.Up, check.Unknown, check.Down},
+		},
+		{
+			name:     "recovery requires successes in a row",
+			failure:  1,
+			success:  2,
+			results:  []check.Error{down, up, down, up, up, up},
+			expected: []check.Status{check.Down, check.Down, check.Down, check.Down, check.Up, check.Up},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			checker := ThresholdChecker{
+				Config:           sequentialConfig{&sequentialChecker{errs: tc.results}},
+				FailureThreshold: tc.failure,
+				SuccessThreshold: tc.success,
+			}.Checker()
+
+			for i, expected := range tc.expected {
+				status := check.Up
+				if err := checker.Check(); err != nil {
+					status = err.Status()
+				}
+				if status != expected {
+					t.Errorf("check #%d: expected status %s, got %s", i+1, expected, status)
+				}
+			}
+		})
+	}
+}
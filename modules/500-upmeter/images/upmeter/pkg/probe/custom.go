@@ -0,0 +1,182 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	CustomProbeHTTP             = "HTTP"
+	CustomProbeTCP              = "TCP"
+	CustomProbeDNS              = "DNS"
+	CustomProbeKubernetesObject = "KubernetesObject"
+)
+
+const (
+	defaultCustomProbePeriod  = 30 * time.Second
+	defaultCustomProbeTimeout = 5 * time.Second
+)
+
+// CustomProbe is the user-defined probe from UpmeterCustomProbe custom resource. The JSON is
+// rendered by the module hook into the command-line arguments.
+type CustomProbe struct {
+	Name             string `json:"name"`
+	Type             string `json:"type"`
+	Period           string `json:"period,omitempty"`
+	Timeout          string `json:"timeout,omitempty"`
+	FailureThreshold int    `json:"failureThreshold,omitempty"`
+	SuccessThreshold int    `json:"successThreshold,omitempty"`
+
+	HTTP             *CustomProbeHTTPSpec             `json:"http,omitempty"`
+	TCP              *CustomProbeTCPSpec              `json:"tcp,omitempty"`
+	DNS              *CustomProbeDNSSpec              `json:"dns,omitempty"`
+	KubernetesObject *CustomProbeKubernetesObjectSpec `json:"kubernetesObject,omitempty"`
+
+	period  time.Duration
+	timeout time.Duration
+}
+
+type CustomProbeHTTPSpec struct {
+	URL                 string `json:"url"`
+	ExpectedStatusCodes []int  `json:"expectedStatusCodes,omitempty"`
+	InsecureSkipVerify  bool   `json:"insecureSkipVerify,omitempty"`
+}
+
+type CustomProbeTCPSpec struct {
+	Address string `json:"address"`
+}
+
+type CustomProbeDNSSpec struct {
+	Name   string `json:"name"`
+	Server string `json:"server,omitempty"`
+}
+
+type CustomProbeKubernetesObjectSpec struct {
+	APIVersion string `json:"apiVersion"`
+	Resource   string `json:"resource"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+// ParseCustomProbes parses JSON definitions of custom probes and fills defaults. Invalid probes are
+// skipped, so that a single invalid custom resource does not stop upmeter, they are returned in the error.
+func ParseCustomProbes(raw []string) ([]CustomProbe, error) {
+	probes := make([]CustomProbe, 0, len(raw))
+	var errs []string
+	for _, s := range raw {
+		var p CustomProbe
+		if err := json.Unmarshal([]byte(s), &p); err != nil {
+			errs = append(errs, fmt.Sprintf("cannot parse custom probe %q: %v", s, err))
+			continue
+		}
+		if err := p.init(); err != nil {
+			errs = append(errs, fmt.Sprintf("custom probe %q: %v", p.Name, err))
+			continue
+		}
+		probes = append(probes, p)
+	}
+
+	if len(errs) > 0 {
+		return probes, fmt.Errorf("invalid custom probes are skipped: %s", strings.Join(errs, "; "))
+	}
+	return probes, nil
+}
+
+func (p *CustomProbe) init() error {
+	if p.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	var err error
+
+	p.period = defaultCustomProbePeriod
+	if p.Period != "" {
+		p.period, err = time.ParseDuration(p.Period)
+		if err != nil {
+			return fmt.Errorf("cannot parse period: %v", err)
+		}
+	}
+
+	p.timeout = defaultCustomProbeTimeout
+	if p.Timeout != "" {
+		p.timeout, err = time.ParseDuration(p.Timeout)
+		if err != nil {
+			return fmt.Errorf("cannot parse timeout: %v", err)
+		}
+	}
+
+	if p.period <= 0 || p.timeout <= 0 {
+		return fmt.Errorf("period and timeout must be positive")
+	}
+	if p.timeout > p.period {
+		return fmt.Errorf("timeout %s exceeds period %s", p.timeout, p.period)
+	}
+
+	if p.FailureThreshold < 1 {
+		p.FailureThreshold = 1
+	}
+	if p.SuccessThreshold < 1 {
+		p.SuccessThreshold = 1
+	}
+
+	return p.validateSpec()
+}
+
+func (p *CustomProbe) validateSpec() error {
+	switch p.Type {
+	case CustomProbeHTTP:
+		if p.HTTP == nil || p.HTTP.URL == "" {
+			return fmt.Errorf("http.url is required")
+		}
+		if len(p.HTTP.ExpectedStatusCodes) == 0 {
+			p.HTTP.ExpectedStatusCodes = []int{200}
+		}
+	case CustomProbeTCP:
+		if p.TCP == nil || p.TCP.Address == "" {
+			return fmt.Errorf("tcp.address is required")
+		}
+	case CustomProbeDNS:
+		if p.DNS == nil || p.DNS.Name == "" {
+			return fmt.Errorf("dns.name is required")
+		}
+	case CustomProbeKubernetesObject:
+		o := p.KubernetesObject
+		if o == nil || o.APIVersion == "" || o.Resource == "" || o.Name == "" {
+			return fmt.Errorf("kubernetesObject.apiVersion, kubernetesObject.resource and kubernetesObject.name are required")
+		}
+		if _, err := schema.ParseGroupVersion(o.APIVersion); err != nil {
+			return fmt.Errorf("cannot parse kubernetesObject.apiVersion: %v", err)
+		}
+	default:
+		return fmt.Errorf("unknown type %q", p.Type)
+	}
+	return nil
+}
+
+// CheckName is the name of the single check of the probe
+func (p *CustomProbe) CheckName() string {
+	if p.Type == CustomProbeKubernetesObject {
+		return "object"
+	}
+	return strings.ToLower(p.Type)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseCustomProbes(t *testing.T) {
+	probes, err := ParseCustomProbes([]string{
+		`{"name":"site","type":"HTTP","http":{"url":"https://example.com"}}`,
+		`{"name":"cm","type":"KubernetesObject","period":"1m","timeout":"10s","failureThreshold":3,
+		  "kubernetesObject":{"apiVersion":"v1","resource":"configmaps","namespace":"default","name":"cm"}}`,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, probes, 2)
+
+	site := probes[0]
+	assert.Equal(t, "http", site.CheckName())
+	assert.Equal(t, defaultCustomProbePeriod, site.period)
+	assert.Equal(t, defaultCustomProbeTimeout, site.timeout)
+	assert.Equal(t, 1, site.FailureThreshold)
+	assert.Equal(t, 1, site.SuccessThreshold)
+	assert.Equal(t, []int{200}, site.HTTP.ExpectedStatusCodes)
+
+	cm := probes[1]
+	assert.Equal(t, "object", cm.CheckName())
+	assert.Equal(t, time.Minute, cm.period)
+	assert.Equal(t, 10*time.Second, cm.timeout)
+	assert.Equal(t, 3, cm.FailureThreshold)
+}
+
+func Test_ParseCustomProbes_Invalid(t *testing.T) {
+	invalid := map[string]string{
+		"not a JSON":         `{"name":`,
+		"no name":            `{"type":"TCP","tcp":{"address":"db:5432"}}`,
+		"unknown type":       `{"name":"x","type":"ICMP"}`,
+		"no type section":    `{"name":"x","type":"DNS"}`,
+		"bad period":         `{"name":"x","type":"TCP","period":"often","tcp":{"address":"db:5432"}}`,
+		"timeout > period":   `{"name":"x","type":"TCP","period":"5s","timeout":"10s","tcp":{"address":"db:5432"}}`,
+		"bad API version":    `{"name":"x","type":"KubernetesObject","kubernetesObject":{"apiVersion":"a/b/c","resource":"r","name":"n"}}`,
+		"no object resource": `{"name":"x","type":"KubernetesObject","kubernetesObject":{"apiVersion":"v1","name":"n"}}`,
+	}
+
+	for name, raw := range invalid {
+		t.Run(name, func(t *testing.T) {
+			probes, err := ParseCustomProbes([]string{raw, `{"name":"db","type":"TCP","tcp":{"address":"db:5432"}}`})
+			assert.Error(t, err)
+			assert.Len(t, probes, 1)
+			assert.Equal(t, "db", probes[0].Name)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"d8.io/upmeter/pkg/check"
+	"d8.io/upmeter/pkg/kubernetes"
+	"d8.io/upmeter/pkg/probe/checker"
+)
+
+func initCustom(access kubernetes.Access, preflight checker.Doer, probes []CustomProbe) []runnerConfig {
+	const (
+		groupCustom         = "custom"
+		controlPlaneTimeout = 5 * time.Second
+	)
+
+	controlPlanePinger := checker.DoOrUnknown(controlPlaneTimeout, preflight)
+
+	configs := []runnerConfig{}
+
+	for _, p := range probes {
+		configs = append(configs, runnerConfig{
+			group:  groupCustom,
+			probe:  p.Name,
+			check:  p.CheckName(),
+			period: p.period,
+			config: checker.ThresholdChecker{
+				Config:           customProbeConfig(access, controlPlanePinger, p),
+				FailureThreshold: p.FailureThreshold,
+				SuccessThreshold: p.SuccessThreshold,
+			},
+		})
+	}
+	return configs
+}
+
+// customProbeConfig expects the probe to be validated by ParseCustomProbes
+func customProbeConfig(access kubernetes.Access, controlPlanePinger check.Checker, p CustomProbe) checker.Config {
+	switch p.Type {
+	case CustomProbeHTTP:
+		return checker.HTTPEndpointAvailable{
+			URL:                 p.HTTP.URL,
+			ExpectedStatusCodes: p.HTTP.ExpectedStatusCodes,
+			InsecureSkipVerify:  p.HTTP.InsecureSkipVerify,
+			UserAgent:           access.UserAgent(),
+			Timeout:             p.timeout,
+		}
+	case CustomProbeTCP:
+		return checker.TCPEndpointAvailable{
+			Address: p.TCP.Address,
+			Timeout: p.timeout,
+		}
+	case CustomProbeDNS:
+		return checker.DNSResolvable{
+			Name:    p.DNS.Name,
+			Server:  p.DNS.Server,
+			Timeout: p.timeout,
+		}
+	default: // CustomProbeKubernetesObject
+		gv, _ := schema.ParseGroupVersion(p.KubernetesObject.APIVersion)
+		return checker.KubeObjectExists{
+			Access:           access,
+			PreflightChecker: controlPlanePinger,
+			GVR:              gv.WithResource(p.KubernetesObject.Resource),
+			Namespace:        p.KubernetesObject.Namespace,
+			Name:             p.KubernetesObject.Name,
+			Timeout:          p.timeout,
+		}
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"d8.io/upmeter/pkg/monitor/downtime"
+	"d8.io/upmeter/pkg/server/entity"
+)
+
+// PublicFeedHandler returns maintenance windows and incidents as an Atom feed, so they can be
+// followed in feed readers.
+type PublicFeedHandler struct {
+	DowntimeMonitor *downtime.Monitor
+}
+
+func (h *PublicFeedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Infoln("PublicFeed", r.RemoteAddr, r.RequestURI)
+
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		fmt.Fprintf(w, "%d GET is required\n", http.StatusMethodNotAllowed)
+		return
+	}
+
+	now := time.Now()
+	events, err := listPublicEvents(h.DowntimeMonitor, now)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "%d Error: %s\n", http.StatusInternalServerError, err)
+		return
+	}
+
+	feed := newAtomFeed(requestBaseURL(r), events, now)
+	out, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "%d Error: %s\n", http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Write([]byte(xml.Header))
+	w.Write(out)
+}
+
+// requestBaseURL restores the URL of the status page behind the ingress
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	host := r.Host
+	if fwd := r.Header.Get("X-Forwarded-Host"); fwd != "" {
+		host = fwd
+	}
+	return scheme + "://" + host
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomAuthor  `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Summary    string         `xml:"summary"`
+	Links      []atomLink     `xml:"link"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+func newAtomFeed(baseURL string, events []entity.PublicEvent, now time.Time) *atomFeed {
+	// The feed is updated with the latest event, events are sorted by the update time.
+	updated := now
+	if len(events) > 0 {
+		updated = events[0].Updated
+	}
+
+	feed := &atomFeed{
+		ID:      baseURL + "/public/api/feed",
+		Title:   "Status",
+		Updated: updated.UTC().Format(time.RFC3339),
+		Author:  atomAuthor{Name: "Upmeter"},
+		Links: []atomLink{
+			{Href: baseURL + "/"},
+			{Href: baseURL + "/public/api/feed", Rel: "self"},
+		},
+		Entries: make([]atomEntry, 0, len(events)),
+	}
+
+	for _, ev := range events {
+		entry := atomEntry{
+			ID:      baseURL + "/public/api/feed#" + ev.ID,
+			Title:   eventTitle(ev),
+			Updated: ev.Updated.UTC().Format(time.RFC3339),
+			Categories: []atomCategory{
+				{Term: ev.Kind},
+				{Term: ev.State},
+			},
+			Summary: eventSummary(ev),
+			Links:   []atomLink{{Href: baseURL + "/"}},
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	return feed
+}
+
+// eventTitle makes titles like "Scheduled maintenance: control-plane, synthetic"
+func eventTitle(ev entity.PublicEvent) string {
+	title := ev.State + " " + strings.ToLower(ev.Kind)
+	if len(ev.Affected) > 0 {
+		title += ": " + strings.Join(ev.Affected, ", ")
+	}
+	return title
+}
+
+func eventSummary(ev entity.PublicEvent) string {
+	period := fmt.Sprintf("From %s to %s.", ev.Start.Format(time.RFC1123), ev.End.Format(time.RFC1123))
+	if ev.Description == "" {
+		return period
+	}
+	return ev.Description + "\n\n" + period
+}
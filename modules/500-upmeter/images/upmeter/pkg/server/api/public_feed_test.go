@@ -0,0 +1,75 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/xml"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"d8.io/upmeter/pkg/server/entity"
+)
+
+func Test_newAtomFeed(t *testing.T) {
+	now := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	events := []entity.PublicEvent{
+		{
+			ID:          "upgrade-1651410000",
+			Kind:        entity.EventMaintenance,
+			State:       entity.EventScheduled,
+			Description: "Kubernetes upgrade",
+			Affected:    []string{"control-plane", "synthetic"},
+			Start:       now.Add(time.Hour),
+			End:         now.Add(2 * time.Hour),
+			Updated:     now.Add(-time.Hour),
+		},
+	}
+
+	feed := newAtomFeed("https://status.example.com", events, now)
+
+	out, err := xml.Marshal(feed)
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), `<feed xmlns="http://www.w3.org/2005/Atom">`)
+
+	assert.Equal(t, "2022-05-01T11:00:00Z", feed.Updated)
+	assert.Len(t, feed.Entries, 1)
+
+	entry := feed.Entries[0]
+	assert.Equal(t, "https://status.example.com/public/api/feed#upgrade-1651410000", entry.ID)
+	assert.Equal(t, "Scheduled maintenance: control-plane, synthetic", entry.Title)
+	assert.Equal(t, "Kubernetes upgrade\n\nFrom Sun, 01 May 2022 13:00:00 UTC to Sun, 01 May 2022 14:00:00 UTC.", entry.Summary)
+}
+
+func Test_newAtomFeed_Empty(t *testing.T) {
+	now := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	feed := newAtomFeed("https://status.example.com", nil, now)
+
+	assert.Equal(t, "2022-05-01T12:00:00Z", feed.Updated)
+	assert.Empty(t, feed.Entries)
+}
+
+func Test_requestBaseURL(t *testing.T) {
+	r := httptest.NewRequest("GET", "http://upmeter.d8-upmeter:8091/public/api/feed", nil)
+	assert.Equal(t, "http://upmeter.d8-upmeter:8091", requestBaseURL(r))
+
+	r.Header.Set("X-Forwarded-Proto", "https")
+	r.Header.Set("X-Forwarded-Host", "status.example.com")
+	assert.Equal(t, "https://status.example.com", requestBaseURL(r))
+}
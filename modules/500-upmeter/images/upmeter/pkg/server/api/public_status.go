@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -36,6 +37,10 @@ import (
 type PublicStatusResponse struct {
 	Status PublicStatus  `json:"status"`
 	Rows   []GroupStatus `json:"rows"`
+	// Maintenance contains scheduled and active maintenance windows, the soonest first
+	Maintenance []entity.PublicEvent `json:"maintenance"`
+	// Incidents contains active and past incidents, the latest first
+	Incidents []entity.PublicEvent `json:"incidents"`
 }
 
 type GroupStatus struct {
@@ -70,13 +75,21 @@ func (h *PublicStatusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	maintenance, incidents, err := h.getEvents(time.Now())
+	if err != nil {
+		log.Errorf("Cannot get maintenance and incidents: %v\n", err)
+		maintenance, incidents = []entity.PublicEvent{}, []entity.PublicEvent{}
+	}
+
 	statuses, status, err := h.getStatusSummary()
 	if err != nil {
 		log.Errorf("Cannot get current status: %v\n", err)
 		// Skipping the error because the JSON structure is defined in advance.
 		out, _ := json.Marshal(&PublicStatusResponse{
-			Rows:   []GroupStatus{},
-			Status: "No data for last 15 min",
+			Rows:        []GroupStatus{},
+			Status:      "No data for last 15 min",
+			Maintenance: maintenance,
+			Incidents:   incidents,
 		})
 		w.WriteHeader(http.StatusOK)
 		w.Write(out)
@@ -85,12 +98,51 @@ func (h *PublicStatusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 
 	// Skipping the error because the JSON structure is defined in advance.
 	out, _ := json.Marshal(&PublicStatusResponse{
-		Rows:   statuses,
-		Status: status,
+		Rows:        statuses,
+		Status:      status,
+		Maintenance: maintenance,
+		Incidents:   incidents,
 	})
 	w.Write(out)
 }
 
+// getEvents returns maintenance windows that are not completed yet, and incidents within the history
+// window.
+func (h *PublicStatusHandler) getEvents(now time.Time) ([]entity.PublicEvent, []entity.PublicEvent, error) {
+	events, err := listPublicEvents(h.DowntimeMonitor, now)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	maintenance := make([]entity.PublicEvent, 0)
+	incidents := make([]entity.PublicEvent, 0)
+	for _, ev := range events {
+		switch {
+		case ev.Kind == entity.EventIncident:
+			incidents = append(incidents, ev)
+		case ev.State != entity.EventCompleted:
+			maintenance = append(maintenance, ev)
+		}
+	}
+
+	sort.SliceStable(maintenance, func(i, j int) bool {
+		return maintenance[i].Start.Before(maintenance[j].Start)
+	})
+
+	return maintenance, incidents, nil
+}
+
+// publicEventsHistory is how long completed maintenance windows and incidents are shown
+const publicEventsHistory = 30 * 24 * time.Hour
+
+func listPublicEvents(monitor *downtime.Monitor, now time.Time) ([]entity.PublicEvent, error) {
+	incidents, err := monitor.List()
+	if err != nil {
+		return nil, fmt.Errorf("cannot list downtimes: %v", err)
+	}
+	return entity.PublicEvents(incidents, now, publicEventsHistory), nil
+}
+
 // getStatusSummary returns total statuses for each group for the current partial 5m timeslot plus
 // previous full 5m timeslot.
 func (h *PublicStatusHandler) getStatusSummary() ([]GroupStatus, PublicStatus, error) {
@@ -0,0 +1,115 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"d8.io/upmeter/pkg/server/report"
+)
+
+// ReportHandler returns the availability report for a calendar month or quarter. Query parameters:
+//   - period: "2022-05" or "2022-Q2", required
+//   - format: "json" (default) or "csv"
+//   - table: "availability" (default) or "incidents", for CSV only
+//   - muteDowntimeTypes: the same as for the status range API
+type ReportHandler struct {
+	Generator *report.Generator
+}
+
+func (h *ReportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Infoln("Report", r.RemoteAddr, r.RequestURI)
+
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		fmt.Fprintf(w, "%d GET is required\n", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+
+	period, err := report.ParsePeriod(query.Get("period"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "%d Error: %s\n", http.StatusBadRequest, err)
+		return
+	}
+
+	muteTypes := parseDowntimeTypes(query.Get("muteDowntimeTypes"))
+	if len(muteTypes) == 0 {
+		muteTypes = []string{
+			"Maintenance",
+			"InfrastructureMaintenance",
+			"InfrastructureAccident",
+		}
+	}
+
+	format, table := query.Get("format"), query.Get("table")
+	if format == "" {
+		format = "json"
+	}
+	if table == "" {
+		table = "availability"
+	}
+	if format != "json" && format != "csv" {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "%d Error: unknown format %q\n", http.StatusBadRequest, format)
+		return
+	}
+	if table != "availability" && table != "incidents" {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "%d Error: unknown table %q\n", http.StatusBadRequest, table)
+		return
+	}
+
+	rep, err := h.Generator.Generate(period, muteTypes)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "%d Error: %s\n", http.StatusInternalServerError, err)
+		return
+	}
+
+	var (
+		buf         bytes.Buffer
+		contentType string
+	)
+	switch {
+	case format == "json":
+		contentType = "application/json"
+		err = json.NewEncoder(&buf).Encode(rep)
+	case table == "incidents":
+		contentType = "text/csv"
+		err = report.WriteIncidentsCSV(&buf, rep)
+	default:
+		contentType = "text/csv"
+		err = report.WriteCSV(&buf, rep)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "%d Error: %s\n", http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"d8.io/upmeter/pkg/server/slo"
+)
+
+// SLOHandler returns error budgets and burn rates for SLO objects. The optional 'name' query
+// parameter selects the single SLO.
+type SLOHandler struct {
+	Calculator *slo.Calculator
+}
+
+func (h *SLOHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Infoln("SLO", r.RemoteAddr, r.RequestURI)
+
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		fmt.Fprintf(w, "%d GET is required\n", http.StatusMethodNotAllowed)
+		return
+	}
+
+	reports, err := h.Calculator.Reports(time.Now())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "%d Error: %s\n", http.StatusInternalServerError, err)
+		return
+	}
+
+	if name := r.URL.Query().Get("name"); name != "" {
+		filtered := make([]*slo.Report, 0, 1)
+		for _, report := range reports {
+			if report.Name == name {
+				filtered = append(filtered, report)
+			}
+		}
+		reports = filtered
+	}
+
+	out, err := json.Marshal(reports)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "%d Error: %s\n", http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Write(out)
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package entity
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"d8.io/upmeter/pkg/check"
+)
+
+// Kinds of public events
+const (
+	EventMaintenance = "Maintenance"
+	EventIncident    = "Incident"
+)
+
+// States of public events
+const (
+	EventScheduled = "Scheduled"
+	EventActive    = "Active"
+	EventCompleted = "Completed"
+)
+
+// PublicEvent is a maintenance or an incident announced on the public status page. It is made of
+// a Downtime custom resource spec.
+type PublicEvent struct {
+	ID          string    `json:"id"`
+	Kind        string    `json:"kind"`
+	Type        string    `json:"type"`
+	State       string    `json:"state"`
+	Description string    `json:"description"`
+	Affected    []string  `json:"affected"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	// Updated is the time of the last known change of the event: creation of the Downtime for
+	// scheduled events, the start for active ones, and the end for completed ones.
+	Updated time.Time `json:"updated"`
+}
+
+// PublicEvents converts downtime incidents to public events. Only incidents marked as public are
+// published, events completed before the history window are skipped. Events are sorted by the update time, the latest first.
+func PublicEvents(incidents []check.DowntimeIncident, now time.Time, history time.Duration) []PublicEvent {
+	since := now.Add(-history)
+
+	events := make([]PublicEvent, 0, len(incidents))
+	for _, inc := range incidents {
+		if !inc.Public {
+			continue
+		}
+
+		start := time.Unix(inc.Start, 0).UTC()
+		end := time.Unix(inc.End, 0).UTC()
+		if end.Before(since) {
+			continue
+		}
+
+		ev := PublicEvent{
+			ID:          fmt.Sprintf("%s-%d", inc.DowntimeName, inc.Start),
+			Kind:        eventKind(inc.Type),
+			Type:        inc.Type,
+			Description: inc.Description,
+			Affected:    inc.Affected,
+			Start:       start,
+			End:         end,
+		}
+		if ev.Affected == nil {
+			ev.Affected = []string{}
+		}
+
+		switch {
+		case now.Before(start):
+			ev.State = EventScheduled
+			ev.Updated = time.Unix(inc.Created, 0).UTC()
+		case now.Before(end):
+			ev.State = EventActive
+			ev.Updated = start
+		default:
+			ev.State = EventCompleted
+			ev.Updated = end
+		}
+
+		events = append(events, ev)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Updated.Equal(events[j].Updated) {
+			return events[i].ID < events[j].ID
+		}
+		return events[i].Updated.After(events[j].Updated)
+	})
+
+	return events
+}
+
+func eventKind(downtimeType string) string {
+	switch downtimeType {
+	case "Maintenance", "InfrastructureMaintenance":
+		return EventMaintenance
+	default:
+		return EventIncident
+	}
+}
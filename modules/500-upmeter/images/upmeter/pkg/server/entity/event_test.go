@@ -0,0 +1,61 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package entity
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"d8.io/upmeter/pkg/check"
+)
+
+func Test_PublicEvents(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Unix(1000000, 0)
+	day := int64(24 * 3600)
+	n := now.Unix()
+
+	incidents := []check.DowntimeIncident{
+		{DowntimeName: "upgrade", Type: "Maintenance", Start: n + 3600, End: n + 7200, Created: n - 60, Affected: []string{"control-plane"}, Public: true},
+		{DowntimeName: "network", Type: "InfrastructureAccident", Start: n - 600, End: n + 600, Description: "Switch failure", Public: true},
+		{DowntimeName: "disk", Type: "Accident", Start: n - 2*day, End: n - day, Public: true},
+		{DowntimeName: "ancient", Type: "Accident", Start: n - 40*day, End: n - 39*day, Public: true},
+		{DowntimeName: "private", Type: "InfrastructureMaintenance", Start: n - 600, End: n + 600, Description: "Internal details"},
+	}
+
+	events := PublicEvents(incidents, now, 30*24*time.Hour)
+	g.Expect(events).To(HaveLen(3))
+
+	// The latest update first, the maintenance is announced after the incident started
+	g.Expect(events[0].ID).To(Equal("upgrade-1003600"))
+	g.Expect(events[0].Kind).To(Equal(EventMaintenance))
+	g.Expect(events[0].State).To(Equal(EventScheduled))
+	g.Expect(events[0].Updated).To(Equal(time.Unix(n-60, 0).UTC()))
+
+	g.Expect(events[1].ID).To(Equal("network-999400"))
+	g.Expect(events[1].Kind).To(Equal(EventIncident))
+	g.Expect(events[1].State).To(Equal(EventActive))
+	g.Expect(events[1].Description).To(Equal("Switch failure"))
+	g.Expect(events[1].Affected).To(Equal([]string{}))
+
+	g.Expect(events[2].Kind).To(Equal(EventIncident))
+	g.Expect(events[2].State).To(Equal(EventCompleted))
+	g.Expect(events[2].Updated).To(Equal(time.Unix(n-day, 0).UTC()))
+}
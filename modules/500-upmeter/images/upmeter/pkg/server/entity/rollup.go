@@ -0,0 +1,80 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"d8.io/upmeter/pkg/db"
+	dbcontext "d8.io/upmeter/pkg/db/context"
+	"d8.io/upmeter/pkg/db/dao"
+)
+
+// rollupLookback covers late updates of 5m episodes. They are calculated from 30s episodes which are
+// kept for a day.
+const rollupLookback = 25 * time.Hour
+
+// Retention is the time to keep episodes of each resolution. Zero duration means forever.
+type Retention struct {
+	Episodes5m time.Duration
+	Episodes1h time.Duration
+	Episodes1d time.Duration
+}
+
+// RollupEpisodes sums up recent 5m episodes into hourly ones, and hourly episodes into daily ones.
+func RollupEpisodes(dbCtx *dbcontext.DbContext, now time.Time) error {
+	from := now.Add(-rollupLookback)
+	return db.WithTx(dbCtx, func(tx *dbcontext.DbContext) error {
+		if err := dao.NewEpisodeDao1h(tx).Rollup(from, now); err != nil {
+			return err
+		}
+		return dao.NewEpisodeDao1d(tx).Rollup(from, now)
+	})
+}
+
+// DeleteExpiredEpisodes deletes episodes older than retention for each resolution.
+func DeleteExpiredEpisodes(dbCtx *dbcontext.DbContext, retention Retention, now time.Time) error {
+	type deleter interface {
+		DeleteUpTo(time.Time) error
+	}
+
+	ctx := dbCtx.Start()
+	defer ctx.Stop()
+
+	tables := []struct {
+		res       dao.Resolution
+		retention time.Duration
+		dao       deleter
+	}{
+		{dao.Resolution5m, retention.Episodes5m, dao.NewEpisodeDao5m(ctx)},
+		{dao.Resolution1h, retention.Episodes1h, dao.NewEpisodeDao1h(ctx)},
+		{dao.Resolution1d, retention.Episodes1d, dao.NewEpisodeDao1d(ctx)},
+	}
+
+	for _, t := range tables {
+		if t.retention <= 0 {
+			continue
+		}
+		deadline := now.Add(-t.retention).Truncate(t.res.Slot)
+		if err := t.dao.DeleteUpTo(deadline); err != nil {
+			return fmt.Errorf("cannot delete expired episodes from %s: %v", t.res.Table, err)
+		}
+	}
+
+	return nil
+}
@@ -89,8 +89,8 @@ func Statuses(dbctx *dbcontext.DbContext, ref check.ProbeRef, rng ranges.StepRan
 	daoCtx := dbctx.Start()
 	defer daoCtx.Stop()
 
-	dao5m := dao.NewEpisodeDao5m(daoCtx)
-	episodes, err := dao5m.ListEpisodeSumsForRanges(rng, ref)
+	// Long ranges are read from rolled up episodes
+	episodes, err := dao.ListEpisodeSumsForRanges(daoCtx, rng, ref)
 	if err != nil {
 		return nil, err
 	}
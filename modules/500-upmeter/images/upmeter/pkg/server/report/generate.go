@@ -0,0 +1,167 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"fmt"
+	"time"
+
+	"d8.io/upmeter/pkg/check"
+	dbcontext "d8.io/upmeter/pkg/db/context"
+	"d8.io/upmeter/pkg/db/dao"
+	"d8.io/upmeter/pkg/monitor/downtime"
+	"d8.io/upmeter/pkg/registry"
+	"d8.io/upmeter/pkg/server/entity"
+	"d8.io/upmeter/pkg/server/ranges"
+)
+
+// step is the size of subranges to calculate muting. Muting is calculated as the longest downtime
+// within a subrange, so the step is kept short, and daily episodes are read.
+const step = 24 * time.Hour
+
+// Generator builds reports from stored episodes and Downtime custom resources
+type Generator struct {
+	DbCtx           *dbcontext.DbContext
+	DowntimeMonitor *downtime.Monitor
+	ProbeLister     registry.ProbeLister
+}
+
+func (g *Generator) Generate(period Period, mutedTypes []string) (*Report, error) {
+	allIncidents, err := g.DowntimeMonitor.List()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get incidents: %v", err)
+	}
+
+	from, to := period.From.Unix(), period.To.Unix()
+	inPeriod := make([]check.DowntimeIncident, 0)
+	for _, inc := range allIncidents {
+		if inc.Start < to && inc.End > from {
+			inPeriod = append(inPeriod, inc)
+		}
+	}
+
+	rng := ranges.NewStepRange(from, to, int64(step.Seconds()))
+
+	rows := make([]Row, 0)
+	for _, group := range g.ProbeLister.Groups() {
+		groupRows, err := g.groupRows(group, rng, inPeriod, mutedTypes)
+		if err != nil {
+			return nil, fmt.Errorf("cannot calculate report for group %q: %v", group, err)
+		}
+		rows = append(rows, groupRows...)
+	}
+	sortRows(rows)
+
+	return &Report{
+		Period:     period.Name,
+		From:       period.From,
+		To:         period.To,
+		MutedTypes: mutedTypes,
+		Rows:       rows,
+		Incidents:  newIncidents(inPeriod, mutedTypes),
+	}, nil
+}
+
+func (g *Generator) groupRows(group string, rng ranges.StepRange, incidents []check.DowntimeIncident, mutedTypes []string) ([]Row, error) {
+	totals, err := g.totals(group, rng, filterIncidents(incidents, group, mutedTypes...))
+	if err != nil {
+		return nil, err
+	}
+
+	// Muted time is calculated for each type alone
+	mutedByType := make(map[string]map[string]time.Duration)
+	for _, t := range mutedTypes {
+		typed := filterIncidents(incidents, group, t)
+		if len(typed) == 0 {
+			continue
+		}
+		typedTotals, err := g.totals(group, rng, typed)
+		if err != nil {
+			return nil, err
+		}
+		for probe, summary := range typedTotals {
+			if _, ok := mutedByType[probe]; !ok {
+				mutedByType[probe] = make(map[string]time.Duration)
+			}
+			mutedByType[probe][t] = summary.Muted
+		}
+	}
+
+	rows := make([]Row, 0, len(totals))
+	for probe, summary := range totals {
+		row := Row{
+			Group:              group,
+			Probe:              probe,
+			UpSeconds:          seconds(summary.Up),
+			DownSeconds:        seconds(summary.Down),
+			UnknownSeconds:     seconds(summary.Unknown),
+			NoDataSeconds:      seconds(summary.NoData),
+			MutedSeconds:       seconds(summary.Muted),
+			MutedSecondsByType: make(map[string]int64),
+			Availability:       availability(summary.Up, summary.Down, summary.Muted),
+		}
+		for _, t := range mutedTypes {
+			row.MutedSecondsByType[t] = seconds(mutedByType[probe][t])
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// totals returns summaries for the whole range for probes of the group and the group total
+func (g *Generator) totals(group string, rng ranges.StepRange, incidents []check.DowntimeIncident) (map[string]entity.EpisodeSummary, error) {
+	res := make(map[string]entity.EpisodeSummary)
+
+	// Probe enumeration does not include the group total
+	for _, probe := range []string{dao.ProbeEnumeration, dao.GroupAggregation} {
+		ref := check.ProbeRef{Group: group, Probe: probe}
+		statuses, err := entity.Statuses(g.DbCtx, ref, rng, incidents)
+		if err != nil {
+			return nil, err
+		}
+		for probeName, summaries := range statuses[group] {
+			for _, summary := range summaries {
+				// The total column is the sum for the whole range
+				if summary.TimeSlot == -1 {
+					res[probeName] = summary
+				}
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// filterIncidents returns incidents of the types that affect the group
+func filterIncidents(incidents []check.DowntimeIncident, group string, types ...string) []check.DowntimeIncident {
+	res := make([]check.DowntimeIncident, 0)
+	for _, inc := range incidents {
+		if contains(types, inc.Type) && contains(inc.Affected, group) {
+			res = append(res, inc)
+		}
+	}
+	return res
+}
+
+func contains(xs []string, x string) bool {
+	for _, s := range xs {
+		if s == x {
+			return true
+		}
+	}
+	return false
+}
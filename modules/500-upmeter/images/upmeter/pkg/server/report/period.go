@@ -0,0 +1,58 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Period is a calendar month or quarter in UTC
+type Period struct {
+	Name string
+	From time.Time
+	To   time.Time
+}
+
+var (
+	monthRe   = regexp.MustCompile(`^(\d{4})-(\d{2})$`)
+	quarterRe = regexp.MustCompile(`^(\d{4})-Q([1-4])$`)
+)
+
+// ParsePeriod parses a month in the form of "2022-05" or a quarter in the form of "2022-Q2"
+func ParsePeriod(s string) (Period, error) {
+	if m := monthRe.FindStringSubmatch(s); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		if month < 1 || month > 12 {
+			return Period{}, fmt.Errorf("invalid month in period %q", s)
+		}
+		from := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+		return Period{Name: s, From: from, To: from.AddDate(0, 1, 0)}, nil
+	}
+
+	if m := quarterRe.FindStringSubmatch(s); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		quarter, _ := strconv.Atoi(m[2])
+		from := time.Date(year, time.Month(3*(quarter-1)+1), 1, 0, 0, 0, 0, time.UTC)
+		return Period{Name: s, From: from, To: from.AddDate(0, 3, 0)}, nil
+	}
+
+	return Period{}, fmt.Errorf("period %q must be a month like 2022-05 or a quarter like 2022-Q2", s)
+}
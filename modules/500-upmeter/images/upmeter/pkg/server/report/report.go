@@ -0,0 +1,207 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"d8.io/upmeter/pkg/check"
+	"d8.io/upmeter/pkg/db/dao"
+)
+
+// Report contains availability of groups and probes for a calendar period. Group totals are rows
+// with the "__total__" probe.
+type Report struct {
+	Period     string     `json:"period"`
+	From       time.Time  `json:"from"`
+	To         time.Time  `json:"to"`
+	MutedTypes []string   `json:"mutedTypes"`
+	Rows       []Row      `json:"rows"`
+	Incidents  []Incident `json:"incidents"`
+}
+
+type Row struct {
+	Group          string `json:"group"`
+	Probe          string `json:"probe"`
+	UpSeconds      int64  `json:"upSeconds"`
+	DownSeconds    int64  `json:"downSeconds"`
+	UnknownSeconds int64  `json:"unknownSeconds"`
+	NoDataSeconds  int64  `json:"nodataSeconds"`
+	MutedSeconds   int64  `json:"mutedSeconds"`
+	// MutedSecondsByType is calculated for each downtime type alone, so the sum can exceed
+	// MutedSeconds when downtimes of different types overlap.
+	MutedSecondsByType map[string]int64 `json:"mutedSecondsByType"`
+	// Availability in percent, nil when there is no data in the period
+	Availability *float64 `json:"availability"`
+}
+
+// Incident is a downtime that intersects with the period
+type Incident struct {
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	Description string    `json:"description"`
+	Affected    []string  `json:"affected"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Muted       bool      `json:"muted"`
+}
+
+// availability is calculated the same way as in the web UI: muted time counts as up time.
+func availability(up, down, muted time.Duration) *float64 {
+	if up+down+muted == 0 {
+		return nil
+	}
+	a := 100 * float64(up+muted) / float64(up+down+muted)
+	return &a
+}
+
+func newIncidents(incidents []check.DowntimeIncident, mutedTypes []string) []Incident {
+	muted := make(map[string]bool)
+	for _, t := range mutedTypes {
+		muted[t] = true
+	}
+
+	res := make([]Incident, 0, len(incidents))
+	for _, inc := range incidents {
+		affected := inc.Affected
+		if affected == nil {
+			affected = []string{}
+		}
+		res = append(res, Incident{
+			Name:        inc.DowntimeName,
+			Type:        inc.Type,
+			Description: inc.Description,
+			Affected:    affected,
+			Start:       time.Unix(inc.Start, 0).UTC(),
+			End:         time.Unix(inc.End, 0).UTC(),
+			Muted:       muted[inc.Type],
+		})
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Start.Before(res[j].Start)
+	})
+	return res
+}
+
+// sortRows orders rows by group, the group total goes first
+func sortRows(rows []Row) {
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Group != rows[j].Group {
+			return rows[i].Group < rows[j].Group
+		}
+		if rows[i].Probe == dao.GroupAggregation || rows[j].Probe == dao.GroupAggregation {
+			return rows[i].Probe == dao.GroupAggregation
+		}
+		return rows[i].Probe < rows[j].Probe
+	})
+}
+
+// WriteCSV writes availability rows as CSV
+func WriteCSV(w io.Writer, r *Report) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{"period", "group", "probe", "up_seconds", "down_seconds", "unknown_seconds", "nodata_seconds", "muted_seconds"}
+	for _, t := range r.MutedTypes {
+		header = append(header, "muted_"+toSnakeCase(t)+"_seconds")
+	}
+	header = append(header, "availability_percent")
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range r.Rows {
+		record := []string{
+			r.Period,
+			row.Group,
+			row.Probe,
+			strconv.FormatInt(row.UpSeconds, 10),
+			strconv.FormatInt(row.DownSeconds, 10),
+			strconv.FormatInt(row.UnknownSeconds, 10),
+			strconv.FormatInt(row.NoDataSeconds, 10),
+			strconv.FormatInt(row.MutedSeconds, 10),
+		}
+		for _, t := range r.MutedTypes {
+			record = append(record, strconv.FormatInt(row.MutedSecondsByType[t], 10))
+		}
+		avail := ""
+		if row.Availability != nil {
+			avail = strconv.FormatFloat(*row.Availability, 'f', 3, 64)
+		}
+		record = append(record, avail)
+
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteIncidentsCSV writes incidents as CSV
+func WriteIncidentsCSV(w io.Writer, r *Report) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{"period", "name", "type", "start", "end", "affected", "muted", "description"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, inc := range r.Incidents {
+		record := []string{
+			r.Period,
+			inc.Name,
+			inc.Type,
+			inc.Start.Format(time.RFC3339),
+			inc.End.Format(time.RFC3339),
+			strings.Join(inc.Affected, " "),
+			strconv.FormatBool(inc.Muted),
+			inc.Description,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// toSnakeCase converts downtime types like "InfrastructureMaintenance" to "infrastructure_maintenance"
+func toSnakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func seconds(d time.Duration) int64 {
+	return int64(d / time.Second)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"d8.io/upmeter/pkg/check"
+)
+
+func Test_ParsePeriod(t *testing.T) {
+	p, err := ParsePeriod("2022-05")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC), p.From)
+	assert.Equal(t, time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC), p.To)
+
+	p, err = ParsePeriod("2022-Q4")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC), p.From)
+	assert.Equal(t, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), p.To)
+
+	for _, invalid := range []string{"", "2022", "2022-13", "2022-Q5", "22-05"} {
+		_, err = ParsePeriod(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func Test_WriteCSV(t *testing.T) {
+	avail := 99.5
+	rep := &Report{
+		Period:     "2022-05",
+		MutedTypes: []string{"Maintenance", "InfrastructureAccident"},
+		Rows: []Row{
+			{
+				Group:              "control-plane",
+				Probe:              "__total__",
+				UpSeconds:          1990,
+				DownSeconds:        10,
+				MutedSeconds:       60,
+				MutedSecondsByType: map[string]int64{"Maintenance": 60},
+				Availability:       &avail,
+			},
+			{
+				Group:              "control-plane",
+				Probe:              "apiserver",
+				MutedSecondsByType: map[string]int64{},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, rep)
+	assert.NoError(t, err)
+
+	expected := "period,group,probe,up_seconds,down_seconds,unknown_seconds,nodata_seconds,muted_seconds,muted_maintenance_seconds,muted_infrastructure_accident_seconds,availability_percent\n" +
+		"2022-05,control-plane,__total__,1990,10,0,0,60,60,0,99.500\n" +
+		"2022-05,control-plane,apiserver,0,0,0,0,0,0,0,\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func Test_WriteIncidentsCSV(t *testing.T) {
+	start := time.Date(2022, 5, 3, 10, 0, 0, 0, time.UTC)
+	rep := &Report{
+		Period: "2022-05",
+		Incidents: newIncidents([]check.DowntimeIncident{
+			{
+				DowntimeName: "upgrade",
+				Type:         "Maintenance",
+				Description:  "Upgrade, planned",
+				Affected:     []string{"control-plane", "synthetic"},
+				Start:        start.Unix(),
+				End:          start.Add(time.Hour).Unix(),
+			},
+		}, []string{"Maintenance"}),
+	}
+
+	var buf bytes.Buffer
+	err := WriteIncidentsCSV(&buf, rep)
+	assert.NoError(t, err)
+
+	expected := "period,name,type,start,end,affected,muted,description\n" +
+		"2022-05,upgrade,Maintenance,2022-05-03T10:00:00Z,2022-05-03T11:00:00Z,control-plane synthetic,true,\"Upgrade, planned\"\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func Test_availability(t *testing.T) {
+	assert.Nil(t, availability(0, 0, 0))
+	assert.Equal(t, 100.0, *availability(time.Hour, 0, 0))
+	assert.Equal(t, 75.0, *availability(time.Hour, time.Hour, 2*time.Hour))
+}
+
+func Test_sortRows(t *testing.T) {
+	rows := []Row{
+		{Group: "b", Probe: "x"},
+		{Group: "a", Probe: "z"},
+		{Group: "a", Probe: "__total__"},
+		{Group: "a", Probe: "b"},
+	}
+	sortRows(rows)
+
+	assert.Equal(t, []Row{
+		{Group: "a", Probe: "__total__"},
+		{Group: "a", Probe: "b"},
+		{Group: "a", Probe: "z"},
+		{Group: "b", Probe: "x"},
+	}, rows)
+}
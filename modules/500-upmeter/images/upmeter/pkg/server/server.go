@@ -26,6 +26,8 @@ import (
 
 	kube "github.com/flant/kube-client/client"
 	_ "github.com/mattn/go-sqlite3"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	log "github.com/sirupsen/logrus"
 
 	"d8.io/upmeter/pkg/db"
@@ -33,12 +35,16 @@ import (
 	"d8.io/upmeter/pkg/db/dao"
 	"d8.io/upmeter/pkg/kubernetes"
 	"d8.io/upmeter/pkg/monitor/downtime"
+	slomonitor "d8.io/upmeter/pkg/monitor/slo"
 	"d8.io/upmeter/pkg/probe"
 	"d8.io/upmeter/pkg/probe/calculated"
 	"d8.io/upmeter/pkg/probe/checker"
 	"d8.io/upmeter/pkg/registry"
 	"d8.io/upmeter/pkg/server/api"
+	"d8.io/upmeter/pkg/server/entity"
 	"d8.io/upmeter/pkg/server/remotewrite"
+	"d8.io/upmeter/pkg/server/report"
+	"d8.io/upmeter/pkg/server/slo"
 )
 
 // server initializes all dependencies:
@@ -56,6 +62,7 @@ type Server struct {
 
 	server                *http.Server
 	downtimeMonitor       *downtime.Monitor
+	sloMonitor            *slomonitor.Monitor
 	remoteWriteController *remotewrite.Controller
 }
 
@@ -65,6 +72,7 @@ type Config struct {
 	UserAgent  string
 
 	DatabasePath string
+	Retention    entity.Retention
 
 	OriginsCount int
 
@@ -75,6 +83,8 @@ type Config struct {
 type DynamicProbesConfig struct {
 	IngressControllers []string
 	NodeGroups         []string
+	CustomProbes       []string
+	CalculatedProbes   []string
 }
 
 func NewConfig() *Config {
@@ -111,6 +121,16 @@ func (s *Server) Start(ctx context.Context) error {
 		return fmt.Errorf("cannot start downtimes.deckhouse.io monitor: %v", err)
 	}
 
+	// SLO CR monitor
+	s.sloMonitor, err = initSLOMonitor(ctx, kubeClient, s.logger)
+	if err != nil {
+		return fmt.Errorf("cannot start upmeterslos.deckhouse.io monitor: %v", err)
+	}
+	sloCalculator := &slo.Calculator{
+		Objectives: &slo.MonitorObjectives{Monitor: s.sloMonitor, Logger: log.NewEntry(s.logger)},
+		Source:     &slo.EpisodeSource{DbCtx: dbctx, DowntimeMonitor: s.downtimeMonitor},
+	}
+
 	// Metrics controller
 	s.remoteWriteController, err = initRemoteWriteController(ctx, dbctx, kubeClient, s.config.OriginsCount, s.logger, s.config.UserAgent)
 	if err != nil {
@@ -119,14 +139,18 @@ func (s *Server) Start(ctx context.Context) error {
 	}
 
 	go cleanOld30sEpisodes(ctx, dbctx)
+	go rollupEpisodes(ctx, dbctx, s.config.Retention)
 
 	// Probe lister that can only list groups and probes
-	probeLister := newProbeLister(s.config.DisabledProbes, s.config.DynamicProbes)
+	probeLister, err := newProbeLister(s.config.DisabledProbes, s.config.DynamicProbes, s.logger)
+	if err != nil {
+		return fmt.Errorf("cannot init probe lister: %v", err)
+	}
 
 	// Start http server. It blocks, that's why it is the last here.
 	s.logger.Debugf("starting HTTP server")
 	listenAddr := s.config.ListenHost + ":" + s.config.ListenPort
-	s.server = initHttpServer(dbctx, s.downtimeMonitor, s.remoteWriteController, probeLister, listenAddr)
+	s.server = initHttpServer(dbctx, s.downtimeMonitor, s.remoteWriteController, probeLister, sloCalculator, listenAddr, s.logger)
 
 	err = s.server.ListenAndServe()
 	if err == http.ErrServerClosed {
@@ -142,6 +166,7 @@ func (s *Server) Stop() error {
 		return err
 	}
 	s.remoteWriteController.Stop()
+	s.sloMonitor.Stop()
 	s.downtimeMonitor.Stop()
 
 	return nil
@@ -173,15 +198,47 @@ func cleanOld30sEpisodes(ctx context.Context, dbCtx *dbcontext.DbContext) {
 	}
 }
 
-func initHttpServer(dbCtx *dbcontext.DbContext, downtimeMonitor *downtime.Monitor, controller *remotewrite.Controller, probeLister registry.ProbeLister, addr string) *http.Server {
+func rollupEpisodes(ctx context.Context, dbCtx *dbcontext.DbContext, retention entity.Retention) {
+	period := time.Minute
+
+	ticker := time.NewTicker(period)
+
+	for {
+		select {
+		case <-ticker.C:
+			now := time.Now()
+			err := entity.RollupEpisodes(dbCtx, now)
+			if err != nil {
+				log.Errorf("cannot roll up episodes: %v", err)
+			}
+			err = entity.DeleteExpiredEpisodes(dbCtx, retention, now)
+			if err != nil {
+				log.Errorf("cannot clean expired episodes: %v", err)
+			}
+		case <-ctx.Done():
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+func initHttpServer(dbCtx *dbcontext.DbContext, downtimeMonitor *downtime.Monitor, controller *remotewrite.Controller, probeLister registry.ProbeLister, sloCalculator *slo.Calculator, addr string, logger *log.Logger) *http.Server {
 	mux := http.NewServeMux()
 
+	// Prometheus metrics
+	metricsRegistry := prometheus.NewRegistry()
+	metricsRegistry.MustRegister(slo.NewCollector(sloCalculator, log.NewEntry(logger)))
+	mux.Handle("/metrics", promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{}))
+
 	// Setup API handlers
 	mux.Handle("/api/probe", &api.ProbeListHandler{DbCtx: dbCtx, ProbeLister: probeLister})
 	mux.Handle("/api/status/range", &api.StatusRangeHandler{DbCtx: dbCtx, DowntimeMonitor: downtimeMonitor})
 	mux.Handle("/public/api/status", &api.PublicStatusHandler{DbCtx: dbCtx, DowntimeMonitor: downtimeMonitor, ProbeLister: probeLister})
+	mux.Handle("/public/api/feed", &api.PublicFeedHandler{DowntimeMonitor: downtimeMonitor})
 	mux.Handle("/downtime", &api.AddEpisodesHandler{DbCtx: dbCtx, RemoteWrite: controller})
 	mux.Handle("/stats", &api.StatsHandler{DbCtx: dbCtx})
+	mux.Handle("/api/slo", &api.SLOHandler{Calculator: sloCalculator})
+	mux.Handle("/api/report", &api.ReportHandler{Generator: &report.Generator{DbCtx: dbCtx, DowntimeMonitor: downtimeMonitor, ProbeLister: probeLister}})
 	// Kubernetes probes
 	mux.HandleFunc("/healthz", writeOk)
 	mux.HandleFunc("/ready", writeOk)
@@ -214,24 +271,39 @@ func initRemoteWriteController(ctx context.Context, dbCtx *dbcontext.DbContext,
 	return controller, controller.Start(ctx)
 }
 
+func initSLOMonitor(ctx context.Context, kubeClient kube.Client, logger *log.Logger) (*slomonitor.Monitor, error) {
+	m := slomonitor.NewMonitor(kubeClient, log.NewEntry(logger))
+	return m, m.Start(ctx)
+}
+
 func initDowntimeMonitor(ctx context.Context, kubeClient kube.Client, logger *log.Logger) (*downtime.Monitor, error) {
 	m := downtime.NewMonitor(kubeClient, log.NewEntry(logger))
 	return m, m.Start(ctx)
 }
 
-func newProbeLister(disabled []string, dynamic *DynamicProbesConfig) *registry.RegistryProbeLister {
+func newProbeLister(disabled []string, dynamic *DynamicProbesConfig, logger *log.Logger) (*registry.RegistryProbeLister, error) {
+	customProbes, err := probe.ParseCustomProbes(dynamic.CustomProbes)
+	if err != nil {
+		logger.Warnf("cannot parse custom probes: %v", err)
+	}
+	calculatedProbes, err := calculated.ParseDefinitions(dynamic.CalculatedProbes)
+	if err != nil {
+		logger.Warnf("cannot parse calculated probes: %v", err)
+	}
+
 	noLogger := newDummyLogger()
 	noFilter := probe.NewProbeFilter(disabled)
 	noAccess := kubernetes.FakeAccessor()
 	dynamicConfig := probe.DynamicConfig{
 		IngressNginxControllers: dynamic.IngressControllers,
 		NodeGroups:              dynamic.NodeGroups,
+		CustomProbes:            customProbes,
 	}
 	dummyDoer := checker.NoopDoer{}
 	runLoader := probe.NewLoader(noFilter, noAccess, nil, dynamicConfig, dummyDoer, noLogger)
-	calcLoader := calculated.NewLoader(noFilter, noLogger)
+	calcLoader := calculated.NewLoader(noFilter, calculatedProbes, noLogger)
 
-	return registry.NewProbeLister(runLoader, calcLoader)
+	return registry.NewProbeLister(runLoader, calcLoader), nil
 }
 
 func newDummyLogger() *log.Logger {
@@ -19,6 +19,7 @@ package server
 import (
 	"testing"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 
 	"d8.io/upmeter/pkg/check"
@@ -26,7 +27,8 @@ import (
 
 // Test how all the known probes and groups are presented
 func Test_newProbeLister(t *testing.T) {
-	pl := newProbeLister([]string{}, &DynamicProbesConfig{})
+	pl, err := newProbeLister([]string{}, &DynamicProbesConfig{}, log.New())
+	assert.NoError(t, err)
 
 	allProbesSorted := []check.ProbeRef{
 		{Group: "control-plane", Probe: "apiserver"},
@@ -73,18 +75,29 @@ func Test_newProbeLister(t *testing.T) {
 
 // Test how all the known probes and groups are presented including dynamic probes
 func Test_newProbeLister_with_dynamic(t *testing.T) {
-	pl := newProbeLister([]string{}, &DynamicProbesConfig{
+	pl, err := newProbeLister([]string{}, &DynamicProbesConfig{
 		IngressControllers: []string{"main", "main-w-pp"},
 		NodeGroups:         []string{"system", "frontend", "worker"},
-	})
+		CustomProbes: []string{
+			`{"name":"site","type":"HTTP","http":{"url":"https://example.com"}}`,
+			`{"name":"db","type":"TCP","tcp":{"address":"db.example.com:5432"}}`,
+		},
+		CalculatedProbes: []string{
+			`{"group":"checkout","probe":"service","probes":[{"ref":"custom/site"},{"ref":"custom/db"}]}`,
+		},
+	}, log.New())
+	assert.NoError(t, err)
 
 	allProbesSorted := []check.ProbeRef{
+		{Group: "checkout", Probe: "service"},
 		{Group: "control-plane", Probe: "apiserver"},
 		{Group: "control-plane", Probe: "basic-functionality"},
 		{Group: "control-plane", Probe: "cert-manager"},
 		{Group: "control-plane", Probe: "controller-manager"},
 		{Group: "control-plane", Probe: "namespace"},
 		{Group: "control-plane", Probe: "scheduler"},
+		{Group: "custom", Probe: "db"},
+		{Group: "custom", Probe: "site"},
 		{Group: "deckhouse", Probe: "cluster-configuration"},
 		{Group: "extensions", Probe: "cluster-autoscaler"},
 		{Group: "extensions", Probe: "cluster-scaling"},
@@ -114,7 +127,9 @@ func Test_newProbeLister_with_dynamic(t *testing.T) {
 	}
 
 	allGroupsSorted := []string{
+		"checkout",
 		"control-plane",
+		"custom",
 		"deckhouse",
 		"extensions",
 		"load-balancing",
@@ -0,0 +1,90 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slo
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	sloLabels = []string{"slo", "group", "probe"}
+
+	targetDesc = prometheus.NewDesc(
+		"upmeter_slo_target_ratio",
+		"The target availability of the SLO.",
+		sloLabels, nil)
+	availabilityDesc = prometheus.NewDesc(
+		"upmeter_slo_availability_ratio",
+		"The availability in the SLO window. Absent when there is no data.",
+		sloLabels, nil)
+	budgetRemainingDesc = prometheus.NewDesc(
+		"upmeter_slo_error_budget_remaining_ratio",
+		"The share of the error budget remaining in the SLO window, negative when the budget is exhausted.",
+		sloLabels, nil)
+	budgetRemainingSecondsDesc = prometheus.NewDesc(
+		"upmeter_slo_error_budget_remaining_seconds",
+		"The down time remaining in the SLO window.",
+		sloLabels, nil)
+	burnRateDesc = prometheus.NewDesc(
+		"upmeter_slo_burn_rate",
+		"The ratio of the error rate in the window to the allowed one.",
+		append(sloLabels, "window"), nil)
+)
+
+// Collector exports SLO reports as Prometheus metrics. Reports are calculated on scrape.
+type Collector struct {
+	calculator *Calculator
+	logger     *log.Entry
+}
+
+func NewCollector(calculator *Calculator, logger *log.Entry) *Collector {
+	return &Collector{calculator: calculator, logger: logger}
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- targetDesc
+	ch <- availabilityDesc
+	ch <- budgetRemainingDesc
+	ch <- budgetRemainingSecondsDesc
+	ch <- burnRateDesc
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	reports, err := c.calculator.Reports(time.Now())
+	if err != nil {
+		c.logger.Errorf("cannot calculate SLO reports: %v", err)
+		return
+	}
+
+	for _, r := range reports {
+		labels := []string{r.Name, r.Group, r.Probe}
+
+		ch <- prometheus.MustNewConstMetric(targetDesc, prometheus.GaugeValue, r.Target/100, labels...)
+		if r.Availability != nil {
+			ch <- prometheus.MustNewConstMetric(availabilityDesc, prometheus.GaugeValue, *r.Availability/100, labels...)
+		}
+		ch <- prometheus.MustNewConstMetric(budgetRemainingDesc, prometheus.GaugeValue, r.ErrorBudget.RemainingRatio, labels...)
+		ch <- prometheus.MustNewConstMetric(budgetRemainingSecondsDesc, prometheus.GaugeValue, r.ErrorBudget.RemainingSeconds, labels...)
+
+		for _, br := range r.BurnRates {
+			ch <- prometheus.MustNewConstMetric(burnRateDesc, prometheus.GaugeValue, br.Rate, append(labels, br.Window)...)
+		}
+	}
+}
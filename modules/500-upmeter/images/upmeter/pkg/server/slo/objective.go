@@ -0,0 +1,124 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slo
+
+import (
+	"fmt"
+	"time"
+
+	"d8.io/upmeter/pkg/check"
+	"d8.io/upmeter/pkg/db/dao"
+	slomonitor "d8.io/upmeter/pkg/monitor/slo"
+)
+
+const (
+	WindowRolling  = "Rolling"
+	WindowCalendar = "Calendar"
+
+	PeriodWeek  = "Week"
+	PeriodMonth = "Month"
+)
+
+const (
+	defaultRollingDays    = 30
+	defaultCalendarPeriod = PeriodMonth
+)
+
+// Objective is the validated service level objective
+type Objective struct {
+	Name   string
+	Group  string
+	Probe  string
+	Target float64 // percent
+	Window slomonitor.Window
+}
+
+// NewObjective validates the UpmeterSLO and fills defaults
+func NewObjective(obj slomonitor.UpmeterSLO) (*Objective, error) {
+	spec := obj.Spec
+
+	if spec.Group == "" {
+		return nil, fmt.Errorf("group is required")
+	}
+	if spec.Target <= 0 || spec.Target >= 100 {
+		return nil, fmt.Errorf("target must be between 0 and 100, got %v", spec.Target)
+	}
+
+	window := spec.Window
+	switch window.Type {
+	case WindowRolling:
+		if window.Days == 0 {
+			window.Days = defaultRollingDays
+		}
+		if window.Days < 0 {
+			return nil, fmt.Errorf("window days must be positive, got %d", window.Days)
+		}
+	case WindowCalendar:
+		if window.Period == "" {
+			window.Period = defaultCalendarPeriod
+		}
+		if window.Period != PeriodWeek && window.Period != PeriodMonth {
+			return nil, fmt.Errorf("unknown window period %q", window.Period)
+		}
+	default:
+		return nil, fmt.Errorf("unknown window type %q", window.Type)
+	}
+
+	return &Objective{
+		Name:   obj.Name,
+		Group:  spec.Group,
+		Probe:  spec.Probe,
+		Target: spec.Target,
+		Window: window,
+	}, nil
+}
+
+// Ref returns the probe reference, the group total is used when the probe is not specified
+func (o *Objective) Ref() check.ProbeRef {
+	probe := o.Probe
+	if probe == "" {
+		probe = dao.GroupAggregation
+	}
+	return check.ProbeRef{Group: o.Group, Probe: probe}
+}
+
+// budgetRatio is the allowed share of down time
+func (o *Objective) budgetRatio() float64 {
+	return 1 - o.Target/100
+}
+
+// Period returns the start and the end of the window containing the moment. The rolling window ends
+// with the end of the current episode slot.
+func (o *Objective) Period(now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+
+	if o.Window.Type == WindowRolling {
+		end := slotEnd(now)
+		return end.Add(-time.Duration(o.Window.Days) * 24 * time.Hour), end
+	}
+
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if o.Window.Period == PeriodWeek {
+		// Monday is the first day of the week
+		offset := (int(day.Weekday()) + 6) % 7
+		start := day.AddDate(0, 0, -offset)
+		return start, start.AddDate(0, 0, 7)
+	}
+
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0)
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slo
+
+import (
+	"time"
+
+	"d8.io/upmeter/pkg/check"
+	slomonitor "d8.io/upmeter/pkg/monitor/slo"
+)
+
+// slotSize is the resolution of stored episodes
+const slotSize = 5 * time.Minute
+
+// BurnRateWindows are windows of the multi-window burn rate alerting
+var BurnRateWindows = []BurnRateWindow{
+	{Name: "5m", Duration: 5 * time.Minute},
+	{Name: "30m", Duration: 30 * time.Minute},
+	{Name: "1h", Duration: time.Hour},
+	{Name: "2h", Duration: 2 * time.Hour},
+	{Name: "6h", Duration: 6 * time.Hour},
+	{Name: "1d", Duration: 24 * time.Hour},
+	{Name: "3d", Duration: 72 * time.Hour},
+}
+
+type BurnRateWindow struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Counters are the measured up and down time, muted time is not included
+type Counters struct {
+	Up   time.Duration
+	Down time.Duration
+}
+
+func (c Counters) known() time.Duration {
+	return c.Up + c.Down
+}
+
+// Source provides counters for the probe in the time range [from; to)
+type Source interface {
+	Counters(ref check.ProbeRef, from, to time.Time) (Counters, error)
+}
+
+type Report struct {
+	Name   string            `json:"name"`
+	Group  string            `json:"group"`
+	Probe  string            `json:"probe,omitempty"`
+	Target float64           `json:"target"`
+	Window slomonitor.Window `json:"window"`
+	From   string            `json:"from"`
+	To     string            `json:"to"`
+
+	// Availability in percent, nil when there is no data in the window
+	Availability *float64    `json:"availability"`
+	ErrorBudget  ErrorBudget `json:"errorBudget"`
+	BurnRates    []BurnRate  `json:"burnRates"`
+}
+
+type ErrorBudget struct {
+	// TotalSeconds is the allowed down time for the whole window
+	TotalSeconds     float64 `json:"totalSeconds"`
+	ConsumedSeconds  float64 `json:"consumedSeconds"`
+	RemainingSeconds float64 `json:"remainingSeconds"`
+	// RemainingRatio is negative when the budget is exhausted
+	RemainingRatio float64 `json:"remainingRatio"`
+}
+
+// BurnRate is the ratio of the error rate in the window to the allowed one. The burn rate of 1
+// consumes exactly the whole budget by the end of the SLO window.
+type BurnRate struct {
+	Window string  `json:"window"`
+	Rate   float64 `json:"rate"`
+}
+
+// Calculate builds the report for the objective at the moment
+func Calculate(o *Objective, source Source, now time.Time) (*Report, error) {
+	ref := o.Ref()
+
+	// Include the current incomplete slot
+	to := slotEnd(now)
+	from, end := o.Period(now)
+
+	counters, err := source.Counters(ref, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		Name:      o.Name,
+		Group:     o.Group,
+		Probe:     o.Probe,
+		Target:    o.Target,
+		Window:    o.Window,
+		From:      from.Format(time.RFC3339),
+		To:        end.Format(time.RFC3339),
+		BurnRates: make([]BurnRate, 0, len(BurnRateWindows)),
+	}
+
+	if counters.known() > 0 {
+		availability := 100 * counters.Up.Seconds() / counters.known().Seconds()
+		report.Availability = &availability
+	}
+
+	total := o.budgetRatio() * end.Sub(from).Seconds()
+	consumed := counters.Down.Seconds()
+	report.ErrorBudget = ErrorBudget{
+		TotalSeconds:     total,
+		ConsumedSeconds:  consumed,
+		RemainingSeconds: total - consumed,
+		RemainingRatio:   (total - consumed) / total,
+	}
+
+	for _, w := range BurnRateWindows {
+		c, err := source.Counters(ref, to.Add(-w.Duration), to)
+		if err != nil {
+			return nil, err
+		}
+		report.BurnRates = append(report.BurnRates, BurnRate{
+			Window: w.Name,
+			Rate:   burnRate(c, o.budgetRatio()),
+		})
+	}
+
+	return report, nil
+}
+
+func slotEnd(t time.Time) time.Time {
+	return t.UTC().Truncate(slotSize).Add(slotSize)
+}
+
+func burnRate(c Counters, budgetRatio float64) float64 {
+	if c.known() == 0 {
+		return 0
+	}
+	errorRate := c.Down.Seconds() / c.known().Seconds()
+	return errorRate / budgetRatio
+}
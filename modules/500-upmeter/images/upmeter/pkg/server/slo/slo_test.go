@@ -0,0 +1,157 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slo
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"d8.io/upmeter/pkg/check"
+	"d8.io/upmeter/pkg/db/dao"
+	slomonitor "d8.io/upmeter/pkg/monitor/slo"
+)
+
+func newSLO(name string, spec slomonitor.Spec) slomonitor.UpmeterSLO {
+	obj := slomonitor.UpmeterSLO{Spec: spec}
+	obj.Name = name
+	return obj
+}
+
+func Test_NewObjective(t *testing.T) {
+	o, err := NewObjective(newSLO("api", slomonitor.Spec{
+		Group:  "control-plane",
+		Target: 99.9,
+		Window: slomonitor.Window{Type: WindowRolling},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, 30, o.Window.Days)
+	assert.Equal(t, check.ProbeRef{Group: "control-plane", Probe: dao.GroupAggregation}, o.Ref())
+
+	o, err = NewObjective(newSLO("api", slomonitor.Spec{
+		Group:  "control-plane",
+		Probe:  "apiserver",
+		Target: 99,
+		Window: slomonitor.Window{Type: WindowCalendar},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, PeriodMonth, o.Window.Period)
+	assert.Equal(t, check.ProbeRef{Group: "control-plane", Probe: "apiserver"}, o.Ref())
+
+	invalid := []slomonitor.Spec{
+		{Target: 99, Window: slomonitor.Window{Type: WindowRolling}},
+		{Group: "g", Target: 100, Window: slomonitor.Window{Type: WindowRolling}},
+		{Group: "g", Target: 0, Window: slomonitor.Window{Type: WindowRolling}},
+		{Group: "g", Target: 99, Window: slomonitor.Window{Type: "Sliding"}},
+		{Group: "g", Target: 99, Window: slomonitor.Window{Type: WindowCalendar, Period: "Year"}},
+	}
+	for i, spec := range invalid {
+		_, err := NewObjective(newSLO(fmt.Sprintf("invalid-%d", i), spec))
+		assert.Error(t, err, "spec #%d", i)
+	}
+}
+
+func Test_Objective_Period(t *testing.T) {
+	// Wednesday
+	now := time.Date(2022, 3, 16, 10, 42, 0, 0, time.UTC)
+
+	cases := []struct {
+		window     slomonitor.Window
+		from, till time.Time
+	}{
+		{
+			window: slomonitor.Window{Type: WindowRolling, Days: 7},
+			from:   time.Date(2022, 3, 9, 10, 45, 0, 0, time.UTC),
+			till:   time.Date(2022, 3, 16, 10, 45, 0, 0, time.UTC),
+		},
+		{
+			window: slomonitor.Window{Type: WindowCalendar, Period: PeriodWeek},
+			from:   time.Date(2022, 3, 14, 0, 0, 0, 0, time.UTC),
+			till:   time.Date(2022, 3, 21, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			window: slomonitor.Window{Type: WindowCalendar, Period: PeriodMonth},
+			from:   time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC),
+			till:   time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.window.Type+tc.window.Period, func(t *testing.T) {
+			o := &Objective{Window: tc.window}
+			from, till := o.Period(now)
+			assert.Equal(t, tc.from, from)
+			assert.Equal(t, tc.till, till)
+		})
+	}
+}
+
+// fakeSource returns counters depending on the length of the requested range
+type fakeSource map[time.Duration]Counters
+
+func (s fakeSource) Counters(_ check.ProbeRef, from, to time.Time) (Counters, error) {
+	return s[to.Sub(from)], nil
+}
+
+func Test_Calculate(t *testing.T) {
+	now := time.Date(2022, 3, 16, 10, 42, 0, 0, time.UTC)
+	o := &Objective{
+		Name:   "api",
+		Group:  "control-plane",
+		Target: 99,
+		Window: slomonitor.Window{Type: WindowRolling, Days: 1},
+	}
+
+	source := fakeSource{
+		24 * time.Hour: {Up: 23 * time.Hour, Down: 6 * time.Minute},
+		time.Hour:      {Up: 54 * time.Minute, Down: 6 * time.Minute},
+	}
+
+	report, err := Calculate(o, source, now)
+	require.NoError(t, err)
+
+	require.NotNil(t, report.Availability)
+	assert.InDelta(t, 100*23*60/(23*60+6.0), *report.Availability, 1e-9)
+
+	// 1% of a day is 864 seconds, 360 seconds are consumed
+	assert.InDelta(t, 864, report.ErrorBudget.TotalSeconds, 1e-9)
+	assert.InDelta(t, 360, report.ErrorBudget.ConsumedSeconds, 1e-9)
+	assert.InDelta(t, 504, report.ErrorBudget.RemainingSeconds, 1e-9)
+	assert.InDelta(t, 504.0/864, report.ErrorBudget.RemainingRatio, 1e-9)
+
+	rates := make(map[string]float64)
+	for _, br := range report.BurnRates {
+		rates[br.Window] = br.Rate
+	}
+	assert.Len(t, rates, len(BurnRateWindows))
+	// 10% of errors in the last hour is 10 times the allowed rate
+	assert.InDelta(t, 10, rates["1h"], 1e-9)
+	// no data
+	assert.Equal(t, float64(0), rates["5m"])
+}
+
+func Test_Calculate_NoData(t *testing.T) {
+	o := &Objective{Group: "g", Target: 99.9, Window: slomonitor.Window{Type: WindowCalendar, Period: PeriodMonth}}
+
+	report, err := Calculate(o, fakeSource{}, time.Now())
+	require.NoError(t, err)
+	assert.Nil(t, report.Availability)
+	assert.Equal(t, float64(1), report.ErrorBudget.RemainingRatio)
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2022 Flant JSC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slo
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"d8.io/upmeter/pkg/check"
+	dbcontext "d8.io/upmeter/pkg/db/context"
+	"d8.io/upmeter/pkg/monitor/downtime"
+	slomonitor "d8.io/upmeter/pkg/monitor/slo"
+	"d8.io/upmeter/pkg/server/entity"
+	"d8.io/upmeter/pkg/server/ranges"
+)
+
+// mutingDowntimeTypes do not consume the error budget, the same types are muted in the status API
+var mutingDowntimeTypes = map[string]bool{
+	"Maintenance":               true,
+	"InfrastructureMaintenance": true,
+	"InfrastructureAccident":    true,
+}
+
+// EpisodeSource reads counters from 5m episodes respecting muting downtimes
+type EpisodeSource struct {
+	DbCtx           *dbcontext.DbContext
+	DowntimeMonitor *downtime.Monitor
+}
+
+func (s *EpisodeSource) Counters(ref check.ProbeRef, from, to time.Time) (Counters, error) {
+	rng := ranges.StepRange{
+		From:      from.Unix(),
+		To:        to.Unix(),
+		Step:      to.Unix() - from.Unix(),
+		Subranges: []ranges.Range{{From: from.Unix(), To: to.Unix()}},
+	}
+
+	allIncidents, err := s.DowntimeMonitor.List()
+	if err != nil {
+		return Counters{}, fmt.Errorf("cannot get incidents: %v", err)
+	}
+	incidents := make([]check.DowntimeIncident, 0)
+	for _, incident := range allIncidents {
+		if mutingDowntimeTypes[incident.Type] {
+			incidents = append(incidents, incident)
+		}
+	}
+
+	statuses, err := entity.Statuses(s.DbCtx, ref, rng, incidents)
+	if err != nil {
+		return Counters{}, err
+	}
+
+	var counters Counters
+	for _, summary := range statuses[ref.Group][ref.Probe] {
+		// The total column is the sum for the whole range
+		if summary.TimeSlot == -1 {
+			counters.Up = summary.Up
+			counters.Down = summary.Down
+		}
+	}
+
+	return counters, nil
+}
+
+// ObjectiveLister lists SLO objects
+type ObjectiveLister interface {
+	List() ([]Objective, error)
+}
+
+// MonitorObjectives lists valid objectives from UpmeterSLO objects, invalid objects are skipped
+type MonitorObjectives struct {
+	Monitor *slomonitor.Monitor
+	Logger  *log.Entry
+}
+
+func (m *MonitorObjectives) List() ([]Objective, error) {
+	objs, err := m.Monitor.List()
+	if err != nil {
+		return nil, err
+	}
+
+	objectives := make([]Objective, 0, len(objs))
+	for _, obj := range objs {
+		o, err := NewObjective(obj)
+		if err != nil {
+			m.Logger.Errorf("skipping UpmeterSLO %q: %v", obj.Name, err)
+			continue
+		}
+		objectives = append(objectives, *o)
+	}
+	return objectives, nil
+}
+
+// Calculator builds reports for all known objectives
+type Calculator struct {
+	Objectives ObjectiveLister
+	Source     Source
+}
+
+func (c *Calculator) Reports(now time.Time) ([]*Report, error) {
+	objectives, err := c.Objectives.List()
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]*Report, 0, len(objectives))
+	for i := range objectives {
+		report, err := Calculate(&objectives[i], c.Source, now)
+		if err != nil {
+			return nil, fmt.Errorf("calculating SLO %q: %v", objectives[i].Name, err)
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
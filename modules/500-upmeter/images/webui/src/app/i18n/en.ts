@@ -133,6 +133,14 @@ const langPack: LangPack = {
             <p>Group result is a combination of probe results with the priority of the worst results.</p>
             `,
     },
+    custom: {
+      ...GROUP_DEFAULT_TOOLTIP,
+      title: "Custom",
+      description: `
+            <p>Checks defined by UpmeterCustomProbe custom resources.</p>
+            <p>Group result is a combination of probe results with the priority of the worst results.</p>
+            `,
+    },
   },
   probe: {
     "control-plane": {
@@ -86,6 +86,10 @@ internal:
 smokeMini: { auth: {} }
 smokeMiniDisabled: false
 statusPageAuthDisabled: false
+retention:
+  fiveMinutesDays: 400
+  hourlyDays: 730
+  dailyDays: 0
 `
 
 var _ = Describe("Module :: upmeter :: helm template :: custom-certificate", func() {